@@ -264,6 +264,16 @@ func (h *Handler) listDocuments(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	exists, err := eventExists(r.Context(), h.db, eventID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to validate event")
+		return
+	}
+	if !exists {
+		httpx.Error(w, http.StatusNotFound, "event not found")
+		return
+	}
+
 	rows, err := h.db.Query(r.Context(), `
 		SELECT id, event_id, vendor_id, doc_type, status, document_number, document_date, due_date, currency, notes, created_at, updated_at
 		FROM accounting_documents
@@ -313,7 +323,7 @@ func (h *Handler) createDocument(w http.ResponseWriter, r *http.Request) {
 		Notes          string `json:"notes"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid JSON payload")
+		httpx.PayloadError(w, err, "invalid JSON payload")
 		return
 	}
 
@@ -385,7 +395,7 @@ func (h *Handler) updateDocument(w http.ResponseWriter, r *http.Request) {
 		Notes          string `json:"notes"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid JSON payload")
+		httpx.PayloadError(w, err, "invalid JSON payload")
 		return
 	}
 
@@ -452,6 +462,16 @@ func (h *Handler) listEntries(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	exists, err := eventExists(r.Context(), h.db, eventID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to validate event")
+		return
+	}
+	if !exists {
+		httpx.Error(w, http.StatusNotFound, "event not found")
+		return
+	}
+
 	rows, err := h.db.Query(r.Context(), `
 		SELECT id, document_id, event_id, schedule_item_cost_id, budget_line_item_id, entry_type, amount, currency, posted_at, description, created_at, updated_at
 		FROM accounting_entries
@@ -508,7 +528,7 @@ func (h *Handler) createEntry(w http.ResponseWriter, r *http.Request) {
 		Description        string  `json:"description"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid JSON payload")
+		httpx.PayloadError(w, err, "invalid JSON payload")
 		return
 	}
 
@@ -619,7 +639,7 @@ func (h *Handler) updateEntry(w http.ResponseWriter, r *http.Request) {
 		Description        string  `json:"description"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid JSON payload")
+		httpx.PayloadError(w, err, "invalid JSON payload")
 		return
 	}
 	entryType := strings.TrimSpace(payload.EntryType)
@@ -806,7 +826,7 @@ func (h *Handler) createPayment(w http.ResponseWriter, r *http.Request) {
 		Notes     string  `json:"notes"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid JSON payload")
+		httpx.PayloadError(w, err, "invalid JSON payload")
 		return
 	}
 	method := strings.TrimSpace(payload.Method)
@@ -869,7 +889,7 @@ func (h *Handler) updatePayment(w http.ResponseWriter, r *http.Request) {
 		Notes     string  `json:"notes"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid JSON payload")
+		httpx.PayloadError(w, err, "invalid JSON payload")
 		return
 	}
 	method := strings.TrimSpace(payload.Method)
@@ -954,6 +974,16 @@ func (h *Handler) listPayments(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	exists, err := eventExists(r.Context(), h.db, eventID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to validate event")
+		return
+	}
+	if !exists {
+		httpx.Error(w, http.StatusNotFound, "event not found")
+		return
+	}
+
 	rows, err := h.db.Query(r.Context(), `
 		SELECT id, event_id, vendor_id, method, amount, currency, paid_at, reference, notes, created_at, updated_at
 		FROM payments
@@ -999,7 +1029,7 @@ func (h *Handler) createPaymentAllocation(w http.ResponseWriter, r *http.Request
 		Currency           string  `json:"currency"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid JSON payload")
+		httpx.PayloadError(w, err, "invalid JSON payload")
 		return
 	}
 	if payload.Amount <= 0 {
@@ -1094,6 +1124,16 @@ func (h *Handler) listAllocations(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	exists, err := eventExists(r.Context(), h.db, eventID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to validate event")
+		return
+	}
+	if !exists {
+		httpx.Error(w, http.StatusNotFound, "event not found")
+		return
+	}
+
 	rows, err := h.db.Query(r.Context(), `
 		SELECT pa.id, pa.payment_id, pa.accounting_entry_id, pa.schedule_item_cost_id, pa.amount, pa.currency, pa.created_at, pa.updated_at
 		FROM payment_allocations pa
@@ -1956,7 +1996,7 @@ func (h *Handler) createScheduleCost(w http.ResponseWriter, r *http.Request) {
 		Notes           string  `json:"notes"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid JSON payload")
+		httpx.PayloadError(w, err, "invalid JSON payload")
 		return
 	}
 	if payload.EstimatedAmount < 0 {
@@ -2033,7 +2073,7 @@ func (h *Handler) updateScheduleCost(w http.ResponseWriter, r *http.Request) {
 		Notes           string  `json:"notes"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid JSON payload")
+		httpx.PayloadError(w, err, "invalid JSON payload")
 		return
 	}
 	if payload.EstimatedAmount < 0 {
@@ -0,0 +1,180 @@
+// Package adminaudit guards the most sensitive admin operations. Every call
+// through a Guard is written to the admin_actions table, whether it
+// succeeded, failed or was throttled, and each actor is held to a tight rate
+// limit that is independent of any other request limits.
+package adminaudit
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/innhopp/central/backend/httpx"
+	"github.com/innhopp/central/backend/internal/clock"
+)
+
+// Defaults used when the corresponding environment variable is unset.
+const (
+	DefaultLimit     = 20
+	DefaultWindow    = time.Minute
+	DefaultRetention = 365 * 24 * time.Hour
+)
+
+// MinRetention is the shortest time audit entries are kept, whatever is
+// configured, so a typo cannot wipe the trail.
+const MinRetention = 30 * 24 * time.Hour
+
+// Actor identifies who performed an admin action. ImpersonatorID is set when
+// an admin acts through an impersonated session.
+type Actor struct {
+	AccountID      int64
+	Email          string
+	ImpersonatorID *int64
+}
+
+// ActorResolver extracts the actor for the current request.
+type ActorResolver func(r *http.Request) Actor
+
+// Execer is the subset of pgx used to write audit entries.
+type Execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// Guard rate-limits and audits admin actions.
+type Guard struct {
+	db     Execer
+	actor  ActorResolver
+	limit  int
+	window time.Duration
+	clock  clock.Clock
+
+	mu      sync.Mutex
+	windows map[string]*actorWindow
+}
+
+type actorWindow struct {
+	start time.Time
+	count int
+}
+
+// NewGuard creates a guard allowing each actor limit actions per window.
+func NewGuard(db Execer, actor ActorResolver, limit int, window time.Duration) *Guard {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Guard{db: db, actor: actor, limit: limit, window: window, clock: clock.Real{}, windows: make(map[string]*actorWindow)}
+}
+
+// Action wraps an admin-mutating route. name is recorded with each entry,
+// e.g. "accounts.approve". A nil guard passes requests through untouched.
+func (g *Guard) Action(name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if g == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			actor := g.actor(r)
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			if retryAfter, ok := g.allow(actorKey(actor, r)); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds()+0.5)))
+				httpx.Error(rec, http.StatusTooManyRequests, "too many admin actions; try again later")
+			} else {
+				next.ServeHTTP(rec, r)
+			}
+			g.record(r, name, actor, rec.status)
+		})
+	}
+}
+
+// allow counts an action against key's fixed window. When the window is full
+// it returns how long until the next one opens.
+func (g *Guard) allow(key string) (time.Duration, bool) {
+	now := g.clock.Now()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for k, w := range g.windows {
+		if now.Sub(w.start) >= g.window {
+			delete(g.windows, k)
+		}
+	}
+	w, ok := g.windows[key]
+	if !ok {
+		w = &actorWindow{start: now}
+		g.windows[key] = w
+	}
+	if w.count >= g.limit {
+		return w.start.Add(g.window).Sub(now), false
+	}
+	w.count++
+	return 0, true
+}
+
+func (g *Guard) record(r *http.Request, name string, actor Actor, status int) {
+	var accountID *int64
+	if actor.AccountID > 0 {
+		accountID = &actor.AccountID
+	}
+	// The request context may already be cancelled; the entry must still be
+	// written.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), 5*time.Second)
+	defer cancel()
+	if _, err := g.db.Exec(ctx, `
+		INSERT INTO admin_actions (actor_account_id, actor_email, impersonator_account_id, action, method, path, status, remote_addr)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		accountID, actor.Email, actor.ImpersonatorID, name, r.Method, r.URL.Path, status, r.RemoteAddr,
+	); err != nil {
+		log.Printf("adminaudit: failed to record %s by account %d (status %d): %v", name, actor.AccountID, status, err)
+	}
+}
+
+// Prune deletes audit entries older than retention and returns how many were
+// removed. A non-positive retention uses the default, and one below
+// MinRetention is raised to it.
+func Prune(ctx context.Context, db Execer, retention time.Duration) (int64, error) {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	if retention < MinRetention {
+		retention = MinRetention
+	}
+	tag, err := db.Exec(ctx, `DELETE FROM admin_actions WHERE created_at < NOW() - make_interval(secs => $1)`, retention.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func actorKey(actor Actor, r *http.Request) string {
+	if actor.AccountID > 0 {
+		return "account:" + strconv.FormatInt(actor.AccountID, 10)
+	}
+	return "addr:" + r.RemoteAddr
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if !s.wroteHeader {
+		s.status = status
+		s.wroteHeader = true
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(p []byte) (int, error) {
+	s.wroteHeader = true
+	return s.ResponseWriter.Write(p)
+}
@@ -0,0 +1,107 @@
+package adminaudit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/innhopp/central/backend/internal/clock"
+)
+
+type recordingExecer struct {
+	statuses []int
+}
+
+func (e *recordingExecer) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	e.statuses = append(e.statuses, args[6].(int))
+	return pgconn.CommandTag{}, nil
+}
+
+func TestGuardAuditsEveryCallAndThrottlesPerActor(t *testing.T) {
+	db := &recordingExecer{}
+	actorID := int64(1)
+	guard := NewGuard(db, func(*http.Request) Actor { return Actor{AccountID: actorID} }, 2, time.Minute)
+	start := time.Date(2025, 6, 14, 9, 0, 0, 0, time.UTC)
+	guard.clock = clock.Fixed{At: start}
+
+	handler := guard.Action("accounts.approve")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	call := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/accounts/5/approve", nil))
+		return rec
+	}
+
+	call()
+	call()
+	rec := call()
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "60" {
+		t.Fatalf("third call status = %d Retry-After = %q, want 429 and 60", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	actorID = 2
+	if rec := call(); rec.Code != http.StatusNoContent {
+		t.Fatalf("other actor status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+
+	actorID = 1
+	guard.clock = clock.Fixed{At: start.Add(time.Minute)}
+	if rec := call(); rec.Code != http.StatusNoContent {
+		t.Fatalf("call in next window status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+
+	want := []int{http.StatusNoContent, http.StatusNoContent, http.StatusTooManyRequests, http.StatusNoContent, http.StatusNoContent}
+	if len(db.statuses) != len(want) {
+		t.Fatalf("recorded %v, want %v", db.statuses, want)
+	}
+	for i := range want {
+		if db.statuses[i] != want[i] {
+			t.Fatalf("recorded %v, want %v", db.statuses, want)
+		}
+	}
+}
+
+func TestNilGuardPassesThrough(t *testing.T) {
+	var guard *Guard
+	called := false
+	guard.Action("noop")(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { called = true })).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", nil))
+	if !called {
+		t.Fatal("nil guard did not call the wrapped handler")
+	}
+}
+
+type pruneExecer struct {
+	secs float64
+}
+
+func (e *pruneExecer) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	e.secs = args[0].(float64)
+	return pgconn.NewCommandTag("DELETE 3"), nil
+}
+
+func TestPruneKeepsAtLeastTheMinimumRetention(t *testing.T) {
+	tests := []struct {
+		retention time.Duration
+		want      time.Duration
+	}{
+		{0, DefaultRetention},
+		{time.Hour, MinRetention},
+		{400 * 24 * time.Hour, 400 * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		db := &pruneExecer{}
+		removed, err := Prune(context.Background(), db, tt.retention)
+		if err != nil || removed != 3 {
+			t.Fatalf("Prune(%v) = %d, %v; want 3, nil", tt.retention, removed, err)
+		}
+		if db.secs != tt.want.Seconds() {
+			t.Errorf("Prune(%v) kept %v, want %v", tt.retention, time.Duration(db.secs)*time.Second, tt.want)
+		}
+	}
+}
@@ -3,7 +3,7 @@ package airfields
 import "time"
 
 // Airfield represents a landing site with location and basic metadata.
-// Elevation is stored in meters; coordinates are stored as raw strings (lat/long).
+// Elevation is stored in meters; latitude and longitude are stored as decimal-degree strings.
 type Airfield struct {
 	ID          int64     `json:"id"`
 	Name        string    `json:"name"`
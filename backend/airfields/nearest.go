@@ -0,0 +1,110 @@
+package airfields
+
+import (
+	"context"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/innhopp/central/backend/internal/geo"
+)
+
+// defaultSuggestRadiusKm is how close an airfield must be to an innhopp to be
+// suggested as its takeoff airfield when INNHOPP_AIRFIELD_SUGGEST_KM is unset.
+const defaultSuggestRadiusKm = 5.0
+
+// SuggestRadiusKmFromEnv reads INNHOPP_AIRFIELD_SUGGEST_KM, the distance within
+// which the nearest airfield is suggested as an innhopp's takeoff airfield.
+// Zero turns suggestions off.
+func SuggestRadiusKmFromEnv() float64 {
+	raw := strings.TrimSpace(os.Getenv("INNHOPP_AIRFIELD_SUGGEST_KM"))
+	if raw == "" {
+		return defaultSuggestRadiusKm
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || value < 0 {
+		log.Printf("airfields: ignoring invalid INNHOPP_AIRFIELD_SUGGEST_KM %q", raw)
+		return defaultSuggestRadiusKm
+	}
+	return value
+}
+
+// Querier is the part of a pool or transaction Nearest needs.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// kmPerDegree is the length of one degree of latitude.
+const kmPerDegree = 6371.0 * math.Pi / 180
+
+// decimalDegrees matches the canonical positions airfields are stored in.
+const decimalDegrees = `'^-?[0-9]+(\.[0-9]+)?$'`
+
+// SuggestTakeoff returns the airfield to suggest as the takeoff airfield of an
+// innhopp at coordinates: the nearest one within radiusKm, or nil when the
+// innhopp already has takeoffID, its coordinates cannot be read or no
+// airfield is close enough.
+func SuggestTakeoff(ctx context.Context, db Querier, coordinates string, takeoffID *int64, radiusKm float64) (*int64, error) {
+	if takeoffID != nil || radiusKm <= 0 {
+		return nil, nil
+	}
+	lat, lng, ok := geo.ParseCoordinates(coordinates)
+	if !ok {
+		return nil, nil
+	}
+	return Nearest(ctx, db, lat, lng, radiusKm)
+}
+
+// Nearest returns the id of the airfield closest to lat, lng that is no more
+// than radiusKm away, or nil when none is. Only airfields inside the bounding
+// box of the radius are read; the longitude bound is dropped near the poles
+// and the antimeridian, where the box would wrap. Airfields whose position is
+// not in decimal degrees are skipped.
+func Nearest(ctx context.Context, db Querier, lat, lng, radiusKm float64) (*int64, error) {
+	if radiusKm <= 0 {
+		return nil, nil
+	}
+	latSpan := radiusKm / kmPerDegree
+	var minLng, maxLng *float64
+	if cos := math.Cos(lat * math.Pi / 180); cos > 0.01 {
+		lngSpan := radiusKm / (kmPerDegree * cos)
+		if lng-lngSpan >= -180 && lng+lngSpan <= 180 {
+			low, high := lng-lngSpan, lng+lngSpan
+			minLng, maxLng = &low, &high
+		}
+	}
+	rows, err := db.Query(ctx, `
+		SELECT id, latitude, longitude
+		FROM airfields
+		WHERE CASE WHEN latitude ~ `+decimalDegrees+` THEN latitude::float8 END BETWEEN $1::float8 AND $2::float8
+		  AND CASE WHEN longitude ~ `+decimalDegrees+` THEN longitude::float8 END BETWEEN COALESCE($3::float8, -180) AND COALESCE($4::float8, 180)`,
+		lat-latSpan, lat+latSpan, minLng, maxLng,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var nearest *int64
+	best := radiusKm
+	for rows.Next() {
+		var id int64
+		var latitude, longitude string
+		if err := rows.Scan(&id, &latitude, &longitude); err != nil {
+			return nil, err
+		}
+		airfieldLat, airfieldLng, ok := geo.ParseCoordinates(latitude + " " + longitude)
+		if !ok {
+			continue
+		}
+		if distance := geo.DistanceKm(lat, lng, airfieldLat, airfieldLng); distance <= best {
+			best = distance
+			nearest = &id
+		}
+	}
+	return nearest, rows.Err()
+}
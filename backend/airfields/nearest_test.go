@@ -0,0 +1,131 @@
+package airfields
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeQuerier answers every query with rows and records the arguments.
+type fakeQuerier struct {
+	rows  [][]any
+	calls [][]any
+}
+
+func (f *fakeQuerier) Query(_ context.Context, _ string, args ...any) (pgx.Rows, error) {
+	f.calls = append(f.calls, args)
+	return &fakeRows{rows: f.rows, index: -1}, nil
+}
+
+type fakeRows struct {
+	rows  [][]any
+	index int
+}
+
+func (r *fakeRows) Close()                                       {}
+func (r *fakeRows) Err() error                                   { return nil }
+func (r *fakeRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (r *fakeRows) RawValues() [][]byte                          { return nil }
+func (r *fakeRows) Conn() *pgx.Conn                              { return nil }
+func (r *fakeRows) Values() ([]any, error)                       { return r.rows[r.index], nil }
+
+func (r *fakeRows) Next() bool {
+	r.index++
+	return r.index < len(r.rows)
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	row := r.rows[r.index]
+	if len(row) != len(dest) {
+		return errors.New("fake rows: column count mismatch")
+	}
+	for i, value := range row {
+		switch d := dest[i].(type) {
+		case *int64:
+			*d = value.(int64)
+		case *string:
+			*d = value.(string)
+		default:
+			return fmt.Errorf("fake rows: cannot scan into %T", dest[i])
+		}
+	}
+	return nil
+}
+
+func TestNearestPicksClosestAirfieldWithinRadius(t *testing.T) {
+	db := &fakeQuerier{rows: [][]any{
+		{int64(1), "60.42", "5.36"},
+		{int64(2), "60.401", "5.33"},
+		{int64(3), "not a", "position"},
+	}}
+	nearest, err := Nearest(context.Background(), db, 60.3913, 5.3221, 5)
+	if err != nil || nearest == nil || *nearest != 2 {
+		t.Fatalf("Nearest() = %v, %v; want airfield 2", nearest, err)
+	}
+
+	args := db.calls[0]
+	minLat, maxLat := args[0].(float64), args[1].(float64)
+	if minLat > 60.35 || minLat < 60.34 || maxLat < 60.43 || maxLat > 60.44 {
+		t.Fatalf("latitude bounds = %v..%v, want about 0.045 degrees either side of 60.3913", minLat, maxLat)
+	}
+	if minLng, ok := args[2].(*float64); !ok || minLng == nil || *minLng > 5.24 || *minLng < 5.22 {
+		t.Fatalf("longitude lower bound = %v, want one about 0.09 degrees west", args[2])
+	}
+}
+
+func TestNearestHonoursRadius(t *testing.T) {
+	// 60.42 5.36 is about 4 km from the innhopp; the bounding box lets it
+	// through at 3 km, but the distance check must not.
+	db := &fakeQuerier{rows: [][]any{{int64(1), "60.42", "5.36"}}}
+	if nearest, err := Nearest(context.Background(), db, 60.3913, 5.3221, 3); err != nil || nearest != nil {
+		t.Fatalf("Nearest(3 km) = %v, %v; want none", nearest, err)
+	}
+	if nearest, err := Nearest(context.Background(), db, 60.3913, 5.3221, 5); err != nil || nearest == nil {
+		t.Fatalf("Nearest(5 km) = %v, %v; want airfield 1", nearest, err)
+	}
+
+	db = &fakeQuerier{}
+	if nearest, err := Nearest(context.Background(), db, 60.3913, 5.3221, 0); err != nil || nearest != nil || len(db.calls) != 0 {
+		t.Fatalf("Nearest(0 km) = %v, %v after %d queries; want none without a query", nearest, err, len(db.calls))
+	}
+}
+
+func TestNearestDropsLongitudeBoundAcrossAntimeridian(t *testing.T) {
+	db := &fakeQuerier{}
+	if _, err := Nearest(context.Background(), db, -17.7, 179.99, 5); err != nil {
+		t.Fatalf("Nearest() returned error: %v", err)
+	}
+	if minLng := db.calls[0][2].(*float64); minLng != nil {
+		t.Fatalf("longitude lower bound = %v, want none where the box wraps", *minLng)
+	}
+}
+
+func TestSuggestTakeoff(t *testing.T) {
+	db := &fakeQuerier{rows: [][]any{{int64(2), "60.401", "5.33"}}}
+	suggested, err := SuggestTakeoff(context.Background(), db, "60.3913 5.3221", nil, 5)
+	if err != nil || suggested == nil || *suggested != 2 {
+		t.Fatalf("SuggestTakeoff() = %v, %v; want airfield 2", suggested, err)
+	}
+
+	chosen := int64(1)
+	for name, tc := range map[string]struct {
+		coordinates string
+		takeoffID   *int64
+		radiusKm    float64
+	}{
+		"takeoff already chosen": {"60.3913 5.3221", &chosen, 5},
+		"unreadable coordinates": {"somewhere", nil, 5},
+		"suggestions off":        {"60.3913 5.3221", nil, 0},
+	} {
+		db := &fakeQuerier{rows: [][]any{{int64(2), "60.401", "5.33"}}}
+		suggested, err := SuggestTakeoff(context.Background(), db, tc.coordinates, tc.takeoffID, tc.radiusKm)
+		if err != nil || suggested != nil || len(db.calls) != 0 {
+			t.Fatalf("%s: SuggestTakeoff() = %v, %v after %d queries; want none without a query", name, suggested, err, len(db.calls))
+		}
+	}
+}
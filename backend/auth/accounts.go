@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/innhopp/central/backend/httpx"
+)
+
+// lastSeenInterval throttles last_seen_at writes to one per account per
+// interval.
+const lastSeenInterval = 5 * time.Minute
+
+// lastSeenWriteTimeout bounds one last_seen_at write. The write runs after the
+// request that triggered it, so it cannot use the request's context.
+const lastSeenWriteTimeout = 5 * time.Second
+
+const defaultDormantDays = 90
+
+// AccountActivity summarizes an account and when it was last used.
+type AccountActivity struct {
+	ID          int64      `json:"id"`
+	Email       string     `json:"email"`
+	FullName    string     `json:"full_name"`
+	Roles       []string   `json:"roles"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	LastSeenAt  *time.Time `json:"last_seen_at,omitempty"`
+}
+
+// lastSeenTracker records account activity, skipping writes for accounts that
+// were already recorded within the throttle interval.
+type lastSeenTracker struct {
+	db       *pgxpool.Pool
+	interval time.Duration
+
+	mu   sync.Mutex
+	seen map[int64]time.Time
+	// writes tracks the last_seen_at updates still running.
+	writes sync.WaitGroup
+}
+
+func newLastSeenTracker(db *pgxpool.Pool, interval time.Duration) *lastSeenTracker {
+	return &lastSeenTracker{db: db, interval: interval, seen: make(map[int64]time.Time)}
+}
+
+func (t *lastSeenTracker) due(accountID int64, at time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if last, ok := t.seen[accountID]; ok && at.Sub(last) < t.interval {
+		return false
+	}
+	t.seen[accountID] = at
+	return true
+}
+
+// touch records activity without holding up the request: the write runs in
+// the background and a failure is only logged.
+func (t *lastSeenTracker) touch(_ context.Context, accountID int64, at time.Time) {
+	if !t.due(accountID, at) {
+		return
+	}
+	t.writes.Add(1)
+	go func() {
+		defer t.writes.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), lastSeenWriteTimeout)
+		defer cancel()
+		if _, err := t.db.Exec(ctx, `UPDATE accounts SET last_seen_at = $2 WHERE id = $1`, accountID, at); err != nil {
+			log.Printf("failed to record last seen for account %d: %v", accountID, err)
+		}
+	}()
+}
+
+// prune forgets accounts last recorded an interval or more before now, whose
+// next activity is due anyway, so the map only holds recently active accounts.
+func (t *lastSeenTracker) prune(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for accountID, last := range t.seen {
+		if now.Sub(last) >= t.interval {
+			delete(t.seen, accountID)
+		}
+	}
+}
+
+func (t *lastSeenTracker) len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.seen)
+}
+
+func (h *Handler) recordLogin(ctx context.Context, accountID int64) error {
+	_, err := h.db.Exec(ctx,
+		`UPDATE accounts SET last_login_at = $2, last_seen_at = $2 WHERE id = $1`,
+		accountID, h.sessions.clock.Now(),
+	)
+	return err
+}
+
+const accountActivitySelect = `
+	SELECT a.id, a.email, COALESCE(a.full_name, ''), a.created_at, a.last_login_at, a.last_seen_at,
+	       COALESCE(array_agg(ar.role_name ORDER BY ar.role_name) FILTER (WHERE ar.role_name IS NOT NULL), '{}')
+	FROM accounts a
+	LEFT JOIN account_roles ar ON ar.account_id = a.id`
+
+func (h *Handler) listAccounts(w http.ResponseWriter, r *http.Request) {
+	h.writeAccountActivity(w, r, accountActivitySelect+`
+	GROUP BY a.id
+	ORDER BY a.id ASC`)
+}
+
+func (h *Handler) listDormantAccounts(w http.ResponseWriter, r *http.Request) {
+	days := defaultDormantDays
+	if raw := strings.TrimSpace(r.URL.Query().Get("days")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			httpx.Error(w, http.StatusBadRequest, "days must be a positive integer")
+			return
+		}
+		days = parsed
+	}
+
+	cutoff := h.sessions.clock.Now().AddDate(0, 0, -days)
+	h.writeAccountActivity(w, r, accountActivitySelect+`
+	WHERE COALESCE(a.last_seen_at, a.last_login_at, a.created_at) < $1
+	GROUP BY a.id
+	ORDER BY COALESCE(a.last_seen_at, a.last_login_at, a.created_at) ASC, a.id ASC`, cutoff)
+}
+
+func (h *Handler) writeAccountActivity(w http.ResponseWriter, r *http.Request, query string, args ...any) {
+	rows, err := h.db.Query(r.Context(), query, args...)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to list accounts")
+		return
+	}
+	defer rows.Close()
+
+	accounts := []AccountActivity{}
+	for rows.Next() {
+		var a AccountActivity
+		if err := rows.Scan(&a.ID, &a.Email, &a.FullName, &a.CreatedAt, &a.LastLoginAt, &a.LastSeenAt, &a.Roles); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to parse account")
+			return
+		}
+		accounts = append(accounts, a)
+	}
+	if err := rows.Err(); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to list accounts")
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, accounts)
+}
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/innhopp/central/backend/httpx"
+)
+
+// approvalExemptPaths stay reachable for sessions awaiting approval so the
+// frontend can show a pending state and let the user log out.
+var approvalExemptPaths = map[string]bool{
+	"/api/health":        true,
+	"/api/enums":         true,
+	"/api/auth/login":    true,
+	"/api/auth/callback": true,
+	"/api/auth/session":  true,
+	"/api/auth/logout":   true,
+}
+
+// RequireApproval rejects API calls from sessions whose account has not been
+// approved by an admin. Sessions issued while approval is disabled are never
+// pending, so it is safe to install unconditionally.
+func RequireApproval(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if claims := FromContext(r.Context()); claims != nil && claims.PendingApproval && !approvalExemptPaths[r.URL.Path] {
+			httpx.Error(w, http.StatusForbidden, "account pending approval")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// refreshApproval reissues a pending session once an admin has approved the
+// account, so the user does not have to log in again. It returns the claims
+// to report and the new token, if one was issued.
+func (h *Handler) refreshApproval(w http.ResponseWriter, r *http.Request, claims *Claims) (*Claims, string) {
+	if !claims.PendingApproval || h.db == nil {
+		return claims, ""
+	}
+	approved, err := h.accountApproved(r.Context(), claims.AccountID)
+	if err != nil || !approved {
+		return claims, ""
+	}
+	next := *claims
+	next.PendingApproval = false
+	token, err := h.sessions.Issue(w, r, &next)
+	if err != nil {
+		return claims, ""
+	}
+	return &next, token
+}
+
+func (h *Handler) accountApproved(ctx context.Context, accountID int64) (bool, error) {
+	var approved bool
+	err := h.db.QueryRow(ctx, `SELECT approved FROM accounts WHERE id = $1`, accountID).Scan(&approved)
+	return approved, err
+}
+
+// sessionRejected reports whether the session's account was rejected after
+// the session was issued. Sessions issued since then are pending and blocked
+// by RequireApproval instead, and approving the account clears rejected_at.
+// It is only installed when Config.RequireApproval is set.
+func (h *Handler) sessionRejected(ctx context.Context, claims *Claims) (bool, error) {
+	var rejectedAt *time.Time
+	err := h.db.QueryRow(ctx, `SELECT rejected_at FROM accounts WHERE id = $1`, claims.AccountID).Scan(&rejectedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return rejectedAt != nil && rejectedAt.Unix() >= claims.IssuedAt, nil
+}
+
+func (h *Handler) listPendingAccounts(w http.ResponseWriter, r *http.Request) {
+	h.writeAccountActivity(w, r, accountActivitySelect+`
+	WHERE NOT a.approved AND a.rejected_at IS NULL
+	GROUP BY a.id
+	ORDER BY a.created_at ASC, a.id ASC`)
+}
+
+func (h *Handler) approveAccount(w http.ResponseWriter, r *http.Request) {
+	h.setAccountApproval(w, r, `UPDATE accounts SET approved = TRUE, rejected_at = NULL WHERE id = $1`)
+}
+
+// rejectAccount keeps the account unapproved and drops it from the pending
+// list. Sessions issued before the rejection stop working at once; see
+// sessionRejected. It can still be approved later.
+func (h *Handler) rejectAccount(w http.ResponseWriter, r *http.Request) {
+	h.setAccountApproval(w, r, `UPDATE accounts SET approved = FALSE, rejected_at = NOW() WHERE id = $1`)
+}
+
+func (h *Handler) setAccountApproval(w http.ResponseWriter, r *http.Request, query string) {
+	accountID, err := strconv.ParseInt(chi.URLParam(r, "accountID"), 10, 64)
+	if err != nil || accountID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid account id")
+		return
+	}
+	tag, err := h.db.Exec(r.Context(), query, accountID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to update account")
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		httpx.Error(w, http.StatusNotFound, "account not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
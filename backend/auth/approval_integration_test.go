@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestRejectAccountRevokesExistingSessions(t *testing.T) {
+	db := openAuthTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ensureAuthTestSchema(t, ctx, db)
+
+	var accountID int64
+	if err := db.QueryRow(ctx,
+		`INSERT INTO accounts (subject, email) VALUES ($1, 'rejected@example.com') RETURNING id`,
+		"reject-"+strconv.FormatInt(time.Now().UnixNano(), 10),
+	).Scan(&accountID); err != nil {
+		t.Fatalf("insert account failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM accounts WHERE id = $1`, accountID)
+
+	manager, err := NewSessionManager("test-secret", false)
+	if err != nil {
+		t.Fatalf("NewSessionManager() returned error: %v", err)
+	}
+	plain, err := NewHandler(db, manager, Config{})
+	if err != nil {
+		t.Fatalf("NewHandler() returned error: %v", err)
+	}
+	plain.Close()
+	if manager.revoked != nil {
+		t.Fatal("revocation check installed without RequireApproval")
+	}
+	h, err := NewHandler(db, manager, Config{RequireApproval: true})
+	if err != nil {
+		t.Fatalf("NewHandler() returned error: %v", err)
+	}
+	defer h.Close()
+	token, err := manager.Issue(httptest.NewRecorder(), nil, &Claims{AccountID: accountID, Roles: []string{"participant"}})
+	if err != nil {
+		t.Fatalf("Issue() returned error: %v", err)
+	}
+
+	api := manager.Middleware(RequireApproval(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+	call := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/api/events/events", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := call(); code != http.StatusNoContent {
+		t.Fatalf("status before rejection = %d, want %d", code, http.StatusNoContent)
+	}
+
+	router := chi.NewRouter()
+	router.Post("/accounts/{accountID}/reject", h.rejectAccount)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/accounts/"+strconv.FormatInt(accountID, 10)+"/reject", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("reject status = %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	if code := call(); code != http.StatusUnauthorized {
+		t.Fatalf("status after rejection = %d, want %d", code, http.StatusUnauthorized)
+	}
+}
+
+func openAuthTestDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Skip("DATABASE_URL not set; skipping integration auth tests")
+	}
+	db, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatalf("connect db failed: %v", err)
+	}
+	return db
+}
+
+func ensureAuthTestSchema(t *testing.T, ctx context.Context, db *pgxpool.Pool) {
+	t.Helper()
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS accounts (
+            id SERIAL PRIMARY KEY,
+            subject TEXT NOT NULL UNIQUE,
+            email TEXT NOT NULL,
+            full_name TEXT,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMPTZ`,
+		`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMPTZ`,
+		`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS approved BOOLEAN NOT NULL DEFAULT TRUE`,
+		`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS rejected_at TIMESTAMPTZ`,
+		`CREATE TABLE IF NOT EXISTS participant_profiles (
+            id SERIAL PRIMARY KEY,
+            full_name TEXT NOT NULL,
+            email TEXT NOT NULL UNIQUE,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`ALTER TABLE participant_profiles ADD COLUMN IF NOT EXISTS account_id INTEGER UNIQUE REFERENCES accounts(id) ON DELETE SET NULL`,
+		`ALTER TABLE participant_profiles ADD COLUMN IF NOT EXISTS account_subject TEXT`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS participant_profiles_account_subject_key ON participant_profiles (account_subject) WHERE account_subject IS NOT NULL`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(ctx, stmt); err != nil {
+			t.Fatalf("schema setup failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/innhopp/central/backend/rbac"
+)
+
+// DefaultRolePolicy picks the role given to an account on login when it has
+// no roles yet and the ID token maps to none. Rules are tried in order and
+// the first match wins; Role applies when none match.
+type DefaultRolePolicy struct {
+	Role  string
+	Rules []DefaultRoleRule
+}
+
+// DefaultRoleRule grants Role when the ID token's Claim has Value. Supported
+// claims are "group" (one of the token's groups) and "email_domain", which
+// only matches when the identity provider marks the email as verified.
+type DefaultRoleRule struct {
+	Claim string
+	Value string
+	Role  string
+}
+
+// ParseDefaultRolePolicy builds a policy from a role name and a rule list of
+// the form "group:club-instructors=staff,email_domain:club.org=staff". A
+// blank role keeps participant. Unknown roles and claims are rejected so a
+// typo fails at startup rather than on someone's first login.
+func ParseDefaultRolePolicy(role, rules string) (DefaultRolePolicy, error) {
+	policy := DefaultRolePolicy{Role: string(rbac.RoleParticipant)}
+	if strings.TrimSpace(role) != "" {
+		policy.Role = normalizeRole(role)
+		if policy.Role == "" {
+			return DefaultRolePolicy{}, fmt.Errorf("unknown default role %q", role)
+		}
+	}
+
+	for _, raw := range strings.Split(rules, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		match, ruleRole, ok := strings.Cut(raw, "=")
+		claim, value, hasClaim := strings.Cut(match, ":")
+		if !ok || !hasClaim {
+			return DefaultRolePolicy{}, fmt.Errorf("invalid default role rule %q, want claim:value=role", raw)
+		}
+		rule := DefaultRoleRule{
+			Claim: strings.ToLower(strings.TrimSpace(claim)),
+			Value: strings.TrimSpace(value),
+			Role:  normalizeRole(ruleRole),
+		}
+		if rule.Claim != "group" && rule.Claim != "email_domain" {
+			return DefaultRolePolicy{}, fmt.Errorf("default role rule %q: claim must be group or email_domain", raw)
+		}
+		if rule.Value == "" {
+			return DefaultRolePolicy{}, errors.New("default role rule " + raw + ": value is required")
+		}
+		if rule.Role == "" {
+			return DefaultRolePolicy{}, fmt.Errorf("default role rule %q: unknown role %q", raw, ruleRole)
+		}
+		policy.Rules = append(policy.Rules, rule)
+	}
+	return policy, nil
+}
+
+// roleFor returns the role a new account gets for claims.
+func (p DefaultRolePolicy) roleFor(claims *idTokenClaims) string {
+	for _, rule := range p.Rules {
+		if rule.matches(claims) {
+			return rule.Role
+		}
+	}
+	if p.Role == "" {
+		return string(rbac.RoleParticipant)
+	}
+	return p.Role
+}
+
+func (r DefaultRoleRule) matches(claims *idTokenClaims) bool {
+	if claims == nil {
+		return false
+	}
+	switch r.Claim {
+	case "group":
+		for _, group := range claims.Groups {
+			if strings.EqualFold(strings.TrimSpace(group), r.Value) {
+				return true
+			}
+		}
+	case "email_domain":
+		if !claims.EmailVerified {
+			return false
+		}
+		_, domain, ok := strings.Cut(strings.TrimSpace(claims.Email), "@")
+		return ok && strings.EqualFold(domain, strings.TrimPrefix(r.Value, "@"))
+	}
+	return false
+}
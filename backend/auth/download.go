@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/innhopp/central/backend/httpx"
+	"github.com/innhopp/central/backend/rbac"
+)
+
+// DefaultDownloadTokenTTL is how long a download token stays valid when
+// DOWNLOAD_TOKEN_TTL is unset.
+const DefaultDownloadTokenTTL = 5 * time.Minute
+
+// DownloadTokenParam is the query parameter binary endpoints accept in place
+// of a session, for contexts such as <img> tags that cannot send one.
+const DownloadTokenParam = "token"
+
+// downloadGrant is the payload of a download token. It carries the issuing
+// session's claims so the binary endpoint applies the caller's own
+// permissions, but only for the single path it was issued for.
+type downloadGrant struct {
+	Path      string `json:"path"`
+	Claims    Claims `json:"claims"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// downloadSigningPrefix separates download token signatures from session
+// signatures, so neither kind of token verifies as the other.
+const downloadSigningPrefix = "download:"
+
+var errDownloadPathNotAllowed = errors.New("path does not serve downloads")
+
+// SetDownloadTokenTTL sets how long issued download tokens stay valid.
+// Non-positive values keep the default.
+func (m *SessionManager) SetDownloadTokenTTL(ttl time.Duration) {
+	if ttl > 0 {
+		m.downloadTTL = ttl
+	}
+}
+
+// SetDownloadRoutes lists the route patterns, such as
+// /api/participants/profiles/{profileID}/photo, that download tokens may be
+// issued for. Only GET requests to these paths honour ?token=.
+func (m *SessionManager) SetDownloadRoutes(patterns []string) {
+	m.downloadRoutes = append([]string(nil), patterns...)
+}
+
+func (m *SessionManager) downloadable(path string) bool {
+	for _, pattern := range m.downloadRoutes {
+		if matchRoutePattern(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRoutePattern compares path to a chi-style pattern segment by segment;
+// {name} segments match any non-empty value.
+func matchRoutePattern(pattern, path string) bool {
+	want := strings.Split(strings.Trim(pattern, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+	if len(want) != len(got) {
+		return false
+	}
+	for i, segment := range want {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if got[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != got[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// IssueDownloadToken signs a token that lets the holder GET path with the
+// supplied claims until it expires.
+func (m *SessionManager) IssueDownloadToken(claims *Claims, path string) (string, time.Time, error) {
+	if !m.downloadable(path) {
+		return "", time.Time{}, errDownloadPathNotAllowed
+	}
+	expiresAt := m.clock.Now().Add(m.downloadTTL)
+	grant := downloadGrant{Path: path, ExpiresAt: expiresAt.Unix()}
+	if claims != nil {
+		grant.Claims = *claims
+		grant.Claims.Device = nil
+	}
+
+	raw, err := json.Marshal(grant)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(raw)
+	signature := signWith(m.secrets[0], downloadSigningPrefix+payload)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(signature), expiresAt, nil
+}
+
+// verifyDownloadToken returns the claims of a token issued for path that has
+// not expired yet.
+func (m *SessionManager) verifyDownloadToken(token, path string) (*Claims, error) {
+	encoded, err := m.checkSignature(token, downloadSigningPrefix)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	var grant downloadGrant
+	if err := json.Unmarshal(raw, &grant); err != nil {
+		return nil, err
+	}
+	if grant.Path != path {
+		return nil, errors.New("token was issued for another resource")
+	}
+	if grant.ExpiresAt <= m.clock.Now().Unix() {
+		return nil, errors.New("token expired")
+	}
+	return &grant.Claims, nil
+}
+
+// downloadRequestToken returns the ?token= of a GET to a download route.
+func (m *SessionManager) downloadRequestToken(r *http.Request) string {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return ""
+	}
+	token := strings.TrimSpace(r.URL.Query().Get(DownloadTokenParam))
+	if token == "" || !m.downloadable(r.URL.Path) {
+		return ""
+	}
+	return token
+}
+
+type downloadTokenRequest struct {
+	Path string `json:"path"`
+}
+
+type downloadTokenResponse struct {
+	Token     string    `json:"token"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// DownloadRoutes registers the endpoint that issues download tokens.
+func (h *Handler) DownloadRoutes(enforcer *rbac.Enforcer) chi.Router {
+	r := chi.NewRouter()
+	r.With(enforcer.Authorize(rbac.PermissionViewSession)).Post("/token", h.issueDownloadToken)
+	return r
+}
+
+func (h *Handler) issueDownloadToken(w http.ResponseWriter, r *http.Request) {
+	var payload downloadTokenRequest
+	if err := httpx.DecodeJSON(r, &payload); err != nil {
+		httpx.PayloadError(w, err, "invalid request payload")
+		return
+	}
+	path := strings.TrimSpace(payload.Path)
+	if path == "" {
+		httpx.Error(w, http.StatusBadRequest, "path is required")
+		return
+	}
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+
+	token, expiresAt, err := h.sessions.IssueDownloadToken(FromContext(r.Context()), path)
+	if errors.Is(err, errDownloadPathNotAllowed) {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to issue download token")
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusCreated, downloadTokenResponse{
+		Token:     token,
+		URL:       path + "?" + DownloadTokenParam + "=" + token,
+		ExpiresAt: expiresAt.UTC(),
+	})
+}
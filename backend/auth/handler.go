@@ -22,6 +22,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/innhopp/central/backend/httpx"
+	"github.com/innhopp/central/backend/internal/email"
 	"github.com/innhopp/central/backend/rbac"
 )
 
@@ -35,6 +36,22 @@ type Config struct {
 	FrontendURL  string
 	Scopes       []string
 	DevAllowAll  bool
+	// DefaultRole decides the first role of accounts that log in without
+	// any. The zero value grants participant.
+	DefaultRole DefaultRolePolicy
+	// RequireApproval leaves accounts created on first login unapproved
+	// until an admin approves them.
+	RequireApproval bool
+	// LandingRoutes picks the frontend route each role starts on.
+	LandingRoutes LandingRoutes
+	// MaxRoles caps the roles kept on a session, most privileged first.
+	// Zero means DefaultMaxRoles.
+	MaxRoles int
+	// LinkProfilesBySubject finds the participant profile of a login by the
+	// OIDC subject stored on it, and moves the profile's email along when the
+	// identity provider reports a new one. Without it profiles are only
+	// linked by email.
+	LinkProfilesBySubject bool
 }
 
 func (c Config) enabled() bool {
@@ -61,6 +78,12 @@ type Handler struct {
 	keys       *jwksCache
 	httpClient *http.Client
 	disabled   bool
+	// lastSeen throttles last_seen_at writes; nil without a database.
+	lastSeen *lastSeenTracker
+
+	stopSweeper chan struct{}
+	sweeperDone chan struct{}
+	closeOnce   sync.Once
 }
 
 const defaultPostLoginPath = "/events"
@@ -76,7 +99,9 @@ func sanitizePostLoginPath(raw string) string {
 	return trimmed
 }
 
-// NewHandler constructs an auth handler with OIDC configuration.
+// NewHandler constructs an auth handler with OIDC configuration. It starts a
+// background sweeper for the login state, key and last-seen caches; call
+// Close to stop it.
 func NewHandler(db *pgxpool.Pool, sessions *SessionManager, cfg Config) (*Handler, error) {
 	handler := &Handler{
 		db:         db,
@@ -85,9 +110,20 @@ func NewHandler(db *pgxpool.Pool, sessions *SessionManager, cfg Config) (*Handle
 		cfg:        cfg,
 		httpClient: &http.Client{Timeout: 10 * time.Second},
 	}
+	handler.states.clock = sessions.clock
+	if db != nil {
+		handler.lastSeen = newLastSeenTracker(db, lastSeenInterval)
+		sessions.SetActivityRecorder(handler.lastSeen.touch)
+		// Rejection only matters when accounts need approval, so the lookup
+		// is skipped on every request otherwise.
+		if cfg.RequireApproval {
+			sessions.SetRevocationCheck(handler.sessionRejected)
+		}
+	}
 
 	if !cfg.enabled() {
 		handler.disabled = true
+		handler.startSweeper(cacheSweepInterval)
 		return handler, nil
 	}
 
@@ -98,19 +134,26 @@ func NewHandler(db *pgxpool.Pool, sessions *SessionManager, cfg Config) (*Handle
 
 	handler.provider = metadata
 	handler.keys = newJWKSCache(metadata.JWKSURI, handler.httpClient)
+	handler.startSweeper(cacheSweepInterval)
 	return handler, nil
 }
 
 // Routes exposes the auth endpoints.
-func (h *Handler) Routes() chi.Router {
+func (h *Handler) Routes(enforcer *rbac.Enforcer) chi.Router {
 	r := chi.NewRouter()
 	r.Get("/login", h.beginLogin)
 	r.Get("/callback", h.handleCallback)
 	r.Get("/session", h.sessionInfo)
-	r.Post("/impersonate", h.impersonate)
-	r.Post("/impersonate-new-user", h.impersonateNewUser)
+	r.With(enforcer.AdminAction("auth.impersonate")).Post("/impersonate", h.impersonate)
+	r.With(enforcer.AdminAction("auth.impersonate_new_user")).Post("/impersonate-new-user", h.impersonateNewUser)
 	r.Post("/stop-impersonation", h.stopImpersonation)
 	r.Post("/logout", h.logout)
+	r.With(enforcer.Authorize(rbac.PermissionManageAccounts)).Get("/accounts", h.listAccounts)
+	r.With(enforcer.Authorize(rbac.PermissionManageAccounts)).Get("/accounts/dormant", h.listDormantAccounts)
+	r.With(enforcer.Authorize(rbac.PermissionManageAccounts)).Get("/accounts/pending", h.listPendingAccounts)
+	r.With(enforcer.Authorize(rbac.PermissionManageAccounts)).Get("/reconcile", h.reconcileAccounts)
+	r.With(enforcer.Authorize(rbac.PermissionManageAccounts)).With(enforcer.AdminAction("accounts.approve")).Post("/accounts/{accountID}/approve", h.approveAccount)
+	r.With(enforcer.Authorize(rbac.PermissionManageAccounts)).With(enforcer.AdminAction("accounts.reject")).Post("/accounts/{accountID}/reject", h.rejectAccount)
 	return r
 }
 
@@ -187,12 +230,24 @@ func (h *Handler) handleCallback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.recordLogin(r.Context(), account.ID); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to record login")
+		return
+	}
+
+	if h.cfg.LinkProfilesBySubject {
+		if err := h.linkParticipantProfileBySubject(r.Context(), account); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to link participant profile")
+			return
+		}
+	}
+
 	if err := h.ensureParticipantProfileForAccount(r.Context(), account); err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to ensure participant profile")
 		return
 	}
 
-	if err := h.linkParticipantProfileByEmail(r.Context(), account.ID, account.Email); err != nil {
+	if err := h.linkParticipantProfileByEmail(r.Context(), account); err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to link participant profile")
 		return
 	}
@@ -212,9 +267,9 @@ func (h *Handler) handleCallback(w http.ResponseWriter, r *http.Request) {
 	roleCandidates = append(roleCandidates, participantRoles...)
 	roleCandidates = append(roleCandidates, desiredAccountRoles...)
 
-	normalized := h.collectRoles(account.Roles, roleCandidates)
+	normalized := h.limitRoles(account.ID, h.collectRoles(account.Roles, roleCandidates))
 	if len(normalized) == 0 {
-		normalized = append(normalized, string(rbac.RoleParticipant))
+		normalized = append(normalized, h.cfg.DefaultRole.roleFor(claims))
 	}
 
 	if err := h.assignRoles(r.Context(), account.ID, normalized); err != nil {
@@ -227,26 +282,33 @@ func (h *Handler) handleCallback(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, http.StatusInternalServerError, "failed to load account roles")
 		return
 	}
+	finalRoles = h.limitRoles(account.ID, finalRoles)
 
 	claimsToPersist := &Claims{
-		AccountID: account.ID,
-		Email:     account.Email,
-		FullName:  account.FullName,
-		Roles:     finalRoles,
+		AccountID:       account.ID,
+		Email:           account.Email,
+		FullName:        account.FullName,
+		Roles:           finalRoles,
+		PendingApproval: !account.Approved,
 	}
 
-	rawToken, err := h.sessions.Issue(w, claimsToPersist)
+	rawToken, err := h.sessions.Issue(w, r, claimsToPersist)
 	if err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to create session")
 		return
 	}
 
 	resp := sessionResponse{
-		AccountID: account.ID,
-		Email:     account.Email,
-		FullName:  account.FullName,
-		Roles:     finalRoles,
-		Token:     rawToken,
+		AccountID:       account.ID,
+		Email:           account.Email,
+		FullName:        account.FullName,
+		Roles:           finalRoles,
+		PendingApproval: !account.Approved,
+		LandingRoute:    h.cfg.LandingRoutes.For(finalRoles),
+		Token:           rawToken,
+	}
+	if redirectPath == "" {
+		redirectPath = resp.LandingRoute
 	}
 	if redirectURL := h.postLoginRedirectURL(redirectPath); redirectURL != "" {
 		http.Redirect(w, r, redirectURL, http.StatusFound)
@@ -262,7 +324,13 @@ type sessionResponse struct {
 	FullName     string              `json:"full_name"`
 	Roles        []string            `json:"roles"`
 	Impersonator *ImpersonatorClaims `json:"impersonator,omitempty"`
-	Token        string              `json:"token,omitempty"`
+	// PendingApproval is set while an admin has not approved the account;
+	// every other API call answers 403 until then.
+	PendingApproval bool `json:"pending_approval,omitempty"`
+	// LandingRoute is where the SPA should send the user after login when
+	// they did not ask for a specific page.
+	LandingRoute string `json:"landing_route"`
+	Token        string `json:"token,omitempty"`
 }
 
 func (h *Handler) sessionInfo(w http.ResponseWriter, r *http.Request) {
@@ -270,10 +338,11 @@ func (h *Handler) sessionInfo(w http.ResponseWriter, r *http.Request) {
 	if claims == nil {
 		if h.cfg.DevAllowAll {
 			httpx.WriteJSON(w, http.StatusOK, sessionResponse{
-				AccountID: 0,
-				Email:     "dev@localhost",
-				FullName:  "Development Admin",
-				Roles:     []string{string(rbac.RoleAdmin)},
+				AccountID:    0,
+				Email:        "dev@localhost",
+				FullName:     "Development Admin",
+				Roles:        []string{string(rbac.RoleAdmin)},
+				LandingRoute: h.cfg.LandingRoutes.For([]string{string(rbac.RoleAdmin)}),
 			})
 			return
 		}
@@ -281,12 +350,16 @@ func (h *Handler) sessionInfo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	claims, token := h.refreshApproval(w, r, claims)
 	resp := sessionResponse{
-		AccountID:    claims.AccountID,
-		Email:        claims.Email,
-		FullName:     claims.FullName,
-		Roles:        claims.Roles,
-		Impersonator: claims.Impersonator,
+		AccountID:       claims.AccountID,
+		Email:           claims.Email,
+		FullName:        claims.FullName,
+		Roles:           claims.Roles,
+		Impersonator:    claims.Impersonator,
+		PendingApproval: claims.PendingApproval,
+		LandingRoute:    h.cfg.LandingRoutes.For(claims.Roles),
+		Token:           token,
 	}
 
 	httpx.WriteJSON(w, http.StatusOK, resp)
@@ -311,7 +384,7 @@ func (h *Handler) impersonate(w http.ResponseWriter, r *http.Request) {
 		ParticipantID int64 `json:"participant_id"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	if payload.ParticipantID <= 0 {
@@ -348,7 +421,7 @@ func (h *Handler) impersonate(w http.ResponseWriter, r *http.Request) {
 		Impersonator: cloneImpersonatorClaims(claims),
 	}
 
-	if _, err := h.sessions.Issue(w, nextClaims); err != nil {
+	if _, err := h.sessions.Issue(w, r, nextClaims); err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to create impersonation session")
 		return
 	}
@@ -391,7 +464,7 @@ func (h *Handler) impersonateNewUser(w http.ResponseWriter, r *http.Request) {
 		Impersonator: cloneImpersonatorClaims(claims),
 	}
 
-	if _, err := h.sessions.Issue(w, nextClaims); err != nil {
+	if _, err := h.sessions.Issue(w, r, nextClaims); err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to create impersonation session")
 		return
 	}
@@ -423,7 +496,7 @@ func (h *Handler) stopImpersonation(w http.ResponseWriter, r *http.Request) {
 		Roles:     append([]string{}, claims.Impersonator.Roles...),
 	}
 
-	if _, err := h.sessions.Issue(w, restored); err != nil {
+	if _, err := h.sessions.Issue(w, r, restored); err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to restore session")
 		return
 	}
@@ -536,7 +609,7 @@ func (h *Handler) verifyIDToken(ctx context.Context, raw string, nonce string) (
 		return nil, err
 	}
 
-	if err := claims.Validate(h.cfg.ClientID, h.cfg.Issuer, nonce); err != nil {
+	if err := claims.Validate(h.cfg.ClientID, h.cfg.Issuer, nonce, h.sessions.clock.Now()); err != nil {
 		return nil, err
 	}
 
@@ -545,16 +618,16 @@ func (h *Handler) verifyIDToken(ctx context.Context, raw string, nonce string) (
 
 func (h *Handler) ensureAccount(ctx context.Context, claims *idTokenClaims) (*Account, error) {
 	row := h.db.QueryRow(ctx,
-		`INSERT INTO accounts (subject, email, full_name)
-         VALUES ($1, $2, $3)
+		`INSERT INTO accounts (subject, email, full_name, approved)
+         VALUES ($1, $2, $3, $4)
          ON CONFLICT (subject)
          DO UPDATE SET email = EXCLUDED.email, full_name = EXCLUDED.full_name
-         RETURNING id, subject, email, full_name`,
-		claims.Subject, strings.ToLower(claims.Email), claims.Name,
+         RETURNING id, subject, email, full_name, approved`,
+		claims.Subject, email.Normalize(claims.Email), claims.Name, !h.cfg.RequireApproval,
 	)
 
 	var account Account
-	if err := row.Scan(&account.ID, &account.Subject, &account.Email, &account.FullName); err != nil {
+	if err := row.Scan(&account.ID, &account.Subject, &account.Email, &account.FullName, &account.Approved); err != nil {
 		return nil, err
 	}
 
@@ -585,13 +658,13 @@ func (h *Handler) loadAccountRoles(ctx context.Context, accountID int64) ([]stri
 	return roles, nil
 }
 
-func (h *Handler) loadParticipantRoles(ctx context.Context, accountID int64, email string) ([]string, error) {
+func (h *Handler) loadParticipantRoles(ctx context.Context, accountID int64, address string) ([]string, error) {
 	var roles []string
-	normalizedEmail := strings.ToLower(strings.TrimSpace(email))
+	normalizedEmail := email.Normalize(address)
 	err := h.db.QueryRow(ctx, `
 		SELECT roles
 		FROM participant_profiles
-		WHERE ($1 > 0 AND account_id = $1) OR lower(email) = $2
+		WHERE ($1 > 0 AND account_id = $1) OR lower(email) = lower($2)
 		ORDER BY CASE WHEN $1 > 0 AND account_id = $1 THEN 0 ELSE 1 END, id ASC
 		LIMIT 1
 	`, accountID, normalizedEmail).Scan(&roles)
@@ -604,13 +677,13 @@ func (h *Handler) loadParticipantRoles(ctx context.Context, accountID int64, ema
 	return roles, nil
 }
 
-func (h *Handler) loadDesiredParticipantAccountRoles(ctx context.Context, accountID int64, email string) ([]string, error) {
+func (h *Handler) loadDesiredParticipantAccountRoles(ctx context.Context, accountID int64, address string) ([]string, error) {
 	var roles []string
-	normalizedEmail := strings.ToLower(strings.TrimSpace(email))
+	normalizedEmail := email.Normalize(address)
 	err := h.db.QueryRow(ctx, `
 		SELECT account_roles
 		FROM participant_profiles
-		WHERE ($1 > 0 AND account_id = $1) OR lower(email) = $2
+		WHERE ($1 > 0 AND account_id = $1) OR lower(email) = lower($2)
 		ORDER BY CASE WHEN $1 > 0 AND account_id = $1 THEN 0 ELSE 1 END, id ASC
 		LIMIT 1
 	`, accountID, normalizedEmail).Scan(&roles)
@@ -628,14 +701,14 @@ func (h *Handler) ensureParticipantProfileForAccount(ctx context.Context, accoun
 		return nil
 	}
 
-	email := strings.ToLower(strings.TrimSpace(account.Email))
-	if email == "" {
+	address := email.Normalize(account.Email)
+	if address == "" {
 		return nil
 	}
 
 	fullName := strings.TrimSpace(account.FullName)
 	if fullName == "" {
-		fullName = email
+		fullName = address
 	}
 
 	_, err := h.db.Exec(ctx, `
@@ -643,6 +716,7 @@ func (h *Handler) ensureParticipantProfileForAccount(ctx context.Context, accoun
 			full_name,
 			email,
 			account_id,
+			account_subject,
 			roles,
 			account_roles
 		)
@@ -650,28 +724,63 @@ func (h *Handler) ensureParticipantProfileForAccount(ctx context.Context, accoun
 			$1,
 			$2,
 			$3,
+			NULLIF($5, ''),
 			ARRAY['Participant']::TEXT[],
 			$4
 		WHERE NOT EXISTS (
 			SELECT 1
 			FROM participant_profiles
-			WHERE account_id = $3 OR lower(email) = $2
+			WHERE account_id = $3 OR lower(email) = lower($2) OR (NULLIF($5, '') IS NOT NULL AND account_subject = $5)
 		)
 		ON CONFLICT DO NOTHING
-	`, fullName, email, account.ID, account.Roles)
+	`, fullName, address, account.ID, account.Roles, account.Subject)
+	return err
+}
+
+// linkParticipantProfileBySubject relinks the profile that carries the
+// account's OIDC subject, so the link survives a new account row or a new
+// email at the identity provider. The profile takes the account's current
+// email unless another profile already uses it.
+func (h *Handler) linkParticipantProfileBySubject(ctx context.Context, account *Account) error {
+	if account == nil || account.ID <= 0 || strings.TrimSpace(account.Subject) == "" {
+		return nil
+	}
+	address := email.Normalize(account.Email)
+	_, err := h.db.Exec(ctx, `
+		UPDATE participant_profiles p
+		SET account_id = $1,
+		    email = CASE
+		        WHEN $3 = '' OR lower(p.email) = lower($3) THEN p.email
+		        WHEN EXISTS (SELECT 1 FROM participant_profiles o WHERE o.id <> p.id AND lower(o.email) = lower($3)) THEN p.email
+		        ELSE $3
+		    END
+		WHERE p.account_subject = $2
+		  AND NOT EXISTS (SELECT 1 FROM participant_profiles o WHERE o.id <> p.id AND o.account_id = $1)
+	`, account.ID, account.Subject, address)
 	return err
 }
 
-func (h *Handler) linkParticipantProfileByEmail(ctx context.Context, accountID int64, email string) error {
-	if accountID <= 0 {
+// linkParticipantProfileByEmail links the unlinked profile with the account's
+// email and records the account's subject on it. Profiles claimed by another
+// account or subject are left alone, as is everything when another profile
+// already holds this account.
+func (h *Handler) linkParticipantProfileByEmail(ctx context.Context, account *Account) error {
+	if account == nil || account.ID <= 0 {
 		return nil
 	}
 
 	_, err := h.db.Exec(ctx, `
 		UPDATE participant_profiles
-		SET account_id = $1
-		WHERE account_id IS NULL AND lower(email) = $2
-	`, accountID, strings.ToLower(strings.TrimSpace(email)))
+		SET account_id = COALESCE(account_id, $1),
+		    account_subject = COALESCE(account_subject, NULLIF($3, ''))
+		WHERE lower(email) = lower($2)
+		  AND (account_id IS NULL OR account_id = $1)
+		  AND (account_subject IS NULL OR account_subject = $3)
+		  AND NOT EXISTS (
+			SELECT 1 FROM participant_profiles o
+			WHERE o.id <> participant_profiles.id AND (o.account_id = $1 OR o.account_subject = NULLIF($3, ''))
+		  )
+	`, account.ID, email.Normalize(account.Email), account.Subject)
 	return err
 }
 
@@ -692,16 +801,16 @@ func (h *Handler) loadParticipantIdentity(ctx context.Context, participantID int
 		return nil, err
 	}
 
-	participant.Email = strings.ToLower(strings.TrimSpace(participant.Email))
+	participant.Email = email.Normalize(participant.Email)
 	return &participant, nil
 }
 
-func (h *Handler) loadAccountIDByEmail(ctx context.Context, email string) (int64, error) {
+func (h *Handler) loadAccountIDByEmail(ctx context.Context, address string) (int64, error) {
 	var accountID int64
 	err := h.db.QueryRow(
 		ctx,
-		`SELECT id FROM accounts WHERE lower(email) = $1 ORDER BY id ASC LIMIT 1`,
-		strings.ToLower(strings.TrimSpace(email)),
+		`SELECT id FROM accounts WHERE lower(email) = lower($1) ORDER BY id ASC LIMIT 1`,
+		email.Normalize(address),
 	).Scan(&accountID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -810,26 +919,10 @@ func cloneImpersonatorClaims(claims *Claims) *ImpersonatorClaims {
 }
 
 func normalizeRole(role string) string {
-	switch strings.ToLower(strings.TrimSpace(role)) {
-	case "admin":
-		return string(rbac.RoleAdmin)
-	case "staff":
-		return string(rbac.RoleStaff)
-	case "jumpmaster", "jump_master", "jump master":
-		return string(rbac.RoleJumpMaster)
-	case "jumpleader", "jump_leader", "jump leader":
-		return string(rbac.RoleJumpLeader)
-	case "groundcrew", "ground_crew", "ground crew":
-		return string(rbac.RoleGroundCrew)
-	case "driver":
-		return string(rbac.RoleDriver)
-	case "packer":
-		return string(rbac.RolePacker)
-	case "participant":
-		return string(rbac.RoleParticipant)
-	default:
-		return ""
+	if parsed, ok := rbac.ParseRole(role); ok {
+		return string(parsed)
 	}
+	return ""
 }
 
 // Account represents a persisted identity in the database.
@@ -839,6 +932,7 @@ type Account struct {
 	Email    string
 	FullName string
 	Roles    []string
+	Approved bool
 }
 
 type providerMetadata struct {
@@ -911,6 +1005,24 @@ func (c *jwksCache) key(ctx context.Context, kid string) (*rsa.PublicKey, error)
 	return key, nil
 }
 
+// refreshIfStale refetches the keys when they were never fetched or expire
+// within margin.
+func (c *jwksCache) refreshIfStale(ctx context.Context, margin time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.fetched.IsZero() && time.Since(c.fetched) < c.lifespan-margin {
+		return nil
+	}
+	return c.refresh(ctx)
+}
+
+// stats returns the number of cached keys and when they were fetched.
+func (c *jwksCache) stats() (int, time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.keys), c.fetched
+}
+
 func (c *jwksCache) refresh(ctx context.Context) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.source, nil)
 	if err != nil {
@@ -979,18 +1091,19 @@ func (c *jwksCache) refresh(ctx context.Context) error {
 }
 
 type idTokenClaims struct {
-	Issuer   string        `json:"iss"`
-	Subject  string        `json:"sub"`
-	Audience audienceClaim `json:"aud"`
-	Expiry   int64         `json:"exp"`
-	Nonce    string        `json:"nonce"`
-	Email    string        `json:"email"`
-	Name     string        `json:"name"`
-	Roles    []string      `json:"roles"`
-	Groups   []string      `json:"groups"`
+	Issuer        string        `json:"iss"`
+	Subject       string        `json:"sub"`
+	Audience      audienceClaim `json:"aud"`
+	Expiry        int64         `json:"exp"`
+	Nonce         string        `json:"nonce"`
+	Email         string        `json:"email"`
+	EmailVerified bool          `json:"email_verified"`
+	Name          string        `json:"name"`
+	Roles         []string      `json:"roles"`
+	Groups        []string      `json:"groups"`
 }
 
-func (c *idTokenClaims) Validate(clientID, issuer, nonce string) error {
+func (c *idTokenClaims) Validate(clientID, issuer, nonce string, now time.Time) error {
 	if c.Issuer != issuer {
 		return errors.New("issuer mismatch")
 	}
@@ -1000,7 +1113,7 @@ func (c *idTokenClaims) Validate(clientID, issuer, nonce string) error {
 	if c.Nonce != nonce {
 		return errors.New("nonce mismatch")
 	}
-	if time.Now().Unix() > c.Expiry {
+	if now.Unix() > c.Expiry {
 		return errors.New("id token expired")
 	}
 	if strings.TrimSpace(c.Email) == "" {
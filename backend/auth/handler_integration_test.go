@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestLinkProfileBySubjectFollowsEmailChanges(t *testing.T) {
+	db := openAuthTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ensureAuthTestSchema(t, ctx, db)
+
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 10)
+	subject := "link-" + suffix
+	oldEmail := "old-" + suffix + "@example.com"
+	newEmail := "new-" + suffix + "@example.com"
+	h := &Handler{db: db}
+
+	var accountID int64
+	if err := db.QueryRow(ctx, `INSERT INTO accounts (subject, email) VALUES ($1, $2) RETURNING id`, subject, oldEmail).Scan(&accountID); err != nil {
+		t.Fatalf("insert account failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM accounts WHERE id = $1`, accountID)
+	var profileID int64
+	if err := db.QueryRow(ctx, `INSERT INTO participant_profiles (full_name, email) VALUES ('Linked Jumper', $1) RETURNING id`, oldEmail).Scan(&profileID); err != nil {
+		t.Fatalf("insert profile failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM participant_profiles WHERE id = $1`, profileID)
+
+	loadProfile := func() (string, *int64, *string) {
+		t.Helper()
+		var address string
+		var linkedID *int64
+		var linkedSubject *string
+		if err := db.QueryRow(ctx, `SELECT email, account_id, account_subject FROM participant_profiles WHERE id = $1`, profileID).Scan(&address, &linkedID, &linkedSubject); err != nil {
+			t.Fatalf("load profile failed: %v", err)
+		}
+		return address, linkedID, linkedSubject
+	}
+
+	account := &Account{ID: accountID, Subject: subject, Email: oldEmail}
+	if err := h.linkParticipantProfileByEmail(ctx, account); err != nil {
+		t.Fatalf("linkParticipantProfileByEmail() returned error: %v", err)
+	}
+	if _, linkedID, linkedSubject := loadProfile(); linkedID == nil || *linkedID != accountID || linkedSubject == nil || *linkedSubject != subject {
+		t.Fatalf("profile link = %v %v, want account %d with subject %q", linkedID, linkedSubject, accountID, subject)
+	}
+
+	// The identity provider reports a new email for the same subject.
+	if _, err := db.Exec(ctx, `UPDATE accounts SET email = $1 WHERE id = $2`, newEmail, accountID); err != nil {
+		t.Fatalf("update account failed: %v", err)
+	}
+	account.Email = newEmail
+	if err := h.linkParticipantProfileBySubject(ctx, account); err != nil {
+		t.Fatalf("linkParticipantProfileBySubject() returned error: %v", err)
+	}
+	if address, linkedID, _ := loadProfile(); address != newEmail || linkedID == nil || *linkedID != accountID {
+		t.Fatalf("profile = %q linked to %v, want %q linked to %d", address, linkedID, newEmail, accountID)
+	}
+
+	// An email another profile already holds is not taken over.
+	takenEmail := "taken-" + suffix + "@example.com"
+	var otherID int64
+	if err := db.QueryRow(ctx, `INSERT INTO participant_profiles (full_name, email) VALUES ('Other Jumper', $1) RETURNING id`, takenEmail).Scan(&otherID); err != nil {
+		t.Fatalf("insert profile failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM participant_profiles WHERE id = $1`, otherID)
+	account.Email = takenEmail
+	if err := h.linkParticipantProfileBySubject(ctx, account); err != nil {
+		t.Fatalf("linkParticipantProfileBySubject() returned error: %v", err)
+	}
+	if address, linkedID, _ := loadProfile(); address != newEmail || linkedID == nil || *linkedID != accountID {
+		t.Fatalf("profile = %q linked to %v, want it to keep %q and account %d", address, linkedID, newEmail, accountID)
+	}
+}
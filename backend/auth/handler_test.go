@@ -1,6 +1,12 @@
 package auth
 
-import "testing"
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/innhopp/central/backend/rbac"
+)
 
 func TestNormalizeRoleAcceptsParticipantProfileLabels(t *testing.T) {
 	tests := []struct {
@@ -56,3 +62,101 @@ func TestCollectRolesMergesExistingAndParticipantRoles(t *testing.T) {
 		t.Fatalf("collectRoles() missed roles: %v", want)
 	}
 }
+
+func TestDefaultRolePolicy(t *testing.T) {
+	policy, err := ParseDefaultRolePolicy("", "")
+	if err != nil || policy.roleFor(&idTokenClaims{Email: "a@b.c"}) != string(rbac.RoleParticipant) {
+		t.Fatalf("empty policy = %+v, %v; want participant", policy, err)
+	}
+
+	policy, err = ParseDefaultRolePolicy("Staff", "group:club-instructors=jump master, email_domain:@club.org=ground crew")
+	if err != nil {
+		t.Fatalf("ParseDefaultRolePolicy() returned error: %v", err)
+	}
+	cases := []struct {
+		claims idTokenClaims
+		want   rbac.Role
+	}{
+		{idTokenClaims{Email: "pat@club.org", Groups: []string{"Club-Instructors"}}, rbac.RoleJumpMaster},
+		{idTokenClaims{Email: "pat@CLUB.org", EmailVerified: true}, rbac.RoleGroundCrew},
+		{idTokenClaims{Email: "pat@club.org"}, rbac.RoleStaff},
+		{idTokenClaims{Email: "pat@example.com"}, rbac.RoleStaff},
+	}
+	for _, tc := range cases {
+		if got := policy.roleFor(&tc.claims); got != string(tc.want) {
+			t.Fatalf("roleFor(%+v) = %q, want %q", tc.claims, got, tc.want)
+		}
+	}
+
+	for _, bad := range [][2]string{
+		{"superuser", ""},
+		{"", "group:club=superuser"},
+		{"", "issuer:club=staff"},
+		{"", "group=staff"},
+		{"", "group:=staff"},
+	} {
+		if _, err := ParseDefaultRolePolicy(bad[0], bad[1]); err == nil {
+			t.Fatalf("ParseDefaultRolePolicy(%q, %q) expected error", bad[0], bad[1])
+		}
+	}
+}
+
+func TestLandingRoutesPickFirstHeldRole(t *testing.T) {
+	routes, err := ParseLandingRoutes("jump master=/manifests, Staff=/events, participant=/registrations")
+	if err != nil {
+		t.Fatalf("ParseLandingRoutes() returned error: %v", err)
+	}
+	cases := []struct {
+		roles []string
+		want  string
+	}{
+		{[]string{"participant", "staff"}, "/events"},
+		{[]string{"jump_master", "staff"}, "/manifests"},
+		{[]string{"participant"}, "/registrations"},
+		{[]string{"driver"}, defaultPostLoginPath},
+	}
+	for _, tc := range cases {
+		if got := routes.For(tc.roles); got != tc.want {
+			t.Fatalf("For(%v) = %q, want %q", tc.roles, got, tc.want)
+		}
+	}
+
+	for _, bad := range []string{"superuser=/events", "staff", "staff=events", "staff=//evil.example"} {
+		if _, err := ParseLandingRoutes(bad); err == nil {
+			t.Fatalf("ParseLandingRoutes(%q) expected error", bad)
+		}
+	}
+}
+
+func TestLimitRolesKeepsMostPrivileged(t *testing.T) {
+	h := &Handler{cfg: Config{MaxRoles: 3}}
+
+	got := h.limitRoles(7, []string{"participant", "pilot", "driver", "admin", "jump_master"})
+	want := []string{"admin", "jump_master", "driver"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("limitRoles() = %v, want %v", got, want)
+	}
+
+	h.cfg.MaxRoles = 0
+	got = h.limitRoles(7, []string{"zeta", "participant", "alpha", "staff"})
+	want = []string{"staff", "participant", "alpha", "zeta"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("limitRoles() with default cap = %v, want %v", got, want)
+	}
+}
+
+func TestLinkProfileSkipsAccountsWithoutIdentity(t *testing.T) {
+	// A nil db panics if either link runs a query.
+	h := &Handler{}
+	ctx := context.Background()
+	for _, account := range []*Account{nil, {ID: 0, Subject: "sub-1", Email: "a@example.com"}, {ID: 7, Subject: "  ", Email: "a@example.com"}} {
+		if err := h.linkParticipantProfileBySubject(ctx, account); err != nil {
+			t.Fatalf("linkParticipantProfileBySubject(%+v) returned error: %v", account, err)
+		}
+	}
+	for _, account := range []*Account{nil, {ID: 0, Subject: "sub-1", Email: "a@example.com"}} {
+		if err := h.linkParticipantProfileByEmail(ctx, account); err != nil {
+			t.Fatalf("linkParticipantProfileByEmail(%+v) returned error: %v", account, err)
+		}
+	}
+}
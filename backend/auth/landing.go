@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LandingRoutes maps roles to the frontend route a user should see first.
+// Entries are tried in order and the first role the user holds wins, so list
+// the most specific roles first. It is advice for the SPA and the post-login
+// redirect only; permissions are still enforced per endpoint.
+type LandingRoutes []LandingRoute
+
+// LandingRoute sends holders of Role to Path.
+type LandingRoute struct {
+	Role string
+	Path string
+}
+
+// ParseLandingRoutes reads a list of the form
+// "participant=/registrations,admin=/events". Roles are normalised like
+// account roles and paths must be absolute frontend paths.
+func ParseLandingRoutes(raw string) (LandingRoutes, error) {
+	var routes LandingRoutes
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		role, path, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid landing route %q, want role=/path", entry)
+		}
+		route := LandingRoute{Role: normalizeRole(role), Path: sanitizePostLoginPath(path)}
+		if route.Role == "" {
+			return nil, fmt.Errorf("landing route %q: unknown role %q", entry, role)
+		}
+		if route.Path == "" {
+			return nil, fmt.Errorf("landing route %q: path must start with a single /", entry)
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// For returns the landing route for a user holding roles, falling back to
+// the default post-login path.
+func (l LandingRoutes) For(roles []string) string {
+	for _, route := range l {
+		if hasRole(roles, route.Role) {
+			return route.Path
+		}
+	}
+	return defaultPostLoginPath
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/innhopp/central/backend/httpx"
+)
+
+// ReconcileReport lists the accounts and participant profiles that do not
+// find each other. A login reaches its profile through the profile's
+// account_id, the OIDC subject recorded on it, or a case-insensitive email
+// match; anything listed here gets 404 from the /me endpoints.
+type ReconcileReport struct {
+	AccountsWithoutProfile []UnlinkedAccount `json:"accounts_without_profile"`
+	ProfilesWithoutAccount []UnlinkedProfile `json:"profiles_without_account"`
+}
+
+// UnlinkedAccount is an account no participant profile points at or shares
+// an email with.
+type UnlinkedAccount struct {
+	ID          int64      `json:"id"`
+	Email       string     `json:"email"`
+	FullName    string     `json:"full_name"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+}
+
+// UnlinkedProfile is a participant profile that no account reaches. AccountID
+// is set when the profile points at a rejected account.
+type UnlinkedProfile struct {
+	ID        int64     `json:"id"`
+	FullName  string    `json:"full_name"`
+	Email     string    `json:"email"`
+	AccountID *int64    `json:"account_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// reconcileAccounts reports accounts without a participant profile and
+// profiles without an account so staff can link or correct them. Rejected
+// accounts are left out because they cannot sign in.
+func (h *Handler) reconcileAccounts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	report := ReconcileReport{AccountsWithoutProfile: []UnlinkedAccount{}, ProfilesWithoutAccount: []UnlinkedProfile{}}
+
+	rows, err := h.db.Query(ctx, `
+		SELECT a.id, a.email, COALESCE(a.full_name, ''), a.created_at, a.last_login_at
+		FROM accounts a
+		WHERE a.rejected_at IS NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM participant_profiles p
+			WHERE p.account_id = a.id OR p.account_subject = a.subject OR lower(p.email) = lower(a.email)
+		  )
+		ORDER BY lower(a.email), a.id`)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to reconcile accounts")
+		return
+	}
+	for rows.Next() {
+		var a UnlinkedAccount
+		if err := rows.Scan(&a.ID, &a.Email, &a.FullName, &a.CreatedAt, &a.LastLoginAt); err != nil {
+			rows.Close()
+			httpx.Error(w, http.StatusInternalServerError, "failed to parse account")
+			return
+		}
+		report.AccountsWithoutProfile = append(report.AccountsWithoutProfile, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to reconcile accounts")
+		return
+	}
+
+	rows, err = h.db.Query(ctx, `
+		SELECT p.id, p.full_name, p.email, p.account_id, p.created_at
+		FROM participant_profiles p
+		WHERE NOT EXISTS (
+			SELECT 1 FROM accounts a
+			WHERE a.rejected_at IS NULL
+			  AND (a.id = p.account_id OR a.subject = p.account_subject OR lower(a.email) = lower(p.email))
+		)
+		ORDER BY lower(p.full_name), p.id`)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to reconcile accounts")
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p UnlinkedProfile
+		if err := rows.Scan(&p.ID, &p.FullName, &p.Email, &p.AccountID, &p.CreatedAt); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to parse participant profile")
+			return
+		}
+		report.ProfilesWithoutAccount = append(report.ProfilesWithoutAccount, p)
+	}
+	if err := rows.Err(); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to reconcile accounts")
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, report)
+}
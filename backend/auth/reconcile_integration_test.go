@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestReconcileListsAccountsAndProfilesThatDoNotMeet(t *testing.T) {
+	db := openAuthTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ensureAuthTestSchema(t, ctx, db)
+
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 10)
+	insertAccount := func(name string) int64 {
+		t.Helper()
+		var id int64
+		if err := db.QueryRow(ctx,
+			`INSERT INTO accounts (subject, email) VALUES ($1, $2) RETURNING id`,
+			"reconcile-"+name+"-"+suffix, name+"-"+suffix+"@example.com",
+		).Scan(&id); err != nil {
+			t.Fatalf("insert account failed: %v", err)
+		}
+		t.Cleanup(func() { db.Exec(context.Background(), `DELETE FROM accounts WHERE id = $1`, id) })
+		return id
+	}
+	insertProfile := func(name string, accountID *int64, subject *string) int64 {
+		t.Helper()
+		var id int64
+		if err := db.QueryRow(ctx,
+			`INSERT INTO participant_profiles (full_name, email, account_id, account_subject) VALUES ($1, $2, $3, $4) RETURNING id`,
+			name, "profile-"+name+"-"+suffix+"@example.com", accountID, subject,
+		).Scan(&id); err != nil {
+			t.Fatalf("insert profile failed: %v", err)
+		}
+		t.Cleanup(func() { db.Exec(context.Background(), `DELETE FROM participant_profiles WHERE id = $1`, id) })
+		return id
+	}
+
+	linkedAccount := insertAccount("linked")
+	linkedProfile := insertProfile("linked", &linkedAccount, nil)
+	insertAccount("by-subject")
+	subject := "reconcile-by-subject-" + suffix
+	subjectProfile := insertProfile("by-subject", nil, &subject)
+	loneAccount := insertAccount("lone")
+	rejectedAccount := insertAccount("rejected")
+	if _, err := db.Exec(ctx, `UPDATE accounts SET rejected_at = NOW() WHERE id = $1`, rejectedAccount); err != nil {
+		t.Fatalf("reject account failed: %v", err)
+	}
+	loneProfile := insertProfile("lone", nil, nil)
+
+	h := &Handler{db: db}
+	rec := httptest.NewRecorder()
+	h.reconcileAccounts(rec, httptest.NewRequest(http.MethodGet, "/reconcile", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	var report ReconcileReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+
+	accounts := map[int64]bool{}
+	for _, a := range report.AccountsWithoutProfile {
+		accounts[a.ID] = true
+	}
+	if !accounts[loneAccount] || accounts[linkedAccount] || accounts[rejectedAccount] {
+		t.Fatalf("accounts without profile = %+v, want %d listed and neither %d nor the rejected %d", report.AccountsWithoutProfile, loneAccount, linkedAccount, rejectedAccount)
+	}
+	profiles := map[int64]bool{}
+	for _, p := range report.ProfilesWithoutAccount {
+		profiles[p.ID] = true
+	}
+	if !profiles[loneProfile] || profiles[linkedProfile] || profiles[subjectProfile] {
+		t.Fatalf("profiles without account = %+v, want %d listed and neither %d nor %d", report.ProfilesWithoutAccount, loneProfile, linkedProfile, subjectProfile)
+	}
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/innhopp/central/backend/rbac"
+)
+
+func TestReconcileRequiresAccountManagement(t *testing.T) {
+	h := &Handler{}
+	for _, tc := range []struct {
+		roles []rbac.Role
+		want  int
+	}{
+		{roles: nil, want: http.StatusUnauthorized},
+		{roles: []rbac.Role{rbac.RoleStaff}, want: http.StatusForbidden},
+		{roles: []rbac.Role{rbac.RoleParticipant}, want: http.StatusForbidden},
+	} {
+		roles := tc.roles
+		router := h.Routes(rbac.NewEnforcer(func(*http.Request) []rbac.Role { return roles }))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/reconcile", nil))
+		if rec.Code != tc.want {
+			t.Fatalf("roles %v: status = %d, want %d", roles, rec.Code, tc.want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"log"
+	"sort"
+
+	"github.com/innhopp/central/backend/rbac"
+)
+
+// DefaultMaxRoles is how many roles a session keeps when Config.MaxRoles is
+// unset: every role rbac defines.
+var DefaultMaxRoles = len(rbac.Roles)
+
+// rolePriority ranks a role by its position in rbac.Roles, most privileged
+// first. Roles rbac does not define rank after all of them.
+func rolePriority(role string) int {
+	for i, known := range rbac.Roles {
+		if string(known) == role {
+			return i
+		}
+	}
+	return len(rbac.Roles)
+}
+
+// limitRoles orders roles from most to least privileged, unknown roles
+// alphabetically last, and keeps at most the configured maximum. Dropped
+// roles are logged so an oversized IdP group mapping is visible.
+func (h *Handler) limitRoles(accountID int64, roles []string) []string {
+	sorted := append([]string{}, roles...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		pi, pj := rolePriority(sorted[i]), rolePriority(sorted[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return sorted[i] < sorted[j]
+	})
+
+	limit := h.cfg.MaxRoles
+	if limit <= 0 {
+		limit = DefaultMaxRoles
+	}
+	if len(sorted) <= limit {
+		return sorted
+	}
+	log.Printf("account %d has %d roles; keeping %v and dropping %v", accountID, len(sorted), sorted[:limit], sorted[limit:])
+	return sorted[:limit]
+}
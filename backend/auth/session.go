@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/hmac"
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	"github.com/innhopp/central/backend/httpx"
+	"github.com/innhopp/central/backend/internal/clock"
 )
 
 // Claims represents the authenticated user context embedded within a session
@@ -22,10 +24,25 @@ type Claims struct {
 	FullName     string              `json:"full_name"`
 	Roles        []string            `json:"roles"`
 	Impersonator *ImpersonatorClaims `json:"impersonator,omitempty"`
+	Device       *DeviceBinding      `json:"device,omitempty"`
 	IssuedAt     int64               `json:"iat"`
 	ExpiresAt    int64               `json:"exp"`
+	// PendingApproval marks sessions of accounts an admin has not approved
+	// yet; RequireApproval blocks them from the API.
+	PendingApproval bool `json:"pending_approval,omitempty"`
 }
 
+// DeviceBinding ties a session to the client that received it. Both values
+// are hashes; DeviceID is empty when the client did not send a device id.
+type DeviceBinding struct {
+	UserAgent string `json:"ua"`
+	DeviceID  string `json:"did,omitempty"`
+}
+
+// DeviceIDHeader carries an opaque, client-generated device identifier used
+// for session binding.
+const DeviceIDHeader = "X-Device-ID"
+
 // ImpersonatorClaims captures the original authenticated identity when an
 // admin temporarily assumes a participant session.
 type ImpersonatorClaims struct {
@@ -42,12 +59,32 @@ const claimsKey contextKey = "authClaims"
 // SessionManager encapsulates signing and verifying session tokens that are
 // stored as HTTP cookies or bearer tokens.
 type SessionManager struct {
-	secret     []byte
+	// secrets holds the signing secrets in order: the first signs new tokens
+	// and every one of them verifies, so a rotated-out secret keeps its
+	// sessions valid until they expire.
+	secrets    [][]byte
 	cookieName string
 	lifetime   time.Duration
 	secure     bool
+	clock      clock.Clock
+	bindDevice bool
+	onActivity ActivityRecorder
+	revoked    RevocationCheck
+	// downloadTTL and downloadRoutes configure download tokens; see
+	// IssueDownloadToken.
+	downloadTTL    time.Duration
+	downloadRoutes []string
 }
 
+// ActivityRecorder is notified with the account id and request time whenever
+// an authenticated, non-impersonated session is used.
+type ActivityRecorder func(ctx context.Context, accountID int64, at time.Time)
+
+// RevocationCheck reports whether a session that verified and has not
+// expired has nevertheless been revoked, for example because an admin
+// rejected the account after it was issued.
+type RevocationCheck func(ctx context.Context, claims *Claims) (bool, error)
+
 // NewSessionManager constructs a session manager with the provided HMAC
 // secret. The secret is required and should be randomly generated for
 // production deployments.
@@ -58,21 +95,66 @@ func NewSessionManager(secret string, secure bool) (*SessionManager, error) {
 	}
 
 	return &SessionManager{
-		secret:     []byte(trimmed),
-		cookieName: "innhopp_session",
-		lifetime:   24 * time.Hour,
-		secure:     secure,
+		secrets:     [][]byte{[]byte(trimmed)},
+		cookieName:  "innhopp_session",
+		lifetime:    24 * time.Hour,
+		secure:      secure,
+		clock:       clock.Real{},
+		downloadTTL: DefaultDownloadTokenTTL,
 	}, nil
 }
 
+// SetPreviousSecrets sets the secrets that still verify tokens after a
+// rotation, in the order they are tried after the primary secret. Blank
+// entries are ignored.
+func (m *SessionManager) SetPreviousSecrets(secrets []string) {
+	m.secrets = m.secrets[:1]
+	for _, secret := range secrets {
+		if trimmed := strings.TrimSpace(secret); trimmed != "" {
+			m.secrets = append(m.secrets, []byte(trimmed))
+		}
+	}
+}
+
+// SetClock replaces the time source used for issuing and expiring sessions.
+func (m *SessionManager) SetClock(c clock.Clock) {
+	if c == nil {
+		c = clock.Real{}
+	}
+	m.clock = c
+}
+
+// SetActivityRecorder registers a callback invoked for each authenticated
+// request. Passing nil disables activity tracking.
+func (m *SessionManager) SetActivityRecorder(recorder ActivityRecorder) {
+	m.onActivity = recorder
+}
+
+// SetRevocationCheck registers a callback consulted for each authenticated
+// request; revoked sessions get a 401. Passing nil disables the check.
+func (m *SessionManager) SetRevocationCheck(check RevocationCheck) {
+	m.revoked = check
+}
+
+// SetDeviceBinding enables binding newly issued sessions to the requesting
+// client's User-Agent and device id. While enabled, bound sessions presented
+// from a different client are rejected.
+func (m *SessionManager) SetDeviceBinding(enabled bool) {
+	m.bindDevice = enabled
+}
+
 // Issue creates a session for the supplied claims and writes it to the
 // response as a secure, HTTP only cookie. The raw token is returned so that
 // API clients can persist it if necessary.
-func (m *SessionManager) Issue(w http.ResponseWriter, claims *Claims) (string, error) {
-	now := time.Now()
+func (m *SessionManager) Issue(w http.ResponseWriter, r *http.Request, claims *Claims) (string, error) {
+	now := m.clock.Now()
 	payload := *claims
 	payload.IssuedAt = now.Unix()
 	payload.ExpiresAt = now.Add(m.lifetime).Unix()
+	payload.Device = nil
+	if m.bindDevice && r != nil {
+		payload.Device = deviceBindingFor(r)
+	}
 
 	token, err := m.sign(&payload)
 	if err != nil {
@@ -112,6 +194,15 @@ func (m *SessionManager) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		token := m.extractToken(r)
 		if token == "" {
+			if download := m.downloadRequestToken(r); download != "" {
+				claims, err := m.verifyDownloadToken(download, r.URL.Path)
+				if err != nil {
+					httpx.Error(w, http.StatusUnauthorized, "invalid download token")
+					return
+				}
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), claimsKey, claims)))
+				return
+			}
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -122,11 +213,32 @@ func (m *SessionManager) Middleware(next http.Handler) http.Handler {
 			return
 		}
 
-		if claims.ExpiresAt <= time.Now().Unix() {
+		if claims.ExpiresAt <= m.clock.Now().Unix() {
 			httpx.Error(w, http.StatusUnauthorized, "session expired")
 			return
 		}
 
+		if m.bindDevice && !claims.Device.matches(r) {
+			httpx.Error(w, http.StatusUnauthorized, "session is bound to another device")
+			return
+		}
+
+		if m.revoked != nil && claims.AccountID > 0 {
+			revoked, err := m.revoked(r.Context(), claims)
+			if err != nil {
+				httpx.Error(w, http.StatusInternalServerError, "failed to check session")
+				return
+			}
+			if revoked {
+				httpx.Error(w, http.StatusUnauthorized, "session revoked")
+				return
+			}
+		}
+
+		if m.onActivity != nil && claims.Impersonator == nil && claims.AccountID > 0 {
+			m.onActivity(r.Context(), claims.AccountID, m.clock.Now())
+		}
+
 		ctx := context.WithValue(r.Context(), claimsKey, claims)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -149,6 +261,32 @@ func (m *SessionManager) extractToken(r *http.Request) string {
 	return strings.TrimSpace(authz[len("bearer "):])
 }
 
+func deviceBindingFor(r *http.Request) *DeviceBinding {
+	binding := &DeviceBinding{UserAgent: fingerprint(r.UserAgent())}
+	if deviceID := strings.TrimSpace(r.Header.Get(DeviceIDHeader)); deviceID != "" {
+		binding.DeviceID = fingerprint(deviceID)
+	}
+	return binding
+}
+
+// matches reports whether r comes from the bound client. Sessions issued
+// without a binding match any request.
+func (b *DeviceBinding) matches(r *http.Request) bool {
+	if b == nil {
+		return true
+	}
+	current := deviceBindingFor(r)
+	if !hmac.Equal([]byte(b.UserAgent), []byte(current.UserAgent)) {
+		return false
+	}
+	return b.DeviceID == "" || hmac.Equal([]byte(b.DeviceID), []byte(current.DeviceID))
+}
+
+func fingerprint(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
 // FromContext retrieves the active session claims, if any.
 func FromContext(ctx context.Context) *Claims {
 	if ctx == nil {
@@ -165,38 +303,69 @@ func (m *SessionManager) sign(claims *Claims) (string, error) {
 	}
 
 	payload := base64.RawURLEncoding.EncodeToString(raw)
-	mac := hmac.New(sha256.New, m.secret)
-	mac.Write([]byte(payload))
-	sig := mac.Sum(nil)
-
-	signature := base64.RawURLEncoding.EncodeToString(sig)
+	signature := base64.RawURLEncoding.EncodeToString(signWith(m.secrets[0], payload))
 	return payload + "." + signature, nil
 }
 
-func (m *SessionManager) verify(token string) (*Claims, error) {
+func signWith(secret []byte, message string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(message))
+	return mac.Sum(nil)
+}
+
+// signedByAny reports whether sig is the signature of message under any of
+// the configured secrets. Every secret is compared, and a signature of the
+// wrong length is compared as zeros, so the time taken does not reveal which
+// secret matched or how the signature was malformed.
+func (m *SessionManager) signedByAny(sig []byte, message string) bool {
+	if len(sig) != sha256.Size {
+		sig = make([]byte, sha256.Size)
+	}
+	matched := 0
+	for _, secret := range m.secrets {
+		matched |= subtle.ConstantTimeCompare(sig, signWith(secret, message))
+	}
+	return matched == 1
+}
+
+var (
+	errTokenStructure = errors.New("token structure is invalid")
+	errTokenSignature = errors.New("token signature mismatch")
+)
+
+// checkSignature returns the payload of a "payload.signature" token whose
+// signature over prefix+payload is valid. Both parts must be present and the
+// signature must be a full HMAC-SHA256 digest; there is no unsigned form. The
+// signature is compared even when the token is malformed.
+func (m *SessionManager) checkSignature(token, prefix string) (string, error) {
 	parts := strings.Split(token, ".")
-	if len(parts) != 2 {
-		return nil, errors.New("token structure is invalid")
+	var payload, encodedSig string
+	if len(parts) == 2 {
+		payload, encodedSig = parts[0], parts[1]
 	}
+	sig, decodeErr := base64.RawURLEncoding.DecodeString(encodedSig)
+	valid := m.signedByAny(sig, prefix+payload)
+
+	switch {
+	case payload == "" || encodedSig == "":
+		return "", errTokenStructure
+	case decodeErr != nil, len(sig) != sha256.Size, !valid:
+		return "", errTokenSignature
+	}
+	return payload, nil
+}
 
-	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+func (m *SessionManager) verify(token string) (*Claims, error) {
+	encoded, err := m.checkSignature(token, "")
 	if err != nil {
 		return nil, err
 	}
 
-	providedSig, err := base64.RawURLEncoding.DecodeString(parts[1])
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
 	if err != nil {
 		return nil, err
 	}
 
-	mac := hmac.New(sha256.New, m.secret)
-	mac.Write([]byte(parts[0]))
-	expected := mac.Sum(nil)
-
-	if !hmac.Equal(providedSig, expected) {
-		return nil, errors.New("token signature mismatch")
-	}
-
 	var claims Claims
 	if err := json.Unmarshal(payload, &claims); err != nil {
 		return nil, err
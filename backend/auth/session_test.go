@@ -0,0 +1,338 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/innhopp/central/backend/internal/clock"
+	"github.com/innhopp/central/backend/rbac"
+)
+
+func TestSessionMiddlewareRejectsExpiredSessionsUsingInjectedClock(t *testing.T) {
+	manager, err := NewSessionManager("test-secret", false)
+	if err != nil {
+		t.Fatalf("NewSessionManager() returned error: %v", err)
+	}
+	issuedAt := time.Date(2025, 6, 14, 9, 0, 0, 0, time.UTC)
+	manager.SetClock(clock.Fixed{At: issuedAt})
+
+	token, err := manager.Issue(httptest.NewRecorder(), nil, &Claims{AccountID: 1, Email: "jumper@example.com"})
+	if err != nil {
+		t.Fatalf("Issue() returned error: %v", err)
+	}
+
+	handler := manager.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	serve := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	manager.SetClock(clock.Fixed{At: issuedAt.Add(time.Hour)})
+	if code := serve(); code != http.StatusNoContent {
+		t.Fatalf("status before expiry = %d, want %d", code, http.StatusNoContent)
+	}
+
+	manager.SetClock(clock.Fixed{At: issuedAt.Add(25 * time.Hour)})
+	if code := serve(); code != http.StatusUnauthorized {
+		t.Fatalf("status after expiry = %d, want %d", code, http.StatusUnauthorized)
+	}
+}
+
+func TestIDTokenClaimsValidateUsesSuppliedTime(t *testing.T) {
+	now := time.Date(2025, 6, 14, 9, 0, 0, 0, time.UTC)
+	claims := idTokenClaims{
+		Issuer:   "https://issuer.example.com",
+		Audience: audienceClaim{"client"},
+		Nonce:    "nonce",
+		Email:    "jumper@example.com",
+		Expiry:   now.Add(time.Minute).Unix(),
+	}
+
+	if err := claims.Validate("client", "https://issuer.example.com", "nonce", now); err != nil {
+		t.Fatalf("Validate() returned error: %v", err)
+	}
+	if err := claims.Validate("client", "https://issuer.example.com", "nonce", now.Add(2*time.Minute)); err == nil {
+		t.Fatal("Validate() expected error for expired token")
+	}
+}
+
+func TestSessionMiddlewareRejectsTokensFromOtherDevicesWhenBindingEnabled(t *testing.T) {
+	manager, err := NewSessionManager("test-secret", false)
+	if err != nil {
+		t.Fatalf("NewSessionManager() returned error: %v", err)
+	}
+	manager.SetDeviceBinding(true)
+
+	issueReq := httptest.NewRequest(http.MethodGet, "/", nil)
+	issueReq.Header.Set("User-Agent", "jumper-phone")
+	issueReq.Header.Set(DeviceIDHeader, "device-1")
+	token, err := manager.Issue(httptest.NewRecorder(), issueReq, &Claims{AccountID: 1})
+	if err != nil {
+		t.Fatalf("Issue() returned error: %v", err)
+	}
+
+	handler := manager.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	serve := func(userAgent, deviceID string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("User-Agent", userAgent)
+		if deviceID != "" {
+			req.Header.Set(DeviceIDHeader, deviceID)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := serve("jumper-phone", "device-1"); code != http.StatusNoContent {
+		t.Fatalf("status from bound device = %d, want %d", code, http.StatusNoContent)
+	}
+	if code := serve("attacker-laptop", "device-1"); code != http.StatusUnauthorized {
+		t.Fatalf("status from other user agent = %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := serve("jumper-phone", "device-2"); code != http.StatusUnauthorized {
+		t.Fatalf("status from other device id = %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	manager.SetDeviceBinding(false)
+	if code := serve("attacker-laptop", ""); code != http.StatusNoContent {
+		t.Fatalf("status with binding disabled = %d, want %d", code, http.StatusNoContent)
+	}
+}
+
+func TestLastSeenTrackerThrottlesWritesPerAccount(t *testing.T) {
+	tracker := newLastSeenTracker(nil, 5*time.Minute)
+	at := time.Date(2025, 6, 14, 9, 0, 0, 0, time.UTC)
+
+	if !tracker.due(1, at) {
+		t.Fatal("first activity should be recorded")
+	}
+	if tracker.due(1, at.Add(time.Minute)) {
+		t.Fatal("activity within the interval should be skipped")
+	}
+	if !tracker.due(2, at.Add(time.Minute)) {
+		t.Fatal("activity for another account should be recorded")
+	}
+	if !tracker.due(1, at.Add(6*time.Minute)) {
+		t.Fatal("activity after the interval should be recorded")
+	}
+}
+
+func TestLastSeenTrackerPrunesEntriesPastTheInterval(t *testing.T) {
+	tracker := newLastSeenTracker(nil, 5*time.Minute)
+	at := time.Date(2025, 6, 14, 9, 0, 0, 0, time.UTC)
+	tracker.due(1, at)
+	tracker.due(2, at.Add(3*time.Minute))
+
+	tracker.prune(at.Add(6 * time.Minute))
+	if got := tracker.len(); got != 1 {
+		t.Fatalf("entries after prune = %d, want only the account seen within the interval", got)
+	}
+	if tracker.due(2, at.Add(6*time.Minute)) {
+		t.Fatal("pruning should keep the throttle for recently seen accounts")
+	}
+}
+
+func TestRequireApprovalBlocksPendingSessions(t *testing.T) {
+	manager, err := NewSessionManager("test-secret", false)
+	if err != nil {
+		t.Fatalf("NewSessionManager() returned error: %v", err)
+	}
+	pending, _ := manager.Issue(httptest.NewRecorder(), nil, &Claims{AccountID: 1, Roles: []string{"participant"}, PendingApproval: true})
+	approved, _ := manager.Issue(httptest.NewRecorder(), nil, &Claims{AccountID: 2, Roles: []string{"participant"}})
+
+	handler := manager.Middleware(RequireApproval(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+	cases := []struct {
+		token, path string
+		want        int
+	}{
+		{pending, "/api/events/events", http.StatusForbidden},
+		{pending, "/api/participants/profiles/me", http.StatusForbidden},
+		{pending, "/api/auth/session", http.StatusNoContent},
+		{pending, "/api/auth/logout", http.StatusNoContent},
+		{approved, "/api/events/events", http.StatusNoContent},
+		{"", "/api/events/events", http.StatusNoContent},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Fatalf("GET %s status = %d, want %d", tc.path, rec.Code, tc.want)
+		}
+	}
+}
+
+func TestRevokedSessionsAreRejected(t *testing.T) {
+	manager, err := NewSessionManager("test-secret", false)
+	if err != nil {
+		t.Fatalf("NewSessionManager() returned error: %v", err)
+	}
+	rejected, _ := manager.Issue(httptest.NewRecorder(), nil, &Claims{AccountID: 1, Roles: []string{"participant"}})
+	active, _ := manager.Issue(httptest.NewRecorder(), nil, &Claims{AccountID: 2, Roles: []string{"participant"}})
+	manager.SetRevocationCheck(func(_ context.Context, claims *Claims) (bool, error) {
+		return claims.AccountID == 1, nil
+	})
+
+	handler := manager.Middleware(RequireApproval(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+	for token, want := range map[string]int{rejected: http.StatusUnauthorized, active: http.StatusNoContent} {
+		req := httptest.NewRequest(http.MethodGet, "/api/events/events", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Fatalf("status = %d, want %d", rec.Code, want)
+		}
+	}
+}
+
+func TestDownloadTokenIsScopedToOnePathAndExpires(t *testing.T) {
+	manager, err := NewSessionManager("test-secret", false)
+	if err != nil {
+		t.Fatalf("NewSessionManager() returned error: %v", err)
+	}
+	issuedAt := time.Date(2025, 6, 14, 9, 0, 0, 0, time.UTC)
+	manager.SetClock(clock.Fixed{At: issuedAt})
+	manager.SetDownloadRoutes([]string{"/api/participants/profiles/{profileID}/photo"})
+
+	if _, _, err := manager.IssueDownloadToken(&Claims{AccountID: 1}, "/api/participants/profiles/7"); err == nil {
+		t.Fatal("IssueDownloadToken() accepted a path that is not a download route")
+	}
+	token, _, err := manager.IssueDownloadToken(&Claims{AccountID: 1, Roles: []string{"staff"}}, "/api/participants/profiles/7/photo")
+	if err != nil {
+		t.Fatalf("IssueDownloadToken() returned error: %v", err)
+	}
+
+	handler := manager.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if claims := FromContext(r.Context()); claims == nil || claims.AccountID != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	serve := func(target string, bearer bool) int {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if bearer {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := serve("/api/participants/profiles/7/photo?size=thumb&token="+token, false); code != http.StatusNoContent {
+		t.Fatalf("status with download token = %d, want %d", code, http.StatusNoContent)
+	}
+	if code := serve("/api/participants/profiles/8/photo?token="+token, false); code != http.StatusUnauthorized {
+		t.Fatalf("status for another resource = %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := serve("/api/participants/profiles/7/photo", true); code != http.StatusUnauthorized {
+		t.Fatalf("download token accepted as a session: status = %d", code)
+	}
+
+	manager.SetClock(clock.Fixed{At: issuedAt.Add(DefaultDownloadTokenTTL + time.Second)})
+	if code := serve("/api/participants/profiles/7/photo?token="+token, false); code != http.StatusUnauthorized {
+		t.Fatalf("status after expiry = %d, want %d", code, http.StatusUnauthorized)
+	}
+}
+
+func TestVerifyAcceptsPreviousSecretsDuringRotation(t *testing.T) {
+	old, err := NewSessionManager("old-secret", false)
+	if err != nil {
+		t.Fatalf("NewSessionManager() returned error: %v", err)
+	}
+	token, err := old.Issue(httptest.NewRecorder(), nil, &Claims{AccountID: 1, Email: "jumper@example.com"})
+	if err != nil {
+		t.Fatalf("Issue() returned error: %v", err)
+	}
+
+	rotated, err := NewSessionManager("new-secret", false)
+	if err != nil {
+		t.Fatalf("NewSessionManager() returned error: %v", err)
+	}
+	if _, err := rotated.verify(token); err == nil {
+		t.Fatalf("verify() accepted a token signed with an unknown secret")
+	}
+
+	rotated.SetPreviousSecrets([]string{"", " older-secret ", "old-secret"})
+	claims, err := rotated.verify(token)
+	if err != nil {
+		t.Fatalf("verify() during overlap returned error: %v", err)
+	}
+	if claims.AccountID != 1 {
+		t.Fatalf("AccountID = %d, want 1", claims.AccountID)
+	}
+
+	fresh, err := rotated.Issue(httptest.NewRecorder(), nil, &Claims{AccountID: 2})
+	if err != nil {
+		t.Fatalf("Issue() returned error: %v", err)
+	}
+	if _, err := old.verify(fresh); err == nil {
+		t.Fatalf("new token was signed with a previous secret")
+	}
+	primaryOnly, _ := NewSessionManager("new-secret", false)
+	if _, err := primaryOnly.verify(fresh); err != nil {
+		t.Fatalf("new token does not verify under the primary secret: %v", err)
+	}
+
+	rotated.SetPreviousSecrets(nil)
+	if _, err := rotated.verify(token); err == nil {
+		t.Fatalf("verify() accepted a token after its secret was retired")
+	}
+}
+
+func TestVerifyRejectsUnsignedAndTruncatedTokens(t *testing.T) {
+	manager, err := NewSessionManager("test-secret", false)
+	if err != nil {
+		t.Fatalf("NewSessionManager() returned error: %v", err)
+	}
+	token, err := manager.Issue(httptest.NewRecorder(), nil, &Claims{AccountID: 1, Roles: []string{string(rbac.RoleAdmin)}})
+	if err != nil {
+		t.Fatalf("Issue() returned error: %v", err)
+	}
+	payload, signature, _ := strings.Cut(token, ".")
+	noneHeader := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+
+	cases := map[string]string{
+		"empty":               "",
+		"payload only":        payload,
+		"empty signature":     payload + ".",
+		"empty payload":       "." + signature,
+		"dot only":            ".",
+		"none algorithm":      noneHeader + "." + payload + ".",
+		"extra part":          token + ".",
+		"truncated signature": payload + "." + signature[:len(signature)-4],
+		"single byte":         payload + "." + base64.RawURLEncoding.EncodeToString([]byte{0}),
+		"padded signature":    payload + "." + signature + "AAAA",
+		"invalid base64":      payload + ".!!!!",
+		"truncated payload":   payload[:len(payload)-4] + "." + signature,
+	}
+	for name, forged := range cases {
+		if claims, err := manager.verify(forged); err == nil {
+			t.Errorf("%s: verify() accepted %q with claims %+v", name, forged, claims)
+		}
+	}
+
+	if _, err := manager.verify(token); err != nil {
+		t.Fatalf("verify() rejected the genuine token: %v", err)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/hex"
 	"sync"
 	"time"
+
+	"github.com/innhopp/central/backend/internal/clock"
 )
 
 type stateEntry struct {
@@ -19,6 +21,7 @@ type StateStore struct {
 	mu     sync.Mutex
 	values map[string]stateEntry
 	ttl    time.Duration
+	clock  clock.Clock
 }
 
 // NewStateStore constructs a state store with the provided TTL.
@@ -26,6 +29,7 @@ func NewStateStore(ttl time.Duration) *StateStore {
 	return &StateStore{
 		values: make(map[string]stateEntry),
 		ttl:    ttl,
+		clock:  clock.Real{},
 	}
 }
 
@@ -46,7 +50,7 @@ func (s *StateStore) Create(redirectPath string) (state string, nonce string, er
 	s.values[state] = stateEntry{
 		nonce:        nonce,
 		redirectPath: redirectPath,
-		expiry:       time.Now().Add(s.ttl),
+		expiry:       s.clock.Now().Add(s.ttl),
 	}
 	s.evictExpiredLocked()
 	return state, nonce, nil
@@ -64,7 +68,7 @@ func (s *StateStore) Verify(state string) (string, string, bool) {
 	}
 
 	delete(s.values, state)
-	if time.Now().After(entry.expiry) {
+	if s.clock.Now().After(entry.expiry) {
 		return "", "", false
 	}
 
@@ -72,8 +76,23 @@ func (s *StateStore) Verify(state string) (string, string, bool) {
 	return entry.nonce, entry.redirectPath, true
 }
 
+// Sweep drops expired entries. Create and Verify also sweep, so this only
+// matters when logins are rare.
+func (s *StateStore) Sweep() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evictExpiredLocked()
+}
+
+// Len returns the number of pending state entries.
+func (s *StateStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.values)
+}
+
 func (s *StateStore) evictExpiredLocked() {
-	now := time.Now()
+	now := s.clock.Now()
 	for key, entry := range s.values {
 		if now.After(entry.expiry) {
 			delete(s.values, key)
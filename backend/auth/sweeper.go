@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"expvar"
+	"log"
+	"time"
+)
+
+const (
+	// cacheSweepInterval is how often the background sweeper runs.
+	cacheSweepInterval = time.Minute
+	// jwksRefreshMargin is how long before expiry the sweeper refetches the
+	// provider's keys, so logins never wait on the fetch.
+	jwksRefreshMargin = 5 * time.Minute
+)
+
+// cacheMetrics publishes the sizes of the in-memory auth caches under
+// auth_caches in /api/debug/vars.
+var cacheMetrics = expvar.NewMap("auth_caches")
+
+// startSweeper runs sweepCaches every interval until Close is called.
+func (h *Handler) startSweeper(interval time.Duration) {
+	h.stopSweeper = make(chan struct{})
+	h.sweeperDone = make(chan struct{})
+	go func() {
+		defer close(h.sweeperDone)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				h.sweepCaches()
+			case <-h.stopSweeper:
+				return
+			}
+		}
+	}()
+}
+
+// sweepCaches drops expired login state and last-seen entries, refreshes
+// provider keys that are close to expiry and updates the cache gauges.
+func (h *Handler) sweepCaches() {
+	h.states.Sweep()
+	if h.lastSeen != nil {
+		h.lastSeen.prune(h.sessions.clock.Now())
+	}
+	if h.keys != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), h.httpClient.Timeout)
+		if err := h.keys.refreshIfStale(ctx, jwksRefreshMargin); err != nil {
+			log.Printf("jwks background refresh failed: %v", err)
+		}
+		cancel()
+	}
+	h.publishCacheMetrics()
+}
+
+func (h *Handler) publishCacheMetrics() {
+	cacheMetrics.Set("state_entries", expvarInt(int64(h.states.Len())))
+	if h.lastSeen != nil {
+		cacheMetrics.Set("last_seen_entries", expvarInt(int64(h.lastSeen.len())))
+	}
+	if h.keys != nil {
+		keys, fetched := h.keys.stats()
+		cacheMetrics.Set("jwks_keys", expvarInt(int64(keys)))
+		if !fetched.IsZero() {
+			cacheMetrics.Set("jwks_age_seconds", expvarInt(int64(time.Since(fetched).Seconds())))
+		}
+	}
+}
+
+func expvarInt(value int64) *expvar.Int {
+	v := new(expvar.Int)
+	v.Set(value)
+	return v
+}
+
+// Close stops the background cache sweeper and waits for it, and for any
+// last_seen_at writes still running, to finish. It is safe to call more than
+// once.
+func (h *Handler) Close() {
+	h.closeOnce.Do(func() {
+		if h.lastSeen != nil {
+			defer h.lastSeen.writes.Wait()
+		}
+		if h.stopSweeper == nil {
+			return
+		}
+		close(h.stopSweeper)
+		<-h.sweeperDone
+	})
+}
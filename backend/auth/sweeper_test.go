@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/innhopp/central/backend/internal/clock"
+)
+
+func TestSweepCachesDropsExpiredStateAndPublishesSize(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	states := NewStateStore(time.Minute)
+	states.clock = clock.Fixed{At: start}
+	if _, _, err := states.Create("/events"); err != nil {
+		t.Fatalf("Create() returned error: %v", err)
+	}
+	h := &Handler{states: states}
+
+	h.sweepCaches()
+	if got := cacheMetrics.Get("state_entries").String(); got != "1" {
+		t.Fatalf("state_entries = %s, want 1", got)
+	}
+
+	states.clock = clock.Fixed{At: start.Add(2 * time.Minute)}
+	h.sweepCaches()
+	if states.Len() != 0 {
+		t.Fatalf("Len() after sweep = %d, want 0", states.Len())
+	}
+	if got := cacheMetrics.Get("state_entries").String(); got != "0" {
+		t.Fatalf("state_entries = %s, want 0", got)
+	}
+}
+
+func TestCloseStopsSweeper(t *testing.T) {
+	h := &Handler{states: NewStateStore(time.Minute)}
+	h.startSweeper(time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		h.Close()
+		h.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Close() did not stop the sweeper")
+	}
+}
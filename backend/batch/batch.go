@@ -0,0 +1,166 @@
+// Package batch runs several API requests in one round trip. Each
+// sub-request is dispatched through the full router with the caller's
+// session headers, so authentication and RBAC apply to it exactly as if it
+// had been sent on its own.
+package batch
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/innhopp/central/backend/httpx"
+)
+
+// MaxRequests caps the number of sub-requests in one batch.
+const MaxRequests = 20
+
+// Path is where the batch endpoint is mounted; sub-requests may not target it.
+const Path = "/api/batch"
+
+// Request is one sub-request. Path is the full API path including any query
+// string, e.g. "/api/events/events?include=none".
+type Request struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// Response is the outcome of one sub-request. Body holds the JSON response
+// as-is, or a JSON string for non-JSON responses.
+type Response struct {
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+var allowedMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// Handler dispatches batches against router.
+type Handler struct {
+	router http.Handler
+}
+
+// NewHandler creates a batch handler that serves sub-requests with router.
+func NewHandler(router http.Handler) *Handler {
+	return &Handler{router: router}
+}
+
+// ServeHTTP runs the sub-requests in order and returns their responses in
+// the same order. Sub-requests are independent: a failure does not stop the
+// ones after it and nothing is rolled back.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var requests []Request
+	if err := httpx.DecodeJSON(r, &requests); err != nil {
+		httpx.PayloadError(w, err, "invalid request payload")
+		return
+	}
+	if len(requests) == 0 {
+		httpx.Error(w, http.StatusBadRequest, "at least one request is required")
+		return
+	}
+	if len(requests) > MaxRequests {
+		httpx.Error(w, http.StatusBadRequest, "at most "+strconv.Itoa(MaxRequests)+" requests are allowed")
+		return
+	}
+	for i := range requests {
+		requests[i].Method = strings.ToUpper(strings.TrimSpace(requests[i].Method))
+		if requests[i].Method == "" {
+			requests[i].Method = http.MethodGet
+		}
+		if err := validate(requests[i]); err != "" {
+			httpx.Error(w, http.StatusBadRequest, "request "+strconv.Itoa(i)+": "+err)
+			return
+		}
+	}
+
+	responses := make([]Response, len(requests))
+	for i, sub := range requests {
+		responses[i] = h.dispatch(r, sub)
+	}
+	httpx.WriteJSON(w, http.StatusOK, responses)
+}
+
+func validate(sub Request) string {
+	if !allowedMethods[sub.Method] {
+		return "unsupported method " + sub.Method
+	}
+	if !strings.HasPrefix(sub.Path, "/api/") {
+		return "path must start with /api/"
+	}
+	path, _, _ := strings.Cut(sub.Path, "?")
+	if strings.TrimRight(path, "/") == Path {
+		return "batches cannot be nested"
+	}
+	return ""
+}
+
+func (h *Handler) dispatch(parent *http.Request, sub Request) Response {
+	req, err := http.NewRequestWithContext(parent.Context(), sub.Method, sub.Path, bytes.NewReader(sub.Body))
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, "invalid request")
+	}
+	// Carry over the caller's session and device headers so the sub-request
+	// is authenticated as the same user.
+	req.Header = parent.Header.Clone()
+	req.Header.Del("Content-Length")
+	if len(sub.Body) > 0 {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.RemoteAddr = parent.RemoteAddr
+
+	rec := newRecorder()
+	h.router.ServeHTTP(rec, req)
+
+	resp := Response{Status: rec.status}
+	body := bytes.TrimSpace(rec.body.Bytes())
+	switch {
+	case len(body) == 0:
+	case json.Valid(body):
+		resp.Body = body
+	default:
+		resp.Body, _ = json.Marshal(string(body))
+	}
+	return resp
+}
+
+func errorResponse(status int, message string) Response {
+	body, _ := json.Marshal(map[string]string{"error": message})
+	return Response{Status: status, Body: body}
+}
+
+// recorder buffers a sub-response in memory.
+type recorder struct {
+	header      http.Header
+	body        bytes.Buffer
+	status      int
+	wroteHeader bool
+}
+
+func newRecorder() *recorder {
+	return &recorder{header: http.Header{}, status: http.StatusOK}
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if r.wroteHeader {
+		return
+	}
+	r.status = status
+	r.wroteHeader = true
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	return r.body.Write(p)
+}
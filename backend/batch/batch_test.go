@@ -0,0 +1,83 @@
+package batch
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func newTestRouter() chi.Router {
+	router := chi.NewRouter()
+	router.Get("/api/whoami", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"user":"ada"}`))
+	})
+	router.Post("/api/echo", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(body)
+	})
+	router.Post(Path, NewHandler(router).ServeHTTP)
+	return router
+}
+
+func TestBatchDispatchesSubRequestsWithCallerSession(t *testing.T) {
+	router := newTestRouter()
+	req := httptest.NewRequest(http.MethodPost, Path, strings.NewReader(`[
+		{"method":"get","path":"/api/whoami"},
+		{"method":"POST","path":"/api/echo","body":{"n":1}},
+		{"path":"/api/missing"}
+	]`))
+	req.Header.Set("Authorization", "Bearer token")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("batch status = %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	var responses []Response
+	if err := json.NewDecoder(rec.Body).Decode(&responses); err != nil {
+		t.Fatalf("decode responses failed: %v", err)
+	}
+	if len(responses) != 3 {
+		t.Fatalf("got %d responses, want 3", len(responses))
+	}
+	if responses[0].Status != http.StatusOK || string(responses[0].Body) != `{"user":"ada"}` {
+		t.Fatalf("whoami response = %d %s", responses[0].Status, responses[0].Body)
+	}
+	if responses[1].Status != http.StatusCreated || string(responses[1].Body) != `{"n":1}` {
+		t.Fatalf("echo response = %d %s", responses[1].Status, responses[1].Body)
+	}
+	if responses[2].Status != http.StatusNotFound || string(responses[2].Body) != `"404 page not found"` {
+		t.Fatalf("missing response = %d %s", responses[2].Status, responses[2].Body)
+	}
+}
+
+func TestBatchRejectsInvalidBatches(t *testing.T) {
+	tooMany := "[" + strings.TrimSuffix(strings.Repeat(`{"path":"/api/whoami"},`, MaxRequests+1), ",") + "]"
+	tests := map[string]string{
+		"empty":      `[]`,
+		"too many":   tooMany,
+		"nested":     `[{"method":"POST","path":"/api/batch?x=1"}]`,
+		"external":   `[{"path":"https://example.com/api/whoami"}]`,
+		"bad method": `[{"method":"TRACE","path":"/api/whoami"}]`,
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			newTestRouter().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, Path, strings.NewReader(body)))
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, http.StatusBadRequest, rec.Body.String())
+			}
+		})
+	}
+}
@@ -691,7 +691,7 @@ func (h *Handler) createBudgetForEvent(w http.ResponseWriter, r *http.Request) {
 		Notes        string `json:"notes"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
@@ -827,7 +827,7 @@ func (h *Handler) updateBudget(w http.ResponseWriter, r *http.Request) {
 		Notes        *string `json:"notes"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
@@ -952,7 +952,7 @@ func (h *Handler) reorderSections(w http.ResponseWriter, r *http.Request) {
 		SectionIDs []int64 `json:"section_ids"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	if len(payload.SectionIDs) == 0 {
@@ -1056,7 +1056,7 @@ func (h *Handler) createLineItem(w http.ResponseWriter, r *http.Request) {
 		Notes         string  `json:"notes"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	if payload.SectionID <= 0 || strings.TrimSpace(payload.Name) == "" {
@@ -1139,7 +1139,7 @@ func (h *Handler) updateLineItem(w http.ResponseWriter, r *http.Request) {
 		Notes         string  `json:"notes"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	if payload.SectionID <= 0 || strings.TrimSpace(payload.Name) == "" {
@@ -1256,7 +1256,7 @@ func (h *Handler) updateAssumptions(w http.ResponseWriter, r *http.Request) {
 		EstimateCurrencies map[string]string  `json:"estimate_currencies"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	if len(payload.Values) == 0 {
@@ -1403,7 +1403,7 @@ func (h *Handler) updateCurrencies(w http.ResponseWriter, r *http.Request) {
 		Currencies []string `json:"currencies"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	existingCurrencies, err := h.fetchBudgetCurrencies(r.Context(), budgetID)
@@ -1524,7 +1524,7 @@ func (h *Handler) previewCurrencyRates(w http.ResponseWriter, r *http.Request) {
 		Currencies   []string `json:"currencies"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	baseCurrency := normalizeCurrency(payload.BaseCurrency)
@@ -1599,7 +1599,7 @@ func (h *Handler) calculateScenario(w http.ResponseWriter, r *http.Request) {
 		Overrides map[string]float64 `json:"overrides"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	summary, err := h.buildSummary(r.Context(), budgetID, payload.Overrides)
@@ -1673,7 +1673,7 @@ func (h *Handler) createScenario(w http.ResponseWriter, r *http.Request) {
 		IsBaseline bool               `json:"is_baseline"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	name := strings.TrimSpace(payload.Name)
@@ -6,18 +6,21 @@ import (
 	"errors"
 	"fmt"
 	"html"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/innhopp/central/backend/auth"
 	"github.com/innhopp/central/backend/httpx"
+	"github.com/innhopp/central/backend/internal/email"
 	"github.com/innhopp/central/backend/rbac"
 )
 
@@ -109,6 +112,7 @@ type EmailDelivery struct {
 	Subject           string     `json:"subject"`
 	Body              string     `json:"body"`
 	ProviderMessageID string     `json:"provider_message_id,omitempty"`
+	RequestID         string     `json:"request_id,omitempty"`
 	Status            string     `json:"status"`
 	SentAt            *time.Time `json:"sent_at,omitempty"`
 	FailedAt          *time.Time `json:"failed_at,omitempty"`
@@ -186,40 +190,11 @@ func normalizePaymentState(paymentStatus string, dueAt, paidAt *time.Time, regis
 	return "pending"
 }
 
-var allowedAudienceRoles = map[string]struct{}{
-	"Participant": {},
-	"Skydiver":    {},
-	"Staff":       {},
-	"Ground Crew": {},
-	"Jump Master": {},
-	"Jump Leader": {},
-	"Driver":      {},
-	"Pilot":       {},
-	"POC":         {},
-	"Photo":       {},
-}
-
-func canonicalAudienceRole(value string) string {
-	trimmed := strings.TrimSpace(value)
-	if trimmed == "" {
-		return ""
-	}
-	if strings.EqualFold(trimmed, "COP") {
-		trimmed = "POC"
-	}
-	for role := range allowedAudienceRoles {
-		if strings.EqualFold(trimmed, role) {
-			return role
-		}
-	}
-	return ""
-}
-
 func normalizeAudienceRoles(input []string) []string {
 	seen := make(map[string]struct{})
 	roles := make([]string, 0, len(input))
 	for _, value := range input {
-		canonical := canonicalAudienceRole(value)
+		canonical := rbac.CanonicalProfileRole(value)
 		if canonical == "" {
 			continue
 		}
@@ -249,12 +224,12 @@ func normalizeAudienceRegistrationIDs(input []int64) []int64 {
 }
 
 func hasRole(roles []string, target string) bool {
-	canonicalTarget := canonicalAudienceRole(target)
+	canonicalTarget := rbac.CanonicalProfileRole(target)
 	if canonicalTarget == "" {
 		return false
 	}
 	for _, role := range roles {
-		if canonicalAudienceRole(role) == canonicalTarget {
+		if rbac.CanonicalProfileRole(role) == canonicalTarget {
 			return true
 		}
 	}
@@ -352,7 +327,7 @@ func loadAudienceRecipients(ctx context.Context, q interface {
 		); err != nil {
 			return nil, err
 		}
-		recipient.ParticipantEmail = strings.ToLower(strings.TrimSpace(recipient.ParticipantEmail))
+		recipient.ParticipantEmail = email.Normalize(recipient.ParticipantEmail)
 		recipient.DepositState = normalizePaymentState(depositPaymentStatus, recipient.DepositDueAt, recipient.DepositPaidAt, recipient.Status)
 		recipient.MainInvoiceState = normalizePaymentState(mainInvoicePaymentStatus, recipient.MainInvoiceDueAt, recipient.MainInvoicePaidAt, recipient.Status)
 		if filter.Status != "" && recipient.Status != filter.Status {
@@ -437,7 +412,7 @@ func loadAudienceRecipientsByRegistrationIDs(ctx context.Context, q interface {
 		); err != nil {
 			return nil, err
 		}
-		recipient.ParticipantEmail = strings.ToLower(strings.TrimSpace(recipient.ParticipantEmail))
+		recipient.ParticipantEmail = email.Normalize(recipient.ParticipantEmail)
 		recipient.DepositState = normalizePaymentState(depositPaymentStatus, recipient.DepositDueAt, recipient.DepositPaidAt, recipient.Status)
 		recipient.MainInvoiceState = normalizePaymentState(mainInvoicePaymentStatus, recipient.MainInvoiceDueAt, recipient.MainInvoicePaidAt, recipient.Status)
 		recipients = append(recipients, recipient)
@@ -553,6 +528,14 @@ func loadTemplate(ctx context.Context, q interface {
 	return &template, nil
 }
 
+func eventExists(ctx context.Context, q interface {
+	QueryRow(context.Context, string, ...any) pgx.Row
+}, eventID int64) (bool, error) {
+	var exists bool
+	err := q.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM events WHERE id = $1)`, eventID).Scan(&exists)
+	return exists, err
+}
+
 func loadEventMetadata(ctx context.Context, q interface {
 	QueryRow(context.Context, string, ...any) pgx.Row
 }, eventID int64) (map[string]string, error) {
@@ -652,6 +635,7 @@ func loadDeliveries(ctx context.Context, q interface {
 		       subject,
 		       body,
 		       COALESCE(provider_message_id, ''),
+		       COALESCE(request_id, ''),
 		       status,
 		       sent_at,
 		       failed_at,
@@ -675,6 +659,7 @@ func loadDeliveries(ctx context.Context, q interface {
 			&delivery.Subject,
 			&delivery.Body,
 			&delivery.ProviderMessageID,
+			&delivery.RequestID,
 			&delivery.Status,
 			&delivery.SentAt,
 			&delivery.FailedAt,
@@ -722,7 +707,7 @@ func (h *Handler) listTemplates(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) createTemplate(w http.ResponseWriter, r *http.Request) {
 	var payload templatePayload
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	key := strings.ToLower(strings.TrimSpace(payload.Key))
@@ -768,7 +753,7 @@ func (h *Handler) updateTemplate(w http.ResponseWriter, r *http.Request) {
 	}
 	var payload templatePayload
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	key := strings.ToLower(strings.TrimSpace(payload.Key))
@@ -847,6 +832,16 @@ func (h *Handler) listEventCampaigns(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, http.StatusBadRequest, "invalid event id")
 		return
 	}
+
+	exists, err := eventExists(r.Context(), h.db, eventID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to validate event")
+		return
+	}
+	if !exists {
+		httpx.Error(w, http.StatusNotFound, "event not found")
+		return
+	}
 	rows, err := h.db.Query(r.Context(), `
 		SELECT c.id,
 		       c.event_id,
@@ -891,7 +886,7 @@ func (h *Handler) createCampaign(w http.ResponseWriter, r *http.Request) {
 
 	var payload campaignPayload
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	if payload.EventID <= 0 || payload.TemplateID <= 0 {
@@ -968,6 +963,7 @@ func (h *Handler) createCampaign(w http.ResponseWriter, r *http.Request) {
 	eventScheduleLink := buildEventScheduleLink(h.frontendURL, payload.EventID)
 	eventNameLink := renderEventNameLink(eventName, eventScheduleLink)
 	deliveries := make([]preparedDelivery, 0, len(recipients))
+	requestID := middleware.GetReqID(ctx)
 
 	for _, recipient := range recipients {
 		replacements := map[string]string{
@@ -993,10 +989,10 @@ func (h *Handler) createCampaign(w http.ResponseWriter, r *http.Request) {
 		body := renderTemplate(template.BodyTemplate, bodyReplacements)
 		var deliveryID int64
 		if err := tx.QueryRow(ctx, `
-			INSERT INTO email_deliveries (campaign_id, registration_id, email, subject, body, status)
-			VALUES ($1, $2, $3, $4, $5, 'pending')
+			INSERT INTO email_deliveries (campaign_id, registration_id, email, subject, body, status, request_id)
+			VALUES ($1, $2, $3, $4, $5, 'pending', NULLIF($6, ''))
 			RETURNING id
-		`, campaignID, recipient.RegistrationID, recipient.ParticipantEmail, subject, body).Scan(&deliveryID); err != nil {
+		`, campaignID, recipient.RegistrationID, recipient.ParticipantEmail, subject, body, requestID).Scan(&deliveryID); err != nil {
 			httpx.Error(w, http.StatusInternalServerError, "failed to create deliveries")
 			return
 		}
@@ -1024,9 +1020,11 @@ func (h *Handler) createCampaign(w http.ResponseWriter, r *http.Request) {
 			HTML:              htmlBody,
 			PlainText:         plainTextBody,
 			InlineAttachments: inlineAttachments,
+			RequestID:         requestID,
 		})
 		if err != nil {
 			failedCount++
+			log.Printf("email delivery %d for campaign %d failed (request %s): %v", delivery.ID, campaignID, requestID, err)
 			if _, updateErr := h.db.Exec(r.Context(), `
 				UPDATE email_deliveries
 				SET status = 'failed',
@@ -121,7 +121,7 @@ func (s *SentFolderCopyingSender) Send(ctx context.Context, message EmailMessage
 	}
 	if s.store != nil && len(result.Raw) > 0 {
 		if err := s.store.AppendSent(ctx, result.Raw); err != nil && s.logf != nil {
-			s.logf("sent-folder append failed for %s: %v", message.To, err)
+			s.logf("sent-folder append failed for %s (request %s): %v", message.To, message.RequestID, err)
 		}
 	}
 	return result, nil
@@ -22,6 +22,8 @@ type EmailMessage struct {
 	HTML              string
 	PlainText         string
 	InlineAttachments []EmailAttachment
+	// RequestID correlates the message with the API request that triggered it.
+	RequestID string
 }
 
 type EmailSendResult struct {
@@ -258,6 +260,9 @@ func buildMIMEMessage(config SMTPConfig, message EmailMessage, messageID string)
 		"Message-ID: <" + messageID + ">",
 		"MIME-Version: 1.0",
 	}
+	if requestID := sanitizeHeaderValue(message.RequestID); requestID != "" {
+		headers = append(headers, "X-Request-ID: "+requestID)
+	}
 
 	var builder strings.Builder
 	if len(message.InlineAttachments) > 0 {
@@ -0,0 +1,135 @@
+// Package cors answers cross-origin preflights and adds the CORS response
+// headers the SPA needs when it is served from a different origin than the
+// API.
+package cors
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults used when the corresponding environment variable is unset.
+var (
+	DefaultAllowedMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	DefaultAllowedHeaders = []string{"Authorization", "Content-Type", "X-Device-ID", "X-Request-ID"}
+	DefaultExposedHeaders = []string{"X-Total-Count", "ETag", "X-Request-ID"}
+)
+
+// DefaultMaxAge is how long browsers may cache a preflight response.
+const DefaultMaxAge = 10 * time.Minute
+
+// Config describes which cross-origin requests are allowed. CORS is off when
+// AllowedOrigins is empty. An origin of "*" allows every origin, which
+// Validate rejects together with AllowCredentials.
+type Config struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	MaxAge           time.Duration
+	AllowCredentials bool
+}
+
+// ErrWildcardWithCredentials is returned by Validate for a config that would
+// let any site make credentialed requests.
+var ErrWildcardWithCredentials = errors.New(`cors: "*" cannot be an allowed origin when credentials are allowed`)
+
+// Validate reports configurations that must not be served.
+func (c Config) Validate() error {
+	if !c.AllowCredentials {
+		return nil
+	}
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			return ErrWildcardWithCredentials
+		}
+	}
+	return nil
+}
+
+// EffectiveOrigins returns the origins the middleware will echo, normalized
+// as they are matched.
+func (c Config) EffectiveOrigins() []string {
+	origins := make([]string, 0, len(c.AllowedOrigins))
+	for _, origin := range c.AllowedOrigins {
+		origins = append(origins, strings.TrimRight(origin, "/"))
+	}
+	return origins
+}
+
+// ParseList splits a comma-separated setting, dropping blanks. A blank value
+// returns fallback.
+func ParseList(raw string, fallback []string) []string {
+	var values []string
+	for _, part := range strings.Split(raw, ",") {
+		if value := strings.TrimSpace(part); value != "" {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return fallback
+	}
+	return values
+}
+
+// Middleware applies cfg to every request. Preflights from allowed origins
+// are answered directly with 204; other requests get the allow and expose
+// headers and continue to next. With AllowCredentials only origins listed
+// exactly are echoed: "*" never matches, so a config that skipped Validate
+// still cannot grant credentialed access to an arbitrary site.
+func Middleware(cfg Config) func(http.Handler) http.Handler {
+	allowed := make(map[string]bool, len(cfg.AllowedOrigins))
+	for _, origin := range cfg.EffectiveOrigins() {
+		allowed[origin] = true
+	}
+	anyOrigin := allowed["*"] && !cfg.AllowCredentials
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	exposed := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge / time.Second))
+
+	return func(next http.Handler) http.Handler {
+		if len(allowed) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			if origin == "*" || (!allowed[origin] && !anyOrigin) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			h.Set("Access-Control-Allow-Origin", origin)
+			if cfg.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+				h.Set("Access-Control-Allow-Methods", methods)
+				if headers != "" {
+					h.Set("Access-Control-Allow-Headers", headers)
+				}
+				if cfg.MaxAge > 0 {
+					h.Set("Access-Control-Max-Age", maxAge)
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			if exposed != "" {
+				h.Set("Access-Control-Expose-Headers", exposed)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMiddlewareCachesPreflightAndExposesHeaders(t *testing.T) {
+	handler := Middleware(Config{
+		AllowedOrigins: []string{"https://app.example.com/"},
+		AllowedMethods: DefaultAllowedMethods,
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+		ExposedHeaders: []string{"X-Total-Count", "ETag"},
+		MaxAge:         2 * time.Hour,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	preflight := httptest.NewRequest(http.MethodOptions, "/api/events/events", nil)
+	preflight.Header.Set("Origin", "https://app.example.com")
+	preflight.Header.Set("Access-Control-Request-Method", http.MethodPut)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, preflight)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("preflight status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if got := rec.Header().Get("Access-Control-Max-Age"); got != "7200" {
+		t.Fatalf("Access-Control-Max-Age = %q, want 7200", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Headers"); got != "Authorization, Content-Type" {
+		t.Fatalf("Access-Control-Allow-Headers = %q", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/events/events", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusTeapot {
+		t.Fatalf("request status = %d, want it passed through", rec.Code)
+	}
+	if got := rec.Header().Get("Access-Control-Expose-Headers"); got != "X-Total-Count, ETag" {
+		t.Fatalf("Access-Control-Expose-Headers = %q", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Fatalf("Access-Control-Allow-Origin = %q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/events/events", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("disallowed origin got Access-Control-Allow-Origin %q", got)
+	}
+}
+
+func TestParseListFallsBackWhenBlank(t *testing.T) {
+	if got := ParseList(" , ", DefaultExposedHeaders); len(got) != len(DefaultExposedHeaders) {
+		t.Fatalf("ParseList(blank) = %v, want defaults", got)
+	}
+	if got := ParseList("ETag, X-Custom ", nil); len(got) != 2 || got[1] != "X-Custom" {
+		t.Fatalf("ParseList() = %v", got)
+	}
+}
+
+func TestCredentialsOnlyEchoListedOrigins(t *testing.T) {
+	handler := Middleware(Config{
+		AllowedOrigins:   []string{"https://app.example.com", "*"},
+		AllowedMethods:   DefaultAllowedMethods,
+		AllowCredentials: true,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for origin, want := range map[string]string{
+		"https://app.example.com":  "https://app.example.com",
+		"https://evil.example.com": "",
+		"*":                        "",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/events/events", nil)
+		req.Header.Set("Origin", origin)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != want {
+			t.Errorf("origin %q: Access-Control-Allow-Origin = %q, want %q", origin, got, want)
+		}
+		wantCredentials := ""
+		if want != "" {
+			wantCredentials = "true"
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != wantCredentials {
+			t.Errorf("origin %q: Access-Control-Allow-Credentials = %q, want %q", origin, got, wantCredentials)
+		}
+	}
+}
+
+func TestValidateRejectsWildcardWithCredentials(t *testing.T) {
+	if err := (Config{AllowedOrigins: []string{"*"}, AllowCredentials: true}).Validate(); err != ErrWildcardWithCredentials {
+		t.Fatalf("Validate() = %v, want ErrWildcardWithCredentials", err)
+	}
+	if err := (Config{AllowedOrigins: []string{"*"}}).Validate(); err != nil {
+		t.Fatalf("Validate() without credentials = %v", err)
+	}
+	if err := (Config{AllowedOrigins: []string{"https://app.example.com/"}, AllowCredentials: true}).Validate(); err != nil {
+		t.Fatalf("Validate() with listed origin = %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package debuglog
+
+import (
+	"expvar"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/innhopp/central/backend/httpx"
+	"github.com/innhopp/central/backend/rbac"
+)
+
+// Handler exposes the runtime toggle for request/response body logging.
+type Handler struct {
+	logger *BodyLogger
+}
+
+// NewHandler creates a debug logging handler.
+func NewHandler(logger *BodyLogger) *Handler {
+	return &Handler{logger: logger}
+}
+
+// Routes registers the body logging toggle endpoints and the expvar dump.
+func (h *Handler) Routes(enforcer *rbac.Enforcer) chi.Router {
+	r := chi.NewRouter()
+	r.With(enforcer.Authorize(rbac.PermissionManageDebugLogging)).Get("/body-logging", h.getBodyLogging)
+	r.With(enforcer.Authorize(rbac.PermissionManageDebugLogging)).With(enforcer.AdminAction("debug_logging.set")).Put("/body-logging", h.setBodyLogging)
+	r.With(enforcer.Authorize(rbac.PermissionManageDebugLogging)).Get("/vars", expvar.Handler().ServeHTTP)
+	return r
+}
+
+type bodyLoggingStatus struct {
+	Enabled bool `json:"enabled"`
+}
+
+func (h *Handler) getBodyLogging(w http.ResponseWriter, r *http.Request) {
+	httpx.WriteJSON(w, http.StatusOK, bodyLoggingStatus{Enabled: h.logger.Enabled()})
+}
+
+func (h *Handler) setBodyLogging(w http.ResponseWriter, r *http.Request) {
+	var payload bodyLoggingStatus
+	if err := httpx.DecodeJSON(r, &payload); err != nil {
+		httpx.PayloadError(w, err, "invalid request payload")
+		return
+	}
+	h.logger.SetEnabled(payload.Enabled)
+	httpx.WriteJSON(w, http.StatusOK, bodyLoggingStatus{Enabled: h.logger.Enabled()})
+}
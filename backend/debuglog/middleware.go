@@ -0,0 +1,218 @@
+// Package debuglog provides opt-in logging of request and response bodies for
+// diagnosing integration problems. It is disabled unless explicitly enabled and
+// redacts sensitive fields before anything is written to the log.
+package debuglog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/innhopp/central/backend/querybudget"
+)
+
+const (
+	// DefaultMaxBytes caps how much of each body is written to the log.
+	DefaultMaxBytes = 4096
+	// captureFactor lets the recorder keep more raw bytes than are logged so
+	// that a modestly oversized JSON response can still be parsed and redacted.
+	captureFactor = 4
+	redacted      = "[redacted]"
+)
+
+// sensitiveKeyFragments mark JSON keys whose values are never logged. Matching
+// is case-insensitive on any part of the key.
+var sensitiveKeyFragments = []string{
+	"token",
+	"secret",
+	"password",
+	"authorization",
+	"cookie",
+	"email",
+	"phone",
+	"image",
+	"photo",
+	"land_owner",
+}
+
+// sensitiveKeys are exact key names that carry sensitive or bulky content.
+var sensitiveKeys = map[string]struct{}{
+	"data": {},
+}
+
+// BodyLogger logs bodies of write requests while enabled.
+type BodyLogger struct {
+	enabled  atomic.Bool
+	maxBytes int
+	logf     func(format string, args ...any)
+}
+
+// NewBodyLogger constructs a body logger. A non-positive maxBytes falls back to
+// DefaultMaxBytes.
+func NewBodyLogger(enabled bool, maxBytes int) *BodyLogger {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	l := &BodyLogger{maxBytes: maxBytes, logf: log.Printf}
+	l.enabled.Store(enabled)
+	return l
+}
+
+// Enabled reports whether bodies are currently being logged.
+func (l *BodyLogger) Enabled() bool {
+	return l.enabled.Load()
+}
+
+// SetEnabled toggles body logging at runtime.
+func (l *BodyLogger) SetEnabled(enabled bool) {
+	l.enabled.Store(enabled)
+}
+
+// Middleware logs request and response bodies for write methods. Up to
+// maxBytes*captureFactor of the request body is buffered and put back in
+// front of the rest, so downstream handlers still read all of it and a large
+// upload is never held in memory twice. Headers are never logged.
+func (l *BodyLogger) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !l.Enabled() || !isWriteMethod(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var requestBody []byte
+		requestTruncated := false
+		if r.Body != nil {
+			limit := l.maxBytes * captureFactor
+			body, err := io.ReadAll(io.LimitReader(r.Body, int64(limit)+1))
+			if err != nil {
+				l.logf("debug body %s %s: failed to read request body: %v", r.Method, r.URL.Path, err)
+			}
+			requestBody = body
+			if len(body) > limit {
+				requestBody, requestTruncated = body[:limit], true
+			}
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK, limit: l.maxBytes}
+		next.ServeHTTP(rec, r)
+
+		l.logf("debug body %s %s status=%d queries=%d request=%s response=%s",
+			r.Method, r.URL.Path, rec.status, querybudget.Count(r.Context()),
+			l.render(r.Header.Get("Content-Type"), requestBody, requestTruncated),
+			l.render(rec.Header().Get("Content-Type"), rec.body.Bytes(), rec.truncated),
+		)
+	})
+}
+
+// render formats a body for the log. A truncated body holds only the first
+// bytes of a larger one, which cannot be parsed and redacted, so only its
+// size is logged.
+func (l *BodyLogger) render(contentType string, body []byte, truncated bool) string {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return "<empty>"
+	}
+	if truncated {
+		return fmt.Sprintf("<body over %d bytes truncated and omitted>", len(body))
+	}
+	if contentType != "" && !strings.Contains(strings.ToLower(contentType), "json") {
+		return fmt.Sprintf("<%d bytes of %s omitted>", len(body), contentType)
+	}
+
+	var decoded any
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return fmt.Sprintf("<%d bytes of non-JSON body omitted>", len(body))
+	}
+	sanitized, err := json.Marshal(Redact(decoded))
+	if err != nil {
+		return "<unrenderable body>"
+	}
+	if len(sanitized) > l.maxBytes {
+		return string(sanitized[:l.maxBytes]) + "...<truncated>"
+	}
+	return string(sanitized)
+}
+
+// Redact returns a copy of a decoded JSON value with sensitive fields replaced.
+func Redact(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			if isSensitiveKey(key) {
+				out[key] = redacted
+				continue
+			}
+			out[key] = Redact(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = Redact(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	lower := strings.ToLower(key)
+	if _, ok := sensitiveKeys[lower]; ok {
+		return true
+	}
+	for _, fragment := range sensitiveKeyFragments {
+		if strings.Contains(lower, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWriteMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// responseRecorder passes writes through while keeping a capped copy.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	limit  int
+	body   bytes.Buffer
+	// truncated is set once a write did not fit in body.
+	truncated bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	remaining := r.limit*captureFactor - r.body.Len()
+	if len(p) > remaining {
+		r.truncated = true
+	}
+	if remaining > 0 {
+		if len(p) > remaining {
+			r.body.Write(p[:remaining])
+		} else {
+			r.body.Write(p)
+		}
+	}
+	return r.ResponseWriter.Write(p)
+}
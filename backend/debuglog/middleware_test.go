@@ -0,0 +1,100 @@
+package debuglog
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestLogger(enabled bool, maxBytes int) (*BodyLogger, *[]string) {
+	var lines []string
+	l := NewBodyLogger(enabled, maxBytes)
+	l.logf = func(format string, args ...any) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+	return l, &lines
+}
+
+func TestMiddlewareRestoresRequestBodyAndRedactsSensitiveFields(t *testing.T) {
+	logger, lines := newTestLogger(true, 0)
+	var seen string
+	handler := logger.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		seen = string(body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":7,"email":"ada@example.com"}`))
+	}))
+
+	body := `{"name":"Fjord","land_owners":[{"name":"Ola"}],"images":[{"data":"aGVsbG8="}],"id_token":"abc"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/events/events", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Cookie", "innhopp_session=secret-cookie")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if seen != body {
+		t.Fatalf("handler read %q, want original body", seen)
+	}
+	if len(*lines) != 1 {
+		t.Fatalf("logged %d lines, want 1", len(*lines))
+	}
+	line := (*lines)[0]
+	for _, leaked := range []string{"Ola", "aGVsbG8=", "abc", "ada@example.com", "secret-cookie"} {
+		if strings.Contains(line, leaked) {
+			t.Fatalf("log line leaked %q: %s", leaked, line)
+		}
+	}
+	if !strings.Contains(line, `"name":"Fjord"`) || !strings.Contains(line, "status=201") {
+		t.Fatalf("log line missing expected content: %s", line)
+	}
+}
+
+func TestMiddlewareSkipsReadsAndDisabledLogger(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	enabled, lines := newTestLogger(true, 0)
+	enabled.Middleware(next).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if len(*lines) != 0 {
+		t.Fatalf("GET request was logged: %v", *lines)
+	}
+
+	disabled, lines := newTestLogger(false, 0)
+	disabled.Middleware(next).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`)))
+	if len(*lines) != 0 {
+		t.Fatalf("disabled logger wrote: %v", *lines)
+	}
+}
+
+func TestMiddlewareTruncatesLargeBodies(t *testing.T) {
+	logger, lines := newTestLogger(true, 32)
+	handler := logger.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	body := `{"notes":"` + strings.Repeat("x", 100) + `"}`
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body)))
+
+	if len(*lines) != 1 || !strings.Contains((*lines)[0], "<truncated>") {
+		t.Fatalf("expected truncated body in log, got %v", *lines)
+	}
+}
+
+func TestMiddlewareCapsBufferedRequestBody(t *testing.T) {
+	logger, lines := newTestLogger(true, 8)
+	var seen int
+	handler := logger.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		seen = len(body)
+	}))
+
+	body := `{"notes":"` + strings.Repeat("x", 1000) + `"}`
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+
+	if seen != len(body) {
+		t.Fatalf("handler read %d bytes, want all %d", seen, len(body))
+	}
+	if len(*lines) != 1 || !strings.Contains((*lines)[0], "request=<body over 32 bytes truncated and omitted>") {
+		t.Fatalf("expected the request body to be logged as truncated, got %v", *lines)
+	}
+}
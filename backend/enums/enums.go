@@ -0,0 +1,68 @@
+// Package enums publishes the server-side enumerations that clients use to
+// populate dropdowns, so the frontend does not hard-code its own copies.
+package enums
+
+import (
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/innhopp/central/backend/events"
+	"github.com/innhopp/central/backend/httpx"
+	"github.com/innhopp/central/backend/logistics"
+	"github.com/innhopp/central/backend/participants"
+	"github.com/innhopp/central/backend/rbac"
+)
+
+// Catalog groups every enumeration exposed by the API.
+type Catalog struct {
+	EventStatuses           []string `json:"event_statuses"`
+	EventCommercialStatuses []string `json:"event_commercial_statuses"`
+	ParticipantRoles        []string `json:"participant_roles"`
+	AccountRoles            []string `json:"account_roles"`
+	GearStatuses            []string `json:"gear_statuses"`
+	CrewRoles               []string `json:"crew_roles"`
+	ExperienceLevels        []string `json:"experience_levels"`
+}
+
+// Load assembles the catalog from the packages that validate each value.
+func Load() Catalog {
+	accountRoles := make([]string, 0, len(rbac.Roles))
+	for _, role := range rbac.Roles {
+		accountRoles = append(accountRoles, string(role))
+	}
+	return Catalog{
+		EventStatuses:           events.StatusValues(),
+		EventCommercialStatuses: events.CommercialStatusValues(),
+		ParticipantRoles:        participants.RoleValues(),
+		AccountRoles:            accountRoles,
+		GearStatuses:            logistics.GearStatusValues(),
+		CrewRoles:               append([]string(nil), rbac.CrewRoles...),
+		ExperienceLevels:        participants.ExperienceLevelValues(),
+	}
+}
+
+// Handler serves the catalog. The values are not sensitive, so it requires
+// no authentication.
+type Handler struct {
+	db *pgxpool.Pool
+}
+
+// NewHandler creates an enums handler reading the rows Seed stores in db.
+// Without a database it serves the catalog in code.
+func NewHandler(db *pgxpool.Pool) *Handler {
+	return &Handler{db: db}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	catalog := Load()
+	if h.db != nil {
+		seeded, err := loadSeeded(r.Context(), h.db)
+		if err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to load enums")
+			return
+		}
+		catalog = seeded
+	}
+	httpx.WriteJSON(w, http.StatusOK, catalog)
+}
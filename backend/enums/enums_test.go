@@ -0,0 +1,68 @@
+package enums
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestHandlerServesCatalogFromOwningPackages(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewHandler(nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/enums", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("ServeHTTP() status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var catalog Catalog
+	if err := json.Unmarshal(rec.Body.Bytes(), &catalog); err != nil {
+		t.Fatalf("decode catalog: %v", err)
+	}
+	checks := map[string]struct {
+		values []string
+		want   string
+	}{
+		"event_statuses":            {catalog.EventStatuses, "live"},
+		"event_commercial_statuses": {catalog.EventCommercialStatuses, "confirmed"},
+		"participant_roles":         {catalog.ParticipantRoles, "Jump Master"},
+		"account_roles":             {catalog.AccountRoles, "admin"},
+		"gear_statuses":             {catalog.GearStatuses, "checked_out"},
+		"crew_roles":                {catalog.CrewRoles, "Jump Master"},
+		"experience_levels":         {catalog.ExperienceLevels, "Novice"},
+	}
+	for name, check := range checks {
+		found := false
+		for _, value := range check.values {
+			found = found || value == check.want
+		}
+		if !found {
+			t.Errorf("%s = %v, want it to include %q", name, check.values, check.want)
+		}
+	}
+}
+
+func TestListsCoverCatalogExceptAccountRoles(t *testing.T) {
+	catalog := Load()
+	seen := map[string]bool{}
+	for _, list := range catalog.Lists() {
+		if len(list.Values) == 0 {
+			t.Errorf("list %s is empty", list.Kind)
+		}
+		if catalog.field(list.Kind) == nil {
+			t.Errorf("field(%q) = nil", list.Kind)
+		}
+		seen[list.Kind] = true
+	}
+
+	fields := reflect.TypeOf(catalog)
+	for i := 0; i < fields.NumField(); i++ {
+		kind := fields.Field(i).Tag.Get("json")
+		if kind == "account_roles" {
+			continue
+		}
+		if !seen[kind] {
+			t.Errorf("Lists() is missing %s", kind)
+		}
+	}
+}
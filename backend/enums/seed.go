@@ -0,0 +1,101 @@
+package enums
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// List is one enumeration of the catalog, named by its JSON key.
+type List struct {
+	Kind   string
+	Values []string
+}
+
+// Lists returns the catalog's enumerations in a fixed order. Account roles
+// are left out: they are seeded into the roles table that account_roles
+// references.
+func (c Catalog) Lists() []List {
+	return []List{
+		{Kind: "event_statuses", Values: c.EventStatuses},
+		{Kind: "event_commercial_statuses", Values: c.EventCommercialStatuses},
+		{Kind: "participant_roles", Values: c.ParticipantRoles},
+		{Kind: "gear_statuses", Values: c.GearStatuses},
+		{Kind: "crew_roles", Values: c.CrewRoles},
+		{Kind: "experience_levels", Values: c.ExperienceLevels},
+	}
+}
+
+// field returns the catalog slice that holds kind, or nil for an unknown
+// kind.
+func (c *Catalog) field(kind string) *[]string {
+	switch kind {
+	case "event_statuses":
+		return &c.EventStatuses
+	case "event_commercial_statuses":
+		return &c.EventCommercialStatuses
+	case "participant_roles":
+		return &c.ParticipantRoles
+	case "gear_statuses":
+		return &c.GearStatuses
+	case "crew_roles":
+		return &c.CrewRoles
+	case "experience_levels":
+		return &c.ExperienceLevels
+	}
+	return nil
+}
+
+type batchSender interface {
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// Seed inserts every catalog value into reference_values. Values already
+// present are left alone, so it is safe to run on every startup; values
+// dropped from the code are not removed.
+func Seed(ctx context.Context, db batchSender) error {
+	batch := &pgx.Batch{}
+	for _, list := range Load().Lists() {
+		for position, value := range list.Values {
+			batch.Queue(`INSERT INTO reference_values (kind, value, position) VALUES ($1, $2, $3)
+                ON CONFLICT (kind, value) DO NOTHING`, list.Kind, value, position)
+		}
+	}
+
+	br := db.SendBatch(ctx, batch)
+	defer br.Close()
+	for range batch.Len() {
+		if _, err := br.Exec(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// loadSeeded builds the catalog from reference_values. Account roles still
+// come from code, which seeds them into roles.
+func loadSeeded(ctx context.Context, db querier) (Catalog, error) {
+	catalog := Catalog{AccountRoles: Load().AccountRoles}
+	for _, list := range catalog.Lists() {
+		*catalog.field(list.Kind) = []string{}
+	}
+	rows, err := db.Query(ctx, `SELECT kind, value FROM reference_values ORDER BY kind, position, value`)
+	if err != nil {
+		return Catalog{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var kind, value string
+		if err := rows.Scan(&kind, &value); err != nil {
+			return Catalog{}, err
+		}
+		if values := catalog.field(kind); values != nil {
+			*values = append(*values, value)
+		}
+	}
+	return catalog, rows.Err()
+}
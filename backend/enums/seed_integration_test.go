@@ -0,0 +1,81 @@
+package enums
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/innhopp/central/backend/participants"
+)
+
+func TestSeedIsIdempotentAndBacksTheHandler(t *testing.T) {
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Skip("DATABASE_URL not set; skipping integration enums tests")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	db, err := pgxpool.New(ctx, url)
+	if err != nil {
+		t.Fatalf("connect db failed: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(ctx, `CREATE TABLE IF NOT EXISTS reference_values (
+            kind TEXT NOT NULL,
+            value TEXT NOT NULL,
+            position INTEGER NOT NULL DEFAULT 0,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+            PRIMARY KEY (kind, value)
+        )`); err != nil {
+		t.Fatalf("create reference_values failed: %v", err)
+	}
+	// A value added by an operator survives reseeding and is served.
+	if _, err := db.Exec(ctx, `INSERT INTO reference_values (kind, value, position) VALUES ('crew_roles', 'Zz Test Role', 999)
+        ON CONFLICT (kind, value) DO NOTHING`); err != nil {
+		t.Fatalf("insert custom value failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM reference_values WHERE kind = 'crew_roles' AND value = 'Zz Test Role'`)
+
+	for i := 0; i < 2; i++ {
+		if err := Seed(ctx, db); err != nil {
+			t.Fatalf("Seed() run %d returned error: %v", i+1, err)
+		}
+	}
+	want := 0
+	for _, list := range Load().Lists() {
+		want += len(list.Values)
+	}
+	var seeded int
+	if err := db.QueryRow(ctx, `SELECT COUNT(*) FROM reference_values WHERE NOT (kind = 'crew_roles' AND value = 'Zz Test Role')`).Scan(&seeded); err != nil {
+		t.Fatalf("count reference_values failed: %v", err)
+	}
+	if seeded < want {
+		t.Fatalf("reference_values rows = %d, want at least %d", seeded, want)
+	}
+
+	rec := httptest.NewRecorder()
+	NewHandler(db).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/enums", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("ServeHTTP() status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	var catalog Catalog
+	if err := json.Unmarshal(rec.Body.Bytes(), &catalog); err != nil {
+		t.Fatalf("decode catalog: %v", err)
+	}
+	if n := len(catalog.CrewRoles); n == 0 || catalog.CrewRoles[n-1] != "Zz Test Role" {
+		t.Fatalf("crew_roles = %v, want the seeded roles then the custom one", catalog.CrewRoles)
+	}
+	if len(catalog.ExperienceLevels) == 0 || catalog.ExperienceLevels[0] != participants.LevelNovice {
+		t.Fatalf("experience_levels = %v, want them in seeded order", catalog.ExperienceLevels)
+	}
+	if len(catalog.AccountRoles) == 0 {
+		t.Fatal("account_roles is empty")
+	}
+}
@@ -0,0 +1,124 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/innhopp/central/backend/internal/geo"
+)
+
+// coordinateTolerance is how far, in degrees, coordinates and
+// latitude/longitude may differ and still be taken as the same point.
+const coordinateTolerance = 1e-6
+
+var (
+	errAirfieldCoordinates = errors.New("coordinates must be decimal degrees such as \"60.3913 5.3221\" or degrees-minutes-seconds such as 60°23'29\"N 5°19'20\"E, with latitude between -90 and 90 and longitude between -180 and 180")
+	errAirfieldLatLongPair = errors.New("latitude and longitude must be sent together")
+	errAirfieldMismatch    = errors.New("coordinates and latitude/longitude describe different points; send one or make them agree")
+)
+
+// airfieldPayload is the body of an airfield create or update. The position
+// may come as coordinates, as latitude and longitude, or as both when they
+// agree.
+type airfieldPayload struct {
+	Name        string  `json:"name"`
+	Elevation   int     `json:"elevation"`
+	Coordinates string  `json:"coordinates"`
+	Latitude    *string `json:"latitude"`
+	Longitude   *string `json:"longitude"`
+	Description string  `json:"description"`
+}
+
+func (p airfieldPayload) hasCoordinates() bool {
+	if strings.TrimSpace(p.Coordinates) != "" {
+		return true
+	}
+	return p.Latitude != nil && strings.TrimSpace(*p.Latitude) != "" &&
+		p.Longitude != nil && strings.TrimSpace(*p.Longitude) != ""
+}
+
+// canonicalCoordinates returns the latitude and longitude to store, in
+// decimal degrees, so that the coordinates read back always match them.
+func (p airfieldPayload) canonicalCoordinates() (string, string, error) {
+	coords := strings.TrimSpace(p.Coordinates)
+	hasLat := p.Latitude != nil && strings.TrimSpace(*p.Latitude) != ""
+	hasLng := p.Longitude != nil && strings.TrimSpace(*p.Longitude) != ""
+	if hasLat != hasLng {
+		return "", "", errAirfieldLatLongPair
+	}
+
+	var lat, lng float64
+	if coords != "" {
+		var ok bool
+		if lat, lng, ok = geo.ParseCoordinates(coords); !ok {
+			return "", "", errAirfieldCoordinates
+		}
+	}
+	if hasLat {
+		pairLat, pairLng, ok := geo.ParseCoordinates(strings.TrimSpace(*p.Latitude) + " " + strings.TrimSpace(*p.Longitude))
+		if !ok {
+			return "", "", errAirfieldCoordinates
+		}
+		if coords != "" && (math.Abs(pairLat-lat) > coordinateTolerance || math.Abs(pairLng-lng) > coordinateTolerance) {
+			return "", "", errAirfieldMismatch
+		}
+		lat, lng = pairLat, pairLng
+	}
+	return geo.FormatDegrees(lat), geo.FormatDegrees(lng), nil
+}
+
+// BackfillAirfieldCoordinates rewrites every stored airfield position in
+// canonical decimal degrees, calling progress with the running totals when it
+// is done. Rows written before the position was validated can hold DMS text
+// or stray spaces; those are converted, and any that cannot be read are
+// logged and left for an admin to fix by hand. Rows already canonical are not
+// touched, so it is safe to run again.
+func BackfillAirfieldCoordinates(ctx context.Context, db DB, progress func(processed, updated int)) error {
+	rows, err := db.Query(ctx, `SELECT id, latitude, longitude FROM airfields ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	var ids []int64
+	var lats, lngs []string
+	processed := 0
+	for rows.Next() {
+		var id int64
+		var latitude, longitude string
+		if err := rows.Scan(&id, &latitude, &longitude); err != nil {
+			rows.Close()
+			return err
+		}
+		processed++
+		lat, lng, ok := geo.ParseCoordinates(strings.TrimSpace(latitude) + " " + strings.TrimSpace(longitude))
+		if !ok {
+			log.Printf("events: airfield %d has unreadable coordinates %q %q; fix it by hand", id, latitude, longitude)
+			continue
+		}
+		canonicalLat, canonicalLng := geo.FormatDegrees(lat), geo.FormatDegrees(lng)
+		if canonicalLat != latitude || canonicalLng != longitude {
+			ids = append(ids, id)
+			lats = append(lats, canonicalLat)
+			lngs = append(lngs, canonicalLng)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(ids) > 0 {
+		if _, err := db.Exec(ctx, `
+			UPDATE airfields a SET latitude = s.latitude, longitude = s.longitude
+			FROM unnest($1::int[], $2::text[], $3::text[]) AS s(id, latitude, longitude)
+			WHERE a.id = s.id`, ids, lats, lngs); err != nil {
+			return err
+		}
+	}
+	if progress != nil {
+		progress(processed, len(ids))
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/innhopp/central/backend/airfields"
+	"github.com/innhopp/central/backend/httpx"
+)
+
+// airfieldNameIndex is the unique index on lower(name) that main creates once
+// no duplicate names are left.
+const airfieldNameIndex = "airfields_name_key"
+
+// findAirfieldByName returns the id of the airfield other than excludeID whose
+// name matches name ignoring case, or 0 when there is none.
+func findAirfieldByName(ctx context.Context, db rowQuerier, name string, excludeID int64) (int64, error) {
+	var id int64
+	err := db.QueryRow(ctx,
+		`SELECT id FROM airfields WHERE lower(name) = lower($1) AND id <> $2 ORDER BY id LIMIT 1`,
+		name, excludeID,
+	).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	return id, err
+}
+
+func writeAirfieldNameConflict(w http.ResponseWriter, name string, existingID int64) {
+	httpx.WriteJSON(w, http.StatusConflict, map[string]any{
+		"error":       fmt.Sprintf("an airfield named %q already exists; use it or merge into it", name),
+		"airfield_id": existingID,
+	})
+}
+
+// checkAirfieldName answers 409 when another airfield already has name and
+// reports whether the caller may carry on.
+func (h *Handler) checkAirfieldName(w http.ResponseWriter, r *http.Request, name string, excludeID int64) bool {
+	existingID, err := findAirfieldByName(r.Context(), h.db, name, excludeID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to check airfield name")
+		return false
+	}
+	if existingID != 0 {
+		writeAirfieldNameConflict(w, name, existingID)
+		return false
+	}
+	return true
+}
+
+// isAirfieldNameConflict reports whether err is the unique index refusing a
+// name taken between checkAirfieldName and the write.
+func isAirfieldNameConflict(err error) bool {
+	return httpx.UniqueViolation(err, airfieldNameIndex)
+}
+
+type mergeAirfieldPayload struct {
+	SourceID int64 `json:"source_id"`
+}
+
+// mergeAirfield points everything that uses the source airfield at the target
+// airfield and deletes the source: innhopp takeoff and landing airfields,
+// event links, event base airfields, and logistics pickups, destinations and
+// meal locations.
+func (h *Handler) mergeAirfield(w http.ResponseWriter, r *http.Request) {
+	targetID, err := strconv.ParseInt(chi.URLParam(r, "airfieldID"), 10, 64)
+	if err != nil || targetID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid airfield id")
+		return
+	}
+
+	var payload mergeAirfieldPayload
+	if err := httpx.DecodeJSON(r, &payload); err != nil {
+		httpx.PayloadError(w, err, "invalid request payload")
+		return
+	}
+	if payload.SourceID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "source_id is required")
+		return
+	}
+	if payload.SourceID == targetID {
+		httpx.Error(w, http.StatusBadRequest, "cannot merge an airfield into itself")
+		return
+	}
+
+	ctx := r.Context()
+	tx, err := h.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to merge airfields")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var found int
+	if err := tx.QueryRow(ctx,
+		`SELECT COUNT(*) FROM (SELECT id FROM airfields WHERE id = ANY($1::int[]) FOR UPDATE) locked`,
+		[]int64{targetID, payload.SourceID},
+	).Scan(&found); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load airfields")
+		return
+	}
+	if found < 2 {
+		var targetExists bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM airfields WHERE id = $1)`, targetID).Scan(&targetExists); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to load airfields")
+			return
+		}
+		if !targetExists {
+			httpx.Error(w, http.StatusNotFound, "airfield not found")
+			return
+		}
+		httpx.Error(w, http.StatusNotFound, "source airfield not found")
+		return
+	}
+
+	stmts := []string{
+		`UPDATE event_innhopps SET takeoff_airfield_id = $1 WHERE takeoff_airfield_id = $2`,
+		`UPDATE event_innhopps SET landing_airfield_id = $1 WHERE landing_airfield_id = $2`,
+		`UPDATE events SET base_airfield_id = $1 WHERE base_airfield_id = $2`,
+		`INSERT INTO event_airfields (event_id, airfield_id)
+		 SELECT event_id, $1 FROM event_airfields WHERE airfield_id = $2
+		 ON CONFLICT DO NOTHING`,
+		`UPDATE logistics_transports SET pickup_location_id = $1 WHERE pickup_location_type = 'Airfield' AND pickup_location_id = $2`,
+		`UPDATE logistics_transports SET destination_id = $1 WHERE destination_type = 'Airfield' AND destination_id = $2`,
+		`UPDATE logistics_ground_crews SET pickup_location_id = $1 WHERE pickup_location_type = 'Airfield' AND pickup_location_id = $2`,
+		`UPDATE logistics_ground_crews SET destination_id = $1 WHERE destination_type = 'Airfield' AND destination_id = $2`,
+		`UPDATE logistics_meals SET location_id = $1 WHERE location_type = 'Airfield' AND location_id = $2`,
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(ctx, stmt, targetID, payload.SourceID); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to move airfield references")
+			return
+		}
+	}
+	// The source's event links go with it.
+	if _, err := tx.Exec(ctx, `DELETE FROM airfields WHERE id = $1`, payload.SourceID); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to delete source airfield")
+		return
+	}
+
+	var a airfields.Airfield
+	if err := tx.QueryRow(ctx,
+		`SELECT id, name, latitude, longitude, elevation, description, created_at FROM airfields WHERE id = $1`,
+		targetID,
+	).Scan(&a.ID, &a.Name, &a.Latitude, &a.Longitude, &a.Elevation, &a.Description, &a.CreatedAt); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load airfield")
+		return
+	}
+	a.Coordinates = strings.TrimSpace(a.Latitude + " " + a.Longitude)
+
+	if err := httpx.FinishTx(ctx, w, r, tx, http.StatusOK, a); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to merge airfields")
+	}
+}
@@ -0,0 +1,316 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/innhopp/central/backend/httpx"
+)
+
+const (
+	defaultArchiveAfterDays = 365
+	maxArchiveBatch         = 100
+)
+
+// archivedRelation is a table holding rows that belong to an event, either
+// directly or through another archived table. where selects the rows for the
+// event id in $1.
+type archivedRelation struct {
+	table string
+	where string
+}
+
+// archivedRelations lists every table that cascades from events, parents
+// before children so a restore can reinsert them in order.
+var archivedRelations = []archivedRelation{
+	{"event_participants", "event_id = $1"},
+	{"event_aircraft", "event_id = $1"},
+	{"event_airfields", "event_id = $1"},
+	{"event_seasons", "event_id = $1"},
+	{"event_innhopps", "event_id = $1"},
+	{"event_innhopp_revisions", "innhopp_id IN (SELECT id FROM event_innhopps WHERE event_id = $1)"},
+	{"manifests", "event_id = $1"},
+	{"manifest_participants", "manifest_id IN (SELECT id FROM manifests WHERE event_id = $1)"},
+	{"crew_assignments", "manifest_id IN (SELECT id FROM manifests WHERE event_id = $1)"},
+	{"event_accommodation", "event_id = $1"},
+	{"logistics_event_vehicles", "event_id = $1"},
+	{"logistics_transports", "event_id = $1"},
+	{"logistics_transport_vehicles", "transport_id IN (SELECT id FROM logistics_transports WHERE event_id = $1)"},
+	{"logistics_ground_crews", "event_id = $1"},
+	{"logistics_ground_crew_vehicles", "ground_crew_id IN (SELECT id FROM logistics_ground_crews WHERE event_id = $1)"},
+	{"logistics_other", "event_id = $1"},
+	{"logistics_meals", "event_id = $1"},
+	{"event_gear_checkouts", "event_id = $1"},
+	{"event_budgets", "event_id = $1"},
+	{"budget_sections", "budget_id IN (SELECT id FROM event_budgets WHERE event_id = $1)"},
+	{"budget_line_items", "budget_id IN (SELECT id FROM event_budgets WHERE event_id = $1)"},
+	{"budget_currencies", "budget_id IN (SELECT id FROM event_budgets WHERE event_id = $1)"},
+	{"budget_assumptions", "budget_id IN (SELECT id FROM event_budgets WHERE event_id = $1)"},
+	{"budget_scenarios", "budget_id IN (SELECT id FROM event_budgets WHERE event_id = $1)"},
+	{"schedule_item_costs", "event_id = $1"},
+	{"accounting_documents", "event_id = $1"},
+	{"accounting_entries", "event_id = $1"},
+	{"payments", "event_id = $1"},
+	{"payment_allocations", "payment_id IN (SELECT id FROM payments WHERE event_id = $1)"},
+	{"event_registrations", "event_id = $1"},
+	{"registration_payments", "registration_id IN (SELECT id FROM event_registrations WHERE event_id = $1)"},
+	{"registration_activity", "registration_id IN (SELECT id FROM event_registrations WHERE event_id = $1)"},
+	{"email_campaigns", "event_id = $1"},
+	{"email_deliveries", "campaign_id IN (SELECT id FROM email_campaigns WHERE event_id = $1)"},
+}
+
+// eventsWithArchiveSource stands in for the events table when a listing asks
+// for archived events too. Archived rows are rebuilt from their JSON
+// snapshot, so the outer query can keep referring to "events".
+const eventsWithArchiveSource = `(
+	SELECT events.*, FALSE AS archived FROM events
+	UNION ALL
+	SELECT (jsonb_populate_record(NULL::events, a.event)).*, TRUE FROM events_archive a
+) events`
+
+const eventsOnlySource = `(SELECT events.*, FALSE AS archived FROM events) events`
+
+// archiveAfterDaysFromEnv reads EVENT_ARCHIVE_AFTER_DAYS, falling back to a
+// year.
+func archiveAfterDaysFromEnv() int {
+	raw := strings.TrimSpace(os.Getenv("EVENT_ARCHIVE_AFTER_DAYS"))
+	if raw == "" {
+		return defaultArchiveAfterDays
+	}
+	days, err := strconv.Atoi(raw)
+	if err != nil || days <= 0 {
+		log.Printf("events: ignoring invalid EVENT_ARCHIVE_AFTER_DAYS %q", raw)
+		return defaultArchiveAfterDays
+	}
+	return days
+}
+
+type archiveEventsPayload struct {
+	OlderThanDays *int `json:"older_than_days"`
+}
+
+type archiveEventsResult struct {
+	OlderThanDays    int     `json:"older_than_days"`
+	ArchivedEventIDs []int64 `json:"archived_event_ids"`
+}
+
+func (h *Handler) archiveEvents(w http.ResponseWriter, r *http.Request) {
+	var payload archiveEventsPayload
+	if err := httpx.DecodeOptionalJSON(r, &payload); err != nil {
+		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		return
+	}
+	days := h.archiveAfterDays
+	if payload.OlderThanDays != nil {
+		if *payload.OlderThanDays <= 0 {
+			httpx.Error(w, http.StatusBadRequest, "older_than_days must be positive")
+			return
+		}
+		days = *payload.OlderThanDays
+	}
+
+	ctx := r.Context()
+	rows, err := h.db.Query(ctx, `
+		SELECT id FROM events
+		WHERE status = 'past'
+		  AND COALESCE(ends_at, starts_at) < NOW() - make_interval(days => $1)
+		ORDER BY starts_at, id
+		LIMIT $2`, days, maxArchiveBatch)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to find events to archive")
+		return
+	}
+	var eventIDs []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			httpx.Error(w, http.StatusInternalServerError, "failed to find events to archive")
+			return
+		}
+		eventIDs = append(eventIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to find events to archive")
+		return
+	}
+
+	dryRun := httpx.DryRun(r)
+	result := archiveEventsResult{OlderThanDays: days, ArchivedEventIDs: []int64{}}
+	for _, id := range eventIDs {
+		if err := h.archiveEvent(ctx, id, dryRun); err != nil {
+			log.Printf("events.archiveEvents id=%d failed: %v", id, err)
+			httpx.Error(w, http.StatusInternalServerError, "failed to archive event "+strconv.FormatInt(id, 10))
+			return
+		}
+		result.ArchivedEventIDs = append(result.ArchivedEventIDs, id)
+	}
+
+	if dryRun {
+		httpx.WriteDryRun(w, result)
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, result)
+}
+
+// archiveEvent snapshots an event and all of its related rows into
+// events_archive and removes them from the hot tables in one transaction.
+// With dryRun the transaction is rolled back once every step has succeeded.
+func (h *Handler) archiveEvent(ctx context.Context, eventID int64, dryRun bool) error {
+	tx, err := h.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	relations := make(map[string]json.RawMessage, len(archivedRelations))
+	for _, rel := range archivedRelations {
+		var rowsJSON []byte
+		if err := tx.QueryRow(ctx,
+			`SELECT COALESCE(jsonb_agg(to_jsonb(t)), '[]'::jsonb) FROM `+rel.table+` t WHERE `+rel.where,
+			eventID,
+		).Scan(&rowsJSON); err != nil {
+			return err
+		}
+		relations[rel.table] = rowsJSON
+	}
+	relationsJSON, err := json.Marshal(relations)
+	if err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO events_archive (id, season_id, name, starts_at, event, relations)
+		SELECT e.id, e.season_id, e.name, e.starts_at, to_jsonb(e), $2::jsonb
+		FROM events e WHERE e.id = $1`, eventID, string(relationsJSON))
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM events WHERE id = $1`, eventID); err != nil {
+		return err
+	}
+	if dryRun {
+		return tx.Rollback(ctx)
+	}
+	return tx.Commit(ctx)
+}
+
+func (h *Handler) restoreEvent(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.ParseInt(chi.URLParam(r, "eventID"), 10, 64)
+	if err != nil || eventID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid event id")
+		return
+	}
+
+	ctx := r.Context()
+	tx, err := h.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to restore event")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var eventJSON, relationsJSON []byte
+	if err := tx.QueryRow(ctx, `SELECT event, relations FROM events_archive WHERE id = $1 FOR UPDATE`, eventID).Scan(&eventJSON, &relationsJSON); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			httpx.Error(w, http.StatusNotFound, "archived event not found")
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to restore event")
+		return
+	}
+	var relations map[string]json.RawMessage
+	if err := json.Unmarshal(relationsJSON, &relations); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to decode archived event")
+		return
+	}
+
+	if err := restoreArchivedRows(ctx, tx, "events", eventJSON, `jsonb_populate_record(NULL::events, $1::jsonb)`, string(eventJSON)); err != nil {
+		writeRestoreError(w, eventID, "events", err)
+		return
+	}
+	for _, rel := range archivedRelations {
+		rowsJSON, ok := relations[rel.table]
+		if !ok {
+			continue
+		}
+		var rows []json.RawMessage
+		if err := json.Unmarshal(rowsJSON, &rows); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to decode archived event")
+			return
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		if err := restoreArchivedRows(ctx, tx, rel.table, rows[0], `jsonb_populate_recordset(NULL::`+rel.table+`, $1::jsonb)`, string(rowsJSON)); err != nil {
+			writeRestoreError(w, eventID, rel.table, err)
+			return
+		}
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM events_archive WHERE id = $1`, eventID); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to restore event")
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to restore event")
+		return
+	}
+
+	event, err := h.fetchEvent(ctx, eventID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load restored event")
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, event)
+}
+
+// restoreArchivedRows inserts the rows source selects from the archived JSON
+// in arg back into table. Only the columns sample, one archived row, has a
+// value for are written, so columns added since the rows were archived get
+// their defaults rather than NULL.
+func restoreArchivedRows(ctx context.Context, tx pgx.Tx, table string, sample json.RawMessage, source, arg string) error {
+	var columns []string
+	if err := tx.QueryRow(ctx, `
+		SELECT COALESCE(array_agg(quote_ident(column_name::text) ORDER BY ordinal_position), '{}')
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1
+		  AND is_generated = 'NEVER' AND $2::jsonb ? column_name::text`,
+		table, string(sample),
+	).Scan(&columns); err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("archived %s rows share no columns with the table", table)
+	}
+	list := strings.Join(columns, ", ")
+	_, err := tx.Exec(ctx, `INSERT INTO `+table+` (`+list+`) SELECT `+list+` FROM `+source, arg)
+	return err
+}
+
+// writeRestoreError reports rows that no longer fit the live schema as a
+// conflict: an event whose season was deleted while it was archived, or a
+// column added since then that is required and has no default.
+func writeRestoreError(w http.ResponseWriter, eventID int64, table string, err error) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && (pgErr.Code == "23503" || pgErr.Code == "23505" || pgErr.Code == "23502" || pgErr.Code == "23514") {
+		httpx.Error(w, http.StatusConflict, "archived "+table+" rows conflict with current data: "+pgErr.Message)
+		return
+	}
+	log.Printf("events.restoreEvent id=%d table=%s failed: %v", eventID, table, err)
+	httpx.Error(w, http.StatusInternalServerError, "failed to restore event")
+}
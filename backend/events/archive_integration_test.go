@@ -0,0 +1,146 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestRestoreArchivedRowsDefaultsColumnsAddedSince(t *testing.T) {
+	db := openEventTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ensureEventTestSchema(t, ctx, db)
+
+	var seasonID, eventID int64
+	if err := db.QueryRow(ctx, `INSERT INTO seasons (name, starts_on) VALUES ('Archive Season', CURRENT_DATE) RETURNING id`).Scan(&seasonID); err != nil {
+		t.Fatalf("insert season failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM seasons WHERE id = $1`, seasonID)
+	if err := db.QueryRow(ctx, `INSERT INTO events (season_id, name, starts_at, status, slots) VALUES ($1, 'Archived Event', NOW(), 'past', 12) RETURNING id`, seasonID).Scan(&eventID); err != nil {
+		t.Fatalf("insert event failed: %v", err)
+	}
+
+	// A snapshot taken before status and slots existed.
+	var snapshot []byte
+	if err := db.QueryRow(ctx, `DELETE FROM events WHERE id = $1 RETURNING to_jsonb(events) - 'status' - 'slots'`, eventID).Scan(&snapshot); err != nil {
+		t.Fatalf("archive event failed: %v", err)
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		t.Fatalf("begin failed: %v", err)
+	}
+	defer tx.Rollback(ctx)
+	if err := restoreArchivedRows(ctx, tx, "events", snapshot, `jsonb_populate_record(NULL::events, $1::jsonb)`, string(snapshot)); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+
+	var name, status string
+	var slots int
+	if err := tx.QueryRow(ctx, `SELECT name, status, slots FROM events WHERE id = $1`, eventID).Scan(&name, &status, &slots); err != nil {
+		t.Fatalf("reload event failed: %v", err)
+	}
+	if name != "Archived Event" || status != defaultEventStatus || slots != 0 {
+		t.Fatalf("restored event = %q %q %d, want the archived name with default status and slots", name, status, slots)
+	}
+}
+
+func TestRestoreEventReinstatesTheEventAndItsLinks(t *testing.T) {
+	db := openEventTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ensureEventTestSchema(t, ctx, db)
+
+	var seasonID, linkedSeasonID, eventID, participantID int64
+	if err := db.QueryRow(ctx, `INSERT INTO seasons (name, starts_on) VALUES ('Restore Season', CURRENT_DATE) RETURNING id`).Scan(&seasonID); err != nil {
+		t.Fatalf("insert season failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM seasons WHERE id = $1`, seasonID)
+	if err := db.QueryRow(ctx, `INSERT INTO seasons (name, starts_on) VALUES ('Restore Linked Season', CURRENT_DATE) RETURNING id`).Scan(&linkedSeasonID); err != nil {
+		t.Fatalf("insert season failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM seasons WHERE id = $1`, linkedSeasonID)
+	if err := db.QueryRow(ctx, `INSERT INTO events (season_id, name, starts_at, status) VALUES ($1, 'Restored Event', NOW() - INTERVAL '2 years', 'past') RETURNING id`, seasonID).Scan(&eventID); err != nil {
+		t.Fatalf("insert event failed: %v", err)
+	}
+	email := "restore-" + strconv.FormatInt(time.Now().UnixNano(), 10) + "@example.com"
+	if err := db.QueryRow(ctx, `INSERT INTO participant_profiles (full_name, email) VALUES ('Restore Participant', $1) RETURNING id`, email).Scan(&participantID); err != nil {
+		t.Fatalf("insert participant failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM participant_profiles WHERE id = $1`, participantID)
+
+	// Snapshot the event with its roster and season link the way archiving
+	// does, then drop it from the hot tables.
+	relations := fmt.Sprintf(`{"event_participants": [{"event_id": %d, "participant_id": %d}], "event_seasons": [{"event_id": %d, "season_id": %d}]}`,
+		eventID, participantID, eventID, linkedSeasonID)
+	if _, err := db.Exec(ctx, `
+		WITH moved AS (DELETE FROM events WHERE id = $1 RETURNING *)
+		INSERT INTO events_archive (id, season_id, name, starts_at, event, relations)
+		SELECT id, season_id, name, starts_at, to_jsonb(moved), $2::jsonb
+		FROM moved`, eventID, relations); err != nil {
+		t.Fatalf("archive event failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM events_archive WHERE id = $1`, eventID)
+
+	router := chi.NewRouter()
+	router.Post("/events/{eventID}/restore", NewHandler(db).restoreEvent)
+	restore := func(id int64) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/events/"+strconv.FormatInt(id, 10)+"/restore", nil))
+		return rec
+	}
+
+	rec := restore(eventID)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("restoreEvent() status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	var restored Event
+	if err := json.NewDecoder(rec.Body).Decode(&restored); err != nil {
+		t.Fatalf("decode restored event failed: %v", err)
+	}
+	if restored.ID != eventID || restored.Name != "Restored Event" || len(restored.ParticipantIDs) != 1 || restored.ParticipantIDs[0] != participantID {
+		t.Fatalf("restored event = id %d %q participants %v, want %d %q [%d]", restored.ID, restored.Name, restored.ParticipantIDs, eventID, "Restored Event", participantID)
+	}
+	var linked, archived bool
+	if err := db.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM event_seasons WHERE event_id = $1 AND season_id = $2),
+		       EXISTS (SELECT 1 FROM events_archive WHERE id = $1)`, eventID, linkedSeasonID).Scan(&linked, &archived); err != nil {
+		t.Fatalf("check restored rows failed: %v", err)
+	}
+	if !linked || archived {
+		t.Fatalf("after restore season link = %t, still archived = %t; want the link back and the archive row gone", linked, archived)
+	}
+
+	if rec := restore(eventID); rec.Code != http.StatusNotFound {
+		t.Fatalf("restoreEvent(not archived) status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	// A snapshot whose event id is live again conflicts instead of
+	// overwriting it, and stays in the archive.
+	if _, err := db.Exec(ctx, `
+		INSERT INTO events_archive (id, season_id, name, starts_at, event)
+		SELECT id, season_id, name, starts_at, to_jsonb(events) FROM events WHERE id = $1`, eventID); err != nil {
+		t.Fatalf("archive duplicate failed: %v", err)
+	}
+	if rec := restore(eventID); rec.Code != http.StatusConflict {
+		t.Fatalf("restoreEvent(live id) status = %d body=%s, want %d", rec.Code, rec.Body.String(), http.StatusConflict)
+	}
+	if err := db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM events_archive WHERE id = $1)`, eventID).Scan(&archived); err != nil {
+		t.Fatalf("check archive failed: %v", err)
+	}
+	if !archived {
+		t.Fatalf("conflicting restore removed the archived snapshot")
+	}
+}
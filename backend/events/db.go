@@ -0,0 +1,38 @@
+package events
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DB is the subset of the pgx pool API used by the events handler. It is
+// satisfied by *pgxpool.Pool and lets tests substitute a fake database.
+type DB interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// rowQuerier is implemented by DB and pgx.Tx for helpers that only read a
+// single row.
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// rowExecer is implemented by DB and pgx.Tx for helpers that read single
+// rows and write.
+type rowExecer interface {
+	rowQuerier
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// querier is implemented by DB and pgx.Tx for helpers that read single rows
+// and row sets.
+type querier interface {
+	rowQuerier
+	rowsQuerier
+}
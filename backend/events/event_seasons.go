@@ -0,0 +1,73 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// An event always has a primary season in events.season_id. Events that span
+// a season boundary or are co-hosted are also linked to further seasons in
+// event_seasons; the primary season is never repeated there.
+
+// normalizeLinkedSeasonIDs dedupes and sorts linked season ids, dropping the
+// primary season.
+func normalizeLinkedSeasonIDs(raw []int64, primaryID int64) ([]int64, error) {
+	seen := map[int64]bool{primaryID: true}
+	ids := make([]int64, 0, len(raw))
+	for _, id := range raw {
+		if id <= 0 {
+			return nil, errors.New("season_ids must contain positive integers")
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+func replaceEventSeasonsTx(ctx context.Context, tx pgx.Tx, eventID int64, seasonIDs []int64) error {
+	if err := ensureReferencesExist(ctx, tx, "seasons", "season_ids", seasonIDs); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM event_seasons WHERE event_id = $1`, eventID); err != nil {
+		return err
+	}
+	if len(seasonIDs) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(ctx,
+		`INSERT INTO event_seasons (event_id, season_id) SELECT $1, unnest($2::integer[])`,
+		eventID, seasonIDs,
+	)
+	return err
+}
+
+func (h *Handler) fetchSeasonsForEvents(ctx context.Context, eventIDs []int64) (map[int64][]int64, error) {
+	result := make(map[int64][]int64, len(eventIDs))
+	rows, err := h.db.Query(ctx,
+		`SELECT event_id, season_id
+         FROM event_seasons
+         WHERE event_id = ANY($1)
+         ORDER BY event_id, season_id`,
+		eventIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var eventID, seasonID int64
+		if err := rows.Scan(&eventID, &seasonID); err != nil {
+			return nil, err
+		}
+		result[eventID] = append(result[eventID], seasonID)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,116 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/innhopp/central/backend/internal/clock"
+)
+
+func TestReplaceEventSeasonsRejectsUnknownSeasons(t *testing.T) {
+	db := openEventTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ensureEventTestSchema(t, ctx, db)
+
+	var primaryID, linkedID, eventID int64
+	if err := db.QueryRow(ctx, `INSERT INTO seasons (name, starts_on) VALUES ('Primary Season', CURRENT_DATE) RETURNING id`).Scan(&primaryID); err != nil {
+		t.Fatalf("insert season failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM seasons WHERE id = $1`, primaryID)
+	if err := db.QueryRow(ctx, `INSERT INTO seasons (name, starts_on) VALUES ('Linked Season', CURRENT_DATE) RETURNING id`).Scan(&linkedID); err != nil {
+		t.Fatalf("insert season failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM seasons WHERE id = $1`, linkedID)
+	if err := db.QueryRow(ctx, `INSERT INTO events (season_id, name, starts_at) VALUES ($1, 'Co-hosted Event', NOW()) RETURNING id`, primaryID).Scan(&eventID); err != nil {
+		t.Fatalf("insert event failed: %v", err)
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		t.Fatalf("begin failed: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	missingID := linkedID + 1000000
+	err = replaceEventSeasonsTx(ctx, tx, eventID, []int64{linkedID, missingID})
+	var unknown *unknownReferencesError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("replaceEventSeasonsTx() error = %v, want unknownReferencesError", err)
+	}
+	if unknown.field != "season_ids" || len(unknown.ids) != 1 || unknown.ids[0] != missingID {
+		t.Fatalf("unknown references = %s %v, want season_ids [%d]", unknown.field, unknown.ids, missingID)
+	}
+
+	if err := replaceEventSeasonsTx(ctx, tx, eventID, []int64{linkedID}); err != nil {
+		t.Fatalf("replaceEventSeasonsTx() returned error: %v", err)
+	}
+	var linked []int64
+	if err := tx.QueryRow(ctx, `SELECT array_agg(season_id) FROM event_seasons WHERE event_id = $1`, eventID).Scan(&linked); err != nil {
+		t.Fatalf("load links failed: %v", err)
+	}
+	if len(linked) != 1 || linked[0] != linkedID {
+		t.Fatalf("linked seasons = %v, want [%d]", linked, linkedID)
+	}
+}
+
+func TestGetSeasonCountsUpcomingEventsByTheHandlerClock(t *testing.T) {
+	db := openEventTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ensureEventTestSchema(t, ctx, db)
+
+	var primaryID, linkedID int64
+	if err := db.QueryRow(ctx, `INSERT INTO seasons (name, starts_on) VALUES ('Counted Season', CURRENT_DATE) RETURNING id`).Scan(&primaryID); err != nil {
+		t.Fatalf("insert season failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM seasons WHERE id = $1`, primaryID)
+	if err := db.QueryRow(ctx, `INSERT INTO seasons (name, starts_on) VALUES ('Counted Linked Season', CURRENT_DATE) RETURNING id`).Scan(&linkedID); err != nil {
+		t.Fatalf("insert season failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM seasons WHERE id = $1`, linkedID)
+
+	// The clock runs ten days ahead of the database, so only the event after
+	// it is upcoming even though all three are still ahead of NOW().
+	now := time.Now().UTC().Add(10 * 24 * time.Hour)
+	for _, startsAt := range []time.Time{now.Add(-5 * 24 * time.Hour), now.Add(-time.Hour), now.Add(24 * time.Hour)} {
+		var eventID int64
+		if err := db.QueryRow(ctx, `INSERT INTO events (season_id, name, starts_at) VALUES ($1, 'Counted Event', $2) RETURNING id`, primaryID, startsAt).Scan(&eventID); err != nil {
+			t.Fatalf("insert event failed: %v", err)
+		}
+		if _, err := db.Exec(ctx, `INSERT INTO event_seasons (event_id, season_id) VALUES ($1, $2)`, eventID, linkedID); err != nil {
+			t.Fatalf("link event failed: %v", err)
+		}
+	}
+
+	h := NewHandler(db)
+	h.SetClock(clock.Fixed{At: now})
+	router := chi.NewRouter()
+	router.Get("/seasons/{seasonID}", h.getSeason)
+	for _, seasonID := range []int64{primaryID, linkedID} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/seasons/"+strconv.FormatInt(seasonID, 10), nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("getSeason(%d) status = %d body=%s", seasonID, rec.Code, rec.Body.String())
+		}
+		var season Season
+		if err := json.NewDecoder(rec.Body).Decode(&season); err != nil {
+			t.Fatalf("decode season failed: %v", err)
+		}
+		if season.EventCount != 3 || season.UpcomingEventCount != 1 {
+			t.Fatalf("season %d counts = %d events, %d upcoming; want 3 and 1", seasonID, season.EventCount, season.UpcomingEventCount)
+		}
+	}
+}
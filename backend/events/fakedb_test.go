@@ -0,0 +1,131 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var errFakeDBUnsupported = errors.New("fake db: operation not supported")
+
+// fakeResult returns rows for any query whose SQL contains match.
+type fakeResult struct {
+	match string
+	rows  [][]any
+}
+
+// fakeExec answers any Exec whose SQL contains match with tag.
+type fakeExec struct {
+	match string
+	tag   string
+}
+
+// fakeDB is an in-memory DB that records every statement it receives and
+// answers queries from canned results. Exec fails unless execs has a match.
+type fakeDB struct {
+	results []fakeResult
+	execs   []fakeExec
+	calls   []string
+}
+
+func (f *fakeDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	f.calls = append(f.calls, "BEGIN")
+	return nil, errFakeDBUnsupported
+}
+
+func (f *fakeDB) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	f.calls = append(f.calls, "BEGIN")
+	return nil, errFakeDBUnsupported
+}
+
+func (f *fakeDB) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	f.calls = append(f.calls, sql)
+	for _, exec := range f.execs {
+		if strings.Contains(sql, exec.match) {
+			return pgconn.NewCommandTag(exec.tag), nil
+		}
+	}
+	return pgconn.CommandTag{}, errFakeDBUnsupported
+}
+
+func (f *fakeDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	f.calls = append(f.calls, sql)
+	for _, result := range f.results {
+		if strings.Contains(sql, result.match) {
+			return &fakeRows{rows: result.rows, index: -1}, nil
+		}
+	}
+	return &fakeRows{index: -1}, nil
+}
+
+func (f *fakeDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	rows, _ := f.Query(ctx, sql, args...)
+	return fakeRow{rows: rows.(*fakeRows)}
+}
+
+type fakeRows struct {
+	rows  [][]any
+	index int
+	err   error
+}
+
+func (r *fakeRows) Close()                                       {}
+func (r *fakeRows) Err() error                                   { return r.err }
+func (r *fakeRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (r *fakeRows) RawValues() [][]byte                          { return nil }
+func (r *fakeRows) Conn() *pgx.Conn                              { return nil }
+
+func (r *fakeRows) Next() bool {
+	if r.index+1 >= len(r.rows) {
+		return false
+	}
+	r.index++
+	return true
+}
+
+func (r *fakeRows) Values() ([]any, error) {
+	if r.index < 0 || r.index >= len(r.rows) {
+		return nil, errors.New("fake db: no current row")
+	}
+	return r.rows[r.index], nil
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	values, err := r.Values()
+	if err != nil {
+		return err
+	}
+	if len(values) != len(dest) {
+		return fmt.Errorf("fake db: row has %d values, scan wants %d", len(values), len(dest))
+	}
+	for i, value := range values {
+		target := reflect.ValueOf(dest[i]).Elem()
+		if value == nil {
+			target.Set(reflect.Zero(target.Type()))
+			continue
+		}
+		source := reflect.ValueOf(value)
+		if !source.Type().AssignableTo(target.Type()) {
+			return fmt.Errorf("fake db: cannot scan %T into %s", value, target.Type())
+		}
+		target.Set(source)
+	}
+	return nil
+}
+
+type fakeRow struct {
+	rows *fakeRows
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if !r.rows.Next() {
+		return pgx.ErrNoRows
+	}
+	return r.rows.Scan(dest...)
+}
@@ -0,0 +1,107 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// eventFieldNames is the set of top-level JSON fields an Event can render,
+// derived from its struct tags.
+var eventFieldNames = func() map[string]bool {
+	names := make(map[string]bool)
+	t := reflect.TypeOf(Event{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}()
+
+// eventRelationFields maps the fields filled by attachEventRelations to the
+// include name that loads them.
+var eventRelationFields = map[string]string{
+	"participant_ids": "participants",
+	"innhopps":        "innhopps",
+	"aircraft":        "aircraft",
+	"airfield_ids":    "airfields",
+	"season_ids":      "seasons",
+	"remaining_slots": "remaining_slots",
+}
+
+// eventFieldSelection is the set of fields requested with ?fields=. A nil
+// selection means the full event.
+type eventFieldSelection map[string]bool
+
+func parseEventFields(raw string) (eventFieldSelection, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	selection := eventFieldSelection{"id": true}
+	for _, part := range strings.Split(raw, ",") {
+		name := strings.ToLower(strings.TrimSpace(part))
+		if name == "" {
+			continue
+		}
+		if !eventFieldNames[name] {
+			return nil, errors.New("unknown field: " + name)
+		}
+		selection[name] = true
+	}
+	return selection, nil
+}
+
+// relations narrows include to the relations the selected fields render. A
+// requested relation field is loaded even if include leaves it out.
+func (s eventFieldSelection) relations(include eventIncludes) eventIncludes {
+	if s == nil {
+		return include
+	}
+	needed := eventIncludes{}
+	for field, relation := range eventRelationFields {
+		if s[field] {
+			needed[relation] = true
+		}
+	}
+	return needed
+}
+
+// project renders event limited to the selected fields. It goes through the
+// event's own JSON encoding so formatting such as all-day dates is kept.
+func (s eventFieldSelection) project(event Event) (any, error) {
+	if s == nil {
+		return event, nil
+	}
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+	for name := range fields {
+		if !s[name] {
+			delete(fields, name)
+		}
+	}
+	return fields, nil
+}
+
+func (s eventFieldSelection) projectAll(events []Event) (any, error) {
+	if s == nil {
+		return events, nil
+	}
+	projected := make([]any, len(events))
+	for i, event := range events {
+		value, err := s.project(event)
+		if err != nil {
+			return nil, err
+		}
+		projected[i] = value
+	}
+	return projected, nil
+}
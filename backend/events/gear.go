@@ -0,0 +1,248 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/innhopp/central/backend/httpx"
+)
+
+// GearCheckout is a gear asset taken out for an event. It occupies the asset
+// from the event's start until it is returned.
+type GearCheckout struct {
+	ID           int64      `json:"id"`
+	EventID      int64      `json:"event_id"`
+	GearAssetID  int64      `json:"gear_asset_id"`
+	Name         string     `json:"name"`
+	SerialNumber string     `json:"serial_number"`
+	CheckedOutAt time.Time  `json:"checked_out_at"`
+	ReturnedAt   *time.Time `json:"returned_at,omitempty"`
+}
+
+// gearConflict is the event already holding an asset when a checkout would
+// double-book it.
+type gearConflict struct {
+	ID       int64      `json:"id"`
+	Name     string     `json:"name"`
+	StartsAt time.Time  `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+}
+
+const gearCheckoutSelect = `
+	SELECT c.id, c.event_id, c.gear_asset_id, g.name, g.serial_number, c.checked_out_at, c.returned_at
+	FROM event_gear_checkouts c
+	JOIN gear_assets g ON g.id = c.gear_asset_id`
+
+func scanGearCheckout(row pgx.Row) (GearCheckout, error) {
+	var c GearCheckout
+	err := row.Scan(&c.ID, &c.EventID, &c.GearAssetID, &c.Name, &c.SerialNumber, &c.CheckedOutAt, &c.ReturnedAt)
+	return c, err
+}
+
+// findGearConflict returns the event holding assetID during a window ending
+// at endsAt, or nil. A checkout holds the asset from its event's start until
+// it is returned, so an open checkout keeps holding it after its event ends.
+// Returned checkouts ended before any new one starts and never conflict.
+func findGearConflict(ctx context.Context, db rowQuerier, assetID int64, endsAt time.Time) (*gearConflict, error) {
+	var c gearConflict
+	err := db.QueryRow(ctx, `
+		SELECT e.id, e.name, e.starts_at, e.ends_at
+		FROM event_gear_checkouts c
+		JOIN events e ON e.id = c.event_id
+		WHERE c.gear_asset_id = $1
+		  AND c.returned_at IS NULL
+		  AND e.starts_at <= $2
+		ORDER BY e.starts_at, e.id
+		LIMIT 1`, assetID, endsAt,
+	).Scan(&c.ID, &c.Name, &c.StartsAt, &c.EndsAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (h *Handler) listEventGear(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.ParseInt(chi.URLParam(r, "eventID"), 10, 64)
+	if err != nil || eventID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid event id")
+		return
+	}
+	ctx := r.Context()
+	exists, err := eventExists(ctx, h.db, eventID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to validate event")
+		return
+	}
+	if !exists {
+		httpx.Error(w, http.StatusNotFound, "event not found")
+		return
+	}
+
+	rows, err := h.db.Query(ctx, gearCheckoutSelect+`
+		WHERE c.event_id = $1
+		ORDER BY c.checked_out_at, c.id`, eventID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to list event gear")
+		return
+	}
+	defer rows.Close()
+
+	checkouts := make([]GearCheckout, 0)
+	for rows.Next() {
+		c, err := scanGearCheckout(rows)
+		if err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to parse event gear")
+			return
+		}
+		checkouts = append(checkouts, c)
+	}
+	if err := rows.Err(); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to list event gear")
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, checkouts)
+}
+
+// checkOutEventGear checks a gear asset out to an event. An asset still held
+// by an open checkout whose event starts before this one ends, including one
+// for this same event, is refused with 409 naming that event. The asset row is
+// locked so two checkouts cannot both pass the check.
+func (h *Handler) checkOutEventGear(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.ParseInt(chi.URLParam(r, "eventID"), 10, 64)
+	if err != nil || eventID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid event id")
+		return
+	}
+	var payload struct {
+		GearAssetID int64 `json:"gear_asset_id"`
+	}
+	if err := httpx.DecodeJSON(r, &payload); err != nil {
+		httpx.PayloadError(w, err, "invalid request payload")
+		return
+	}
+	if payload.GearAssetID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "gear_asset_id is required")
+		return
+	}
+
+	ctx := r.Context()
+	tx, err := h.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to check out gear")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var startsAt time.Time
+	var endsAt *time.Time
+	err = tx.QueryRow(ctx, `SELECT starts_at, ends_at FROM events WHERE id = $1`, eventID).Scan(&startsAt, &endsAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		httpx.Error(w, http.StatusNotFound, "event not found")
+		return
+	}
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load event")
+		return
+	}
+	windowEnd := startsAt
+	if endsAt != nil {
+		windowEnd = *endsAt
+	}
+
+	var status string
+	err = tx.QueryRow(ctx, `SELECT status FROM gear_assets WHERE id = $1 FOR UPDATE`, payload.GearAssetID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		httpx.Error(w, http.StatusNotFound, "gear asset not found")
+		return
+	}
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to check out gear")
+		return
+	}
+	if strings.EqualFold(strings.TrimSpace(status), "retired") {
+		httpx.Error(w, http.StatusConflict, "retired gear cannot be checked out")
+		return
+	}
+
+	conflict, err := findGearConflict(ctx, tx, payload.GearAssetID, windowEnd)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to check out gear")
+		return
+	}
+	if conflict != nil {
+		httpx.WriteJSON(w, http.StatusConflict, map[string]any{
+			"error":             "gear asset is already checked out for an overlapping event",
+			"conflicting_event": conflict,
+		})
+		return
+	}
+
+	var checkoutID int64
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO event_gear_checkouts (event_id, gear_asset_id) VALUES ($1, $2)
+		RETURNING id`, eventID, payload.GearAssetID,
+	).Scan(&checkoutID); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to check out gear")
+		return
+	}
+	checkout, err := scanGearCheckout(tx.QueryRow(ctx, gearCheckoutSelect+` WHERE c.id = $1`, checkoutID))
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load checkout")
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to check out gear")
+		return
+	}
+	httpx.WriteJSON(w, http.StatusCreated, checkout)
+}
+
+// returnEventGear marks a checkout returned, freeing the asset from now on.
+func (h *Handler) returnEventGear(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.ParseInt(chi.URLParam(r, "eventID"), 10, 64)
+	if err != nil || eventID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid event id")
+		return
+	}
+	checkoutID, err := strconv.ParseInt(chi.URLParam(r, "checkoutID"), 10, 64)
+	if err != nil || checkoutID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid checkout id")
+		return
+	}
+
+	ctx := r.Context()
+	var returnedAt *time.Time
+	err = h.db.QueryRow(ctx, `SELECT returned_at FROM event_gear_checkouts WHERE id = $1 AND event_id = $2`, checkoutID, eventID).Scan(&returnedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		httpx.Error(w, http.StatusNotFound, "checkout not found")
+		return
+	}
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to return gear")
+		return
+	}
+	if returnedAt != nil {
+		httpx.Error(w, http.StatusConflict, "gear was already returned")
+		return
+	}
+	if _, err := h.db.Exec(ctx, `UPDATE event_gear_checkouts SET returned_at = NOW() WHERE id = $1 AND returned_at IS NULL`, checkoutID); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to return gear")
+		return
+	}
+	checkout, err := scanGearCheckout(h.db.QueryRow(ctx, gearCheckoutSelect+` WHERE c.id = $1`, checkoutID))
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load checkout")
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, checkout)
+}
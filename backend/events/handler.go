@@ -16,10 +16,11 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/innhopp/central/backend/airfields"
 	"github.com/innhopp/central/backend/httpx"
+	"github.com/innhopp/central/backend/internal/clock"
+	"github.com/innhopp/central/backend/internal/textsearch"
 	"github.com/innhopp/central/backend/internal/timeutil"
 	"github.com/innhopp/central/backend/logistics"
 	"github.com/innhopp/central/backend/rbac"
@@ -46,33 +47,107 @@ var (
 	commercialStatusValues = []string{"draft", "registration_open", "awaiting_threshold", "confirmed", "cancelled"}
 )
 
-const defaultEventStatus = "draft"
+// StatusValues lists the accepted event statuses.
+func StatusValues() []string {
+	return append([]string(nil), eventStatusValues...)
+}
+
+// CommercialStatusValues lists the accepted event commercial statuses.
+func CommercialStatusValues() []string {
+	return append([]string(nil), commercialStatusValues...)
+}
+
+const (
+	defaultEventStatus = "draft"
+	eventDateLayout    = "2006-01-02"
+)
 
 // Handler provides read/write APIs for seasons, events, and manifests.
 type Handler struct {
-	db *pgxpool.Pool
+	db DB
+	// archiveAfterDays is how long past events stay in the hot table before
+	// POST /events/archive moves them out.
+	archiveAfterDays int
+	// listIncludes are the relations listEvents attaches when the request
+	// does not say.
+	listIncludes eventIncludes
+	// enforcer decides whether the caller may see staff-only event notes. It
+	// is set by Routes; without it notes are always redacted.
+	enforcer *rbac.Enforcer
+	// innhoppRequirements names the innhopp fields an event needs before it
+	// moves to each status.
+	innhoppRequirements innhoppRequirements
+	// landingAreaMinimums sets the primary landing area size below which
+	// preflight warns, by participant experience.
+	landingAreaMinimums landingAreaMinimums
+	// minimumLiveCrew is the fewest crew an event needs assigned across its
+	// manifests before it may go live; zero disables the check.
+	minimumLiveCrew int
+	// takeoffSuggestRadiusKm is how near an airfield must be to a saved
+	// innhopp to be suggested as its takeoff airfield; zero disables it.
+	takeoffSuggestRadiusKm float64
+	// clock is the time event statuses are derived against.
+	clock clock.Clock
 }
 
 // NewHandler creates an events handler.
-func NewHandler(db *pgxpool.Pool) *Handler {
-	return &Handler{db: db}
+func NewHandler(db DB) *Handler {
+	return &Handler{
+		db:                     db,
+		archiveAfterDays:       archiveAfterDaysFromEnv(),
+		listIncludes:           listIncludesFromEnv(),
+		innhoppRequirements:    innhoppRequirementsFromEnv(),
+		landingAreaMinimums:    landingAreaMinimumsFromEnv(),
+		minimumLiveCrew:        minimumLiveCrewFromEnv(),
+		takeoffSuggestRadiusKm: airfields.SuggestRadiusKmFromEnv(),
+		clock:                  clock.Real{},
+	}
+}
+
+// SetClock replaces the time source event statuses are derived against.
+func (h *Handler) SetClock(c clock.Clock) {
+	if c == nil {
+		c = clock.Real{}
+	}
+	h.clock = c
+}
+
+// recalculateRouteDurations refreshes logistics route durations that point at
+// the given location.
+func (h *Handler) recalculateRouteDurations(ctx context.Context, refType string, refID int64) error {
+	return logistics.RecalculateRouteDurationsForLocationReference(ctx, h.db, refType, refID)
 }
 
 // Routes configures the HTTP routes for event resources.
 func (h *Handler) Routes(enforcer *rbac.Enforcer) chi.Router {
+	h.enforcer = enforcer
 	r := chi.NewRouter()
 	r.With(enforcer.Authorize(rbac.PermissionViewSeasons)).Get("/seasons", h.listSeasons)
 	r.With(enforcer.Authorize(rbac.PermissionManageSeasons)).Post("/seasons", h.createSeason)
 	r.With(enforcer.Authorize(rbac.PermissionViewSeasons)).Get("/seasons/{seasonID}", h.getSeason)
 	r.With(enforcer.Authorize(rbac.PermissionManageSeasons)).Delete("/seasons/{seasonID}", h.deleteSeason)
+	r.With(enforcer.Authorize(rbac.PermissionManageSeasons), enforcer.AdminAction("seasons.merge")).Post("/seasons/{seasonID}/merge", h.mergeSeason)
+	r.With(enforcer.Authorize(rbac.PermissionViewCrewAssignments)).Get("/seasons/{seasonID}/stats", h.getSeasonStats)
 
 	r.With(enforcer.Authorize(rbac.PermissionViewEvents)).Get("/events", h.listEvents)
+	r.With(enforcer.Authorize(rbac.PermissionViewEvents)).Get("/events/tags", h.listEventTags)
+	r.With(enforcer.Authorize(rbac.PermissionViewEvents)).Get("/events/status-counts", h.listEventStatusCounts)
+	r.With(enforcer.Authorize(rbac.PermissionViewParticipants)).Get("/events/rosters", h.listEventRosters)
+	r.With(enforcer.Authorize(rbac.PermissionManageEvents), enforcer.AdminAction("events.archive")).Post("/events/archive", h.archiveEvents)
 	r.With(enforcer.Authorize(rbac.PermissionManageEvents)).Post("/events", h.createEvent)
 	r.With(enforcer.Authorize(rbac.PermissionViewEvents)).Get("/events/{eventID}", h.getEvent)
 	r.With(enforcer.Authorize(rbac.PermissionManageEvents)).Put("/events/{eventID}", h.updateEvent)
+	r.With(enforcer.Authorize(rbac.PermissionViewEvents)).Get("/events/{eventID}/preflight", h.preflightEvent)
+	r.With(enforcer.Authorize(rbac.PermissionViewParticipants)).Get("/events/{eventID}/roster", h.getEventRoster)
 	r.With(enforcer.Authorize(rbac.PermissionManageEvents)).Post("/events/{eventID}/copy", h.copyEvent)
+	r.With(enforcer.Authorize(rbac.PermissionManageEvents)).Post("/events/{eventID}/restore", h.restoreEvent)
 	r.With(enforcer.Authorize(rbac.PermissionManageEvents)).Delete("/events/{eventID}", h.deleteEvent)
 	r.With(enforcer.Authorize(rbac.PermissionManageEvents)).Post("/events/{eventID}/innhopps", h.createInnhopp)
+	r.With(enforcer.Authorize(rbac.PermissionViewEvents)).Get("/events/{eventID}/innhopps/next-sequence", h.getNextInnhoppSequence)
+	r.With(enforcer.Authorize(rbac.PermissionManageManifests)).Post("/events/{eventID}/manifests/generate", h.generateManifests)
+	r.With(enforcer.Authorize(rbac.PermissionViewLogistics)).Get("/events/{eventID}/gear", h.listEventGear)
+	r.With(enforcer.Authorize(rbac.PermissionManageLogistics)).Post("/events/{eventID}/gear", h.checkOutEventGear)
+	r.With(enforcer.Authorize(rbac.PermissionManageLogistics)).Post("/events/{eventID}/gear/{checkoutID}/return", h.returnEventGear)
 	r.With(enforcer.Authorize(rbac.PermissionViewEvents)).Get("/accommodations", h.listAllAccommodations)
 	r.With(enforcer.Authorize(rbac.PermissionViewEvents)).Get("/events/{eventID}/accommodations", h.listAccommodations)
 	r.With(enforcer.Authorize(rbac.PermissionManageEvents)).Post("/events/{eventID}/accommodations", h.createAccommodation)
@@ -85,6 +160,7 @@ func (h *Handler) Routes(enforcer *rbac.Enforcer) chi.Router {
 	r.With(enforcer.Authorize(rbac.PermissionManageEvents)).Post("/airfields", h.createAirfield)
 	r.With(enforcer.Authorize(rbac.PermissionManageEvents)).Put("/airfields/{airfieldID}", h.updateAirfield)
 	r.With(enforcer.Authorize(rbac.PermissionManageEvents)).Delete("/airfields/{airfieldID}", h.deleteAirfield)
+	r.With(enforcer.Authorize(rbac.PermissionManageEvents), enforcer.AdminAction("airfields.merge")).Post("/airfields/{airfieldID}/merge", h.mergeAirfield)
 	r.With(enforcer.Authorize(rbac.PermissionViewEvents)).Get("/aircraft", h.listAircraft)
 	r.With(enforcer.Authorize(rbac.PermissionViewEvents)).Get("/aircraft/{aircraftID}", h.getAircraft)
 	r.With(enforcer.Authorize(rbac.PermissionManageEvents)).Post("/aircraft", h.createAircraft)
@@ -94,24 +170,61 @@ func (h *Handler) Routes(enforcer *rbac.Enforcer) chi.Router {
 	r.With(enforcer.Authorize(rbac.PermissionViewManifests)).Get("/manifests", h.listManifests)
 	r.With(enforcer.Authorize(rbac.PermissionManageManifests)).Post("/manifests", h.createManifest)
 	r.With(enforcer.Authorize(rbac.PermissionViewManifests)).Get("/manifests/{manifestID}", h.getManifest)
+	r.With(enforcer.Authorize(rbac.PermissionViewManifests)).Get("/manifests/{manifestID}/capacity", h.getManifestCapacity)
 	r.With(enforcer.Authorize(rbac.PermissionManageManifests)).Put("/manifests/{manifestID}", h.updateManifest)
+	r.With(enforcer.Authorize(rbac.PermissionManageManifests)).Delete("/manifests/{manifestID}", h.deleteManifest)
 	return r
 }
 
 type Season struct {
-	ID        int64      `json:"id"`
-	Name      string     `json:"name"`
-	StartsOn  time.Time  `json:"starts_on"`
-	EndsOn    *time.Time `json:"ends_on,omitempty"`
-	CreatedAt time.Time  `json:"created_at"`
+	ID                 int64      `json:"id"`
+	Name               string     `json:"name"`
+	StartsOn           time.Time  `json:"starts_on"`
+	EndsOn             *time.Time `json:"ends_on,omitempty"`
+	EventCount         int        `json:"event_count"`
+	UpcomingEventCount int        `json:"upcoming_event_count"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// seasonSelect loads seasons with their event counts, which include events
+// linked to the season through event_seasons. $1 is the handler clock's now,
+// which upcoming events start at or after; callers append WHERE and ORDER BY
+// clauses numbering their parameters from $2.
+const seasonSelect = `
+	SELECT s.id, s.name, s.starts_on, s.ends_on,
+	       COALESCE(c.event_count, 0), COALESCE(c.upcoming_event_count, 0), s.created_at
+	FROM seasons s
+	LEFT JOIN (
+		SELECT season_id,
+		       COUNT(*) AS event_count,
+		       COUNT(*) FILTER (WHERE starts_at >= $1) AS upcoming_event_count
+		FROM (
+			SELECT season_id, starts_at FROM events
+			UNION ALL
+			SELECT es.season_id, e.starts_at
+			FROM event_seasons es
+			JOIN events e ON e.id = es.event_id
+		) linked
+		GROUP BY season_id
+	) c ON c.season_id = s.id`
+
+func scanSeason(row pgx.Row) (Season, error) {
+	var s Season
+	err := row.Scan(&s.ID, &s.Name, &s.StartsOn, &s.EndsOn, &s.EventCount, &s.UpcomingEventCount, &s.CreatedAt)
+	return s, err
 }
 
 type Event struct {
 	ID                        int64      `json:"id"`
 	SeasonID                  int64      `json:"season_id"`
+	SeasonIDs                 []int64    `json:"season_ids"`
 	Name                      string     `json:"name"`
 	Location                  string     `json:"location,omitempty"`
+	BaseAirfieldID            *int64     `json:"base_airfield_id,omitempty"`
+	BaseAirfieldName          string     `json:"base_airfield_name,omitempty"`
 	Status                    string     `json:"status"`
+	AllDay                    bool       `json:"all_day"`
+	Tags                      []string   `json:"tags"`
 	StartsAt                  time.Time  `json:"starts_at"`
 	EndsAt                    *time.Time `json:"ends_at,omitempty"`
 	Slots                     int        `json:"slots"`
@@ -125,13 +238,40 @@ type Event struct {
 	Currency                  string     `json:"currency,omitempty"`
 	MinimumDepositCount       int        `json:"minimum_deposit_count"`
 	CommercialStatus          string     `json:"commercial_status"`
+	Briefing                  string     `json:"briefing,omitempty"`
+	Notes                     string     `json:"notes,omitempty"`
 	AirfieldIDs               []int64    `json:"airfield_ids"`
 	ParticipantIDs            []int64    `json:"participant_ids"`
 	Aircraft                  []Aircraft `json:"aircraft"`
 	Innhopps                  []Innhopp  `json:"innhopps"`
+	Archived                  bool       `json:"archived,omitempty"`
+	ClientID                  string     `json:"client_id,omitempty"`
 	CreatedAt                 time.Time  `json:"created_at"`
 }
 
+// MarshalJSON renders starts_at and ends_at as plain dates for all-day events.
+func (e Event) MarshalJSON() ([]byte, error) {
+	type eventJSON Event
+	if !e.AllDay {
+		return json.Marshal(eventJSON(e))
+	}
+
+	var endsAt *string
+	if e.EndsAt != nil {
+		value := e.EndsAt.Format(eventDateLayout)
+		endsAt = &value
+	}
+	return json.Marshal(struct {
+		eventJSON
+		StartsAt string  `json:"starts_at"`
+		EndsAt   *string `json:"ends_at,omitempty"`
+	}{
+		eventJSON: eventJSON(e),
+		StartsAt:  e.StartsAt.Format(eventDateLayout),
+		EndsAt:    endsAt,
+	})
+}
+
 type AircraftPricingModel string
 
 const (
@@ -200,36 +340,40 @@ type InnhoppImage struct {
 }
 
 type Innhopp struct {
-	ID                    int64          `json:"id"`
-	EventID               int64          `json:"event_id"`
-	Sequence              int            `json:"sequence"`
-	Name                  string         `json:"name"`
-	Coordinates           string         `json:"coordinates,omitempty"`
-	AircraftID            *int64         `json:"aircraft_id,omitempty"`
-	TakeoffAirfieldID     *int64         `json:"takeoff_airfield_id,omitempty"`
-	LandingAirfieldID     *int64         `json:"landing_airfield_id,omitempty"`
-	Elevation             *int           `json:"elevation,omitempty"`
-	ScheduledAt           *time.Time     `json:"scheduled_at,omitempty"`
-	Notes                 string         `json:"notes,omitempty"`
-	ReasonForChoice       string         `json:"reason_for_choice,omitempty"`
-	AdjustAltimeterAAD    string         `json:"adjust_altimeter_aad,omitempty"`
-	Notam                 string         `json:"notam,omitempty"`
-	DistanceByAir         *float64       `json:"distance_by_air,omitempty"`
-	DistanceByRoad        *float64       `json:"distance_by_road,omitempty"`
-	LandingDistanceByAir  *float64       `json:"landing_distance_by_air,omitempty"`
-	LandingDistanceByRoad *float64       `json:"landing_distance_by_road,omitempty"`
-	PrimaryLandingArea    LandingArea    `json:"primary_landing_area"`
-	SecondaryLandingArea  LandingArea    `json:"secondary_landing_area"`
-	RiskAssessment        string         `json:"risk_assessment,omitempty"`
-	SafetyPrecautions     string         `json:"safety_precautions,omitempty"`
-	Jumprun               string         `json:"jumprun,omitempty"`
-	Hospital              string         `json:"hospital,omitempty"`
-	RescueBoat            *bool          `json:"rescue_boat,omitempty"`
-	MinimumRequirements   string         `json:"minimum_requirements,omitempty"`
-	LandOwners            []LandOwner    `json:"land_owners,omitempty"`
-	LandOwnerPermission   *bool          `json:"land_owner_permission,omitempty"`
-	ImageFiles            []InnhoppImage `json:"image_files,omitempty"`
-	CreatedAt             time.Time      `json:"created_at"`
+	ID                int64  `json:"id"`
+	EventID           int64  `json:"event_id"`
+	Sequence          int    `json:"sequence"`
+	Name              string `json:"name"`
+	Coordinates       string `json:"coordinates,omitempty"`
+	AircraftID        *int64 `json:"aircraft_id,omitempty"`
+	TakeoffAirfieldID *int64 `json:"takeoff_airfield_id,omitempty"`
+	LandingAirfieldID *int64 `json:"landing_airfield_id,omitempty"`
+	// SuggestedTakeoffAirfieldID is only sent when creating an innhopp; see
+	// airfields.SuggestTakeoff.
+	SuggestedTakeoffAirfieldID *int64         `json:"suggested_takeoff_airfield_id,omitempty"`
+	Elevation                  *int           `json:"elevation,omitempty"`
+	ScheduledAt                *time.Time     `json:"scheduled_at,omitempty"`
+	Notes                      string         `json:"notes,omitempty"`
+	ReasonForChoice            string         `json:"reason_for_choice,omitempty"`
+	AdjustAltimeterAAD         string         `json:"adjust_altimeter_aad,omitempty"`
+	Notam                      string         `json:"notam,omitempty"`
+	DistanceByAir              *float64       `json:"distance_by_air,omitempty"`
+	DistanceByRoad             *float64       `json:"distance_by_road,omitempty"`
+	LandingDistanceByAir       *float64       `json:"landing_distance_by_air,omitempty"`
+	LandingDistanceByRoad      *float64       `json:"landing_distance_by_road,omitempty"`
+	PrimaryLandingArea         LandingArea    `json:"primary_landing_area"`
+	SecondaryLandingArea       LandingArea    `json:"secondary_landing_area"`
+	RiskAssessment             string         `json:"risk_assessment,omitempty"`
+	SafetyPrecautions          string         `json:"safety_precautions,omitempty"`
+	Jumprun                    string         `json:"jumprun,omitempty"`
+	Hospital                   string         `json:"hospital,omitempty"`
+	RescueBoat                 *bool          `json:"rescue_boat,omitempty"`
+	MinimumRequirements        string         `json:"minimum_requirements,omitempty"`
+	LandOwners                 []LandOwner    `json:"land_owners,omitempty"`
+	LandOwnerPermission        *bool          `json:"land_owner_permission,omitempty"`
+	ImageFiles                 []InnhoppImage `json:"image_files,omitempty"`
+	Version                    int            `json:"version"`
+	CreatedAt                  time.Time      `json:"created_at"`
 }
 
 type Manifest struct {
@@ -240,14 +384,20 @@ type Manifest struct {
 	StaffSlots     *int      `json:"staff_slots,omitempty"`
 	Notes          string    `json:"notes,omitempty"`
 	ParticipantIDs []int64   `json:"participant_ids"`
+	ClientID       string    `json:"client_id,omitempty"`
 	CreatedAt      time.Time `json:"created_at"`
 }
 
 type eventPayload struct {
 	SeasonID                  int64             `json:"season_id"`
+	SeasonIDs                 []int64           `json:"season_ids"`
+	ClientID                  *string           `json:"client_id"`
 	Name                      string            `json:"name"`
 	Location                  string            `json:"location"`
+	BaseAirfieldID            *int64            `json:"base_airfield_id"`
 	Status                    string            `json:"status"`
+	AllDay                    bool              `json:"all_day"`
+	Tags                      []string          `json:"tags"`
 	StartsAt                  string            `json:"starts_at"`
 	EndsAt                    string            `json:"ends_at"`
 	Slots                     int               `json:"slots"`
@@ -260,10 +410,15 @@ type eventPayload struct {
 	Currency                  string            `json:"currency"`
 	MinimumDepositCount       int               `json:"minimum_deposit_count"`
 	CommercialStatus          string            `json:"commercial_status"`
+	Briefing                  *string           `json:"briefing"`
+	Notes                     *string           `json:"notes"`
 	AirfieldIDs               []int64           `json:"airfield_ids"`
 	ParticipantIDs            []int64           `json:"participant_ids"`
 	Aircraft                  []aircraftPayload `json:"aircraft"`
 	Innhopps                  []innhoppPayload  `json:"innhopps"`
+	// OverrideMinCrew lets staff take an event live with less crew than
+	// MIN_CREW_FOR_LIVE requires.
+	OverrideMinCrew bool `json:"override_min_crew"`
 }
 
 type aircraftSlotPricingBandPayload struct {
@@ -303,38 +458,42 @@ type landOwnerPayload struct {
 }
 
 type innhoppPayload struct {
-	ID                    *int64             `json:"id"`
-	Sequence              *int               `json:"sequence"`
-	Name                  string             `json:"name"`
-	Coordinates           string             `json:"coordinates"`
-	AircraftID            *int64             `json:"aircraft_id"`
-	Elevation             *int               `json:"elevation"`
-	ScheduledAt           string             `json:"scheduled_at"`
-	Notes                 string             `json:"notes"`
-	TakeoffAirfieldID     *int64             `json:"takeoff_airfield_id"`
-	LandingAirfieldID     *int64             `json:"landing_airfield_id"`
-	ReasonForChoice       string             `json:"reason_for_choice"`
-	AdjustAltimeterAAD    string             `json:"adjust_altimeter_aad"`
-	Notam                 string             `json:"notam"`
-	DistanceByAir         *float64           `json:"distance_by_air"`
-	DistanceByRoad        *float64           `json:"distance_by_road"`
-	LandingDistanceByAir  *float64           `json:"landing_distance_by_air"`
-	LandingDistanceByRoad *float64           `json:"landing_distance_by_road"`
-	PrimaryLandingArea    landingAreaPayload `json:"primary_landing_area"`
-	SecondaryLandingArea  landingAreaPayload `json:"secondary_landing_area"`
-	RiskAssessment        string             `json:"risk_assessment"`
-	SafetyPrecautions     string             `json:"safety_precautions"`
-	Jumprun               string             `json:"jumprun"`
-	Hospital              string             `json:"hospital"`
-	RescueBoat            *bool              `json:"rescue_boat"`
-	MinimumRequirements   string             `json:"minimum_requirements"`
-	LandOwners            []landOwnerPayload `json:"land_owners"`
-	LandOwnerPermission   *bool              `json:"land_owner_permission"`
-	ImageFiles            []InnhoppImage     `json:"image_files"`
+	ID                    *int64              `json:"id"`
+	Version               *int                `json:"version"`
+	Sequence              *int                `json:"sequence"`
+	Name                  string              `json:"name"`
+	Coordinates           string              `json:"coordinates"`
+	AircraftID            *int64              `json:"aircraft_id"`
+	Elevation             *int                `json:"elevation"`
+	ScheduledAt           string              `json:"scheduled_at"`
+	Notes                 string              `json:"notes"`
+	TakeoffAirfieldID     *int64              `json:"takeoff_airfield_id"`
+	LandingAirfieldID     *int64              `json:"landing_airfield_id"`
+	ReasonForChoice       string              `json:"reason_for_choice"`
+	AdjustAltimeterAAD    string              `json:"adjust_altimeter_aad"`
+	Notam                 string              `json:"notam"`
+	DistanceByAir         *float64            `json:"distance_by_air"`
+	DistanceByRoad        *float64            `json:"distance_by_road"`
+	LandingDistanceByAir  *float64            `json:"landing_distance_by_air"`
+	LandingDistanceByRoad *float64            `json:"landing_distance_by_road"`
+	PrimaryLandingArea    landingAreaPayload  `json:"primary_landing_area"`
+	SecondaryLandingArea  landingAreaPayload  `json:"secondary_landing_area"`
+	RiskAssessment        string              `json:"risk_assessment"`
+	SafetyPrecautions     string              `json:"safety_precautions"`
+	Jumprun               string              `json:"jumprun"`
+	Hospital              string              `json:"hospital"`
+	RescueBoat            *httpx.FlexibleBool `json:"rescue_boat"`
+	MinimumRequirements   string              `json:"minimum_requirements"`
+	LandOwners            []landOwnerPayload  `json:"land_owners"`
+	LandOwnerPermission   *httpx.FlexibleBool `json:"land_owner_permission"`
+	ImageFiles            []InnhoppImage      `json:"image_files"`
 }
 
+// innhoppInput is a normalized innhopp from an event payload. Version is the
+// version the client last read, required alongside ID.
 type innhoppInput struct {
 	ID                    *int64
+	Version               *int
 	Sequence              int
 	Name                  string
 	Coordinates           string
@@ -388,21 +547,8 @@ type aircraftInput struct {
 	PreserveSlotBands   bool
 }
 
-func decodeEventJSON(r *http.Request, dest any) error {
-	defer r.Body.Close()
-	decoder := json.NewDecoder(r.Body)
-	// intentionally allow unknown fields to remain forward compatible with UI payloads
-	if err := decoder.Decode(dest); err != nil {
-		return err
-	}
-	if decoder.More() {
-		return errors.New("unexpected data after JSON payload")
-	}
-	return nil
-}
-
 func (h *Handler) listSeasons(w http.ResponseWriter, r *http.Request) {
-	rows, err := h.db.Query(r.Context(), `SELECT id, name, starts_on, ends_on, created_at FROM seasons ORDER BY starts_on DESC`)
+	rows, err := h.db.Query(r.Context(), seasonSelect+` ORDER BY s.starts_on DESC, s.id DESC`, h.clock.Now())
 	if err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to list seasons")
 		return
@@ -411,8 +557,8 @@ func (h *Handler) listSeasons(w http.ResponseWriter, r *http.Request) {
 
 	var seasons []Season
 	for rows.Next() {
-		var s Season
-		if err := rows.Scan(&s.ID, &s.Name, &s.StartsOn, &s.EndsOn, &s.CreatedAt); err != nil {
+		s, err := scanSeason(rows)
+		if err != nil {
 			httpx.Error(w, http.StatusInternalServerError, "failed to parse season")
 			return
 		}
@@ -435,7 +581,7 @@ func (h *Handler) createSeason(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
@@ -485,9 +631,8 @@ func (h *Handler) getSeason(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	row := h.db.QueryRow(r.Context(), `SELECT id, name, starts_on, ends_on, created_at FROM seasons WHERE id = $1`, seasonID)
-	var season Season
-	if err := row.Scan(&season.ID, &season.Name, &season.StartsOn, &season.EndsOn, &season.CreatedAt); err != nil {
+	season, err := scanSeason(h.db.QueryRow(r.Context(), seasonSelect+` WHERE s.id = $2`, h.clock.Now(), seasonID))
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			httpx.Error(w, http.StatusNotFound, "season not found")
 			return
@@ -499,6 +644,152 @@ func (h *Handler) getSeason(w http.ResponseWriter, r *http.Request) {
 	httpx.WriteJSON(w, http.StatusOK, season)
 }
 
+type mergeSeasonPayload struct {
+	SourceID        int64 `json:"source_id"`
+	SkipBoundsCheck bool  `json:"skip_bounds_check"`
+}
+
+// mergeSeason moves every event from the source season into the target
+// season and deletes the source. Unless skip_bounds_check is set, each moved
+// event must fall within the target season's dates.
+func (h *Handler) mergeSeason(w http.ResponseWriter, r *http.Request) {
+	targetID, err := strconv.ParseInt(chi.URLParam(r, "seasonID"), 10, 64)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "invalid season id")
+		return
+	}
+
+	var payload mergeSeasonPayload
+	if err := httpx.DecodeJSON(r, &payload); err != nil {
+		httpx.PayloadError(w, err, "invalid request payload")
+		return
+	}
+	if payload.SourceID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "source_id is required")
+		return
+	}
+	if payload.SourceID == targetID {
+		httpx.Error(w, http.StatusBadRequest, "cannot merge a season into itself")
+		return
+	}
+
+	ctx := r.Context()
+	tx, err := h.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to merge seasons")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var targetStartsOn time.Time
+	var targetEndsOn *time.Time
+	if err := tx.QueryRow(ctx,
+		`SELECT starts_on, ends_on FROM seasons WHERE id = $1 FOR UPDATE`, targetID,
+	).Scan(&targetStartsOn, &targetEndsOn); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			httpx.Error(w, http.StatusNotFound, "season not found")
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to load season")
+		return
+	}
+
+	var sourceExists bool
+	if err := tx.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM seasons WHERE id = $1)`, payload.SourceID,
+	).Scan(&sourceExists); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load season")
+		return
+	}
+	if !sourceExists {
+		httpx.Error(w, http.StatusNotFound, "source season not found")
+		return
+	}
+
+	if !payload.SkipBoundsCheck {
+		var outOfBounds int
+		if err := tx.QueryRow(ctx,
+			`SELECT COUNT(*)
+             FROM events
+             WHERE season_id = $1
+               AND (starts_at::date < $2::date
+                    OR ($3::date IS NOT NULL AND COALESCE(ends_at, starts_at)::date > $3::date))`,
+			payload.SourceID, targetStartsOn, targetEndsOn,
+		).Scan(&outOfBounds); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to validate events")
+			return
+		}
+		if outOfBounds > 0 {
+			httpx.Error(w, http.StatusConflict, fmt.Sprintf("%d event(s) fall outside the target season dates", outOfBounds))
+			return
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE events SET season_id = $1 WHERE season_id = $2`, targetID, payload.SourceID); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to move events")
+		return
+	}
+	// Links to the source move to the target unless the target is already
+	// the event's primary season.
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO event_seasons (event_id, season_id)
+		SELECT es.event_id, $1
+		FROM event_seasons es
+		JOIN events e ON e.id = es.event_id
+		WHERE es.season_id = $2 AND e.season_id <> $1
+		ON CONFLICT DO NOTHING`, targetID, payload.SourceID); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to move events")
+		return
+	}
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM event_seasons es
+		USING events e
+		WHERE e.id = es.event_id AND es.season_id = $1 AND es.season_id = e.season_id`, targetID); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to move events")
+		return
+	}
+	// Archived events keep their season in the row and in their snapshots,
+	// which restore reads, so both move the same way.
+	if _, err := tx.Exec(ctx, `
+		UPDATE events_archive
+		SET season_id = $1, event = jsonb_set(event, '{season_id}', to_jsonb($1::int))
+		WHERE season_id = $2`, targetID, payload.SourceID); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to move archived events")
+		return
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE events_archive a
+		SET relations = jsonb_set(a.relations, '{event_seasons}', COALESCE((
+			SELECT jsonb_agg(CASE WHEN (link->>'season_id')::int = $2
+			                      THEN jsonb_set(link, '{season_id}', to_jsonb($1::int))
+			                      ELSE link END)
+			FROM jsonb_array_elements(a.relations->'event_seasons') link
+			WHERE NOT ((link->>'season_id')::int = $2
+			           AND a.relations->'event_seasons' @> jsonb_build_array(jsonb_build_object('season_id', $1::int)))
+			  AND (CASE WHEN (link->>'season_id')::int = $2 THEN $1 ELSE (link->>'season_id')::int END) <> a.season_id
+		), '[]'::jsonb))
+		WHERE a.relations->'event_seasons' @> jsonb_build_array(jsonb_build_object('season_id', $2::int))
+		   OR (a.season_id = $1 AND a.relations->'event_seasons' @> jsonb_build_array(jsonb_build_object('season_id', $1::int)))`,
+		targetID, payload.SourceID); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to move archived events")
+		return
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM seasons WHERE id = $1`, payload.SourceID); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to delete source season")
+		return
+	}
+
+	season, err := scanSeason(tx.QueryRow(ctx, seasonSelect+` WHERE s.id = $2`, h.clock.Now(), targetID))
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load season")
+		return
+	}
+
+	if err := httpx.FinishTx(ctx, w, r, tx, http.StatusOK, season); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to merge seasons")
+	}
+}
+
 func (h *Handler) deleteSeason(w http.ResponseWriter, r *http.Request) {
 	seasonID, err := strconv.ParseInt(chi.URLParam(r, "seasonID"), 10, 64)
 	if err != nil {
@@ -506,41 +797,169 @@ func (h *Handler) deleteSeason(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	commandTag, err := h.db.Exec(r.Context(), `DELETE FROM seasons WHERE id = $1`, seasonID)
+	// Events cascade with their season, so a season that still has events
+	// must be emptied or merged first, and archived events must still find
+	// their season when restored. Linked seasons only lose the link. The
+	// check and the delete are one statement so no event can slip in between.
+	ctx := r.Context()
+	commandTag, err := h.db.Exec(ctx, `
+		DELETE FROM seasons s
+		WHERE s.id = $1
+		  AND NOT EXISTS (SELECT 1 FROM events WHERE season_id = $1)
+		  AND NOT EXISTS (SELECT 1 FROM events_archive WHERE season_id = $1)`, seasonID)
 	if err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to delete season")
 		return
 	}
-	if commandTag.RowsAffected() == 0 {
-		httpx.Error(w, http.StatusNotFound, "season not found")
+	if commandTag.RowsAffected() > 0 {
+		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	var exists bool
+	var eventCount, archivedCount int
+	if err := h.db.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM seasons WHERE id = $1),
+		       (SELECT COUNT(*) FROM events WHERE season_id = $1),
+		       (SELECT COUNT(*) FROM events_archive WHERE season_id = $1)`, seasonID,
+	).Scan(&exists, &eventCount, &archivedCount); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to delete season")
+		return
+	}
+	switch {
+	case !exists:
+		httpx.Error(w, http.StatusNotFound, "season not found")
+	case eventCount > 0:
+		httpx.Error(w, http.StatusConflict, fmt.Sprintf("season has %d events; move or delete them, or merge the season, before deleting it", eventCount))
+	case archivedCount > 0:
+		httpx.Error(w, http.StatusConflict, fmt.Sprintf("season has %d archived events; restore and move them, or merge the season, before deleting it", archivedCount))
+	default:
+		httpx.Error(w, http.StatusConflict, "season changed while it was being deleted; try again")
+	}
+}
+
+// Page sizes of the event list: ?limit= defaults to defaultEventPageSize and
+// is capped at maxEventPageSize.
+const (
+	defaultEventPageSize = 50
+	maxEventPageSize     = 200
+)
+
+// eventPage is the default JSON body of the event list. It predates the
+// shared {data, page} envelope, which ?envelope=true returns instead, and is
+// kept so existing clients need not change.
+type eventPage struct {
+	Items  any `json:"items"`
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
 }
 
 func (h *Handler) listEvents(w http.ResponseWriter, r *http.Request) {
+	tagFilter, err := parseEventTagFilter(r.URL.Query()["tags"])
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	fields, err := parseEventFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	include, err := parseEventIncludes(r.URL.Query().Get("include"), h.listIncludes)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := httpx.ParsePage(r, maxEventPageSize)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// CSV exports stay whole unless a limit is asked for.
+	if page.Limit == 0 && !httpx.AcceptsCSV(r) {
+		page.Limit = defaultEventPageSize
+	}
+	source := eventsOnlySource
+	if includeArchived, _ := strconv.ParseBool(r.URL.Query().Get("include_archived")); includeArchived {
+		source = eventsWithArchiveSource
+	}
+	// The search clause is only added when q is set so the planner can use
+	// the trigram indexes on name and location.
+	args := []any{tagFilter}
+	searchClause := ""
+	if pattern, ok := textsearch.ContainsPattern(r.URL.Query().Get("q")); ok {
+		args = append(args, pattern)
+		searchClause = ` AND (name ILIKE $2 OR location ILIKE $2)`
+	}
+	// season_id matches the primary season or any linked one.
+	if raw := strings.TrimSpace(r.URL.Query().Get("season_id")); raw != "" {
+		seasonID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || seasonID <= 0 {
+			httpx.Error(w, http.StatusBadRequest, "season_id must be a positive integer")
+			return
+		}
+		args = append(args, seasonID)
+		n := strconv.Itoa(len(args))
+		searchClause += ` AND (season_id = $` + n + ` OR EXISTS (SELECT 1 FROM event_seasons es WHERE es.event_id = events.id AND es.season_id = $` + n + `))`
+	}
+	statuses, err := parseEventStatusFilter(r.URL.Query()["status"])
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(statuses) > 0 {
+		args = append(args, statuses)
+		searchClause += ` AND status = ANY($` + strconv.Itoa(len(args)) + `::text[])`
+	}
+	// participant_id and crew_participant_id find the events a person is on
+	// the roster of or crews a load for.
+	for _, filter := range []struct{ param, clause string }{
+		{"participant_id", `EXISTS (SELECT 1 FROM event_participants ep WHERE ep.event_id = events.id AND ep.participant_id = $%s)`},
+		{"crew_participant_id", `EXISTS (SELECT 1 FROM crew_assignments ca JOIN manifests m ON m.id = ca.manifest_id WHERE m.event_id = events.id AND ca.participant_id = $%s)`},
+	} {
+		raw := strings.TrimSpace(r.URL.Query().Get(filter.param))
+		if raw == "" {
+			continue
+		}
+		participantID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || participantID <= 0 {
+			httpx.Error(w, http.StatusBadRequest, filter.param+" must be a positive integer")
+			return
+		}
+		args = append(args, participantID)
+		searchClause += ` AND ` + fmt.Sprintf(filter.clause, strconv.Itoa(len(args)))
+	}
+	where := `
+		FROM ` + source + `
+		WHERE ($1::text[] IS NULL OR tags && $1::text[])` + searchClause
+	filterArgs := args
+	pageClause, pageArgs := page.SQL(len(args) + 1)
+	args = append(args, pageArgs...)
+
 	rows, err := h.db.Query(r.Context(), `
-		SELECT id, season_id, name, location, status, starts_at, ends_at, slots,
+		SELECT id, season_id, name, location, base_airfield_id,
+		       COALESCE((SELECT a.name FROM airfields a WHERE a.id = events.base_airfield_id), ''),
+		       status, all_day, tags, starts_at, ends_at, slots,
 		       COALESCE(public_registration_slug, ''), COALESCE(public_registration_enabled, FALSE), registration_open_at,
 		       main_invoice_deadline, deposit_amount, main_invoice_amount, COALESCE(currency, 'EUR'),
-		       COALESCE(minimum_deposit_count, 0), COALESCE(commercial_status, 'draft'), created_at
-		FROM events
-		ORDER BY starts_at DESC`)
+		       COALESCE(minimum_deposit_count, 0), COALESCE(commercial_status, 'draft'),
+		       COALESCE(briefing, ''), COALESCE(notes, ''), archived, created_at, `+httpx.TotalCountColumn+where+`
+		ORDER BY starts_at DESC, id DESC`+pageClause, args...)
 	if err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to list events")
 		return
 	}
 	defer rows.Close()
 
-	var events []Event
+	events := make([]Event, 0)
 	for rows.Next() {
 		var e Event
 		if err := rows.Scan(
-			&e.ID, &e.SeasonID, &e.Name, &e.Location, &e.Status, &e.StartsAt, &e.EndsAt, &e.Slots,
+			&e.ID, &e.SeasonID, &e.Name, &e.Location, &e.BaseAirfieldID, &e.BaseAirfieldName, &e.Status, &e.AllDay, &e.Tags, &e.StartsAt, &e.EndsAt, &e.Slots,
 			&e.PublicRegistrationSlug, &e.PublicRegistrationEnabled, &e.RegistrationOpenAt,
 			&e.MainInvoiceDeadline, &e.DepositAmount, &e.MainInvoiceAmount, &e.Currency,
-			&e.MinimumDepositCount, &e.CommercialStatus, &e.CreatedAt,
+			&e.MinimumDepositCount, &e.CommercialStatus, &e.Briefing, &e.Notes, &e.Archived, &e.CreatedAt, &page.Total,
 		); err != nil {
 			httpx.Error(w, http.StatusInternalServerError, "failed to parse event")
 			return
@@ -552,25 +971,93 @@ func (h *Handler) listEvents(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, http.StatusInternalServerError, "failed to list events")
 		return
 	}
+	if page.NeedsCount(len(events)) {
+		if err := h.db.QueryRow(r.Context(), `SELECT COUNT(*)`+where, filterArgs...).Scan(&page.Total); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to count events")
+			return
+		}
+	}
 
 	if err := h.syncEventStatuses(r.Context(), events); err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to sync event statuses")
 		return
 	}
 
-	events, err = h.attachEventRelations(r.Context(), events)
+	events, err = h.attachEventRelations(r.Context(), events, fields.relations(include))
 	if err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to load event relations")
 		return
 	}
 
-	httpx.WriteJSON(w, http.StatusOK, events)
+	if !h.canViewEventNotes(r) {
+		for i := range events {
+			events[i].Notes = ""
+		}
+	}
+
+	w.Header().Add("Vary", "Accept")
+	if httpx.AcceptsCSV(r) {
+		var keep func(string) bool
+		if fields != nil {
+			keep = func(column string) bool { return fields[column] }
+		}
+		if err := httpx.WriteCSV(w, http.StatusOK, events, keep); err != nil {
+			log.Printf("events.listEvents csv failed: %v", err)
+		}
+		return
+	}
+
+	body, err := fields.projectAll(events)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to encode events")
+		return
+	}
+	if httpx.WantsEnvelope(r) {
+		httpx.WritePage(w, r, http.StatusOK, body, page)
+		return
+	}
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	httpx.WriteJSON(w, http.StatusOK, eventPage{Items: body, Total: page.Total, Limit: page.Limit, Offset: page.Offset})
+}
+
+type eventTagCount struct {
+	Tag        string `json:"tag"`
+	EventCount int    `json:"event_count"`
+}
+
+func (h *Handler) listEventTags(w http.ResponseWriter, r *http.Request) {
+	rows, err := h.db.Query(r.Context(), `
+		SELECT tag, COUNT(*)
+		FROM events, unnest(tags) AS tag
+		GROUP BY tag
+		ORDER BY COUNT(*) DESC, tag ASC`)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to list event tags")
+		return
+	}
+	defer rows.Close()
+
+	tags := []eventTagCount{}
+	for rows.Next() {
+		var t eventTagCount
+		if err := rows.Scan(&t.Tag, &t.EventCount); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to parse event tag")
+			return
+		}
+		tags = append(tags, t)
+	}
+	if err := rows.Err(); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to list event tags")
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, tags)
 }
 
 func (h *Handler) createEvent(w http.ResponseWriter, r *http.Request) {
 	var payload eventPayload
-	if err := decodeEventJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+	if err := httpx.DecodeJSONLenient(r, &payload); err != nil {
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
@@ -591,7 +1078,7 @@ func (h *Handler) createEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	startsAt, endsAt, err := parseEventTimes(payload.StartsAt, payload.EndsAt)
+	startsAt, endsAt, err := parseEventTimes(payload.StartsAt, payload.EndsAt, payload.AllDay)
 	if err != nil {
 		httpx.Error(w, http.StatusBadRequest, err.Error())
 		return
@@ -617,6 +1104,13 @@ func (h *Handler) createEvent(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	briefing := trimmedText(payload.Briefing)
+	notes := trimmedText(payload.Notes)
+	clientID, err := httpx.ParseClientID(payload.ClientID)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	replaceAirfields := payload.AirfieldIDs != nil
 	var airfieldIDs []int64
@@ -628,6 +1122,16 @@ func (h *Handler) createEvent(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	replaceSeasons := payload.SeasonIDs != nil
+	var seasonIDs []int64
+	if replaceSeasons {
+		seasonIDs, err = normalizeLinkedSeasonIDs(payload.SeasonIDs, payload.SeasonID)
+		if err != nil {
+			httpx.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
 	replaceParticipants := payload.ParticipantIDs != nil
 	var participantIDs []int64
 	if replaceParticipants {
@@ -665,6 +1169,22 @@ func (h *Handler) createEvent(w http.ResponseWriter, r *http.Request) {
 	}
 	defer tx.Rollback(ctx)
 
+	tags, err := normalizeEventTags(payload.Tags)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	baseAirfieldID, baseAirfieldName, err := resolveBaseAirfield(ctx, tx, payload.BaseAirfieldID)
+	if err != nil {
+		if errors.Is(err, errUnknownBaseAirfield) {
+			httpx.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to load base airfield")
+		return
+	}
+
 	slots := payload.Slots
 	if slots < 0 {
 		httpx.Error(w, http.StatusBadRequest, "slots cannot be negative")
@@ -687,24 +1207,31 @@ func (h *Handler) createEvent(w http.ResponseWriter, r *http.Request) {
 			season_id, name, location, status, starts_at, ends_at, slots,
 			public_registration_slug, public_registration_enabled, registration_open_at,
 			main_invoice_deadline, deposit_amount, main_invoice_amount, currency,
-			minimum_deposit_count, commercial_status
+			minimum_deposit_count, commercial_status, all_day, base_airfield_id, tags,
+			briefing, notes, client_id
 		) VALUES (
 			$1, $2, $3, $4, $5, $6, $7,
 			$8, $9, $10,
 			$11, $12, $13, $14,
-			$15, $16
+			$15, $16, $17, $18, $19,
+			NULLIF($20, ''), NULLIF($21, ''), $22
 		) RETURNING id, created_at`,
 		payload.SeasonID, name, strings.TrimSpace(payload.Location), status, startsAt, endsAt, slots,
 		publicRegistrationSlug, payload.PublicRegistrationEnabled, registrationOpenAt,
 		mainInvoiceDeadline, depositAmount, mainInvoiceAmount, currency,
-		minimumDepositCount, commercialStatus,
+		minimumDepositCount, commercialStatus, payload.AllDay, baseAirfieldID, tags,
+		briefing, notes, clientID,
 	)
 
 	var event Event
 	event.SeasonID = payload.SeasonID
 	event.Name = name
 	event.Location = strings.TrimSpace(payload.Location)
+	event.BaseAirfieldID = baseAirfieldID
+	event.BaseAirfieldName = baseAirfieldName
 	event.Status = status
+	event.AllDay = payload.AllDay
+	event.Tags = tags
 	event.StartsAt = startsAt
 	event.EndsAt = endsAt
 	event.Slots = slots
@@ -717,10 +1244,16 @@ func (h *Handler) createEvent(w http.ResponseWriter, r *http.Request) {
 	event.Currency = currency
 	event.MinimumDepositCount = minimumDepositCount
 	event.CommercialStatus = commercialStatus
+	event.Briefing = briefing
+	event.Notes = notes
 
 	if err := row.Scan(&event.ID, &event.CreatedAt); err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			if pgErr.ConstraintName == "events_client_id_key" {
+				h.writeClientIDConflict(w, r, "events", *clientID)
+				return
+			}
 			httpx.Error(w, http.StatusConflict, "public registration slug already exists")
 			return
 		}
@@ -729,6 +1262,11 @@ func (h *Handler) createEvent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := replaceEventParticipantsTx(ctx, tx, event.ID, participantIDs); err != nil {
+		var unknown *unknownReferencesError
+		if errors.As(err, &unknown) {
+			httpx.Error(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "failed to save participants")
 		return
 	}
@@ -738,9 +1276,23 @@ func (h *Handler) createEvent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := replaceEventAirfieldsTx(ctx, tx, event.ID, airfieldIDs); err != nil {
+		var unknown *unknownReferencesError
+		if errors.As(err, &unknown) {
+			httpx.Error(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "failed to save airfields")
 		return
 	}
+	if err := replaceEventSeasonsTx(ctx, tx, event.ID, seasonIDs); err != nil {
+		var unknown *unknownReferencesError
+		if errors.As(err, &unknown) {
+			httpx.Error(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to save seasons")
+		return
+	}
 
 	attachedAircraftIDs := map[int64]struct{}{}
 	if replaceAircraft {
@@ -764,10 +1316,27 @@ func (h *Handler) createEvent(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	if err := replaceEventInnhoppsTx(ctx, tx, event.ID, innhopps); err != nil {
+	if err := saveEventInnhoppsTx(ctx, tx, event.ID, innhopps); err != nil {
+		var stale *innhoppVersionError
+		if errors.As(err, &stale) {
+			httpx.Error(w, http.StatusConflict, err.Error())
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "failed to save innhopps: "+err.Error())
 		return
 	}
+	blockers, err := h.checkStatusTransition(ctx, tx, event.ID, status)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to check status change")
+		return
+	}
+	if len(blockers.Innhopps) > 0 {
+		writeInnhoppRequirementsError(w, status, blockers.Innhopps)
+		return
+	}
+	if !allowCrewShortfall(w, r, event.ID, blockers.Crew, payload.OverrideMinCrew) {
+		return
+	}
 
 	if err := tx.Commit(ctx); err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to create event")
@@ -779,6 +1348,9 @@ func (h *Handler) createEvent(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, http.StatusInternalServerError, "failed to load event")
 		return
 	}
+	if clientID != nil {
+		created.ClientID = *clientID
+	}
 
 	httpx.WriteJSON(w, http.StatusCreated, created)
 }
@@ -789,8 +1361,19 @@ func (h *Handler) getEvent(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, http.StatusBadRequest, "invalid event id")
 		return
 	}
+	fields, err := parseEventFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	event, err := h.fetchEvent(r.Context(), eventID)
+	include, err := parseEventIncludes(r.URL.Query().Get("include"), allEventIncludes())
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	event, err := h.loadEvent(r.Context(), eventID, fields.relations(include))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			httpx.Error(w, http.StatusNotFound, "event not found")
@@ -800,7 +1383,16 @@ func (h *Handler) getEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httpx.WriteJSON(w, http.StatusOK, event)
+	if !h.canViewEventNotes(r) {
+		event.Notes = ""
+	}
+
+	body, err := fields.project(event)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to encode event")
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, body)
 }
 
 func (h *Handler) updateEvent(w http.ResponseWriter, r *http.Request) {
@@ -811,8 +1403,8 @@ func (h *Handler) updateEvent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var payload eventPayload
-	if err := decodeEventJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+	if err := httpx.DecodeJSONLenient(r, &payload); err != nil {
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
@@ -833,7 +1425,7 @@ func (h *Handler) updateEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	startsAt, endsAt, err := parseEventTimes(payload.StartsAt, payload.EndsAt)
+	startsAt, endsAt, err := parseEventTimes(payload.StartsAt, payload.EndsAt, payload.AllDay)
 	if err != nil {
 		httpx.Error(w, http.StatusBadRequest, err.Error())
 		return
@@ -859,6 +1451,8 @@ func (h *Handler) updateEvent(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	briefing := trimmedText(payload.Briefing)
+	notes := trimmedText(payload.Notes)
 
 	replaceAirfields := payload.AirfieldIDs != nil
 	var airfieldIDs []int64
@@ -870,6 +1464,16 @@ func (h *Handler) updateEvent(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	replaceSeasons := payload.SeasonIDs != nil
+	var seasonIDs []int64
+	if replaceSeasons {
+		seasonIDs, err = normalizeLinkedSeasonIDs(payload.SeasonIDs, payload.SeasonID)
+		if err != nil {
+			httpx.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
 	replaceParticipants := payload.ParticipantIDs != nil
 	var participantIDs []int64
 	if replaceParticipants {
@@ -924,6 +1528,32 @@ func (h *Handler) updateEvent(w http.ResponseWriter, r *http.Request) {
 	}
 	defer tx.Rollback(ctx)
 
+	previousStatus, found, err := currentEventStatus(ctx, tx, eventID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to update event")
+		return
+	}
+	if !found {
+		httpx.Error(w, http.StatusNotFound, "event not found")
+		return
+	}
+
+	tags, err := normalizeEventTags(payload.Tags)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	baseAirfieldID, _, err := resolveBaseAirfield(ctx, tx, payload.BaseAirfieldID)
+	if err != nil {
+		if errors.Is(err, errUnknownBaseAirfield) {
+			httpx.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to load base airfield")
+		return
+	}
+
 	tag, err := tx.Exec(ctx,
 		`UPDATE events
 		SET season_id = $1,
@@ -941,12 +1571,19 @@ func (h *Handler) updateEvent(w http.ResponseWriter, r *http.Request) {
 			main_invoice_amount = $13,
 			currency = $14,
 			minimum_deposit_count = $15,
-			commercial_status = $16
-		WHERE id = $17`,
+			commercial_status = $16,
+			all_day = $17,
+			base_airfield_id = CASE WHEN $18::boolean THEN $19::integer ELSE base_airfield_id END,
+			tags = CASE WHEN $20::boolean THEN $21::text[] ELSE tags END,
+			briefing = CASE WHEN $23::boolean THEN NULLIF($24, '') ELSE briefing END,
+			notes = CASE WHEN $25::boolean THEN NULLIF($26, '') ELSE notes END
+		WHERE id = $22`,
 		payload.SeasonID, name, strings.TrimSpace(payload.Location), status, startsAt, endsAt, slots,
 		publicRegistrationSlug, payload.PublicRegistrationEnabled, registrationOpenAt,
 		mainInvoiceDeadline, depositAmount, mainInvoiceAmount, currency,
-		minimumDepositCount, commercialStatus, eventID,
+		minimumDepositCount, commercialStatus, payload.AllDay, payload.BaseAirfieldID != nil, baseAirfieldID,
+		payload.Tags != nil, tags, eventID,
+		payload.Briefing != nil, briefing, payload.Notes != nil, notes,
 	)
 	if err != nil {
 		var pgErr *pgconn.PgError
@@ -964,6 +1601,11 @@ func (h *Handler) updateEvent(w http.ResponseWriter, r *http.Request) {
 
 	if replaceParticipants {
 		if err := replaceEventParticipantsTx(ctx, tx, eventID, participantIDs); err != nil {
+			var unknown *unknownReferencesError
+			if errors.As(err, &unknown) {
+				httpx.Error(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			}
 			httpx.Error(w, http.StatusInternalServerError, "failed to save participants")
 			return
 		}
@@ -975,10 +1617,32 @@ func (h *Handler) updateEvent(w http.ResponseWriter, r *http.Request) {
 
 	if replaceAirfields {
 		if err := replaceEventAirfieldsTx(ctx, tx, eventID, airfieldIDs); err != nil {
+			var unknown *unknownReferencesError
+			if errors.As(err, &unknown) {
+				httpx.Error(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			}
 			httpx.Error(w, http.StatusInternalServerError, "failed to save airfields")
 			return
 		}
 	}
+	if replaceSeasons {
+		if err := replaceEventSeasonsTx(ctx, tx, eventID, seasonIDs); err != nil {
+			var unknown *unknownReferencesError
+			if errors.As(err, &unknown) {
+				httpx.Error(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			}
+			httpx.Error(w, http.StatusInternalServerError, "failed to save seasons")
+			return
+		}
+	} else {
+		// A kept link to the new primary season would count the event twice.
+		if _, err := tx.Exec(ctx, `DELETE FROM event_seasons WHERE event_id = $1 AND season_id = $2`, eventID, payload.SeasonID); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to save seasons")
+			return
+		}
+	}
 
 	attachedAircraftIDs := map[int64]struct{}{}
 	if replaceAircraft {
@@ -1015,12 +1679,34 @@ func (h *Handler) updateEvent(w http.ResponseWriter, r *http.Request) {
 			httpx.Error(w, http.StatusBadRequest, err.Error())
 			return
 		}
-		if err := replaceEventInnhoppsTx(ctx, tx, eventID, innhopps); err != nil {
+		if err := saveEventInnhoppsTx(ctx, tx, eventID, innhopps); err != nil {
+			var stale *innhoppVersionError
+			if errors.As(err, &stale) {
+				httpx.Error(w, http.StatusConflict, err.Error())
+				return
+			}
 			httpx.Error(w, http.StatusInternalServerError, "failed to save innhopps: "+err.Error())
 			return
 		}
 	}
 
+	// Requirements gate status changes only, so an event that went live
+	// under an older policy can still be edited.
+	if status != previousStatus {
+		blockers, err := h.checkStatusTransition(ctx, tx, eventID, status)
+		if err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to check status change")
+			return
+		}
+		if len(blockers.Innhopps) > 0 {
+			writeInnhoppRequirementsError(w, status, blockers.Innhopps)
+			return
+		}
+		if !allowCrewShortfall(w, r, eventID, blockers.Crew, payload.OverrideMinCrew) {
+			return
+		}
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to update event")
 		return
@@ -1143,21 +1829,28 @@ func (h *Handler) copyEvent(w http.ResponseWriter, r *http.Request) {
 			season_id, name, location, status, starts_at, ends_at, slots,
 			public_registration_slug, public_registration_enabled, registration_open_at,
 			main_invoice_deadline, deposit_amount, main_invoice_amount, currency,
-			minimum_deposit_count, commercial_status
+			minimum_deposit_count, commercial_status, all_day, base_airfield_id, tags,
+			briefing, notes
 		)
-         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, COALESCE($19::text[], '{}'),
+                 NULLIF($20, ''), NULLIF($21, ''))
          RETURNING id, created_at`,
 		original.SeasonID, newName, strings.TrimSpace(original.Location), original.Status, original.StartsAt, original.EndsAt, original.Slots,
 		"", false, original.RegistrationOpenAt,
 		original.MainInvoiceDeadline, original.DepositAmount, original.MainInvoiceAmount, original.Currency,
-		original.MinimumDepositCount, "draft",
+		original.MinimumDepositCount, "draft", original.AllDay, original.BaseAirfieldID, original.Tags,
+		original.Briefing, original.Notes,
 	)
 
 	var created Event
 	created.SeasonID = original.SeasonID
 	created.Name = newName
 	created.Location = strings.TrimSpace(original.Location)
+	created.BaseAirfieldID = original.BaseAirfieldID
+	created.BaseAirfieldName = original.BaseAirfieldName
 	created.Status = original.Status
+	created.AllDay = original.AllDay
+	created.Tags = original.Tags
 	created.StartsAt = original.StartsAt
 	created.EndsAt = original.EndsAt
 	created.Slots = original.Slots
@@ -1170,6 +1863,8 @@ func (h *Handler) copyEvent(w http.ResponseWriter, r *http.Request) {
 	created.Currency = original.Currency
 	created.MinimumDepositCount = original.MinimumDepositCount
 	created.CommercialStatus = "draft"
+	created.Briefing = original.Briefing
+	created.Notes = original.Notes
 
 	if err := row.Scan(&created.ID, &created.CreatedAt); err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to copy event")
@@ -1189,6 +1884,10 @@ func (h *Handler) copyEvent(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, http.StatusInternalServerError, "failed to copy airfields")
 		return
 	}
+	if err := replaceEventSeasonsTx(ctx, tx, created.ID, original.SeasonIDs); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to copy seasons")
+		return
+	}
 	aircraftInputs := make([]aircraftInput, 0, len(original.Aircraft))
 	for _, item := range original.Aircraft {
 		aircraftInputs = append(aircraftInputs, aircraftInput{
@@ -1201,7 +1900,7 @@ func (h *Handler) copyEvent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := replaceEventInnhoppsTx(ctx, tx, created.ID, innhoppsInput); err != nil {
+	if err := saveEventInnhoppsTx(ctx, tx, created.ID, innhoppsInput); err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to copy innhopps")
 		return
 	}
@@ -1355,11 +2054,21 @@ func (h *Handler) listAccommodations(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	exists, err := eventExists(r.Context(), h.db, eventID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to validate event")
+		return
+	}
+	if !exists {
+		httpx.Error(w, http.StatusNotFound, "event not found")
+		return
+	}
+
 	rows, err := h.db.Query(r.Context(),
 		`SELECT id, event_id, name, capacity, booked, coordinates, check_in_at, check_out_at, notes, created_at
          FROM event_accommodation
          WHERE event_id = $1
-         ORDER BY created_at DESC`,
+         ORDER BY created_at DESC, id DESC`,
 		eventID,
 	)
 	if err != nil {
@@ -1368,7 +2077,7 @@ func (h *Handler) listAccommodations(w http.ResponseWriter, r *http.Request) {
 	}
 	defer rows.Close()
 
-	var accs []Accommodation
+	accs := make([]Accommodation, 0)
 	for rows.Next() {
 		var a Accommodation
 		var coords sql.NullString
@@ -1395,7 +2104,7 @@ func (h *Handler) listAllAccommodations(w http.ResponseWriter, r *http.Request)
 	rows, err := h.db.Query(r.Context(),
 		`SELECT id, event_id, name, capacity, booked, coordinates, check_in_at, check_out_at, notes, created_at
          FROM event_accommodation
-         ORDER BY created_at DESC`,
+         ORDER BY created_at DESC, id DESC`,
 	)
 	if err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to list accommodations")
@@ -1444,7 +2153,7 @@ func (h *Handler) createAccommodation(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
@@ -1458,7 +2167,7 @@ func (h *Handler) createAccommodation(w http.ResponseWriter, r *http.Request) {
 	if payload.CheckInAt != "" {
 		t, err := timeutil.ParseEventTimestamp(payload.CheckInAt)
 		if err != nil {
-			httpx.Error(w, http.StatusBadRequest, "check_in_at must be RFC3339 timestamp")
+			httpx.Error(w, http.StatusBadRequest, "check_in_at must be RFC3339 or YYYY-MM-DDTHH:MM")
 			return
 		}
 		checkIn = &t
@@ -1468,7 +2177,7 @@ func (h *Handler) createAccommodation(w http.ResponseWriter, r *http.Request) {
 	if payload.CheckOutAt != "" {
 		t, err := timeutil.ParseEventTimestamp(payload.CheckOutAt)
 		if err != nil {
-			httpx.Error(w, http.StatusBadRequest, "check_out_at must be RFC3339 timestamp")
+			httpx.Error(w, http.StatusBadRequest, "check_out_at must be RFC3339 or YYYY-MM-DDTHH:MM")
 			return
 		}
 		checkOut = &t
@@ -1581,7 +2290,7 @@ func (h *Handler) updateAccommodation(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
@@ -1595,7 +2304,7 @@ func (h *Handler) updateAccommodation(w http.ResponseWriter, r *http.Request) {
 	if payload.CheckInAt != "" {
 		t, err := timeutil.ParseEventTimestamp(payload.CheckInAt)
 		if err != nil {
-			httpx.Error(w, http.StatusBadRequest, "check_in_at must be RFC3339 timestamp")
+			httpx.Error(w, http.StatusBadRequest, "check_in_at must be RFC3339 or YYYY-MM-DDTHH:MM")
 			return
 		}
 		checkIn = &t
@@ -1605,7 +2314,7 @@ func (h *Handler) updateAccommodation(w http.ResponseWriter, r *http.Request) {
 	if payload.CheckOutAt != "" {
 		t, err := timeutil.ParseEventTimestamp(payload.CheckOutAt)
 		if err != nil {
-			httpx.Error(w, http.StatusBadRequest, "check_out_at must be RFC3339 timestamp")
+			httpx.Error(w, http.StatusBadRequest, "check_out_at must be RFC3339 or YYYY-MM-DDTHH:MM")
 			return
 		}
 		checkOut = &t
@@ -1653,7 +2362,7 @@ func (h *Handler) updateAccommodation(w http.ResponseWriter, r *http.Request) {
 		val := coordsOut.String
 		acc.Coordinates = &val
 	}
-	if err := logistics.RecalculateRouteDurationsForLocationReference(r.Context(), h.db, "Accommodation", acc.ID); err != nil {
+	if err := h.recalculateRouteDurations(r.Context(), "Accommodation", acc.ID); err != nil {
 		log.Printf("route duration recalculation failed (type=Accommodation id=%d): %v", acc.ID, err)
 	}
 
@@ -1685,19 +2394,56 @@ func (h *Handler) deleteAccommodation(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// maxManifestPageSize caps ?limit= on the manifest list.
+const maxManifestPageSize = 500
+
 func (h *Handler) listManifests(w http.ResponseWriter, r *http.Request) {
-	rows, err := h.db.Query(r.Context(), `SELECT id, event_id, load_number, capacity, staff_slots, notes, created_at FROM manifests ORDER BY load_number ASC`)
+	page, err := httpx.ParsePage(r, maxManifestPageSize)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	direction := "ASC"
+	switch order := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("order"))); order {
+	case "", "asc":
+	case "desc":
+		direction = "DESC"
+	default:
+		httpx.Error(w, http.StatusBadRequest, "order must be asc or desc")
+		return
+	}
+	var args []any
+	where := ""
+	if raw := strings.TrimSpace(r.URL.Query().Get("event_id")); raw != "" {
+		eventID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || eventID <= 0 {
+			httpx.Error(w, http.StatusBadRequest, "event_id must be a positive integer")
+			return
+		}
+		args = append(args, eventID)
+		where = ` WHERE event_id = $1`
+	}
+	filterArgs := args
+	pageClause, pageArgs := page.SQL(len(args) + 1)
+	args = append(args, pageArgs...)
+
+	// id breaks ties between loads with the same number on different events
+	// so pages never overlap.
+	rows, err := h.db.Query(r.Context(), `
+		SELECT id, event_id, load_number, capacity, staff_slots, notes, created_at, `+httpx.TotalCountColumn+`
+		FROM manifests`+where+`
+		ORDER BY load_number `+direction+`, id `+direction+pageClause, args...)
 	if err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to list manifests")
 		return
 	}
 	defer rows.Close()
 
-	var manifests []Manifest
+	manifests := make([]Manifest, 0)
 	for rows.Next() {
 		var m Manifest
 		var staff sql.NullInt32
-		if err := rows.Scan(&m.ID, &m.EventID, &m.LoadNumber, &m.Capacity, &staff, &m.Notes, &m.CreatedAt); err != nil {
+		if err := rows.Scan(&m.ID, &m.EventID, &m.LoadNumber, &m.Capacity, &staff, &m.Notes, &m.CreatedAt, &page.Total); err != nil {
 			httpx.Error(w, http.StatusInternalServerError, "failed to parse manifest")
 			return
 		}
@@ -1712,6 +2458,13 @@ func (h *Handler) listManifests(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, http.StatusInternalServerError, "failed to list manifests")
 		return
 	}
+	rows.Close()
+	if page.NeedsCount(len(manifests)) {
+		if err := h.db.QueryRow(r.Context(), `SELECT COUNT(*) FROM manifests`+where, filterArgs...).Scan(&page.Total); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to count manifests")
+			return
+		}
+	}
 
 	manifests, err = h.attachManifestParticipants(r.Context(), manifests)
 	if err != nil {
@@ -1719,7 +2472,7 @@ func (h *Handler) listManifests(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httpx.WriteJSON(w, http.StatusOK, manifests)
+	httpx.WritePage(w, r, http.StatusOK, manifests, page)
 }
 
 func (h *Handler) createManifest(w http.ResponseWriter, r *http.Request) {
@@ -1730,10 +2483,11 @@ func (h *Handler) createManifest(w http.ResponseWriter, r *http.Request) {
 		StaffSlots     *int    `json:"staff_slots"`
 		Notes          string  `json:"notes"`
 		ParticipantIDs []int64 `json:"participant_ids"`
+		ClientID       *string `json:"client_id"`
 	}
 
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
@@ -1741,6 +2495,10 @@ func (h *Handler) createManifest(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, http.StatusBadRequest, "event_id and load_number are required")
 		return
 	}
+	if payload.LoadNumber < 0 {
+		httpx.Error(w, http.StatusBadRequest, "load_number must be positive")
+		return
+	}
 
 	if payload.Capacity < 0 {
 		httpx.Error(w, http.StatusBadRequest, "capacity cannot be negative")
@@ -1756,6 +2514,11 @@ func (h *Handler) createManifest(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	clientID, err := httpx.ParseClientID(payload.ClientID)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	ctx := r.Context()
 	tx, err := h.db.BeginTx(ctx, pgx.TxOptions{})
@@ -1765,10 +2528,14 @@ func (h *Handler) createManifest(w http.ResponseWriter, r *http.Request) {
 	}
 	defer tx.Rollback(ctx)
 
+	if !checkLoadNumber(ctx, w, tx, payload.EventID, payload.LoadNumber, 0) {
+		return
+	}
+
 	row := tx.QueryRow(ctx,
-		`INSERT INTO manifests (event_id, load_number, capacity, staff_slots, notes) VALUES ($1, $2, $3, $4, $5)
+		`INSERT INTO manifests (event_id, load_number, capacity, staff_slots, notes, client_id) VALUES ($1, $2, $3, $4, $5, $6)
          RETURNING id, created_at`,
-		payload.EventID, payload.LoadNumber, payload.Capacity, payload.StaffSlots, payload.Notes,
+		payload.EventID, payload.LoadNumber, payload.Capacity, payload.StaffSlots, payload.Notes, clientID,
 	)
 
 	var manifest Manifest
@@ -1779,6 +2546,15 @@ func (h *Handler) createManifest(w http.ResponseWriter, r *http.Request) {
 	manifest.Notes = payload.Notes
 
 	if err := row.Scan(&manifest.ID, &manifest.CreatedAt); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.ConstraintName == "manifests_client_id_key" {
+			h.writeClientIDConflict(w, r, "manifests", *clientID)
+			return
+		}
+		if httpx.UniqueViolation(err, manifestLoadNumberIndex) {
+			writeLoadNumberConflict(w, payload.LoadNumber)
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "failed to create manifest")
 		return
 	}
@@ -1798,6 +2574,9 @@ func (h *Handler) createManifest(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, http.StatusInternalServerError, "failed to load manifest")
 		return
 	}
+	if clientID != nil {
+		created.ClientID = *clientID
+	}
 
 	httpx.WriteJSON(w, http.StatusCreated, created)
 }
@@ -1839,7 +2618,7 @@ func (h *Handler) updateManifest(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
@@ -1847,6 +2626,10 @@ func (h *Handler) updateManifest(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, http.StatusBadRequest, "event_id and load_number are required")
 		return
 	}
+	if payload.LoadNumber < 0 {
+		httpx.Error(w, http.StatusBadRequest, "load_number must be positive")
+		return
+	}
 
 	if payload.Capacity < 0 {
 		httpx.Error(w, http.StatusBadRequest, "capacity cannot be negative")
@@ -1871,6 +2654,10 @@ func (h *Handler) updateManifest(w http.ResponseWriter, r *http.Request) {
 	}
 	defer tx.Rollback(ctx)
 
+	if !checkLoadNumber(ctx, w, tx, payload.EventID, payload.LoadNumber, manifestID) {
+		return
+	}
+
 	tag, err := tx.Exec(ctx,
 		`UPDATE manifests
          SET event_id = $1, load_number = $2, capacity = $3, staff_slots = $4, notes = $5
@@ -1878,6 +2665,10 @@ func (h *Handler) updateManifest(w http.ResponseWriter, r *http.Request) {
 		payload.EventID, payload.LoadNumber, payload.Capacity, payload.StaffSlots, payload.Notes, manifestID,
 	)
 	if err != nil {
+		if httpx.UniqueViolation(err, manifestLoadNumberIndex) {
+			writeLoadNumberConflict(w, payload.LoadNumber)
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "failed to update manifest")
 		return
 	}
@@ -1905,20 +2696,49 @@ func (h *Handler) updateManifest(w http.ResponseWriter, r *http.Request) {
 	httpx.WriteJSON(w, http.StatusOK, updated)
 }
 
+// deleteManifest removes a manifest. Its participants and crew assignments
+// go with it through ON DELETE CASCADE rather than blocking the delete, since
+// they describe the load and mean nothing without it.
+func (h *Handler) deleteManifest(w http.ResponseWriter, r *http.Request) {
+	manifestID, err := strconv.ParseInt(chi.URLParam(r, "manifestID"), 10, 64)
+	if err != nil || manifestID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid manifest id")
+		return
+	}
+	tag, err := h.db.Exec(r.Context(), `DELETE FROM manifests WHERE id = $1`, manifestID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to delete manifest")
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		httpx.Error(w, http.StatusNotFound, "manifest not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (h *Handler) fetchEvent(ctx context.Context, eventID int64) (Event, error) {
+	return h.loadEvent(ctx, eventID, allEventIncludes())
+}
+
+// loadEvent reads one event and attaches the included relations.
+func (h *Handler) loadEvent(ctx context.Context, eventID int64, include eventIncludes) (Event, error) {
 	row := h.db.QueryRow(ctx, `
-		SELECT id, season_id, name, location, status, starts_at, ends_at, slots,
+		SELECT id, season_id, name, location, base_airfield_id,
+		       COALESCE((SELECT a.name FROM airfields a WHERE a.id = events.base_airfield_id), ''),
+		       status, all_day, tags, starts_at, ends_at, slots,
 		       COALESCE(public_registration_slug, ''), COALESCE(public_registration_enabled, FALSE), registration_open_at,
 		       main_invoice_deadline, deposit_amount, main_invoice_amount, COALESCE(currency, 'EUR'),
-		       COALESCE(minimum_deposit_count, 0), COALESCE(commercial_status, 'draft'), created_at
+		       COALESCE(minimum_deposit_count, 0), COALESCE(commercial_status, 'draft'),
+		       COALESCE(briefing, ''), COALESCE(notes, ''), created_at
 		FROM events
 		WHERE id = $1`, eventID)
 	var event Event
 	if err := row.Scan(
-		&event.ID, &event.SeasonID, &event.Name, &event.Location, &event.Status, &event.StartsAt, &event.EndsAt, &event.Slots,
+		&event.ID, &event.SeasonID, &event.Name, &event.Location, &event.BaseAirfieldID, &event.BaseAirfieldName, &event.Status, &event.AllDay, &event.Tags, &event.StartsAt, &event.EndsAt, &event.Slots,
 		&event.PublicRegistrationSlug, &event.PublicRegistrationEnabled, &event.RegistrationOpenAt,
 		&event.MainInvoiceDeadline, &event.DepositAmount, &event.MainInvoiceAmount, &event.Currency,
-		&event.MinimumDepositCount, &event.CommercialStatus, &event.CreatedAt,
+		&event.MinimumDepositCount, &event.CommercialStatus, &event.Briefing, &event.Notes, &event.CreatedAt,
 	); err != nil {
 		return Event{}, err
 	}
@@ -1927,8 +2747,7 @@ func (h *Handler) fetchEvent(ctx context.Context, eventID int64) (Event, error)
 	if err := h.syncEventStatuses(ctx, events); err != nil {
 		return Event{}, err
 	}
-
-	events, err := h.attachEventRelations(ctx, events)
+	events, err := h.attachEventRelations(ctx, events, include)
 	if err != nil {
 		return Event{}, err
 	}
@@ -1997,7 +2816,7 @@ func (h *Handler) fetchAccommodationsForEvent(ctx context.Context, eventID int64
 		`SELECT id, event_id, name, capacity, booked, coordinates, check_in_at, check_out_at, notes, created_at
          FROM event_accommodation
          WHERE event_id = $1
-         ORDER BY created_at ASC`,
+         ORDER BY created_at ASC, id ASC`,
 		eventID,
 	)
 	if err != nil {
@@ -2032,7 +2851,7 @@ func (h *Handler) fetchManifestsForEvent(ctx context.Context, eventID int64) ([]
 		`SELECT id, event_id, load_number, capacity, staff_slots, notes, created_at
          FROM manifests
          WHERE event_id = $1
-         ORDER BY load_number ASC`,
+         ORDER BY load_number ASC, id ASC`,
 		eventID,
 	)
 	if err != nil {
@@ -2071,7 +2890,7 @@ func (h *Handler) fetchLogisticsForEvent(ctx context.Context, eventID int64) (ev
 		`SELECT id, name, driver, passenger_capacity, notes
          FROM logistics_event_vehicles
          WHERE event_id = $1
-         ORDER BY created_at ASC`,
+         ORDER BY created_at ASC, id ASC`,
 		eventID,
 	)
 	if err != nil {
@@ -2102,7 +2921,7 @@ func (h *Handler) fetchLogisticsForEvent(ctx context.Context, eventID int64) (ev
 		`SELECT id, pickup_location, pickup_location_type, pickup_location_id, destination, destination_type, destination_id, passenger_count, duration_minutes, scheduled_at, notes
          FROM logistics_transports
          WHERE event_id = $1
-         ORDER BY created_at ASC`,
+         ORDER BY created_at ASC, id ASC`,
 		eventID,
 	)
 	if err != nil {
@@ -2204,7 +3023,7 @@ func (h *Handler) fetchLogisticsForEvent(ctx context.Context, eventID int64) (ev
 		`SELECT name, coordinates, scheduled_at, description, notes
          FROM logistics_other
          WHERE event_id = $1
-         ORDER BY created_at ASC`,
+         ORDER BY created_at ASC, id ASC`,
 		eventID,
 	)
 	if err != nil {
@@ -2247,7 +3066,7 @@ func (h *Handler) fetchLogisticsForEvent(ctx context.Context, eventID int64) (ev
 		`SELECT name, location, location_type, location_id, scheduled_at, notes
          FROM logistics_meals
          WHERE event_id = $1
-         ORDER BY created_at ASC`,
+         ORDER BY created_at ASC, id ASC`,
 		eventID,
 	)
 	if err != nil {
@@ -2294,8 +3113,8 @@ func (h *Handler) fetchLogisticsForEvent(ctx context.Context, eventID int64) (ev
 	return snapshot, nil
 }
 
-func (h *Handler) attachEventRelations(ctx context.Context, events []Event) ([]Event, error) {
-	if len(events) == 0 {
+func (h *Handler) attachEventRelations(ctx context.Context, events []Event, include eventIncludes) ([]Event, error) {
+	if len(events) == 0 || !include.any() {
 		return events, nil
 	}
 
@@ -2304,38 +3123,62 @@ func (h *Handler) attachEventRelations(ctx context.Context, events []Event) ([]E
 		ids[i] = event.ID
 	}
 
-	participantMap, err := h.fetchParticipantsForEvents(ctx, ids)
-	if err != nil {
-		return nil, err
-	}
+	attached := make([]Event, len(events))
+	copy(attached, events)
 
-	innhoppMap, err := h.fetchInnhoppsForEvents(ctx, ids, false)
-	if err != nil {
-		return nil, err
+	if include["participants"] {
+		participantMap, err := h.fetchParticipantsForEvents(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for i := range attached {
+			attached[i].ParticipantIDs = participantMap[attached[i].ID]
+		}
 	}
-	aircraftMap, err := h.fetchAircraftForEvents(ctx, ids)
-	if err != nil {
-		return nil, err
+	if include["innhopps"] {
+		innhoppMap, err := h.fetchInnhoppsForEvents(ctx, ids, false)
+		if err != nil {
+			return nil, err
+		}
+		for i := range attached {
+			attached[i].Innhopps = innhoppMap[attached[i].ID]
+		}
 	}
-
-	airfieldMap, err := h.fetchAirfieldsForEvents(ctx, ids)
-	if err != nil {
-		return nil, err
+	if include["aircraft"] {
+		aircraftMap, err := h.fetchAircraftForEvents(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for i := range attached {
+			attached[i].Aircraft = aircraftMap[attached[i].ID]
+		}
 	}
-
-	remainingSlotsMap, err := h.fetchRemainingSlotsForEvents(ctx, ids)
-	if err != nil {
-		return nil, err
+	if include["airfields"] {
+		airfieldMap, err := h.fetchAirfieldsForEvents(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for i := range attached {
+			attached[i].AirfieldIDs = airfieldMap[attached[i].ID]
+		}
 	}
-
-	attached := make([]Event, len(events))
-	copy(attached, events)
-	for i := range attached {
-		attached[i].ParticipantIDs = participantMap[attached[i].ID]
-		attached[i].Aircraft = aircraftMap[attached[i].ID]
-		attached[i].Innhopps = innhoppMap[attached[i].ID]
-		attached[i].AirfieldIDs = airfieldMap[attached[i].ID]
-		attached[i].RemainingSlots = remainingSlotsMap[attached[i].ID]
+	if include["seasons"] {
+		seasonMap, err := h.fetchSeasonsForEvents(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for i := range attached {
+			attached[i].SeasonIDs = seasonMap[attached[i].ID]
+		}
+	}
+	if include["remaining_slots"] {
+		remainingSlotsMap, err := h.fetchRemainingSlotsForEvents(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for i := range attached {
+			attached[i].RemainingSlots = remainingSlotsMap[attached[i].ID]
+		}
 	}
 	return attached, nil
 }
@@ -2411,6 +3254,34 @@ func (h *Handler) fetchParticipantsForEvents(ctx context.Context, eventIDs []int
 	return result, nil
 }
 
+// eventExists lets sub-resource listings answer 404 for an unknown event
+// instead of an empty list.
+func eventExists(ctx context.Context, db rowQuerier, eventID int64) (bool, error) {
+	var exists bool
+	err := db.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM events WHERE id = $1)`, eventID).Scan(&exists)
+	return exists, err
+}
+
+var errUnknownBaseAirfield = errors.New("base_airfield_id must reference an existing airfield")
+
+// resolveBaseAirfield validates an optional base airfield reference and
+// returns its name. A nil or non-positive id resolves to no airfield; updates
+// leave the stored reference untouched when base_airfield_id is omitted.
+func resolveBaseAirfield(ctx context.Context, db rowQuerier, airfieldID *int64) (*int64, string, error) {
+	if airfieldID == nil || *airfieldID <= 0 {
+		return nil, "", nil
+	}
+	var name string
+	if err := db.QueryRow(ctx, `SELECT name FROM airfields WHERE id = $1`, *airfieldID).Scan(&name); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, "", errUnknownBaseAirfield
+		}
+		return nil, "", err
+	}
+	id := *airfieldID
+	return &id, name, nil
+}
+
 func (h *Handler) fetchAirfieldsForEvents(ctx context.Context, eventIDs []int64) (map[int64][]int64, error) {
 	result := make(map[int64][]int64, len(eventIDs))
 	rows, err := h.db.Query(ctx,
@@ -2516,7 +3387,7 @@ func (h *Handler) getAircraft(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) createAircraft(w http.ResponseWriter, r *http.Request) {
 	var payload aircraftPayload
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	items, err := normalizeAircraftPayloads([]aircraftPayload{payload})
@@ -2555,7 +3426,7 @@ func (h *Handler) updateAircraft(w http.ResponseWriter, r *http.Request) {
 	}
 	var payload aircraftPayload
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	payload.ID = &aircraftID
@@ -2694,8 +3565,20 @@ func (h *Handler) fetchAircraftSlotBands(ctx context.Context, aircraftIDs []int6
 	return result, rows.Err()
 }
 
+// maxAirfieldPageSize caps ?limit= on the airfield list.
+const maxAirfieldPageSize = 500
+
 func (h *Handler) listAirfields(w http.ResponseWriter, r *http.Request) {
-	rows, err := h.db.Query(r.Context(), `SELECT id, name, latitude, longitude, elevation, description, created_at FROM airfields ORDER BY created_at DESC`)
+	page, err := httpx.ParsePage(r, maxAirfieldPageSize)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	pageClause, pageArgs := page.SQL(1)
+	rows, err := h.db.Query(r.Context(), `
+		SELECT id, name, latitude, longitude, elevation, description, created_at, `+httpx.TotalCountColumn+`
+		FROM airfields
+		ORDER BY lower(name), id`+pageClause, pageArgs...)
 	if err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to list airfields")
 		return
@@ -2705,7 +3588,7 @@ func (h *Handler) listAirfields(w http.ResponseWriter, r *http.Request) {
 	var items []airfields.Airfield
 	for rows.Next() {
 		var a airfields.Airfield
-		if err := rows.Scan(&a.ID, &a.Name, &a.Latitude, &a.Longitude, &a.Elevation, &a.Description, &a.CreatedAt); err != nil {
+		if err := rows.Scan(&a.ID, &a.Name, &a.Latitude, &a.Longitude, &a.Elevation, &a.Description, &a.CreatedAt, &page.Total); err != nil {
 			httpx.Error(w, http.StatusInternalServerError, "failed to parse airfield")
 			return
 		}
@@ -2716,8 +3599,15 @@ func (h *Handler) listAirfields(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, http.StatusInternalServerError, "failed to list airfields")
 		return
 	}
+	rows.Close()
+	if page.NeedsCount(len(items)) {
+		if err := h.db.QueryRow(r.Context(), `SELECT COUNT(*) FROM airfields`).Scan(&page.Total); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to count airfields")
+			return
+		}
+	}
 
-	httpx.WriteJSON(w, http.StatusOK, items)
+	httpx.WritePage(w, r, http.StatusOK, items, page)
 }
 
 func (h *Handler) getAirfield(w http.ResponseWriter, r *http.Request) {
@@ -2746,21 +3636,15 @@ func (h *Handler) getAirfield(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) createAirfield(w http.ResponseWriter, r *http.Request) {
-	var payload struct {
-		Name        string `json:"name"`
-		Elevation   int    `json:"elevation"`
-		Coordinates string `json:"coordinates"`
-		Description string `json:"description"`
-	}
+	var payload airfieldPayload
 
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
 	name := strings.TrimSpace(payload.Name)
-	coords := strings.TrimSpace(payload.Coordinates)
-	if name == "" || coords == "" {
+	if name == "" || !payload.hasCoordinates() {
 		httpx.Error(w, http.StatusBadRequest, "name and coordinates are required")
 		return
 	}
@@ -2769,11 +3653,14 @@ func (h *Handler) createAirfield(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	latRaw, lonRaw, err := splitCoords(coords)
+	latRaw, lonRaw, err := payload.canonicalCoordinates()
 	if err != nil {
 		httpx.Error(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if !h.checkAirfieldName(w, r, name, 0) {
+		return
+	}
 
 	row := h.db.QueryRow(r.Context(),
 		`INSERT INTO airfields (name, latitude, longitude, elevation, description) VALUES ($1, $2, $3, $4, $5)
@@ -2790,6 +3677,10 @@ func (h *Handler) createAirfield(w http.ResponseWriter, r *http.Request) {
 	a.Description = strings.TrimSpace(payload.Description)
 
 	if err := row.Scan(&a.ID, &a.CreatedAt); err != nil {
+		if isAirfieldNameConflict(err) {
+			httpx.Error(w, http.StatusConflict, fmt.Sprintf("an airfield named %q already exists; use it or merge into it", name))
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "failed to create airfield")
 		return
 	}
@@ -2804,21 +3695,15 @@ func (h *Handler) updateAirfield(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var payload struct {
-		Name        string `json:"name"`
-		Elevation   int    `json:"elevation"`
-		Coordinates string `json:"coordinates"`
-		Description string `json:"description"`
-	}
+	var payload airfieldPayload
 
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
 	name := strings.TrimSpace(payload.Name)
-	coords := strings.TrimSpace(payload.Coordinates)
-	if name == "" || coords == "" {
+	if name == "" || !payload.hasCoordinates() {
 		httpx.Error(w, http.StatusBadRequest, "name and coordinates are required")
 		return
 	}
@@ -2827,17 +3712,24 @@ func (h *Handler) updateAirfield(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	latRaw, lonRaw, err := splitCoords(coords)
+	latRaw, lonRaw, err := payload.canonicalCoordinates()
 	if err != nil {
 		httpx.Error(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if !h.checkAirfieldName(w, r, name, airfieldID) {
+		return
+	}
 
 	tag, err := h.db.Exec(r.Context(),
 		`UPDATE airfields SET name = $1, latitude = $2, longitude = $3, elevation = $4, description = $5 WHERE id = $6`,
 		name, latRaw, lonRaw, payload.Elevation, strings.TrimSpace(payload.Description), airfieldID,
 	)
 	if err != nil {
+		if isAirfieldNameConflict(err) {
+			httpx.Error(w, http.StatusConflict, fmt.Sprintf("an airfield named %q already exists; use it or merge into it", name))
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "failed to update airfield")
 		return
 	}
@@ -2856,7 +3748,7 @@ func (h *Handler) updateAirfield(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	a.Coordinates = strings.TrimSpace(a.Latitude + " " + a.Longitude)
-	if err := logistics.RecalculateRouteDurationsForLocationReference(r.Context(), h.db, "Airfield", a.ID); err != nil {
+	if err := h.recalculateRouteDurations(r.Context(), "Airfield", a.ID); err != nil {
 		log.Printf("route duration recalculation failed (type=Airfield id=%d): %v", a.ID, err)
 	}
 
@@ -2870,8 +3762,27 @@ func (h *Handler) deleteAirfield(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var innhoppCount int
+	if err := h.db.QueryRow(r.Context(),
+		`SELECT COUNT(*) FROM event_innhopps WHERE takeoff_airfield_id = $1 OR landing_airfield_id = $1`,
+		airfieldID,
+	).Scan(&innhoppCount); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to delete airfield")
+		return
+	}
+	if innhoppCount > 0 {
+		httpx.Error(w, http.StatusConflict, fmt.Sprintf("airfield is the takeoff or landing airfield of %d innhopps; change them first", innhoppCount))
+		return
+	}
+
 	tag, err := h.db.Exec(r.Context(), `DELETE FROM airfields WHERE id = $1`, airfieldID)
 	if err != nil {
+		// An innhopp may have picked the airfield since the check above.
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			httpx.Error(w, http.StatusConflict, "airfield is still in use")
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "failed to delete airfield")
 		return
 	}
@@ -3038,6 +3949,7 @@ func scanInnhopp(row pgx.Row, includeImages bool) (Innhopp, error) {
 		&imageFilesRaw,
 		&landOwnersRaw,
 		&landOwnerPermission,
+		&innhopp.Version,
 		&innhopp.CreatedAt,
 	); err != nil {
 		return innhopp, err
@@ -3116,14 +4028,20 @@ func scanInnhopp(row pgx.Row, includeImages bool) (Innhopp, error) {
 }
 
 func (h *Handler) fetchInnhoppsForEvents(ctx context.Context, eventIDs []int64, includeImages bool) (map[int64][]Innhopp, error) {
+	return queryInnhopps(ctx, h.db, eventIDs, includeImages)
+}
+
+// queryInnhopps loads the innhopps of eventIDs through db, which may be a
+// transaction, keyed by event id.
+func queryInnhopps(ctx context.Context, db rowsQuerier, eventIDs []int64, includeImages bool) (map[int64][]Innhopp, error) {
 	result := make(map[int64][]Innhopp, len(eventIDs))
-	rows, err := h.db.Query(ctx,
+	rows, err := db.Query(ctx,
 		`SELECT id, event_id, sequence, name, coordinates, aircraft_id, takeoff_airfield_id, landing_airfield_id, elevation, scheduled_at, notes,
                 reason_for_choice, adjust_altimeter_aad, notam, distance_by_air, distance_by_road, landing_distance_by_air, landing_distance_by_road,
                 primary_landing_area_name, primary_landing_area_description, primary_landing_area_size, primary_landing_area_obstacles,
                 secondary_landing_area_name, secondary_landing_area_description, secondary_landing_area_size, secondary_landing_area_obstacles,
                 risk_assessment, safety_precautions, jumprun, hospital, rescue_boat, minimum_requirements, image_files, land_owners, land_owner_permission,
-                created_at
+                version, created_at
          FROM event_innhopps
          WHERE event_id = ANY($1)
          ORDER BY event_id, sequence, id`,
@@ -3150,9 +4068,12 @@ func (h *Handler) fetchInnhoppsForEvents(ctx context.Context, eventIDs []int64,
 }
 
 func (h *Handler) syncEventStatuses(ctx context.Context, events []Event) error {
-	now := time.Now().UTC()
+	now := h.clock.Now().UTC()
 	for i := range events {
-		nextStatus := deriveEventStatus(events[i], now)
+		nextStatus, err := h.nextEventStatus(ctx, h.db, events[i], now)
+		if err != nil {
+			return err
+		}
 		if nextStatus == events[i].Status {
 			continue
 		}
@@ -3169,6 +4090,10 @@ func deriveEventStatus(event Event, now time.Time) string {
 	if event.EndsAt != nil {
 		end = *event.EndsAt
 	}
+	if event.AllDay {
+		// All-day events run until the end of their final date.
+		end = end.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
 
 	switch {
 	case now.After(end):
@@ -3195,6 +4120,21 @@ func normalizeEventStatus(raw string) (string, error) {
 	return status, nil
 }
 
+// trimmedText returns the trimmed value of an optional text field, or "" when
+// it was not sent.
+func trimmedText(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return strings.TrimSpace(*value)
+}
+
+// canViewEventNotes reports whether the caller may see the staff-only notes
+// on an event. Participants can read events but not the notes.
+func (h *Handler) canViewEventNotes(r *http.Request) bool {
+	return h.enforcer != nil && h.enforcer.Allows(r, rbac.PermissionManageEvents)
+}
+
 func normalizeCommercialStatus(raw string) (string, error) {
 	status := strings.ToLower(strings.TrimSpace(raw))
 	if status == "" {
@@ -3270,24 +4210,24 @@ func validateRegistrationSettings(publicRegistrationEnabled bool, depositAmount,
 	return nil
 }
 
-func parseEventTimes(starts, ends string) (time.Time, *time.Time, error) {
+func parseEventTimes(starts, ends string, allDay bool) (time.Time, *time.Time, error) {
 	starts = strings.TrimSpace(starts)
 	if starts == "" {
 		return time.Time{}, nil, errors.New("starts_at is required")
 	}
 
-	startsAt, err := timeutil.ParseEventTimestamp(starts)
+	startsAt, err := parseEventBoundary(starts, allDay)
 	if err != nil {
-		return time.Time{}, nil, errors.New("starts_at must be RFC3339 timestamp")
+		return time.Time{}, nil, fmt.Errorf("starts_at %s", err.Error())
 	}
 
 	if strings.TrimSpace(ends) == "" {
 		return startsAt, nil, nil
 	}
 
-	endsAt, err := timeutil.ParseEventTimestamp(strings.TrimSpace(ends))
+	endsAt, err := parseEventBoundary(strings.TrimSpace(ends), allDay)
 	if err != nil {
-		return time.Time{}, nil, errors.New("ends_at must be RFC3339 timestamp")
+		return time.Time{}, nil, fmt.Errorf("ends_at %s", err.Error())
 	}
 	if endsAt.Before(startsAt) {
 		return time.Time{}, nil, errors.New("ends_at cannot be before starts_at")
@@ -3296,6 +4236,30 @@ func parseEventTimes(starts, ends string) (time.Time, *time.Time, error) {
 	return startsAt, &endsAt, nil
 }
 
+// parseEventBoundary parses a start or end value. All-day events take a
+// date (a full timestamp is truncated to its date); timed events must carry a
+// time of day.
+func parseEventBoundary(value string, allDay bool) (time.Time, error) {
+	if allDay {
+		if parsed, err := timeutil.ParseEventDate(value); err == nil {
+			return parsed, nil
+		}
+		parsed, err := timeutil.ParseEventTimestamp(value)
+		if err != nil {
+			return time.Time{}, errors.New("must be a date (YYYY-MM-DD)")
+		}
+		return time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+	if _, err := timeutil.ParseEventDate(value); err == nil {
+		return time.Time{}, errors.New("must include a time unless all_day is set")
+	}
+	parsed, err := timeutil.ParseEventTimestamp(value)
+	if err != nil {
+		return time.Time{}, errors.New("must be RFC3339 or YYYY-MM-DDTHH:MM")
+	}
+	return parsed, nil
+}
+
 func normalizeParticipantIDs(raw []int64) ([]int64, error) {
 	if len(raw) == 0 {
 		return nil, nil
@@ -3318,6 +4282,73 @@ func normalizeParticipantIDs(raw []int64) ([]int64, error) {
 	return ids, nil
 }
 
+const (
+	maxEventTags      = 20
+	maxEventTagLength = 40
+)
+
+// normalizeEventTags lowercases, trims and dedupes tags, returning them
+// sorted. Blank tags are dropped; the result is never nil.
+func normalizeEventTags(raw []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(raw))
+	tags := make([]string, 0, len(raw))
+	for _, tag := range raw {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if len(tag) > maxEventTagLength {
+			return nil, fmt.Errorf("tags must be at most %d characters", maxEventTagLength)
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxEventTags {
+		return nil, fmt.Errorf("an event can have at most %d tags", maxEventTags)
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// parseEventTagFilter reads the tags query parameter, which may be repeated
+// or comma-separated. It returns nil when no tags were requested.
+func parseEventTagFilter(values []string) ([]string, error) {
+	var raw []string
+	for _, value := range values {
+		raw = append(raw, strings.Split(value, ",")...)
+	}
+	tags, err := normalizeEventTags(raw)
+	if err != nil || len(tags) == 0 {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// parseEventStatusFilter reads ?status=, repeated or comma-separated.
+func parseEventStatusFilter(values []string) ([]string, error) {
+	var statuses []string
+	seen := map[string]struct{}{}
+	for _, value := range values {
+		for _, raw := range strings.Split(value, ",") {
+			status := strings.ToLower(strings.TrimSpace(raw))
+			if status == "" {
+				continue
+			}
+			if _, ok := validEventStatuses[status]; !ok {
+				return nil, errors.New("status must be one of: " + strings.Join(eventStatusValues, ", "))
+			}
+			if _, dup := seen[status]; !dup {
+				seen[status] = struct{}{}
+				statuses = append(statuses, status)
+			}
+		}
+	}
+	return statuses, nil
+}
+
 func normalizeAirfieldIDs(raw []int64) ([]int64, error) {
 	if len(raw) == 0 {
 		return nil, nil
@@ -3428,7 +4459,7 @@ func (h *Handler) createInnhopp(w http.ResponseWriter, r *http.Request) {
 
 	var payload innhoppPayload
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
@@ -3465,8 +4496,31 @@ func (h *Handler) createInnhopp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ctx := r.Context()
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to create innhopp")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var lockedEventID int64
+	if err := tx.QueryRow(ctx, `SELECT id FROM events WHERE id = $1 FOR UPDATE`, eventID).Scan(&lockedEventID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			httpx.Error(w, http.StatusNotFound, "event not found")
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to create innhopp")
+		return
+	}
+	in.Sequence, err = resolveInnhoppSequence(ctx, tx, eventID, payload.Sequence)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to assign innhopp sequence")
+		return
+	}
+
 	var created Innhopp
-	row := h.db.QueryRow(r.Context(),
+	row := tx.QueryRow(ctx,
 		`INSERT INTO event_innhopps (
             event_id, sequence, name, coordinates, aircraft_id, takeoff_airfield_id, landing_airfield_id, elevation, scheduled_at, notes,
             reason_for_choice, adjust_altimeter_aad, notam, distance_by_air, distance_by_road, landing_distance_by_air, landing_distance_by_road,
@@ -3486,7 +4540,7 @@ func (h *Handler) createInnhopp(w http.ResponseWriter, r *http.Request) {
                   primary_landing_area_name, primary_landing_area_description, primary_landing_area_size, primary_landing_area_obstacles,
                   secondary_landing_area_name, secondary_landing_area_description, secondary_landing_area_size, secondary_landing_area_obstacles,
                   risk_assessment, safety_precautions, jumprun, hospital, rescue_boat, minimum_requirements, image_files, land_owners, land_owner_permission,
-                  created_at`,
+                  version, created_at`,
 		eventID, in.Sequence, in.Name, in.Coordinates, in.AircraftID, in.TakeoffAirfieldID, in.LandingAirfieldID, in.Elevation, in.ScheduledAt, strings.TrimSpace(payload.Notes),
 		in.ReasonForChoice, in.AdjustAltimeterAAD, in.Notam, in.DistanceByAir, in.DistanceByRoad, in.LandingDistanceByAir, in.LandingDistanceByRoad,
 		in.PrimaryLandingArea.Name, in.PrimaryLandingArea.Description, in.PrimaryLandingArea.Size, in.PrimaryLandingArea.Obstacles,
@@ -3560,6 +4614,7 @@ func (h *Handler) createInnhopp(w http.ResponseWriter, r *http.Request) {
 		&imageFilesRaw,
 		&ownersRaw,
 		&landOwnerPermission,
+		&created.Version,
 		&created.CreatedAt,
 	); err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to create innhopp")
@@ -3661,8 +4716,8 @@ func (h *Handler) createInnhopp(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if created.TakeoffAirfieldID != nil {
-		if _, err := h.db.Exec(
-			r.Context(),
+		if _, err := tx.Exec(
+			ctx,
 			`INSERT INTO event_airfields (event_id, airfield_id) VALUES ($1, $2)
              ON CONFLICT (event_id, airfield_id) DO NOTHING`,
 			eventID,
@@ -3673,8 +4728,8 @@ func (h *Handler) createInnhopp(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	if created.LandingAirfieldID != nil {
-		if _, err := h.db.Exec(
-			r.Context(),
+		if _, err := tx.Exec(
+			ctx,
 			`INSERT INTO event_airfields (event_id, airfield_id) VALUES ($1, $2)
              ON CONFLICT (event_id, airfield_id) DO NOTHING`,
 			eventID,
@@ -3684,9 +4739,20 @@ func (h *Handler) createInnhopp(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	if err := tx.Commit(ctx); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to create innhopp")
+		return
+	}
 
+	// A failed lookup only loses the suggestion.
+	suggested, err := airfields.SuggestTakeoff(ctx, h.db, created.Coordinates, created.TakeoffAirfieldID, h.takeoffSuggestRadiusKm)
+	if err != nil {
+		log.Printf("events: takeoff airfield suggestion for innhopp %d failed: %v", created.ID, err)
+	}
+	created.SuggestedTakeoffAirfieldID = suggested
 	httpx.WriteJSON(w, http.StatusCreated, created)
 }
+
 func normalizeInnhopps(raw []innhoppPayload) ([]innhoppInput, error) {
 	if len(raw) == 0 {
 		return nil, nil
@@ -3699,6 +4765,9 @@ func normalizeInnhopps(raw []innhoppPayload) ([]innhoppInput, error) {
 			return nil, errors.New("innhopps[" + strconv.Itoa(i) + "].name is required")
 		}
 		coordinates := strings.TrimSpace(payload.Coordinates)
+		if payload.ID != nil && payload.Version == nil {
+			return nil, errors.New("innhopps[" + strconv.Itoa(i) + "].version is required with an id")
+		}
 
 		sequence := i + 1
 		if payload.Sequence != nil {
@@ -3785,6 +4854,7 @@ func normalizeInnhopps(raw []innhoppPayload) ([]innhoppInput, error) {
 
 		innhopps = append(innhopps, innhoppInput{
 			ID:                    payload.ID,
+			Version:               payload.Version,
 			Sequence:              sequence,
 			Name:                  name,
 			Coordinates:           coordinates,
@@ -3807,10 +4877,10 @@ func normalizeInnhopps(raw []innhoppPayload) ([]innhoppInput, error) {
 			SafetyPrecautions:     strings.TrimSpace(payload.SafetyPrecautions),
 			Jumprun:               strings.TrimSpace(payload.Jumprun),
 			Hospital:              strings.TrimSpace(payload.Hospital),
-			RescueBoat:            payload.RescueBoat,
+			RescueBoat:            (*bool)(payload.RescueBoat),
 			MinimumRequirements:   strings.TrimSpace(payload.MinimumRequirements),
 			LandOwners:            normalizeLandOwnersPayload(payload.LandOwners),
-			LandOwnerPermission:   payload.LandOwnerPermission,
+			LandOwnerPermission:   (*bool)(payload.LandOwnerPermission),
 			ImageFiles:            normalizeImageFiles(payload.ImageFiles),
 		})
 	}
@@ -3977,6 +5047,9 @@ func normalizeAircraftPayloads(raw []aircraftPayload) ([]aircraftInput, error) {
 }
 
 func replaceEventParticipantsTx(ctx context.Context, tx pgx.Tx, eventID int64, participantIDs []int64) error {
+	if err := ensureReferencesExist(ctx, tx, "participant_profiles", "participant_ids", participantIDs); err != nil {
+		return err
+	}
 	if _, err := tx.Exec(ctx, `DELETE FROM event_participants WHERE event_id = $1`, eventID); err != nil {
 		return err
 	}
@@ -4000,6 +5073,9 @@ func replaceEventParticipantsTx(ctx context.Context, tx pgx.Tx, eventID int64, p
 }
 
 func replaceEventAirfieldsTx(ctx context.Context, tx pgx.Tx, eventID int64, airfieldIDs []int64) error {
+	if err := ensureReferencesExist(ctx, tx, "airfields", "airfield_ids", airfieldIDs); err != nil {
+		return err
+	}
 	if _, err := tx.Exec(ctx, `DELETE FROM event_airfields WHERE event_id = $1`, eventID); err != nil {
 		return err
 	}
@@ -4152,118 +5228,13 @@ func ensureNoDetachedAircraftInUseTx(ctx context.Context, tx pgx.Tx, eventID int
 	return rows.Err()
 }
 
-func replaceEventInnhoppsTx(ctx context.Context, tx pgx.Tx, eventID int64, innhopps []innhoppInput) error {
-	if _, err := tx.Exec(ctx, `DELETE FROM event_innhopps WHERE event_id = $1`, eventID); err != nil {
-		return err
-	}
-	if len(innhopps) == 0 {
-		return nil
-	}
-
-	airfieldIDsFromInnhopps := make(map[int64]struct{})
-	for index, innhopp := range innhopps {
-		landOwnersJSON, err := encodeLandOwners(innhopp.LandOwners)
-		if err != nil {
-			return fmt.Errorf("innhopp %d (%s): %w", index+1, innhopp.Name, err)
-		}
-		imageFilesJSON, err := encodeImageFiles(innhopp.ImageFiles)
-		if err != nil {
-			return fmt.Errorf("innhopp %d (%s): %w", index+1, innhopp.Name, err)
-		}
-
-		if _, err := tx.Exec(ctx, `INSERT INTO event_innhopps (
-                event_id, sequence, name, coordinates, aircraft_id, takeoff_airfield_id, landing_airfield_id, elevation, scheduled_at, notes,
-                reason_for_choice, adjust_altimeter_aad, notam, distance_by_air, distance_by_road, landing_distance_by_air, landing_distance_by_road,
-                primary_landing_area_name, primary_landing_area_description, primary_landing_area_size, primary_landing_area_obstacles,
-                secondary_landing_area_name, secondary_landing_area_description, secondary_landing_area_size, secondary_landing_area_obstacles,
-                risk_assessment, safety_precautions, jumprun, hospital, rescue_boat, minimum_requirements, image_files, land_owners, land_owner_permission
-            ) VALUES (
-                $1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
-                $11, $12, $13, $14, $15, $16, $17,
-                $18, $19, $20, $21,
-                $22, $23, $24, $25,
-                $26, $27, $28, $29, $30, $31, $32::jsonb, $33::jsonb, $34
-            )`,
-			eventID,
-			innhopp.Sequence,
-			innhopp.Name,
-			innhopp.Coordinates,
-			innhopp.AircraftID,
-			innhopp.TakeoffAirfieldID,
-			innhopp.LandingAirfieldID,
-			innhopp.Elevation,
-			innhopp.ScheduledAt,
-			innhopp.Notes,
-			innhopp.ReasonForChoice,
-			innhopp.AdjustAltimeterAAD,
-			innhopp.Notam,
-			innhopp.DistanceByAir,
-			innhopp.DistanceByRoad,
-			innhopp.LandingDistanceByAir,
-			innhopp.LandingDistanceByRoad,
-			innhopp.PrimaryLandingArea.Name,
-			innhopp.PrimaryLandingArea.Description,
-			innhopp.PrimaryLandingArea.Size,
-			innhopp.PrimaryLandingArea.Obstacles,
-			innhopp.SecondaryLandingArea.Name,
-			innhopp.SecondaryLandingArea.Description,
-			innhopp.SecondaryLandingArea.Size,
-			innhopp.SecondaryLandingArea.Obstacles,
-			innhopp.RiskAssessment,
-			innhopp.SafetyPrecautions,
-			innhopp.Jumprun,
-			innhopp.Hospital,
-			innhopp.RescueBoat,
-			innhopp.MinimumRequirements,
-			string(imageFilesJSON),
-			string(landOwnersJSON),
-			innhopp.LandOwnerPermission,
-		); err != nil {
-			return fmt.Errorf("innhopp %d (%s): %w", index+1, innhopp.Name, err)
-		}
-
-		if innhopp.TakeoffAirfieldID != nil {
-			airfieldIDsFromInnhopps[*innhopp.TakeoffAirfieldID] = struct{}{}
-		}
-		if innhopp.LandingAirfieldID != nil {
-			airfieldIDsFromInnhopps[*innhopp.LandingAirfieldID] = struct{}{}
-		}
-	}
-
-	for airfieldID := range airfieldIDsFromInnhopps {
-		if _, err := tx.Exec(ctx,
-			`INSERT INTO event_airfields (event_id, airfield_id) VALUES ($1, $2)
-             ON CONFLICT (event_id, airfield_id) DO NOTHING`,
-			eventID,
-			airfieldID,
-		); err != nil {
-			return fmt.Errorf("failed to link innhopp airfield %d to event: %w", airfieldID, err)
-		}
-	}
-	return nil
-}
-
-func splitCoords(raw string) (string, string, error) {
-	if raw == "" {
-		return "", "", errors.New("coordinates are required")
-	}
-
-	// First try comma separation
-	parts := strings.Split(raw, ",")
-	if len(parts) < 2 {
-		parts = strings.Fields(raw)
-	}
-	if len(parts) < 2 {
-		return "", "", errors.New("coordinates must include latitude and longitude separated by comma or space")
-	}
-
-	lat := strings.TrimSpace(parts[0])
-	lon := strings.TrimSpace(parts[1])
-	if lat == "" || lon == "" {
-		return "", "", errors.New("coordinates must include both latitude and longitude")
+// writeClientIDConflict answers a create whose client_id is already taken in
+// table with the id of the record that holds it.
+func (h *Handler) writeClientIDConflict(w http.ResponseWriter, r *http.Request, table, clientID string) {
+	var existingID int64
+	if err := h.db.QueryRow(r.Context(), `SELECT id FROM `+table+` WHERE client_id = $1`, clientID).Scan(&existingID); err != nil {
+		httpx.Error(w, http.StatusConflict, "client_id is already in use")
+		return
 	}
-
-	return lat, lon, nil
+	httpx.ClientIDConflict(w, existingID)
 }
-
-// NOTE: Coordinate parsing/validation removed per request; values are stored as provided (split into latitude/longitude).
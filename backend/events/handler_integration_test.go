@@ -0,0 +1,58 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestListEventsOrdersStartTimeTiesByID(t *testing.T) {
+	db := openEventTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ensureEventTestSchema(t, ctx, db)
+
+	var seasonID int64
+	if err := db.QueryRow(ctx, `INSERT INTO seasons (name, starts_on) VALUES ('Tie Season', CURRENT_DATE) RETURNING id`).Scan(&seasonID); err != nil {
+		t.Fatalf("insert season failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM seasons WHERE id = $1`, seasonID)
+
+	// Rows go in oldest id first, the reverse of the order the list must
+	// return them in, so a scan in insertion order would fail.
+	name := "Tie " + strconv.FormatInt(time.Now().UnixNano(), 10)
+	startsAt := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second)
+	ids := make([]int64, 3)
+	for i := range ids {
+		if err := db.QueryRow(ctx,
+			`INSERT INTO events (season_id, name, starts_at, status) VALUES ($1, $2, $3, 'planned') RETURNING id`,
+			seasonID, name, startsAt,
+		).Scan(&ids[i]); err != nil {
+			t.Fatalf("insert event failed: %v", err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	NewHandler(db).listEvents(rec, httptest.NewRequest(http.MethodGet, "/events?fields=id&q="+url.QueryEscape(name), nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("listEvents() status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	var page struct {
+		Items []struct {
+			ID int64 `json:"id"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&page); err != nil {
+		t.Fatalf("decode events failed: %v", err)
+	}
+	if len(page.Items) != 3 || page.Items[0].ID != ids[2] || page.Items[1].ID != ids[1] || page.Items[2].ID != ids[0] {
+		t.Fatalf("listEvents() items = %+v, want ids %d, %d, %d", page.Items, ids[2], ids[1], ids[0])
+	}
+}
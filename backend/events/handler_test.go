@@ -1,6 +1,21 @@
 package events
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/innhopp/central/backend/rbac"
+)
 
 func TestNormalizeAircraftPayloadsPreservesExistingSlotBandsWhenOmitted(t *testing.T) {
 	slotPrice := 120.0
@@ -40,3 +55,1316 @@ func TestNormalizeAircraftPayloadsRequiresBandsForNewSlotAircraft(t *testing.T)
 		t.Fatal("normalizeAircraftPayloads() expected error for new slot aircraft without bands")
 	}
 }
+
+func TestParseEventTimesAcceptsDatesForAllDayEvents(t *testing.T) {
+	startsAt, endsAt, err := parseEventTimes("2025-06-14", "2025-06-15", true)
+	if err != nil {
+		t.Fatalf("parseEventTimes() returned error: %v", err)
+	}
+	if got := startsAt.Format(eventDateLayout); got != "2025-06-14" {
+		t.Fatalf("parseEventTimes() starts_at = %s, want 2025-06-14", got)
+	}
+	if endsAt == nil || endsAt.Format(eventDateLayout) != "2025-06-15" {
+		t.Fatalf("parseEventTimes() ends_at = %v, want 2025-06-15", endsAt)
+	}
+}
+
+func TestParseEventTimesRequiresTimeForTimedEvents(t *testing.T) {
+	if _, _, err := parseEventTimes("2025-06-14", "", false); err == nil {
+		t.Fatal("parseEventTimes() expected error for date-only timed event")
+	}
+	if _, _, err := parseEventTimes("2025-06-14T09:00:00Z", "", false); err != nil {
+		t.Fatalf("parseEventTimes() returned error for timed event: %v", err)
+	}
+}
+
+func TestEventMarshalJSONRendersAllDayDates(t *testing.T) {
+	startsAt := time.Date(2025, 6, 14, 0, 0, 0, 0, time.UTC)
+	endsAt := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+	payload, err := json.Marshal(Event{AllDay: true, StartsAt: startsAt, EndsAt: &endsAt})
+	if err != nil {
+		t.Fatalf("json.Marshal() returned error: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() returned error: %v", err)
+	}
+	if decoded["starts_at"] != "2025-06-14" || decoded["ends_at"] != "2025-06-15" {
+		t.Fatalf("unexpected all-day dates: starts_at=%v ends_at=%v", decoded["starts_at"], decoded["ends_at"])
+	}
+	if decoded["all_day"] != true {
+		t.Fatalf("all_day = %v, want true", decoded["all_day"])
+	}
+}
+
+func TestDeriveEventStatusKeepsAllDayEventLiveUntilEndOfDay(t *testing.T) {
+	day := time.Date(2025, 6, 14, 0, 0, 0, 0, time.UTC)
+	event := Event{AllDay: true, Status: "planned", StartsAt: day}
+	if got := deriveEventStatus(event, day.Add(20*time.Hour)); got != "live" {
+		t.Fatalf("deriveEventStatus() = %s, want live", got)
+	}
+	if got := deriveEventStatus(event, day.AddDate(0, 0, 1)); got != "past" {
+		t.Fatalf("deriveEventStatus() = %s, want past", got)
+	}
+}
+
+func TestParseEventTimesAcceptsDatetimeLocalInput(t *testing.T) {
+	startsAt, endsAt, err := parseEventTimes("2025-06-14T09:30", "2025-06-14T17:00:00Z", false)
+	if err != nil {
+		t.Fatalf("parseEventTimes() returned error: %v", err)
+	}
+	if want := time.Date(2025, 6, 14, 9, 30, 0, 0, time.UTC); !startsAt.Equal(want) {
+		t.Fatalf("parseEventTimes() starts_at = %s, want %s", startsAt, want)
+	}
+	if endsAt == nil || !endsAt.Equal(time.Date(2025, 6, 14, 17, 0, 0, 0, time.UTC)) {
+		t.Fatalf("parseEventTimes() ends_at = %v, want 2025-06-14T17:00:00Z", endsAt)
+	}
+}
+
+func TestCreateEventRejectsInvalidPayloadsBeforeTouchingDatabase(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "malformed json", body: `{`},
+		{name: "missing season", body: `{"name":"Voss","starts_at":"2025-06-14T09:00"}`},
+		{name: "missing name", body: `{"season_id":1,"starts_at":"2025-06-14T09:00"}`},
+		{name: "unknown status", body: `{"season_id":1,"name":"Voss","status":"open","starts_at":"2025-06-14T09:00"}`},
+		{name: "missing start", body: `{"season_id":1,"name":"Voss"}`},
+		{name: "date-only timed event", body: `{"season_id":1,"name":"Voss","starts_at":"2025-06-14"}`},
+		{name: "end before start", body: `{"season_id":1,"name":"Voss","starts_at":"2025-06-14T09:00","ends_at":"2025-06-13T09:00"}`},
+		{name: "invalid participant", body: `{"season_id":1,"name":"Voss","starts_at":"2025-06-14T09:00","participant_ids":[0]}`},
+		{name: "invalid airfield", body: `{"season_id":1,"name":"Voss","starts_at":"2025-06-14T09:00","airfield_ids":[-3]}`},
+		{name: "unnamed innhopp", body: `{"season_id":1,"name":"Voss","starts_at":"2025-06-14T09:00","innhopps":[{"name":" "}]}`},
+		{name: "bad slug", body: `{"season_id":1,"name":"Voss","starts_at":"2025-06-14T09:00","public_registration_slug":"Voss 2025"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{}
+			h := NewHandler(db)
+			rec := httptest.NewRecorder()
+			h.createEvent(rec, httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(tt.body)))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("createEvent() status = %d, want %d (body %s)", rec.Code, http.StatusBadRequest, rec.Body.String())
+			}
+			if len(db.calls) != 0 {
+				t.Fatalf("createEvent() issued database calls for invalid payload: %v", db.calls)
+			}
+		})
+	}
+}
+
+func TestUpdateEventRejectsInvalidPayloadsBeforeTouchingDatabase(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{name: "invalid id", path: "/events/abc", body: `{"season_id":1,"name":"Voss","starts_at":"2025-06-14T09:00"}`},
+		{name: "missing name", path: "/events/4", body: `{"season_id":1,"starts_at":"2025-06-14T09:00"}`},
+		{name: "bad start", path: "/events/4", body: `{"season_id":1,"name":"Voss","starts_at":"soon"}`},
+		{name: "negative innhopp sequence", path: "/events/4", body: `{"season_id":1,"name":"Voss","starts_at":"2025-06-14T09:00","innhopps":[{"name":"Fjord","sequence":-1}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{}
+			router := chi.NewRouter()
+			router.Put("/events/{eventID}", NewHandler(db).updateEvent)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, tt.path, strings.NewReader(tt.body)))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("updateEvent() status = %d, want %d (body %s)", rec.Code, http.StatusBadRequest, rec.Body.String())
+			}
+			if len(db.calls) != 0 {
+				t.Fatalf("updateEvent() issued database calls for invalid payload: %v", db.calls)
+			}
+		})
+	}
+}
+
+func TestNormalizeParticipantIDsDeduplicatesAndSorts(t *testing.T) {
+	got, err := normalizeParticipantIDs([]int64{9, 3, 9, 1, 3})
+	if err != nil {
+		t.Fatalf("normalizeParticipantIDs() returned error: %v", err)
+	}
+	if want := []int64{1, 3, 9}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("normalizeParticipantIDs() = %v, want %v", got, want)
+	}
+	if _, err := normalizeParticipantIDs([]int64{4, 0}); err == nil {
+		t.Fatal("normalizeParticipantIDs() expected error for non-positive id")
+	}
+}
+
+func TestNormalizeInnhoppsDefaultsSequenceAndParsesSchedule(t *testing.T) {
+	explicit := 7
+	got, err := normalizeInnhopps([]innhoppPayload{
+		{Name: " Fjord ", ScheduledAt: "2025-06-14T10:15"},
+		{Name: "Glacier", Sequence: &explicit},
+	})
+	if err != nil {
+		t.Fatalf("normalizeInnhopps() returned error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("normalizeInnhopps() returned %d items, want 2", len(got))
+	}
+	if got[0].Sequence != 1 || got[1].Sequence != 7 {
+		t.Fatalf("normalizeInnhopps() sequences = %d, %d; want 1, 7", got[0].Sequence, got[1].Sequence)
+	}
+	if got[0].Name != "Fjord" {
+		t.Fatalf("normalizeInnhopps() name = %q, want Fjord", got[0].Name)
+	}
+	if got[0].ScheduledAt == nil || !got[0].ScheduledAt.Equal(time.Date(2025, 6, 14, 10, 15, 0, 0, time.UTC)) {
+		t.Fatalf("normalizeInnhopps() scheduled_at = %v, want 2025-06-14T10:15:00Z", got[0].ScheduledAt)
+	}
+
+	if _, err := normalizeInnhopps([]innhoppPayload{{Name: "Fjord", ScheduledAt: "later"}}); err == nil {
+		t.Fatal("normalizeInnhopps() expected error for invalid scheduled_at")
+	}
+	id := int64(4)
+	if _, err := normalizeInnhopps([]innhoppPayload{{ID: &id, Name: "Fjord"}}); err == nil {
+		t.Fatal("normalizeInnhopps() expected error for an id without a version")
+	}
+}
+
+func TestAttachEventRelationsGroupsRowsByEvent(t *testing.T) {
+	db := &fakeDB{results: []fakeResult{
+		{match: "FROM event_participants", rows: [][]any{{int64(1), int64(10)}, {int64(1), int64(11)}, {int64(2), int64(12)}}},
+		{match: "FROM event_airfields", rows: [][]any{{int64(2), int64(5)}}},
+		{match: "AS remaining_slots", rows: [][]any{{int64(1), 4}, {int64(2), 0}}},
+	}}
+	h := NewHandler(db)
+
+	got, err := h.attachEventRelations(context.Background(), []Event{{ID: 1}, {ID: 2}, {ID: 3}}, allEventIncludes())
+	if err != nil {
+		t.Fatalf("attachEventRelations() returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got[0].ParticipantIDs, []int64{10, 11}) || !reflect.DeepEqual(got[1].ParticipantIDs, []int64{12}) {
+		t.Fatalf("unexpected participant ids: %v, %v", got[0].ParticipantIDs, got[1].ParticipantIDs)
+	}
+	if got[0].AirfieldIDs != nil || !reflect.DeepEqual(got[1].AirfieldIDs, []int64{5}) {
+		t.Fatalf("unexpected airfield ids: %v, %v", got[0].AirfieldIDs, got[1].AirfieldIDs)
+	}
+	if got[0].RemainingSlots != 4 || got[1].RemainingSlots != 0 || got[2].RemainingSlots != 0 {
+		t.Fatalf("unexpected remaining slots: %d, %d, %d", got[0].RemainingSlots, got[1].RemainingSlots, got[2].RemainingSlots)
+	}
+	if got[2].ParticipantIDs != nil || got[2].Innhopps != nil {
+		t.Fatalf("event without relations got %v participants and %v innhopps", got[2].ParticipantIDs, got[2].Innhopps)
+	}
+}
+
+func TestResolveBaseAirfield(t *testing.T) {
+	ctx := context.Background()
+	db := &fakeDB{results: []fakeResult{{match: "FROM airfields", rows: [][]any{{"Voss"}}}}}
+
+	id := int64(4)
+	gotID, name, err := resolveBaseAirfield(ctx, db, &id)
+	if err != nil {
+		t.Fatalf("resolveBaseAirfield() returned error: %v", err)
+	}
+	if gotID == nil || *gotID != 4 || name != "Voss" {
+		t.Fatalf("resolveBaseAirfield() = %v, %q; want 4, Voss", gotID, name)
+	}
+
+	if gotID, _, err := resolveBaseAirfield(ctx, &fakeDB{}, nil); err != nil || gotID != nil {
+		t.Fatalf("resolveBaseAirfield(nil) = %v, %v; want nil, nil", gotID, err)
+	}
+
+	if _, _, err := resolveBaseAirfield(ctx, &fakeDB{}, &id); !errors.Is(err, errUnknownBaseAirfield) {
+		t.Fatalf("resolveBaseAirfield() error = %v, want errUnknownBaseAirfield", err)
+	}
+}
+
+func TestEnsureReferencesExistListsUnknownIDs(t *testing.T) {
+	ctx := context.Background()
+	db := &fakeDB{results: []fakeResult{{match: "FROM participant_profiles", rows: [][]any{{int64(2)}}}}}
+
+	if err := ensureReferencesExist(ctx, db, "participant_profiles", "participant_ids", []int64{2}); err != nil {
+		t.Fatalf("ensureReferencesExist() returned error: %v", err)
+	}
+
+	err := ensureReferencesExist(ctx, db, "participant_profiles", "participant_ids", []int64{2, 4, 9})
+	var unknown *unknownReferencesError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("ensureReferencesExist() error = %v, want unknownReferencesError", err)
+	}
+	if got := err.Error(); got != "participant_ids reference unknown ids: 4, 9" {
+		t.Fatalf("ensureReferencesExist() error = %q", got)
+	}
+
+	if err := ensureReferencesExist(ctx, &fakeDB{}, "airfields", "airfield_ids", nil); err != nil {
+		t.Fatalf("ensureReferencesExist(nil) returned error: %v", err)
+	}
+}
+
+func TestListSeasonsIncludesEventCountsAndBreaksTiesByID(t *testing.T) {
+	startsOn := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2024, 12, 1, 9, 0, 0, 0, time.UTC)
+	db := &fakeDB{results: []fakeResult{{match: "FROM seasons s", rows: [][]any{
+		{int64(2), "2025 B", startsOn, nil, 3, 1, createdAt},
+		{int64(1), "2025 A", startsOn, nil, 0, 0, createdAt},
+	}}}}
+	h := NewHandler(db)
+
+	rec := httptest.NewRecorder()
+	h.listSeasons(rec, httptest.NewRequest(http.MethodGet, "/seasons", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("listSeasons() status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if len(db.calls) != 1 || !strings.Contains(db.calls[0], "ORDER BY s.starts_on DESC, s.id DESC") {
+		t.Fatalf("listSeasons() query lacks id tiebreak: %v", db.calls)
+	}
+
+	var seasons []Season
+	if err := json.Unmarshal(rec.Body.Bytes(), &seasons); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(seasons) != 2 || seasons[0].EventCount != 3 || seasons[0].UpcomingEventCount != 1 || seasons[1].EventCount != 0 {
+		t.Fatalf("unexpected seasons: %+v", seasons)
+	}
+}
+
+func TestMergeSeasonRejectsInvalidPayloadsBeforeTouchingDatabase(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{name: "invalid id", path: "/seasons/abc/merge", body: `{"source_id":2}`},
+		{name: "missing source", path: "/seasons/1/merge", body: `{}`},
+		{name: "merge into itself", path: "/seasons/1/merge", body: `{"source_id":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeDB{}
+			router := chi.NewRouter()
+			router.Post("/seasons/{seasonID}/merge", NewHandler(db).mergeSeason)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body)))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("mergeSeason() status = %d, want %d (body %s)", rec.Code, http.StatusBadRequest, rec.Body.String())
+			}
+			if len(db.calls) != 0 {
+				t.Fatalf("mergeSeason() issued database calls for invalid payload: %v", db.calls)
+			}
+		})
+	}
+}
+
+func TestNormalizeEventTagsLowercasesDedupesAndSorts(t *testing.T) {
+	got, err := normalizeEventTags([]string{" Freefly", "CRW", "freefly", "", "students"})
+	if err != nil {
+		t.Fatalf("normalizeEventTags() returned error: %v", err)
+	}
+	if want := []string{"crw", "freefly", "students"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("normalizeEventTags() = %v, want %v", got, want)
+	}
+	if got, _ := normalizeEventTags(nil); got == nil || len(got) != 0 {
+		t.Fatalf("normalizeEventTags(nil) = %#v, want empty slice", got)
+	}
+	if _, err := normalizeEventTags([]string{strings.Repeat("x", maxEventTagLength+1)}); err == nil {
+		t.Fatal("normalizeEventTags() expected error for overlong tag")
+	}
+}
+
+func TestParseEventTagFilterSplitsCommasAndRepeats(t *testing.T) {
+	got, err := parseEventTagFilter([]string{"CRW,freefly", "students"})
+	if err != nil {
+		t.Fatalf("parseEventTagFilter() returned error: %v", err)
+	}
+	if want := []string{"crw", "freefly", "students"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseEventTagFilter() = %v, want %v", got, want)
+	}
+	if got, err := parseEventTagFilter([]string{" , "}); err != nil || got != nil {
+		t.Fatalf("parseEventTagFilter(blank) = %v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestListEventTagsWritesCountsInQueryOrder(t *testing.T) {
+	serve := func(db *fakeDB) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		NewHandler(db).listEventTags(rec, httptest.NewRequest(http.MethodGet, "/events/tags", nil))
+		return rec
+	}
+
+	rec := serve(&fakeDB{results: []fakeResult{{match: "unnest(tags)", rows: [][]any{{"crw", 3}, {"students", 1}}}}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("listEventTags() status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var got []eventTagCount
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode tags failed: %v", err)
+	}
+	if want := []eventTagCount{{Tag: "crw", EventCount: 3}, {Tag: "students", EventCount: 1}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("listEventTags() = %v, want %v", got, want)
+	}
+
+	if rec := serve(&fakeDB{}); rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Fatalf("listEventTags(no tags) = %d %s, want 200 []", rec.Code, rec.Body.String())
+	}
+	if rec := serve(&fakeDB{results: []fakeResult{{match: "unnest(tags)", rows: [][]any{{"crw", "three"}}}}}); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("listEventTags(bad row) status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestArchivedRelationsListParentsBeforeChildren(t *testing.T) {
+	seen := map[string]bool{"events": true}
+	for _, rel := range archivedRelations {
+		if seen[rel.table] {
+			t.Fatalf("archivedRelations lists %s twice", rel.table)
+		}
+		if idx := strings.Index(rel.where, "FROM "); idx >= 0 {
+			parent := strings.Fields(rel.where[idx+len("FROM "):])[0]
+			if !seen[parent] {
+				t.Fatalf("archivedRelations lists %s before its parent %s", rel.table, parent)
+			}
+		}
+		seen[rel.table] = true
+	}
+}
+
+func TestListEventsReadsArchiveOnlyWhenRequested(t *testing.T) {
+	for _, tt := range []struct {
+		query       string
+		wantArchive bool
+	}{
+		{query: "", wantArchive: false},
+		{query: "?include_archived=true", wantArchive: true},
+	} {
+		db := &fakeDB{}
+		rec := httptest.NewRecorder()
+		NewHandler(db).listEvents(rec, httptest.NewRequest(http.MethodGet, "/events"+tt.query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("listEvents(%q) status = %d body=%s", tt.query, rec.Code, rec.Body.String())
+		}
+		if got := strings.Contains(db.calls[0], "events_archive"); got != tt.wantArchive {
+			t.Fatalf("listEvents(%q) reads archive = %t, want %t", tt.query, got, tt.wantArchive)
+		}
+	}
+}
+
+func TestArchiveEventsRejectsNonPositiveAge(t *testing.T) {
+	db := &fakeDB{}
+	rec := httptest.NewRecorder()
+	NewHandler(db).archiveEvents(rec, httptest.NewRequest(http.MethodPost, "/events/archive", strings.NewReader(`{"older_than_days":0}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("archiveEvents() status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if len(db.calls) != 0 {
+		t.Fatalf("archiveEvents() issued database calls for invalid payload: %v", db.calls)
+	}
+}
+
+func TestRestoreEventRejectsInvalidIDWithoutTouchingTheDatabase(t *testing.T) {
+	for _, id := range []string{"0", "-3", "abc"} {
+		db := &fakeDB{}
+		router := chi.NewRouter()
+		router.Post("/events/{eventID}/restore", NewHandler(db).restoreEvent)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/events/"+id+"/restore", nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("restoreEvent(%q) status = %d, want %d", id, rec.Code, http.StatusBadRequest)
+		}
+		if len(db.calls) != 0 {
+			t.Fatalf("restoreEvent(%q) issued database calls: %v", id, db.calls)
+		}
+	}
+}
+
+func TestListEventsAddsSearchClauseOnlyForQuery(t *testing.T) {
+	for _, tt := range []struct {
+		query      string
+		wantSearch bool
+	}{
+		{query: "", wantSearch: false},
+		{query: "?q=%20%20", wantSearch: false},
+		{query: "?q=voss", wantSearch: true},
+	} {
+		db := &fakeDB{}
+		rec := httptest.NewRecorder()
+		NewHandler(db).listEvents(rec, httptest.NewRequest(http.MethodGet, "/events"+tt.query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("listEvents(%q) status = %d body=%s", tt.query, rec.Code, rec.Body.String())
+		}
+		if got := strings.Contains(db.calls[0], "ILIKE"); got != tt.wantSearch {
+			t.Fatalf("listEvents(%q) searches = %t, want %t", tt.query, got, tt.wantSearch)
+		}
+	}
+}
+
+func TestParseEventFieldsValidatesNamesAndKeepsID(t *testing.T) {
+	fields, err := parseEventFields(" Name , starts_at,")
+	if err != nil {
+		t.Fatalf("parseEventFields() returned error: %v", err)
+	}
+	if want := (eventFieldSelection{"id": true, "name": true, "starts_at": true}); !reflect.DeepEqual(fields, want) {
+		t.Fatalf("parseEventFields() = %v, want %v", fields, want)
+	}
+	if fields.relations(allEventIncludes()).any() {
+		t.Fatal("scalar fields should not load relations")
+	}
+	if withInnhopps, _ := parseEventFields("name,innhopps"); !reflect.DeepEqual(withInnhopps.relations(eventIncludes{}), eventIncludes{"innhopps": true}) {
+		t.Fatal("innhopps field should load innhopps even when not included")
+	}
+	if _, err := parseEventFields("name,secret"); err == nil {
+		t.Fatal("parseEventFields() expected error for unknown field")
+	}
+	if none, err := parseEventFields(""); none != nil || err != nil || !reflect.DeepEqual(none.relations(allEventIncludes()), allEventIncludes()) {
+		t.Fatalf("parseEventFields(\"\") = %v, %v; want full event", none, err)
+	}
+}
+
+func TestEventFieldSelectionProjectsAllDayDates(t *testing.T) {
+	fields, _ := parseEventFields("name,starts_at")
+	projected, err := fields.project(Event{
+		ID:       4,
+		Name:     "Fjord Boogie",
+		AllDay:   true,
+		StartsAt: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC),
+		Innhopps: []Innhopp{{ID: 1}},
+	})
+	if err != nil {
+		t.Fatalf("project() returned error: %v", err)
+	}
+	encoded, _ := json.Marshal(projected)
+	if want := `{"id":4,"name":"Fjord Boogie","starts_at":"2025-07-01"}`; string(encoded) != want {
+		t.Fatalf("project() = %s, want %s", encoded, want)
+	}
+}
+
+func TestListEventsSkipsRelationsForScalarFields(t *testing.T) {
+	db := &fakeDB{results: []fakeResult{
+		{match: "FROM (SELECT events.*", rows: [][]any{{
+			int64(1), int64(2), "Voss", "Voss", (*int64)(nil), "", "planned", false, []string{}, time.Now().Add(time.Hour), (*time.Time)(nil), 10,
+			"", false, (*time.Time)(nil), (*time.Time)(nil), (*float64)(nil), (*float64)(nil), "EUR",
+			0, "draft", "", "", false, time.Now(), 1,
+		}}},
+	}}
+	rec := httptest.NewRecorder()
+	NewHandler(db).listEvents(rec, httptest.NewRequest(http.MethodGet, "/events?fields=name", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("listEvents() status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	if len(db.calls) != 1 {
+		t.Fatalf("listEvents() issued %d queries, want only the event query: %v", len(db.calls), db.calls)
+	}
+	if want := `{"items":[{"id":1,"name":"Voss"}],"total":1,"limit":50,"offset":0}`; strings.TrimSpace(rec.Body.String()) != want {
+		t.Fatalf("listEvents() body = %s, want %s", rec.Body.String(), want)
+	}
+}
+
+func TestListEventsEnvelopeUsesTheSharedPageShape(t *testing.T) {
+	row := []any{
+		int64(1), int64(2), "Voss", "Voss", (*int64)(nil), "", "planned", false, []string{}, time.Now().Add(time.Hour), (*time.Time)(nil), 10,
+		"", false, (*time.Time)(nil), (*time.Time)(nil), (*float64)(nil), (*float64)(nil), "EUR",
+		0, "draft", "", "", false, time.Now(), 3,
+	}
+	db := &fakeDB{results: []fakeResult{{match: "FROM (SELECT events.*", rows: [][]any{row}}}}
+	rec := httptest.NewRecorder()
+	NewHandler(db).listEvents(rec, httptest.NewRequest(http.MethodGet, "/events?fields=name&limit=1&envelope=true", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("listEvents() status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	if want := `{"data":[{"id":1,"name":"Voss"}],"page":{"limit":1,"offset":0,"total":3,"next_cursor":"1"}}`; strings.TrimSpace(rec.Body.String()) != want {
+		t.Fatalf("listEvents(envelope) body = %s, want %s", rec.Body.String(), want)
+	}
+	if got := rec.Header().Get("X-Total-Count"); got != "3" {
+		t.Fatalf("listEvents(envelope) X-Total-Count = %q, want 3", got)
+	}
+}
+
+func TestListEventsPaging(t *testing.T) {
+	db := &fakeDB{}
+	rec := httptest.NewRecorder()
+	NewHandler(db).listEvents(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+	if len(db.calls) != 1 || !strings.HasSuffix(db.calls[0], "LIMIT $2") {
+		t.Fatalf("listEvents() queries = %v, want the default limit", db.calls)
+	}
+
+	db = &fakeDB{results: []fakeResult{{match: "SELECT COUNT(*)", rows: [][]any{{73}}}}}
+	rec = httptest.NewRecorder()
+	NewHandler(db).listEvents(rec, httptest.NewRequest(http.MethodGet, "/events?limit=500&offset=5000", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("listEvents(offset past the end) status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	if want := `{"items":[],"total":73,"limit":200,"offset":5000}`; strings.TrimSpace(rec.Body.String()) != want {
+		t.Fatalf("listEvents(offset past the end) body = %s, want %s", rec.Body.String(), want)
+	}
+	if len(db.calls) != 2 || !strings.Contains(db.calls[0], "LIMIT $2 OFFSET $3") || strings.Contains(db.calls[1], "LIMIT") {
+		t.Fatalf("listEvents() queries = %v, want the paged event query and an unpaged count", db.calls)
+	}
+
+	for _, query := range []string{"limit=-1", "limit=ten", "offset=-5"} {
+		db := &fakeDB{}
+		rec := httptest.NewRecorder()
+		NewHandler(db).listEvents(rec, httptest.NewRequest(http.MethodGet, "/events?"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("listEvents(%s) status = %d, want %d", query, rec.Code, http.StatusBadRequest)
+		}
+		if len(db.calls) != 0 {
+			t.Fatalf("listEvents(%s) queried the database: %v", query, db.calls)
+		}
+	}
+}
+
+func TestListManifestsFiltersOrdersAndPages(t *testing.T) {
+	db := &fakeDB{results: []fakeResult{{match: "SELECT COUNT(*) FROM manifests", rows: [][]any{{12}}}}}
+	rec := httptest.NewRecorder()
+	NewHandler(db).listManifests(rec, httptest.NewRequest(http.MethodGet, "/manifests?event_id=9&order=desc&limit=25&offset=50", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("listManifests() status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	if strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Fatalf("listManifests() body = %s, want []", rec.Body.String())
+	}
+	query := db.calls[0]
+	for _, want := range []string{"WHERE event_id = $1", "ORDER BY load_number DESC, id DESC LIMIT $2 OFFSET $3"} {
+		if !strings.Contains(query, want) {
+			t.Fatalf("listManifests() query = %s, want %q", query, want)
+		}
+	}
+	// A page past the end has no row to carry the window count.
+	if got := rec.Header().Get("X-Total-Count"); got != "12" {
+		t.Fatalf("listManifests() X-Total-Count = %q past the end, want the counted 12", got)
+	}
+	if len(db.calls) != 2 || !strings.HasSuffix(db.calls[1], "FROM manifests WHERE event_id = $1") {
+		t.Fatalf("listManifests() queries = %v, want the page and an unpaged count", db.calls)
+	}
+
+	db = &fakeDB{}
+	NewHandler(db).listManifests(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/manifests", nil))
+	if !strings.HasSuffix(db.calls[0], "ORDER BY load_number ASC, id ASC") {
+		t.Fatalf("listManifests() default query = %s, want ascending and unpaged", db.calls[0])
+	}
+
+	for _, query := range []string{"order=newest", "event_id=abc", "limit=0"} {
+		db := &fakeDB{}
+		rec := httptest.NewRecorder()
+		NewHandler(db).listManifests(rec, httptest.NewRequest(http.MethodGet, "/manifests?"+query, nil))
+		if rec.Code != http.StatusBadRequest || len(db.calls) != 0 {
+			t.Fatalf("listManifests(%s) status = %d with %d queries, want 400 and none", query, rec.Code, len(db.calls))
+		}
+	}
+}
+
+func TestListAirfieldsPages(t *testing.T) {
+	db := &fakeDB{results: []fakeResult{{match: "SELECT COUNT(*) FROM airfields", rows: [][]any{{3}}}}}
+	rec := httptest.NewRecorder()
+	NewHandler(db).listAirfields(rec, httptest.NewRequest(http.MethodGet, "/airfields?limit=10&offset=20", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("listAirfields() status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(db.calls[0], "ORDER BY lower(name), id LIMIT $1 OFFSET $2") {
+		t.Fatalf("listAirfields() query = %s, want it paged", db.calls[0])
+	}
+	if got := rec.Header().Get("X-Total-Count"); got != "3" {
+		t.Fatalf("listAirfields() X-Total-Count = %q, want 3", got)
+	}
+
+	db = &fakeDB{}
+	rec = httptest.NewRecorder()
+	NewHandler(db).listAirfields(rec, httptest.NewRequest(http.MethodGet, "/airfields?limit=ten", nil))
+	if rec.Code != http.StatusBadRequest || len(db.calls) != 0 {
+		t.Fatalf("listAirfields(limit=ten) status = %d with %d queries, want 400 and none", rec.Code, len(db.calls))
+	}
+}
+
+func TestListEventsBreaksStartTimeTiesByID(t *testing.T) {
+	startsAt := time.Now().Add(time.Hour)
+	row := func(id int64) []any {
+		return []any{
+			id, int64(2), "Voss", "Voss", (*int64)(nil), "", "planned", false, []string{}, startsAt, (*time.Time)(nil), 10,
+			"", false, (*time.Time)(nil), (*time.Time)(nil), (*float64)(nil), (*float64)(nil), "EUR",
+			0, "draft", "", "", false, startsAt, 2,
+		}
+	}
+	db := &fakeDB{results: []fakeResult{{match: "FROM (SELECT events.*", rows: [][]any{row(9), row(4)}}}}
+	rec := httptest.NewRecorder()
+	NewHandler(db).listEvents(rec, httptest.NewRequest(http.MethodGet, "/events?fields=id", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("listEvents() status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	// The fake returns rows as given, so only the query can show the order;
+	// TestListEventsOrdersStartTimeTiesByID checks it against a database.
+	if !strings.HasSuffix(db.calls[0], "ORDER BY starts_at DESC, id DESC LIMIT $2") {
+		t.Fatalf("listEvents() query does not end ordered by start time, then id: %s", db.calls[0])
+	}
+}
+
+func TestParseEventIncludes(t *testing.T) {
+	fallback := eventIncludes{"innhopps": true}
+	if got, err := parseEventIncludes("", fallback); err != nil || !reflect.DeepEqual(got, fallback) {
+		t.Fatalf("parseEventIncludes(\"\") = %v, %v; want fallback", got, err)
+	}
+	if got, _ := parseEventIncludes("Participants, airfields", fallback); !reflect.DeepEqual(got, eventIncludes{"participants": true, "airfields": true}) {
+		t.Fatalf("parseEventIncludes(list) = %v", got)
+	}
+	if got, _ := parseEventIncludes("all", nil); !reflect.DeepEqual(got, allEventIncludes()) {
+		t.Fatalf("parseEventIncludes(all) = %v", got)
+	}
+	if got, _ := parseEventIncludes("none", fallback); got.any() {
+		t.Fatalf("parseEventIncludes(none) = %v", got)
+	}
+	if _, err := parseEventIncludes("innhopps,payments", nil); err == nil {
+		t.Fatal("parseEventIncludes() expected error for unknown relation")
+	}
+}
+
+func TestGetEventRedactsNotesForParticipants(t *testing.T) {
+	row := []any{
+		int64(1), int64(2), "Voss", "Voss", (*int64)(nil), "", "planned", false, []string{}, time.Now().Add(time.Hour), (*time.Time)(nil), 10,
+		"", false, (*time.Time)(nil), (*time.Time)(nil), (*float64)(nil), (*float64)(nil), "EUR",
+		0, "draft", "Weather call at 07:00", "Pilot prefers the north strip", time.Now(),
+	}
+	for _, tc := range []struct {
+		role      rbac.Role
+		wantNotes string
+	}{
+		{rbac.RoleStaff, "Pilot prefers the north strip"},
+		{rbac.RoleParticipant, ""},
+	} {
+		db := &fakeDB{results: []fakeResult{{match: "WHERE id = $1", rows: [][]any{row}}}}
+		h := NewHandler(db)
+		role := tc.role
+		router := h.Routes(rbac.NewEnforcer(func(*http.Request) []rbac.Role { return []rbac.Role{role} }))
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events/1?fields=briefing,notes", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: getEvent() status = %d body=%s", role, rec.Code, rec.Body.String())
+		}
+		var got Event
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatalf("%s: decode event failed: %v", role, err)
+		}
+		if got.Briefing != "Weather call at 07:00" || got.Notes != tc.wantNotes {
+			t.Fatalf("%s: briefing/notes = %q/%q, want briefing and notes %q", role, got.Briefing, got.Notes, tc.wantNotes)
+		}
+	}
+}
+
+func TestGenerateManifestsValidatesBeforeTouchingDB(t *testing.T) {
+	db := &fakeDB{}
+	router := chi.NewRouter()
+	router.Post("/events/{eventID}/manifests/generate", NewHandler(db).generateManifests)
+
+	for _, body := range []string{
+		`{"count":0}`,
+		`{"count":51}`,
+		`{"count":3,"start_load_number":0}`,
+		`{"count":3,"capacity":-1}`,
+		`{"count":3,"scheduled_at":"2025-06-01T09:00:00Z"}`,
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/events/7/manifests/generate", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("generateManifests(%s) status = %d, want %d", body, rec.Code, http.StatusBadRequest)
+		}
+	}
+	if len(db.calls) != 0 {
+		t.Fatalf("generateManifests() touched the database on invalid input: %v", db.calls)
+	}
+}
+
+func TestListEventsSeasonFilterMatchesLinkedSeasons(t *testing.T) {
+	db := &fakeDB{}
+	rec := httptest.NewRecorder()
+	NewHandler(db).listEvents(rec, httptest.NewRequest(http.MethodGet, "/events?season_id=4", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("listEvents() status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(db.calls[0], "season_id = $2 OR EXISTS (SELECT 1 FROM event_seasons") {
+		t.Fatalf("listEvents() query does not match linked seasons: %s", db.calls[0])
+	}
+
+	db = &fakeDB{}
+	rec = httptest.NewRecorder()
+	NewHandler(db).listEvents(rec, httptest.NewRequest(http.MethodGet, "/events?season_id=abc", nil))
+	if rec.Code != http.StatusBadRequest || len(db.calls) != 0 {
+		t.Fatalf("listEvents(season_id=abc) status = %d with %d queries, want 400 and none", rec.Code, len(db.calls))
+	}
+}
+
+func TestListEventsFiltersByStatus(t *testing.T) {
+	got, err := parseEventStatusFilter([]string{"live, Launched", "live", "past"})
+	if err != nil || !reflect.DeepEqual(got, []string{"live", "launched", "past"}) {
+		t.Fatalf("parseEventStatusFilter() = %v, %v; want [live launched past]", got, err)
+	}
+	if got, err := parseEventStatusFilter(nil); err != nil || got != nil {
+		t.Fatalf("parseEventStatusFilter(nil) = %v, %v; want no filter", got, err)
+	}
+
+	db := &fakeDB{}
+	rec := httptest.NewRecorder()
+	NewHandler(db).listEvents(rec, httptest.NewRequest(http.MethodGet, "/events?season_id=4&status=live,launched", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("listEvents() status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(db.calls[0], "AND status = ANY($3::text[])") {
+		t.Fatalf("listEvents() query does not filter by status: %s", db.calls[0])
+	}
+
+	db = &fakeDB{}
+	rec = httptest.NewRecorder()
+	NewHandler(db).listEvents(rec, httptest.NewRequest(http.MethodGet, "/events?status=live&status=flying", nil))
+	if rec.Code != http.StatusBadRequest || len(db.calls) != 0 {
+		t.Fatalf("listEvents(status=flying) status = %d with %d queries, want 400 and none", rec.Code, len(db.calls))
+	}
+}
+
+func TestDeleteSeasonRefusesSeasonsWithEvents(t *testing.T) {
+	serve := func(db *fakeDB) *httptest.ResponseRecorder {
+		router := chi.NewRouter()
+		router.Delete("/seasons/{seasonID}", NewHandler(db).deleteSeason)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/seasons/3", nil))
+		return rec
+	}
+
+	const deleteSQL = "DELETE FROM seasons s"
+	const countsSQL = "SELECT EXISTS (SELECT 1 FROM seasons"
+	blocked := &fakeDB{
+		results: []fakeResult{{match: countsSQL, rows: [][]any{{true, 4, 0}}}},
+		execs:   []fakeExec{{match: deleteSQL, tag: "DELETE 0"}},
+	}
+	rec := serve(blocked)
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "season has 4 events") {
+		t.Fatalf("deleteSeason(with events) = %d %s, want 409 naming 4 events", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(blocked.calls[0], "NOT EXISTS (SELECT 1 FROM events WHERE season_id = $1)") ||
+		!strings.Contains(blocked.calls[0], "NOT EXISTS (SELECT 1 FROM events_archive WHERE season_id = $1)") {
+		t.Fatalf("delete does not guard against events in the same statement: %s", blocked.calls[0])
+	}
+
+	archived := &fakeDB{
+		results: []fakeResult{{match: countsSQL, rows: [][]any{{true, 0, 2}}}},
+		execs:   []fakeExec{{match: deleteSQL, tag: "DELETE 0"}},
+	}
+	if rec := serve(archived); rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "2 archived events") {
+		t.Fatalf("deleteSeason(with archived events) = %d %s, want 409 naming 2 archived events", rec.Code, rec.Body.String())
+	}
+
+	empty := &fakeDB{execs: []fakeExec{{match: deleteSQL, tag: "DELETE 1"}}}
+	if rec := serve(empty); rec.Code != http.StatusNoContent || len(empty.calls) != 1 {
+		t.Fatalf("deleteSeason(empty) = %d %s after %d calls, want 204 after 1", rec.Code, rec.Body.String(), len(empty.calls))
+	}
+
+	missing := &fakeDB{
+		results: []fakeResult{{match: countsSQL, rows: [][]any{{false, 0, 0}}}},
+		execs:   []fakeExec{{match: deleteSQL, tag: "DELETE 0"}},
+	}
+	if rec := serve(missing); rec.Code != http.StatusNotFound {
+		t.Fatalf("deleteSeason(missing) = %d %s, want 404", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCheckLoadNumber(t *testing.T) {
+	taken := &fakeDB{results: []fakeResult{{match: "AND load_number = $2", rows: [][]any{{true}}}}}
+	rec := httptest.NewRecorder()
+	if checkLoadNumber(context.Background(), rec, taken, 7, 3, 0) {
+		t.Fatal("checkLoadNumber() = true for a taken load number")
+	}
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "load number 3 already exists for this event") {
+		t.Fatalf("checkLoadNumber() wrote %d %s, want 409 naming load 3", rec.Code, rec.Body.String())
+	}
+
+	free := &fakeDB{results: []fakeResult{{match: "AND load_number = $2", rows: [][]any{{false}}}}}
+	rec = httptest.NewRecorder()
+	if !checkLoadNumber(context.Background(), rec, free, 7, 3, 12) {
+		t.Fatalf("checkLoadNumber() = false for a free load number: %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGetManifestCapacity(t *testing.T) {
+	serve := func(db *fakeDB) *httptest.ResponseRecorder {
+		router := chi.NewRouter()
+		router.Get("/manifests/{manifestID}/capacity", NewHandler(db).getManifestCapacity)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/manifests/5/capacity", nil))
+		return rec
+	}
+
+	slots := 4
+	limited := serve(&fakeDB{results: []fakeResult{{match: "FROM manifests m", rows: [][]any{{&slots, 3}}}}})
+	if limited.Code != http.StatusOK || strings.TrimSpace(limited.Body.String()) != `{"manifest_id":5,"staff_slots":4,"assigned":3,"remaining":1}` {
+		t.Fatalf("getManifestCapacity() = %d %s", limited.Code, limited.Body.String())
+	}
+	unlimited := serve(&fakeDB{results: []fakeResult{{match: "FROM manifests m", rows: [][]any{{nil, 3}}}}})
+	if unlimited.Code != http.StatusOK || strings.TrimSpace(unlimited.Body.String()) != `{"manifest_id":5,"staff_slots":null,"assigned":3,"remaining":null}` {
+		t.Fatalf("getManifestCapacity(no limit) = %d %s", unlimited.Code, unlimited.Body.String())
+	}
+	if missing := serve(&fakeDB{}); missing.Code != http.StatusNotFound {
+		t.Fatalf("getManifestCapacity(missing) = %d, want 404", missing.Code)
+	}
+}
+
+func TestListEventStatusCounts(t *testing.T) {
+	db := &fakeDB{results: []fakeResult{{match: "GROUP BY status", rows: [][]any{{"live", 2}, {"draft", 5}}}}}
+	rec := httptest.NewRecorder()
+	NewHandler(db).listEventStatusCounts(rec, httptest.NewRequest(http.MethodGet, "/events/status-counts?season_id=3", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d %s, want 200", rec.Code, rec.Body.String())
+	}
+	want := `{"draft":5,"launched":0,"live":2,"past":0,"planned":0,"scouted":0}`
+	if got := strings.TrimSpace(rec.Body.String()); got != want {
+		t.Fatalf("body = %s, want %s", got, want)
+	}
+	if len(db.calls) != 1 || !strings.Contains(db.calls[0], "es.season_id = $1") {
+		t.Fatalf("calls = %v, want one query filtered by season", db.calls)
+	}
+
+	rec = httptest.NewRecorder()
+	NewHandler(&fakeDB{}).listEventStatusCounts(rec, httptest.NewRequest(http.MethodGet, "/events/status-counts?season_id=x", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400 for a bad season_id", rec.Code)
+	}
+}
+
+func TestDeleteManifest(t *testing.T) {
+	serve := func(db *fakeDB, id string) *httptest.ResponseRecorder {
+		router := chi.NewRouter()
+		router.Delete("/manifests/{manifestID}", NewHandler(db).deleteManifest)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/manifests/"+id, nil))
+		return rec
+	}
+
+	deleted := &fakeDB{execs: []fakeExec{{match: "DELETE FROM manifests", tag: "DELETE 1"}}}
+	if rec := serve(deleted, "9"); rec.Code != http.StatusNoContent {
+		t.Fatalf("deleteManifest() = %d %s, want 204", rec.Code, rec.Body.String())
+	}
+	missing := &fakeDB{execs: []fakeExec{{match: "DELETE FROM manifests", tag: "DELETE 0"}}}
+	if rec := serve(missing, "9"); rec.Code != http.StatusNotFound {
+		t.Fatalf("deleteManifest(missing) = %d %s, want 404", rec.Code, rec.Body.String())
+	}
+	if rec := serve(&fakeDB{}, "0"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("deleteManifest(0) = %d %s, want 400", rec.Code, rec.Body.String())
+	}
+}
+
+func TestNormalizeLinkedSeasonIDsDropsPrimarySeason(t *testing.T) {
+	got, err := normalizeLinkedSeasonIDs([]int64{7, 3, 5, 3}, 5)
+	if err != nil || !reflect.DeepEqual(got, []int64{3, 7}) {
+		t.Fatalf("normalizeLinkedSeasonIDs() = %v, %v; want [3 7]", got, err)
+	}
+	if _, err := normalizeLinkedSeasonIDs([]int64{0}, 5); err == nil {
+		t.Fatal("normalizeLinkedSeasonIDs() expected error for non-positive id")
+	}
+}
+
+func TestGetSeasonStatsAggregatesRolesAndLeaderboard(t *testing.T) {
+	db := &fakeDB{results: []fakeResult{
+		{match: "FROM seasons s", rows: [][]any{{"2025", 4, 12}}},
+		{match: "GROUP BY ca.role", rows: [][]any{{"jumpmaster", 9, 3}, {"pilot", 5, 2}}},
+		{match: "GROUP BY p.id", rows: [][]any{{int64(8), "Ada", 7, 6, []string{"jumpmaster", "pilot"}}}},
+	}}
+	router := chi.NewRouter()
+	router.Get("/seasons/{seasonID}/stats", NewHandler(db).getSeasonStats)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/seasons/3/stats?top=5", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("getSeasonStats() status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	if len(db.calls) != 3 {
+		t.Fatalf("getSeasonStats() issued %d queries, want 3", len(db.calls))
+	}
+	var stats SeasonStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("decode stats: %v", err)
+	}
+	if stats.LoadCount != 12 || stats.AssignmentCount != 14 || len(stats.AssignmentsByRole) != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if len(stats.TopCrew) != 1 || stats.TopCrew[0].FullName != "Ada" || stats.TopCrew[0].LoadCount != 6 {
+		t.Fatalf("unexpected leaderboard: %+v", stats.TopCrew)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/seasons/3/stats?top=500", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("getSeasonStats(top=500) status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGetSeasonStatsFiltersByDateRange(t *testing.T) {
+	db := &fakeDB{results: []fakeResult{{match: "FROM seasons s", rows: [][]any{{"2025", 1, 2}}}}}
+	router := chi.NewRouter()
+	router.Get("/seasons/{seasonID}/stats", NewHandler(db).getSeasonStats)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/seasons/3/stats?from=2025-06-01&to=2025-06-30", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("getSeasonStats(from, to) status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	var stats SeasonStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("decode stats: %v", err)
+	}
+	if stats.From != "2025-06-01" || stats.To != "2025-06-30" {
+		t.Fatalf("range = %q..%q, want 2025-06-01..2025-06-30", stats.From, stats.To)
+	}
+	if !strings.Contains(db.calls[0], "e.starts_at < $3") {
+		t.Fatalf("stats query does not bound starts_at: %s", db.calls[0])
+	}
+
+	for _, target := range []string{"/seasons/3/stats?from=June", "/seasons/3/stats?from=2025-06-30&to=2025-06-01"} {
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("GET %s status = %d, want %d", target, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestResolveInnhoppSequenceAppendsOrShiftsOnCollision(t *testing.T) {
+	ctx := context.Background()
+	db := &fakeDB{results: []fakeResult{{match: "MAX(sequence)", rows: [][]any{{5}}}}}
+	if got, err := resolveInnhoppSequence(ctx, db, 3, nil); err != nil || got != 5 {
+		t.Fatalf("resolveInnhoppSequence(nil) = %d, %v; want 5", got, err)
+	}
+
+	requested := 2
+	db = &fakeDB{results: []fakeResult{{match: "sequence = $2", rows: [][]any{{false}}}}}
+	if got, err := resolveInnhoppSequence(ctx, db, 3, &requested); err != nil || got != 2 || len(db.calls) != 1 {
+		t.Fatalf("resolveInnhoppSequence(free 2) = %d, %v with calls %v; want 2 without shifting", got, err, db.calls)
+	}
+
+	db = &fakeDB{results: []fakeResult{{match: "sequence = $2", rows: [][]any{{true}}}}}
+	_, _ = resolveInnhoppSequence(ctx, db, 3, &requested)
+	if len(db.calls) != 2 || !strings.Contains(db.calls[1], "SET sequence = sequence + 1") {
+		t.Fatalf("resolveInnhoppSequence(taken 2) did not shift later innhopps: %v", db.calls)
+	}
+}
+
+func TestGetNextInnhoppSequence(t *testing.T) {
+	for _, tc := range []struct {
+		rows   [][]any
+		status int
+		body   string
+	}{
+		{nil, http.StatusNotFound, "event not found"},
+		{[][]any{{1}}, http.StatusOK, `{"event_id":3,"next_sequence":1}`},
+		{[][]any{{6}}, http.StatusOK, `{"event_id":3,"next_sequence":6}`},
+	} {
+		db := &fakeDB{results: []fakeResult{{match: "MAX(i.sequence)", rows: tc.rows}}}
+		router := chi.NewRouter()
+		router.Get("/events/{eventID}/innhopps/next-sequence", NewHandler(db).getNextInnhoppSequence)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events/3/innhopps/next-sequence", nil))
+		if rec.Code != tc.status || !strings.Contains(rec.Body.String(), tc.body) {
+			t.Fatalf("getNextInnhoppSequence() = %d %s, want %d %s", rec.Code, rec.Body.String(), tc.status, tc.body)
+		}
+	}
+}
+
+func TestListAccommodationsDistinguishesMissingEventFromEmptyList(t *testing.T) {
+	for _, tc := range []struct {
+		exists bool
+		status int
+		body   string
+	}{
+		{false, http.StatusNotFound, "event not found"},
+		{true, http.StatusOK, "[]"},
+	} {
+		db := &fakeDB{results: []fakeResult{{match: "SELECT EXISTS", rows: [][]any{{tc.exists}}}}}
+		router := chi.NewRouter()
+		router.Get("/events/{eventID}/accommodations", NewHandler(db).listAccommodations)
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events/7/accommodations", nil))
+		if rec.Code != tc.status || !strings.Contains(rec.Body.String(), tc.body) {
+			t.Fatalf("listAccommodations(exists=%v) = %d %s, want %d %s", tc.exists, rec.Code, rec.Body.String(), tc.status, tc.body)
+		}
+	}
+}
+
+func TestFindGearConflictOnlyCountsOpenCheckouts(t *testing.T) {
+	ctx := context.Background()
+	startsAt := time.Date(2025, 7, 1, 8, 0, 0, 0, time.UTC)
+	windowEnd := startsAt.Add(48 * time.Hour)
+
+	db := &fakeDB{}
+	conflict, err := findGearConflict(ctx, db, 4, windowEnd)
+	if err != nil || conflict != nil {
+		t.Fatalf("findGearConflict(free) = %+v, %v; want nil", conflict, err)
+	}
+	if !strings.Contains(db.calls[0], "c.returned_at IS NULL") || !strings.Contains(db.calls[0], "e.starts_at <= $2") {
+		t.Fatalf("conflict query does not limit to open, earlier checkouts: %s", db.calls[0])
+	}
+
+	db = &fakeDB{results: []fakeResult{{match: "FROM event_gear_checkouts c", rows: [][]any{{int64(12), "Voss Boogie", startsAt, nil}}}}}
+	conflict, err = findGearConflict(ctx, db, 4, windowEnd)
+	if err != nil || conflict == nil || conflict.ID != 12 || conflict.Name != "Voss Boogie" {
+		t.Fatalf("findGearConflict(held) = %+v, %v; want event 12", conflict, err)
+	}
+}
+
+func TestInnhoppRequirementsApplyFromTheirStatusOnwards(t *testing.T) {
+	policy, err := parseInnhoppRequirements("launched=risk_assessment+primary_landing_area, live=hospital")
+	if err != nil {
+		t.Fatalf("parseInnhoppRequirements() returned error: %v", err)
+	}
+	for status, want := range map[string]string{
+		"draft":    "",
+		"planned":  "",
+		"launched": "primary_landing_area,risk_assessment",
+		"scouted":  "primary_landing_area,risk_assessment",
+		"live":     "hospital,primary_landing_area,risk_assessment",
+	} {
+		if got := strings.Join(policy.requiredFor(status), ","); got != want {
+			t.Errorf("requiredFor(%q) = %q, want %q", status, got, want)
+		}
+	}
+
+	for _, raw := range []string{"launched", "flying=hospital", "live=parachute"} {
+		if _, err := parseInnhoppRequirements(raw); err == nil {
+			t.Errorf("parseInnhoppRequirements(%q) accepted an invalid policy", raw)
+		}
+	}
+	if none, err := parseInnhoppRequirements("none"); err != nil || len(none.requiredFor("live")) != 0 {
+		t.Fatalf("parseInnhoppRequirements(none) = %v, %v; want no requirements", none, err)
+	}
+}
+
+func TestMissingInnhoppFieldsListsEachInnhoppsGaps(t *testing.T) {
+	fields := defaultInnhoppRequirements().requiredFor("live")
+	innhopps := []Innhopp{
+		{ID: 1, Sequence: 1, Name: "Fjord", RiskAssessment: "Low", PrimaryLandingArea: LandingArea{Name: "Beach"}},
+		{ID: 2, Sequence: 2, Name: "Glacier", RiskAssessment: "  "},
+		{ID: 3, Sequence: 3, Name: "Farm", PrimaryLandingArea: LandingArea{Name: "Field"}},
+	}
+	gaps := missingInnhoppFields(innhopps, fields)
+	if len(gaps) != 2 {
+		t.Fatalf("missingInnhoppFields() = %+v, want 2 gaps", gaps)
+	}
+	if gaps[0].InnhoppID != 2 || strings.Join(gaps[0].Missing, ",") != "primary_landing_area,risk_assessment" {
+		t.Fatalf("gap[0] = %+v, want innhopp 2 missing both fields", gaps[0])
+	}
+	if gaps[1].InnhoppID != 3 || strings.Join(gaps[1].Missing, ",") != "risk_assessment" {
+		t.Fatalf("gap[1] = %+v, want innhopp 3 missing risk_assessment", gaps[1])
+	}
+}
+
+func TestPreflightEventRejectsUnknownStatus(t *testing.T) {
+	db := &fakeDB{}
+	router := chi.NewRouter()
+	router.Get("/events/{eventID}/preflight", NewHandler(db).preflightEvent)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events/7/preflight?status=flying", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("preflightEvent(status=flying) status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if len(db.calls) != 0 {
+		t.Fatalf("preflightEvent() touched the database on invalid input: %v", db.calls)
+	}
+}
+
+func TestMinimumCrewGatesOnlyTheMoveToLive(t *testing.T) {
+	db := &fakeDB{results: []fakeResult{{match: "FROM crew_assignments", rows: [][]any{{2}}}}}
+	h := &Handler{db: db, minimumLiveCrew: 5}
+
+	if shortfall, err := h.checkMinimumCrew(context.Background(), db, 7, "scouted"); err != nil || shortfall != nil {
+		t.Fatalf("checkMinimumCrew(scouted) = %+v, %v; want no check", shortfall, err)
+	}
+	if len(db.calls) != 0 {
+		t.Fatalf("checkMinimumCrew(scouted) queried the database: %v", db.calls)
+	}
+
+	blockers, err := h.checkStatusTransition(context.Background(), db, 7, "live")
+	if err != nil || blockers.Crew == nil {
+		t.Fatalf("checkStatusTransition(live) = %+v, %v; want a crew shortfall", blockers, err)
+	}
+	rec := httptest.NewRecorder()
+	if allowCrewShortfall(rec, httptest.NewRequest(http.MethodPut, "/events/7", nil), 7, blockers.Crew, false) {
+		t.Fatalf("allowCrewShortfall() let an event with 2 of 5 crew go live")
+	}
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	if !strings.Contains(rec.Body.String(), "at least 5 assigned crew to go live but has 2 (3 short)") {
+		t.Fatalf("body = %s, want the shortfall spelled out", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	if !allowCrewShortfall(rec, httptest.NewRequest(http.MethodPut, "/events/7", nil), 7, blockers.Crew, true) {
+		t.Fatalf("allowCrewShortfall(override) refused the move: %s", rec.Body.String())
+	}
+
+	h.minimumLiveCrew = 2
+	if shortfall, err := h.checkMinimumCrew(context.Background(), db, 7, "live"); err != nil || shortfall != nil {
+		t.Fatalf("checkMinimumCrew(2 of 2) = %+v, %v; want enough crew", shortfall, err)
+	}
+}
+
+func TestDeleteAirfieldRefusesAirfieldsUsedByInnhopps(t *testing.T) {
+	db := &fakeDB{results: []fakeResult{{match: "FROM event_innhopps", rows: [][]any{{2}}}}}
+	router := chi.NewRouter()
+	router.Delete("/airfields/{airfieldID}", NewHandler(db).deleteAirfield)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/airfields/4", nil))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if !strings.Contains(rec.Body.String(), "of 2 innhopps") {
+		t.Fatalf("body = %s, want the innhopp count", rec.Body.String())
+	}
+	for _, call := range db.calls {
+		if strings.Contains(call, "DELETE") {
+			t.Fatalf("airfield was deleted: %s", call)
+		}
+	}
+}
+
+func TestCreateAirfieldRefusesDuplicateName(t *testing.T) {
+	db := &fakeDB{results: []fakeResult{{match: "WHERE lower(name) = lower($1)", rows: [][]any{{int64(3)}}}}}
+	rec := httptest.NewRecorder()
+	body := strings.NewReader(`{"name":"  voss  ","coordinates":"60.63 6.5","elevation":50}`)
+	NewHandler(db).createAirfield(rec, httptest.NewRequest(http.MethodPost, "/airfields", body))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d %s, want %d", rec.Code, rec.Body.String(), http.StatusConflict)
+	}
+	if !strings.Contains(rec.Body.String(), `"airfield_id":3`) || !strings.Contains(rec.Body.String(), `\"voss\"`) {
+		t.Fatalf("body = %s, want the existing airfield id and trimmed name", rec.Body.String())
+	}
+	for _, call := range db.calls {
+		if strings.Contains(call, "INSERT") {
+			t.Fatalf("duplicate airfield was inserted: %s", call)
+		}
+	}
+}
+
+func TestMergeAirfieldValidatesSource(t *testing.T) {
+	for body, want := range map[string]string{
+		`{}`:              "source_id is required",
+		`{"source_id":4}`: "into itself",
+	} {
+		router := chi.NewRouter()
+		db := &fakeDB{}
+		router.Post("/airfields/{airfieldID}/merge", NewHandler(db).mergeAirfield)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/airfields/4/merge", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("mergeAirfield(%s) = %d %s, want 400 with %q", body, rec.Code, rec.Body.String(), want)
+		}
+		if len(db.calls) != 0 {
+			t.Errorf("mergeAirfield(%s) touched the database: %v", body, db.calls)
+		}
+	}
+}
+
+func TestAirfieldPayloadCanonicalCoordinates(t *testing.T) {
+	str := func(v string) *string { return &v }
+	for _, tc := range []struct {
+		name      string
+		payload   airfieldPayload
+		lat, lng  string
+		wantError error
+	}{
+		{name: "decimal", payload: airfieldPayload{Coordinates: " 60.391300, 5.3221 "}, lat: "60.3913", lng: "5.3221"},
+		{name: "dms", payload: airfieldPayload{Coordinates: `60°23'29"N 5°19'20"W`}, lat: "60.391389", lng: "-5.322222"},
+		{name: "latitude and longitude", payload: airfieldPayload{Latitude: str("-33.9"), Longitude: str("18.6")}, lat: "-33.9", lng: "18.6"},
+		{name: "agreeing triple", payload: airfieldPayload{Coordinates: "-33.9 18.6", Latitude: str("-33.9000001"), Longitude: str("18.6")}, lat: "-33.9", lng: "18.6"},
+		{name: "disagreeing triple", payload: airfieldPayload{Coordinates: "-33.9 18.6", Latitude: str("33.9"), Longitude: str("18.6")}, wantError: errAirfieldMismatch},
+		{name: "latitude out of range", payload: airfieldPayload{Coordinates: "91 5"}, wantError: errAirfieldCoordinates},
+		{name: "longitude out of range", payload: airfieldPayload{Latitude: str("60"), Longitude: str("181")}, wantError: errAirfieldCoordinates},
+		{name: "unreadable", payload: airfieldPayload{Coordinates: "somewhere near Voss"}, wantError: errAirfieldCoordinates},
+		{name: "latitude alone", payload: airfieldPayload{Coordinates: "60 5", Latitude: str("60")}, wantError: errAirfieldLatLongPair},
+	} {
+		lat, lng, err := tc.payload.canonicalCoordinates()
+		if err != tc.wantError {
+			t.Errorf("%s: error = %v, want %v", tc.name, err, tc.wantError)
+			continue
+		}
+		if lat != tc.lat || lng != tc.lng {
+			t.Errorf("%s: got %q %q, want %q %q", tc.name, lat, lng, tc.lat, tc.lng)
+		}
+	}
+}
+
+func TestParseLandingAreaSize(t *testing.T) {
+	for raw, want := range map[string]float64{
+		"50x200":         10000,
+		"50 x 200 m":     10000,
+		"50m by 200m":    10000,
+		"100ft x 100 ft": 929.0304,
+		"4000 m²":        4000,
+		"4,5 ha":         45000,
+		"10000 sq ft":    929.0304,
+		"1.5 x 2 metres": 3,
+	} {
+		got, ok := parseLandingAreaSize(raw)
+		if !ok || math.Abs(got-want) > 0.001 {
+			t.Errorf("parseLandingAreaSize(%q) = %v, %v; want %v", raw, got, ok, want)
+		}
+	}
+	for _, raw := range []string{"", "big", "football pitch", "about 50x200", "0 x 200", "200 yards"} {
+		if got, ok := parseLandingAreaSize(raw); ok {
+			t.Errorf("parseLandingAreaSize(%q) = %v, want unparsed", raw, got)
+		}
+	}
+}
+
+func TestSmallLandingAreaWarningsSkipUnparseableSizes(t *testing.T) {
+	tiers, err := parseLandingAreaMinimums("200=5000, 0=10000")
+	if err != nil {
+		t.Fatalf("parseLandingAreaMinimums() returned error: %v", err)
+	}
+	if got := tiers.forJumps(150); got != 10000 {
+		t.Fatalf("forJumps(150) = %v, want 10000", got)
+	}
+	if got := tiers.forJumps(800); got != 5000 {
+		t.Fatalf("forJumps(800) = %v, want 5000", got)
+	}
+
+	innhopps := []Innhopp{
+		{ID: 1, Name: "Beach", PrimaryLandingArea: LandingArea{Size: "100 x 120 m"}},
+		{ID: 2, Name: "Farm", PrimaryLandingArea: LandingArea{Size: "40 x 100 m"}},
+		{ID: 3, Name: "Glacier", PrimaryLandingArea: LandingArea{Size: "tight, see notes"}},
+	}
+	warnings := smallLandingAreaWarnings(innhopps, tiers.forJumps(150))
+	if len(warnings) != 1 || warnings[0].InnhoppID != 2 || warnings[0].Code != warningSmallLandingArea || warnings[0].AreaM2 != 4000 {
+		t.Fatalf("smallLandingAreaWarnings() = %+v, want only innhopp 2", warnings)
+	}
+	if warnings := smallLandingAreaWarnings(innhopps, tiers.forJumps(800)); len(warnings) != 1 {
+		t.Fatalf("smallLandingAreaWarnings(experienced) = %+v, want 1 warning", warnings)
+	}
+	if warnings := smallLandingAreaWarnings(innhopps, 0); len(warnings) != 0 {
+		t.Fatalf("smallLandingAreaWarnings(no minimum) = %+v, want none", warnings)
+	}
+}
+
+func TestListEventsFiltersByParticipantAndCrew(t *testing.T) {
+	db := &fakeDB{}
+	rec := httptest.NewRecorder()
+	NewHandler(db).listEvents(rec, httptest.NewRequest(http.MethodGet, "/events?season_id=4&participant_id=9&crew_participant_id=9", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("listEvents() status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	if want := `{"items":[],"total":0,"limit":50,"offset":0}`; strings.TrimSpace(rec.Body.String()) != want {
+		t.Fatalf("listEvents() body = %s, want an empty list", rec.Body.String())
+	}
+	query := db.calls[0]
+	if !strings.Contains(query, "ep.event_id = events.id AND ep.participant_id = $3") {
+		t.Fatalf("listEvents() query does not filter by roster: %s", query)
+	}
+	if !strings.Contains(query, "m.event_id = events.id AND ca.participant_id = $4") {
+		t.Fatalf("listEvents() query does not filter by crew: %s", query)
+	}
+
+	for _, param := range []string{"participant_id=0", "crew_participant_id=abc"} {
+		db = &fakeDB{}
+		rec = httptest.NewRecorder()
+		NewHandler(db).listEvents(rec, httptest.NewRequest(http.MethodGet, "/events?"+param, nil))
+		if rec.Code != http.StatusBadRequest || len(db.calls) != 0 {
+			t.Fatalf("listEvents(%s) status = %d with %d queries, want 400 and none", param, rec.Code, len(db.calls))
+		}
+	}
+}
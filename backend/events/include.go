@@ -0,0 +1,65 @@
+package events
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strings"
+)
+
+// eventIncludeNames are the relations attachEventRelations can load, as named
+// in the include query parameter.
+var eventIncludeNames = []string{"participants", "innhopps", "aircraft", "airfields", "seasons", "remaining_slots"}
+
+// eventIncludes is the set of relations to attach to events.
+type eventIncludes map[string]bool
+
+func allEventIncludes() eventIncludes {
+	include := make(eventIncludes, len(eventIncludeNames))
+	for _, name := range eventIncludeNames {
+		include[name] = true
+	}
+	return include
+}
+
+// parseEventIncludes reads a comma-separated include list. "all" and "none"
+// select every or no relation; a blank value returns fallback.
+func parseEventIncludes(raw string, fallback eventIncludes) (eventIncludes, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return fallback, nil
+	}
+	include := eventIncludes{}
+	for _, part := range strings.Split(raw, ",") {
+		name := strings.ToLower(strings.TrimSpace(part))
+		switch name {
+		case "":
+		case "all":
+			return allEventIncludes(), nil
+		case "none":
+			return eventIncludes{}, nil
+		default:
+			if !allEventIncludes()[name] {
+				return nil, errors.New("include must be a list of: " + strings.Join(eventIncludeNames, ", ") + ", or all/none")
+			}
+			include[name] = true
+		}
+	}
+	return include, nil
+}
+
+func (i eventIncludes) any() bool {
+	return len(i) > 0
+}
+
+// listIncludesFromEnv reads EVENTS_LIST_INCLUDE, the relations the event list
+// attaches when the request has no include parameter. It defaults to none.
+func listIncludesFromEnv() eventIncludes {
+	raw := os.Getenv("EVENTS_LIST_INCLUDE")
+	include, err := parseEventIncludes(raw, eventIncludes{})
+	if err != nil {
+		log.Printf("events: ignoring invalid EVENTS_LIST_INCLUDE %q: %v", raw, err)
+		return eventIncludes{}
+	}
+	return include
+}
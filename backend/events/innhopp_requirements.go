@@ -0,0 +1,255 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/innhopp/central/backend/httpx"
+)
+
+// innhoppFieldChecks reports, for each innhopp field a requirement policy can
+// name, whether an innhopp has it filled in. Names match the innhopp JSON
+// fields. A landing area counts as filled in once it has a name.
+var innhoppFieldChecks = map[string]func(Innhopp) bool{
+	"coordinates":            func(i Innhopp) bool { return strings.TrimSpace(i.Coordinates) != "" },
+	"aircraft_id":            func(i Innhopp) bool { return i.AircraftID != nil },
+	"takeoff_airfield_id":    func(i Innhopp) bool { return i.TakeoffAirfieldID != nil },
+	"landing_airfield_id":    func(i Innhopp) bool { return i.LandingAirfieldID != nil },
+	"elevation":              func(i Innhopp) bool { return i.Elevation != nil },
+	"scheduled_at":           func(i Innhopp) bool { return i.ScheduledAt != nil },
+	"reason_for_choice":      func(i Innhopp) bool { return strings.TrimSpace(i.ReasonForChoice) != "" },
+	"adjust_altimeter_aad":   func(i Innhopp) bool { return strings.TrimSpace(i.AdjustAltimeterAAD) != "" },
+	"notam":                  func(i Innhopp) bool { return strings.TrimSpace(i.Notam) != "" },
+	"primary_landing_area":   func(i Innhopp) bool { return strings.TrimSpace(i.PrimaryLandingArea.Name) != "" },
+	"secondary_landing_area": func(i Innhopp) bool { return strings.TrimSpace(i.SecondaryLandingArea.Name) != "" },
+	"risk_assessment":        func(i Innhopp) bool { return strings.TrimSpace(i.RiskAssessment) != "" },
+	"safety_precautions":     func(i Innhopp) bool { return strings.TrimSpace(i.SafetyPrecautions) != "" },
+	"jumprun":                func(i Innhopp) bool { return strings.TrimSpace(i.Jumprun) != "" },
+	"hospital":               func(i Innhopp) bool { return strings.TrimSpace(i.Hospital) != "" },
+	"rescue_boat":            func(i Innhopp) bool { return i.RescueBoat != nil },
+	"minimum_requirements":   func(i Innhopp) bool { return strings.TrimSpace(i.MinimumRequirements) != "" },
+	"land_owners":            func(i Innhopp) bool { return len(i.LandOwners) > 0 },
+	"land_owner_permission":  func(i Innhopp) bool { return i.LandOwnerPermission != nil && *i.LandOwnerPermission },
+}
+
+// innhoppRequirements maps an event status to the innhopp fields that must be
+// filled in before an event may move to it. Fields required at one status
+// are also required at every later status in eventStatusValues.
+type innhoppRequirements map[string][]string
+
+// defaultInnhoppRequirements gates safety information from launched onwards.
+func defaultInnhoppRequirements() innhoppRequirements {
+	return innhoppRequirements{"launched": {"risk_assessment", "primary_landing_area"}}
+}
+
+// parseInnhoppRequirements reads a list of the form
+// "launched=risk_assessment+primary_landing_area,live=hospital". "none"
+// disables the policy.
+func parseInnhoppRequirements(raw string) (innhoppRequirements, error) {
+	raw = strings.TrimSpace(raw)
+	policy := innhoppRequirements{}
+	if strings.EqualFold(raw, "none") {
+		return policy, nil
+	}
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		status, fields, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid requirement %q, want status=field+field", entry)
+		}
+		status = strings.ToLower(strings.TrimSpace(status))
+		if _, known := validEventStatuses[status]; !known {
+			return nil, fmt.Errorf("requirement %q: unknown status %q", entry, status)
+		}
+		for _, field := range strings.Split(fields, "+") {
+			field = strings.ToLower(strings.TrimSpace(field))
+			if _, known := innhoppFieldChecks[field]; !known {
+				return nil, fmt.Errorf("requirement %q: unknown innhopp field %q", entry, field)
+			}
+			policy[status] = append(policy[status], field)
+		}
+	}
+	return policy, nil
+}
+
+// innhoppRequirementsFromEnv reads INNHOPP_REQUIRED_FIELDS, falling back to
+// the defaults when it is unset or invalid.
+func innhoppRequirementsFromEnv() innhoppRequirements {
+	raw, ok := os.LookupEnv("INNHOPP_REQUIRED_FIELDS")
+	if !ok || strings.TrimSpace(raw) == "" {
+		return defaultInnhoppRequirements()
+	}
+	policy, err := parseInnhoppRequirements(raw)
+	if err != nil {
+		log.Printf("events: ignoring invalid INNHOPP_REQUIRED_FIELDS %q: %v", raw, err)
+		return defaultInnhoppRequirements()
+	}
+	return policy
+}
+
+// requiredFor returns the sorted fields every innhopp needs at status.
+func (p innhoppRequirements) requiredFor(status string) []string {
+	seen := map[string]struct{}{}
+	fields := make([]string, 0)
+	for _, s := range eventStatusValues {
+		for _, field := range p[s] {
+			if _, dup := seen[field]; !dup {
+				seen[field] = struct{}{}
+				fields = append(fields, field)
+			}
+		}
+		if s == status {
+			break
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// innhoppGap lists the required fields one innhopp is missing.
+type innhoppGap struct {
+	InnhoppID int64    `json:"innhopp_id"`
+	Sequence  int      `json:"sequence"`
+	Name      string   `json:"name"`
+	Missing   []string `json:"missing"`
+}
+
+// missingInnhoppFields returns a gap for each innhopp missing any of fields.
+func missingInnhoppFields(innhopps []Innhopp, fields []string) []innhoppGap {
+	gaps := make([]innhoppGap, 0)
+	for _, innhopp := range innhopps {
+		var missing []string
+		for _, field := range fields {
+			if !innhoppFieldChecks[field](innhopp) {
+				missing = append(missing, field)
+			}
+		}
+		if len(missing) > 0 {
+			gaps = append(gaps, innhoppGap{InnhoppID: innhopp.ID, Sequence: innhopp.Sequence, Name: innhopp.Name, Missing: missing})
+		}
+	}
+	return gaps
+}
+
+// checkInnhoppRequirements loads the innhopps of eventID through db and
+// returns the ones missing fields required at status.
+func (h *Handler) checkInnhoppRequirements(ctx context.Context, db rowsQuerier, eventID int64, status string) ([]innhoppGap, error) {
+	fields := h.innhoppRequirements.requiredFor(status)
+	if len(fields) == 0 {
+		return []innhoppGap{}, nil
+	}
+	innhopps, err := queryInnhopps(ctx, db, []int64{eventID}, false)
+	if err != nil {
+		return nil, err
+	}
+	return missingInnhoppFields(innhopps[eventID], fields), nil
+}
+
+// writeInnhoppRequirementsError answers a status change refused because
+// innhopps are missing required fields.
+func writeInnhoppRequirementsError(w http.ResponseWriter, status string, gaps []innhoppGap) {
+	httpx.WriteJSON(w, http.StatusUnprocessableEntity, map[string]any{
+		"error":    "innhopps are missing fields required for status " + status,
+		"status":   status,
+		"innhopps": gaps,
+	})
+}
+
+type preflightResult struct {
+	EventID        int64            `json:"event_id"`
+	Status         string           `json:"status"`
+	Ready          bool             `json:"ready"`
+	RequiredFields []string         `json:"required_fields"`
+	Innhopps       []innhoppGap     `json:"innhopps"`
+	Warnings       []innhoppWarning `json:"warnings"`
+	CrewShortfall  *crewShortfall   `json:"crew_shortfall,omitempty"`
+}
+
+// preflightEvent reports whether an event's innhopps are complete enough for
+// it to move to ?status= (live when omitted), listing each innhopp's missing
+// fields and any shortfall against MIN_CREW_FOR_LIVE, without changing
+// anything. Warnings point out innhopps that need a second look but never
+// make the event unready.
+func (h *Handler) preflightEvent(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.ParseInt(chi.URLParam(r, "eventID"), 10, 64)
+	if err != nil || eventID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid event id")
+		return
+	}
+	status := "live"
+	if raw := strings.TrimSpace(r.URL.Query().Get("status")); raw != "" {
+		status, err = normalizeEventStatus(raw)
+		if err != nil {
+			httpx.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	ctx := r.Context()
+	exists, err := eventExists(ctx, h.db, eventID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to validate event")
+		return
+	}
+	if !exists {
+		httpx.Error(w, http.StatusNotFound, "event not found")
+		return
+	}
+	innhopps, err := h.fetchInnhoppsForEvents(ctx, []int64{eventID}, false)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load innhopps")
+		return
+	}
+	fields := h.innhoppRequirements.requiredFor(status)
+	result := preflightResult{
+		EventID:        eventID,
+		Status:         status,
+		RequiredFields: fields,
+		Innhopps:       missingInnhoppFields(innhopps[eventID], fields),
+		Warnings:       []innhoppWarning{},
+	}
+	result.CrewShortfall, err = h.checkMinimumCrew(ctx, h.db, eventID, status)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to count crew")
+		return
+	}
+	result.Ready = len(result.Innhopps) == 0 && result.CrewShortfall == nil
+
+	if len(h.landingAreaMinimums) > 0 && len(innhopps[eventID]) > 0 {
+		jumps, hasRoster, err := leastParticipantJumps(ctx, h.db, eventID)
+		if err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to load participants")
+			return
+		}
+		if hasRoster {
+			result.Warnings = smallLandingAreaWarnings(innhopps[eventID], h.landingAreaMinimums.forJumps(jumps))
+		}
+	}
+	httpx.WriteJSON(w, http.StatusOK, result)
+}
+
+// currentEventStatus returns the stored status of eventID, locking the row.
+func currentEventStatus(ctx context.Context, tx pgx.Tx, eventID int64) (string, bool, error) {
+	var status string
+	err := tx.QueryRow(ctx, `SELECT status FROM events WHERE id = $1 FOR UPDATE`, eventID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return status, true, nil
+}
@@ -0,0 +1,72 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/innhopp/central/backend/httpx"
+)
+
+// resolveInnhoppSequence picks the sequence for an innhopp added on its own
+// to eventID. Without a requested sequence it returns one past the event's
+// highest. A requested sequence that is already taken is kept, and the
+// innhopps at or after it move down one place so the new one slots in
+// between. Callers must lock the event row in the same transaction so two
+// concurrent creates cannot pick the same sequence.
+func resolveInnhoppSequence(ctx context.Context, db rowExecer, eventID int64, requested *int) (int, error) {
+	if requested == nil {
+		var next int
+		err := db.QueryRow(ctx, `SELECT COALESCE(MAX(sequence), 0) + 1 FROM event_innhopps WHERE event_id = $1`, eventID).Scan(&next)
+		return next, err
+	}
+
+	var taken bool
+	if err := db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM event_innhopps WHERE event_id = $1 AND sequence = $2)`, eventID, *requested).Scan(&taken); err != nil {
+		return 0, err
+	}
+	if taken {
+		if _, err := db.Exec(ctx,
+			`UPDATE event_innhopps SET sequence = sequence + 1, version = version + 1 WHERE event_id = $1 AND sequence >= $2`,
+			eventID, *requested,
+		); err != nil {
+			return 0, err
+		}
+	}
+	return *requested, nil
+}
+
+type nextInnhoppSequence struct {
+	EventID      int64 `json:"event_id"`
+	NextSequence int   `json:"next_sequence"`
+}
+
+// getNextInnhoppSequence returns the sequence a new innhopp on the event gets
+// when it asks for none, so forms can show it up front. Another innhopp
+// created in the meantime takes it first; the create then appends as usual.
+func (h *Handler) getNextInnhoppSequence(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.ParseInt(chi.URLParam(r, "eventID"), 10, 64)
+	if err != nil || eventID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid event id")
+		return
+	}
+	result := nextInnhoppSequence{EventID: eventID}
+	err = h.db.QueryRow(r.Context(), `
+		SELECT (SELECT COALESCE(MAX(i.sequence), 0) + 1 FROM event_innhopps i WHERE i.event_id = e.id)
+		FROM events e
+		WHERE e.id = $1`, eventID,
+	).Scan(&result.NextSequence)
+	if errors.Is(err, pgx.ErrNoRows) {
+		httpx.Error(w, http.StatusNotFound, "event not found")
+		return
+	}
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load innhopp sequence")
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,193 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/innhopp/central/backend/innhopps"
+)
+
+// innhoppVersionError reports an innhopp in an event save whose version is
+// not the one stored, because someone edited it since the client read it.
+type innhoppVersionError struct {
+	id int64
+}
+
+func (e *innhoppVersionError) Error() string {
+	return fmt.Sprintf("innhopp %d was modified by someone else; reload and try again", e.id)
+}
+
+// saveEventInnhoppsTx makes the event's innhopps match entries. Entries whose
+// id belongs to the event update that row in place when their version is the
+// stored one, bumping it and recording a revision only when something
+// changed; a stale version fails with *innhoppVersionError. Entries without an
+// id are inserted; rows that are no longer listed are deleted. Keeping the
+// rows keeps their ids, versions and revision history across event saves.
+func saveEventInnhoppsTx(ctx context.Context, tx pgx.Tx, eventID int64, entries []innhoppInput) error {
+	rows, err := tx.Query(ctx, `SELECT id FROM event_innhopps WHERE event_id = $1 FOR UPDATE`, eventID)
+	if err != nil {
+		return err
+	}
+	owned := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		owned[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	kept := make([]int64, 0, len(entries))
+	airfieldIDsFromInnhopps := make(map[int64]struct{})
+	for index, innhopp := range entries {
+		landOwnersJSON, err := encodeLandOwners(innhopp.LandOwners)
+		if err != nil {
+			return fmt.Errorf("innhopp %d (%s): %w", index+1, innhopp.Name, err)
+		}
+		imageFilesJSON, err := encodeImageFiles(innhopp.ImageFiles)
+		if err != nil {
+			return fmt.Errorf("innhopp %d (%s): %w", index+1, innhopp.Name, err)
+		}
+		values := []any{
+			innhopp.Sequence,
+			innhopp.Name,
+			innhopp.Coordinates,
+			innhopp.AircraftID,
+			innhopp.TakeoffAirfieldID,
+			innhopp.LandingAirfieldID,
+			innhopp.Elevation,
+			innhopp.ScheduledAt,
+			innhopp.Notes,
+			innhopp.ReasonForChoice,
+			innhopp.AdjustAltimeterAAD,
+			innhopp.Notam,
+			innhopp.DistanceByAir,
+			innhopp.DistanceByRoad,
+			innhopp.LandingDistanceByAir,
+			innhopp.LandingDistanceByRoad,
+			innhopp.PrimaryLandingArea.Name,
+			innhopp.PrimaryLandingArea.Description,
+			innhopp.PrimaryLandingArea.Size,
+			innhopp.PrimaryLandingArea.Obstacles,
+			innhopp.SecondaryLandingArea.Name,
+			innhopp.SecondaryLandingArea.Description,
+			innhopp.SecondaryLandingArea.Size,
+			innhopp.SecondaryLandingArea.Obstacles,
+			innhopp.RiskAssessment,
+			innhopp.SafetyPrecautions,
+			innhopp.Jumprun,
+			innhopp.Hospital,
+			innhopp.RescueBoat,
+			innhopp.MinimumRequirements,
+			string(imageFilesJSON),
+			string(landOwnersJSON),
+			innhopp.LandOwnerPermission,
+		}
+
+		if innhopp.ID != nil && owned[*innhopp.ID] {
+			id := *innhopp.ID
+			previous, err := innhopps.LoadForUpdate(ctx, tx, id)
+			if err != nil {
+				return fmt.Errorf("innhopp %d (%s): %w", index+1, innhopp.Name, err)
+			}
+			var version int
+			err = tx.QueryRow(ctx, updateEventInnhoppSQL, append([]any{id, eventID, innhopp.Version}, values...)...).Scan(&version)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return &innhoppVersionError{id: id}
+			}
+			if err != nil {
+				return fmt.Errorf("innhopp %d (%s): %w", index+1, innhopp.Name, err)
+			}
+			if version != previous.Version {
+				if err := innhopps.RecordRevision(ctx, tx, previous); err != nil {
+					return fmt.Errorf("innhopp %d (%s): %w", index+1, innhopp.Name, err)
+				}
+			}
+			kept = append(kept, id)
+			delete(owned, id)
+			linkInnhoppAirfields(airfieldIDsFromInnhopps, innhopp)
+			continue
+		}
+
+		var id int64
+		if err := tx.QueryRow(ctx, insertEventInnhoppSQL, append([]any{eventID}, values...)...).Scan(&id); err != nil {
+			return fmt.Errorf("innhopp %d (%s): %w", index+1, innhopp.Name, err)
+		}
+		kept = append(kept, id)
+		linkInnhoppAirfields(airfieldIDsFromInnhopps, innhopp)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM event_innhopps WHERE event_id = $1 AND id <> ALL($2::int[])`, eventID, kept); err != nil {
+		return err
+	}
+
+	for airfieldID := range airfieldIDsFromInnhopps {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO event_airfields (event_id, airfield_id) VALUES ($1, $2)
+             ON CONFLICT (event_id, airfield_id) DO NOTHING`,
+			eventID,
+			airfieldID,
+		); err != nil {
+			return fmt.Errorf("failed to link innhopp airfield %d to event: %w", airfieldID, err)
+		}
+	}
+	return nil
+}
+
+func linkInnhoppAirfields(ids map[int64]struct{}, innhopp innhoppInput) {
+	if innhopp.TakeoffAirfieldID != nil {
+		ids[*innhopp.TakeoffAirfieldID] = struct{}{}
+	}
+	if innhopp.LandingAirfieldID != nil {
+		ids[*innhopp.LandingAirfieldID] = struct{}{}
+	}
+}
+
+const eventInnhoppColumns = `sequence, name, coordinates, aircraft_id, takeoff_airfield_id, landing_airfield_id, elevation, scheduled_at, notes,
+                reason_for_choice, adjust_altimeter_aad, notam, distance_by_air, distance_by_road, landing_distance_by_air, landing_distance_by_road,
+                primary_landing_area_name, primary_landing_area_description, primary_landing_area_size, primary_landing_area_obstacles,
+                secondary_landing_area_name, secondary_landing_area_description, secondary_landing_area_size, secondary_landing_area_obstacles,
+                risk_assessment, safety_precautions, jumprun, hospital, rescue_boat, minimum_requirements, image_files, land_owners, land_owner_permission`
+
+const insertEventInnhoppSQL = `INSERT INTO event_innhopps (event_id, ` + eventInnhoppColumns + `)
+            VALUES (
+                $1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
+                $11, $12, $13, $14, $15, $16, $17,
+                $18, $19, $20, $21,
+                $22, $23, $24, $25,
+                $26, $27, $28, $29, $30, $31, $32::jsonb, $33::jsonb, $34
+            )
+            RETURNING id`
+
+// updateEventInnhoppSQL only updates the row when $3 is its version, and
+// only bumps the version when one of the values differs from what is stored.
+// It returns the version the row ends up with.
+const updateEventInnhoppSQL = `UPDATE event_innhopps SET
+                sequence = $4, name = $5, coordinates = $6, aircraft_id = $7, takeoff_airfield_id = $8, landing_airfield_id = $9,
+                elevation = $10, scheduled_at = $11, notes = $12,
+                reason_for_choice = $13, adjust_altimeter_aad = $14, notam = $15, distance_by_air = $16, distance_by_road = $17,
+                landing_distance_by_air = $18, landing_distance_by_road = $19,
+                primary_landing_area_name = $20, primary_landing_area_description = $21, primary_landing_area_size = $22, primary_landing_area_obstacles = $23,
+                secondary_landing_area_name = $24, secondary_landing_area_description = $25, secondary_landing_area_size = $26, secondary_landing_area_obstacles = $27,
+                risk_assessment = $28, safety_precautions = $29, jumprun = $30, hospital = $31, rescue_boat = $32, minimum_requirements = $33,
+                image_files = $34::jsonb, land_owners = $35::jsonb, land_owner_permission = $36,
+                version = CASE
+                    WHEN (` + eventInnhoppColumns + `) IS DISTINCT FROM (
+                        $4, $5, $6, $7, $8, $9, $10, $11, $12,
+                        $13, $14, $15, $16, $17, $18, $19,
+                        $20, $21, $22, $23,
+                        $24, $25, $26, $27,
+                        $28, $29, $30, $31, $32, $33, $34::jsonb, $35::jsonb, $36
+                    ) THEN version + 1
+                    ELSE version
+                END
+            WHERE id = $1 AND event_id = $2 AND version = $3
+            RETURNING version`
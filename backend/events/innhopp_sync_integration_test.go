@@ -0,0 +1,323 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestSaveEventInnhoppsKeepsVersionAndRevisions(t *testing.T) {
+	db := openEventTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ensureEventTestSchema(t, ctx, db)
+
+	var seasonID, eventID, keptID, droppedID, revisionID int64
+	if err := db.QueryRow(ctx, `INSERT INTO seasons (name, starts_on) VALUES ('Sync Season', CURRENT_DATE) RETURNING id`).Scan(&seasonID); err != nil {
+		t.Fatalf("insert season failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM seasons WHERE id = $1`, seasonID)
+	if err := db.QueryRow(ctx, `INSERT INTO events (season_id, name, starts_at) VALUES ($1, 'Sync Event', NOW()) RETURNING id`, seasonID).Scan(&eventID); err != nil {
+		t.Fatalf("insert event failed: %v", err)
+	}
+	if err := db.QueryRow(ctx, `INSERT INTO event_innhopps (event_id, sequence, name, version) VALUES ($1, 1, 'Kept', 3) RETURNING id`, eventID).Scan(&keptID); err != nil {
+		t.Fatalf("insert innhopp failed: %v", err)
+	}
+	if err := db.QueryRow(ctx, `INSERT INTO event_innhopps (event_id, sequence, name) VALUES ($1, 2, 'Dropped') RETURNING id`, eventID).Scan(&droppedID); err != nil {
+		t.Fatalf("insert innhopp failed: %v", err)
+	}
+	if err := db.QueryRow(ctx,
+		`INSERT INTO event_innhopp_revisions (innhopp_id, version, snapshot, actor_name) VALUES ($1, 2, '{}'::jsonb, 'Earlier') RETURNING id`,
+		keptID,
+	).Scan(&revisionID); err != nil {
+		t.Fatalf("insert revision failed: %v", err)
+	}
+
+	trySave := func(innhopps []innhoppInput) error {
+		t.Helper()
+		tx, err := db.Begin(ctx)
+		if err != nil {
+			t.Fatalf("begin failed: %v", err)
+		}
+		defer tx.Rollback(ctx)
+		if err := saveEventInnhoppsTx(ctx, tx, eventID, innhopps); err != nil {
+			return err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			t.Fatalf("commit failed: %v", err)
+		}
+		return nil
+	}
+	save := func(innhopps []innhoppInput) {
+		t.Helper()
+		if err := trySave(innhopps); err != nil {
+			t.Fatalf("save innhopps failed: %v", err)
+		}
+	}
+	keptVersion, droppedVersion, staleVersion := 3, 1, 2
+
+	// A save made against an older version is refused and changes nothing.
+	err := trySave([]innhoppInput{{ID: &keptID, Version: &staleVersion, Sequence: 1, Name: "Stale"}})
+	var versionErr *innhoppVersionError
+	if !errors.As(err, &versionErr) || versionErr.id != keptID {
+		t.Fatalf("stale save err = %v, want a version error for %d", err, keptID)
+	}
+
+	// Saving the event unchanged leaves the innhopp alone.
+	save([]innhoppInput{
+		{ID: &keptID, Version: &keptVersion, Sequence: 1, Name: "Kept"},
+		{ID: &droppedID, Version: &droppedVersion, Sequence: 2, Name: "Dropped"},
+	})
+	var version, revisions int
+	if err := db.QueryRow(ctx, `SELECT version FROM event_innhopps WHERE id = $1`, keptID).Scan(&version); err != nil {
+		t.Fatalf("reload innhopp failed: %v", err)
+	}
+	if version != 3 {
+		t.Fatalf("version after unchanged save = %d, want 3", version)
+	}
+
+	// An edit updates the row in place; a missing entry is deleted and one
+	// without an id is added.
+	save([]innhoppInput{{ID: &keptID, Version: &keptVersion, Sequence: 1, Name: "Renamed"}, {Sequence: 2, Name: "Added"}})
+	var name string
+	if err := db.QueryRow(ctx, `SELECT name, version FROM event_innhopps WHERE id = $1`, keptID).Scan(&name, &version); err != nil {
+		t.Fatalf("kept innhopp was not kept: %v", err)
+	}
+	if name != "Renamed" || version != 4 {
+		t.Fatalf("kept innhopp = %q v%d, want \"Renamed\" v4", name, version)
+	}
+	if err := db.QueryRow(ctx, `SELECT COUNT(*) FROM event_innhopp_revisions WHERE innhopp_id = $1`, keptID).Scan(&revisions); err != nil {
+		t.Fatalf("count revisions failed: %v", err)
+	}
+	if revisions != 2 {
+		t.Fatalf("revisions = %d, want the earlier one plus one for the edit", revisions)
+	}
+	var snapshotName string
+	if err := db.QueryRow(ctx,
+		`SELECT snapshot->>'name' FROM event_innhopp_revisions WHERE innhopp_id = $1 AND version = 3`, keptID,
+	).Scan(&snapshotName); err != nil || snapshotName != "Kept" {
+		t.Fatalf("revision snapshot name = %q, %v", snapshotName, err)
+	}
+
+	var names []string
+	rows, err := db.Query(ctx, `SELECT name FROM event_innhopps WHERE event_id = $1 ORDER BY sequence`, eventID)
+	if err != nil {
+		t.Fatalf("list innhopps failed: %v", err)
+	}
+	for rows.Next() {
+		var n string
+		if err := rows.Scan(&n); err != nil {
+			t.Fatalf("scan innhopp failed: %v", err)
+		}
+		names = append(names, n)
+	}
+	rows.Close()
+	if len(names) != 2 || names[0] != "Renamed" || names[1] != "Added" {
+		t.Fatalf("innhopps after save = %v", names)
+	}
+}
+
+func openEventTestDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Skip("DATABASE_URL not set; skipping integration event tests")
+	}
+	db, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatalf("connect db failed: %v", err)
+	}
+	return db
+}
+
+func ensureEventTestSchema(t *testing.T, ctx context.Context, db *pgxpool.Pool) {
+	t.Helper()
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS seasons (
+            id SERIAL PRIMARY KEY,
+            name TEXT NOT NULL,
+            starts_on DATE NOT NULL,
+            ends_on DATE,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE TABLE IF NOT EXISTS events (
+            id SERIAL PRIMARY KEY,
+            season_id INTEGER NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+            name TEXT NOT NULL,
+            location TEXT,
+            slots INTEGER NOT NULL DEFAULT 0,
+            status TEXT NOT NULL DEFAULT 'draft',
+            starts_at TIMESTAMPTZ NOT NULL,
+            ends_at TIMESTAMPTZ,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE TABLE IF NOT EXISTS airfields (
+            id SERIAL PRIMARY KEY,
+            name TEXT NOT NULL,
+            latitude TEXT NOT NULL,
+            longitude TEXT NOT NULL,
+            elevation INTEGER NOT NULL,
+            description TEXT,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS public_registration_slug TEXT`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS public_registration_enabled BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS registration_open_at TIMESTAMPTZ`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS main_invoice_deadline TIMESTAMPTZ`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS deposit_amount NUMERIC(12,2)`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS main_invoice_amount NUMERIC(12,2)`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'EUR'`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS minimum_deposit_count INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS commercial_status TEXT NOT NULL DEFAULT 'draft'`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS all_day BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}'`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS briefing TEXT`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS notes TEXT`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS base_airfield_id INTEGER REFERENCES airfields(id) ON DELETE SET NULL`,
+		`CREATE TABLE IF NOT EXISTS event_innhopps (
+            id SERIAL PRIMARY KEY,
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            sequence INTEGER NOT NULL,
+            name TEXT NOT NULL,
+            aircraft_id INTEGER,
+            takeoff_airfield_id INTEGER,
+            landing_airfield_id INTEGER,
+            elevation INTEGER,
+            scheduled_at TIMESTAMPTZ,
+            notes TEXT,
+            coordinates TEXT,
+            reason_for_choice TEXT,
+            adjust_altimeter_aad TEXT,
+            notam TEXT,
+            distance_by_air NUMERIC,
+            distance_by_road NUMERIC,
+            landing_distance_by_air NUMERIC,
+            landing_distance_by_road NUMERIC,
+            primary_landing_area_name TEXT,
+            primary_landing_area_description TEXT,
+            primary_landing_area_size TEXT,
+            primary_landing_area_obstacles TEXT,
+            secondary_landing_area_name TEXT,
+            secondary_landing_area_description TEXT,
+            secondary_landing_area_size TEXT,
+            secondary_landing_area_obstacles TEXT,
+            risk_assessment TEXT,
+            safety_precautions TEXT,
+            jumprun TEXT,
+            hospital TEXT,
+            rescue_boat BOOLEAN,
+            minimum_requirements TEXT,
+            image_files JSONB DEFAULT '[]'::jsonb,
+            land_owners JSONB,
+            land_owner_permission BOOLEAN,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`ALTER TABLE event_innhopps ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1`,
+		`CREATE TABLE IF NOT EXISTS accounts (
+            id SERIAL PRIMARY KEY,
+            subject TEXT NOT NULL UNIQUE,
+            email TEXT NOT NULL,
+            full_name TEXT,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE TABLE IF NOT EXISTS event_innhopp_revisions (
+            id SERIAL PRIMARY KEY,
+            innhopp_id INTEGER NOT NULL REFERENCES event_innhopps(id) ON DELETE CASCADE,
+            version INTEGER NOT NULL,
+            snapshot JSONB NOT NULL,
+            actor_account_id INTEGER REFERENCES accounts(id) ON DELETE SET NULL,
+            actor_name TEXT,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE TABLE IF NOT EXISTS event_seasons (
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            season_id INTEGER NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+            added_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+            PRIMARY KEY (event_id, season_id)
+        )`,
+		`CREATE TABLE IF NOT EXISTS events_archive (
+            id INTEGER PRIMARY KEY,
+            season_id INTEGER NOT NULL,
+            name TEXT NOT NULL,
+            starts_at TIMESTAMPTZ NOT NULL,
+            event JSONB NOT NULL,
+            relations JSONB NOT NULL DEFAULT '{}'::jsonb,
+            archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE TABLE IF NOT EXISTS manifests (
+            id SERIAL PRIMARY KEY,
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            load_number INTEGER NOT NULL,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`ALTER TABLE manifests ADD COLUMN IF NOT EXISTS staff_slots INTEGER`,
+		`ALTER TABLE manifests ADD COLUMN IF NOT EXISTS capacity INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE manifests ADD COLUMN IF NOT EXISTS notes TEXT`,
+		`CREATE TABLE IF NOT EXISTS participant_profiles (
+            id SERIAL PRIMARY KEY,
+            full_name TEXT NOT NULL,
+            email TEXT NOT NULL UNIQUE,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE TABLE IF NOT EXISTS crew_assignments (
+            id SERIAL PRIMARY KEY,
+            manifest_id INTEGER NOT NULL REFERENCES manifests(id) ON DELETE CASCADE,
+            participant_id INTEGER NOT NULL REFERENCES participant_profiles(id) ON DELETE CASCADE,
+            role TEXT NOT NULL,
+            assigned_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`ALTER TABLE participant_profiles ADD COLUMN IF NOT EXISTS roles TEXT[] NOT NULL DEFAULT '{}'`,
+		`CREATE TABLE IF NOT EXISTS event_participants (
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            participant_id INTEGER NOT NULL REFERENCES participant_profiles(id) ON DELETE CASCADE,
+            added_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+            PRIMARY KEY (event_id, participant_id)
+        )`,
+		`CREATE TABLE IF NOT EXISTS event_airfields (
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            airfield_id INTEGER NOT NULL REFERENCES airfields(id) ON DELETE CASCADE,
+            added_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+            PRIMARY KEY (event_id, airfield_id)
+        )`,
+		`CREATE TABLE IF NOT EXISTS aircraft (
+            id SERIAL PRIMARY KEY,
+            name TEXT NOT NULL,
+            pricing_model TEXT NOT NULL DEFAULT 'time',
+            rate_currency TEXT NOT NULL DEFAULT 'EUR',
+            capacity INTEGER NOT NULL DEFAULT 14,
+            crew_on_load_count INTEGER NOT NULL DEFAULT 2,
+            rate_per_minute NUMERIC,
+            cruising_speed_kmh NUMERIC,
+            minimum_load_duration NUMERIC,
+            price_per_slot NUMERIC,
+            notes TEXT,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+            updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE TABLE IF NOT EXISTS event_aircraft (
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            aircraft_id INTEGER NOT NULL REFERENCES aircraft(id) ON DELETE CASCADE,
+            sort_order INTEGER NOT NULL DEFAULT 0,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+            PRIMARY KEY (event_id, aircraft_id)
+        )`,
+		`CREATE TABLE IF NOT EXISTS event_registrations (
+            id SERIAL PRIMARY KEY,
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            participant_id INTEGER NOT NULL REFERENCES participant_profiles(id) ON DELETE CASCADE,
+            cancelled_at TIMESTAMPTZ,
+            expired_at TIMESTAMPTZ
+        )`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(ctx, stmt); err != nil {
+			t.Fatalf("schema setup failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const squareFeetToSquareMetres = 0.09290304
+
+// landingAreaMinimum is the smallest primary landing area, in square metres,
+// for an event whose least experienced participant has at least MinJumps.
+type landingAreaMinimum struct {
+	MinJumps int
+	AreaM2   float64
+}
+
+// landingAreaMinimums are tiers sorted by MinJumps ascending.
+type landingAreaMinimums []landingAreaMinimum
+
+func defaultLandingAreaMinimums() landingAreaMinimums {
+	return landingAreaMinimums{
+		{MinJumps: 0, AreaM2: 10000},
+		{MinJumps: 200, AreaM2: 5000},
+		{MinJumps: 500, AreaM2: 2500},
+		{MinJumps: 1000, AreaM2: 1000},
+	}
+}
+
+// parseLandingAreaMinimums reads a list of the form "0=10000,200=5000":
+// participants with at least that many jumps need at least that many square
+// metres. "none" disables the warning.
+func parseLandingAreaMinimums(raw string) (landingAreaMinimums, error) {
+	raw = strings.TrimSpace(raw)
+	tiers := landingAreaMinimums{}
+	if strings.EqualFold(raw, "none") {
+		return tiers, nil
+	}
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		jumps, area, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid minimum %q, want jumps=square_metres", entry)
+		}
+		minJumps, err := strconv.Atoi(strings.TrimSpace(jumps))
+		if err != nil || minJumps < 0 {
+			return nil, fmt.Errorf("minimum %q: jumps must be a non-negative integer", entry)
+		}
+		squareM, err := strconv.ParseFloat(strings.TrimSpace(area), 64)
+		if err != nil || squareM <= 0 {
+			return nil, fmt.Errorf("minimum %q: area must be a positive number of square metres", entry)
+		}
+		tiers = append(tiers, landingAreaMinimum{MinJumps: minJumps, AreaM2: squareM})
+	}
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].MinJumps < tiers[j].MinJumps })
+	return tiers, nil
+}
+
+// landingAreaMinimumsFromEnv reads LANDING_AREA_MIN_SIZES, falling back to
+// the defaults when it is unset or invalid.
+func landingAreaMinimumsFromEnv() landingAreaMinimums {
+	raw := strings.TrimSpace(os.Getenv("LANDING_AREA_MIN_SIZES"))
+	if raw == "" {
+		return defaultLandingAreaMinimums()
+	}
+	tiers, err := parseLandingAreaMinimums(raw)
+	if err != nil {
+		log.Printf("events: ignoring invalid LANDING_AREA_MIN_SIZES %q: %v", raw, err)
+		return defaultLandingAreaMinimums()
+	}
+	return tiers
+}
+
+// forJumps returns the minimum area for a participant with jumps, or 0 when
+// no tier covers them.
+func (t landingAreaMinimums) forJumps(jumps int) float64 {
+	minimum := 0.0
+	for _, tier := range t {
+		if jumps >= tier.MinJumps {
+			minimum = tier.AreaM2
+		}
+	}
+	return minimum
+}
+
+var (
+	landingAreaDimensions = regexp.MustCompile(`^(\d+(?:[.,]\d+)?)\s*(m|meters?|metres?|ft|feet)?\s*(?:x|×|\*|by)\s*(\d+(?:[.,]\d+)?)\s*(m|meters?|metres?|ft|feet)?$`)
+	landingAreaTotal      = regexp.MustCompile(`^(\d+(?:[.,]\d+)?)\s*(m2|m²|sqm|sq\s*m|square\s+met(?:er|re)s?|ha|hectares?|ft2|ft²|sq\s*ft|square\s+f(?:ee|oo)t)$`)
+)
+
+// parseLandingAreaSize reads a free-text landing area size such as
+// "50 x 200 m", "150ft x 300ft", "4000 m²" or "1.2 ha" and returns its area in
+// square metres. Dimensions without a unit are taken as metres. Anything else
+// is not understood and reports false.
+func parseLandingAreaSize(raw string) (float64, bool) {
+	size := strings.ToLower(strings.TrimSpace(raw))
+	if m := landingAreaDimensions.FindStringSubmatch(size); m != nil {
+		width, okW := parseSizeNumber(m[1])
+		length, okL := parseSizeNumber(m[3])
+		if !okW || !okL {
+			return 0, false
+		}
+		// A unit written once applies to both dimensions.
+		widthUnit, lengthUnit := m[2], m[4]
+		if widthUnit == "" {
+			widthUnit = lengthUnit
+		}
+		if lengthUnit == "" {
+			lengthUnit = widthUnit
+		}
+		return toMetres(width, widthUnit) * toMetres(length, lengthUnit), true
+	}
+	if m := landingAreaTotal.FindStringSubmatch(size); m != nil {
+		area, ok := parseSizeNumber(m[1])
+		if !ok {
+			return 0, false
+		}
+		switch unit := m[2]; {
+		case strings.HasPrefix(unit, "ha") || strings.HasPrefix(unit, "hectare"):
+			return area * 10000, true
+		case strings.Contains(unit, "ft") || strings.Contains(unit, "fe") || strings.Contains(unit, "foot"):
+			return area * squareFeetToSquareMetres, true
+		default:
+			return area, true
+		}
+	}
+	return 0, false
+}
+
+func parseSizeNumber(raw string) (float64, bool) {
+	value, err := strconv.ParseFloat(strings.ReplaceAll(raw, ",", "."), 64)
+	if err != nil || value <= 0 || math.IsInf(value, 0) {
+		return 0, false
+	}
+	return value, true
+}
+
+func toMetres(value float64, unit string) float64 {
+	if unit == "ft" || unit == "feet" {
+		return value * 0.3048
+	}
+	return value
+}
+
+// innhoppWarning flags an innhopp that may be unsafe without blocking it.
+type innhoppWarning struct {
+	InnhoppID int64   `json:"innhopp_id"`
+	Sequence  int     `json:"sequence"`
+	Name      string  `json:"name"`
+	Code      string  `json:"code"`
+	Message   string  `json:"message"`
+	AreaM2    float64 `json:"area_m2,omitempty"`
+	MinimumM2 float64 `json:"minimum_m2,omitempty"`
+}
+
+const warningSmallLandingArea = "small_landing_area"
+
+// smallLandingAreaWarnings flags innhopps whose primary landing area is below
+// minimum square metres. Sizes that cannot be parsed are skipped.
+func smallLandingAreaWarnings(innhopps []Innhopp, minimum float64) []innhoppWarning {
+	warnings := make([]innhoppWarning, 0)
+	if minimum <= 0 {
+		return warnings
+	}
+	for _, innhopp := range innhopps {
+		area, ok := parseLandingAreaSize(innhopp.PrimaryLandingArea.Size)
+		if !ok || area >= minimum {
+			continue
+		}
+		warnings = append(warnings, innhoppWarning{
+			InnhoppID: innhopp.ID,
+			Sequence:  innhopp.Sequence,
+			Name:      innhopp.Name,
+			Code:      warningSmallLandingArea,
+			Message:   fmt.Sprintf("primary landing area is %.0f m², below the %.0f m² minimum for the least experienced participant", area, minimum),
+			AreaM2:    math.Round(area),
+			MinimumM2: minimum,
+		})
+	}
+	return warnings
+}
+
+// leastParticipantJumps returns the lowest jump count on eventID's roster,
+// counting unknown jump counts as zero, and false when the roster is empty.
+func leastParticipantJumps(ctx context.Context, db rowQuerier, eventID int64) (int, bool, error) {
+	var count, jumps int
+	err := db.QueryRow(ctx, `
+		SELECT COUNT(*), COALESCE(MIN(COALESCE(p.jump_count, 0)), 0)
+		FROM event_participants ep
+		JOIN participant_profiles p ON p.id = ep.participant_id
+		WHERE ep.event_id = $1`, eventID,
+	).Scan(&count, &jumps)
+	if err != nil {
+		return 0, false, err
+	}
+	return jumps, count > 0, nil
+}
@@ -0,0 +1,54 @@
+package events
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/innhopp/central/backend/httpx"
+)
+
+// manifestCrewCapacity is how full a manifest's staff slots are. StaffSlots
+// and Remaining are nil when the manifest has no crew limit.
+type manifestCrewCapacity struct {
+	ManifestID int64 `json:"manifest_id"`
+	StaffSlots *int  `json:"staff_slots"`
+	Assigned   int   `json:"assigned"`
+	Remaining  *int  `json:"remaining"`
+}
+
+// getManifestCapacity reports the crew assigned to a manifest against its
+// staff slots, the same limit crew assignment enforces.
+func (h *Handler) getManifestCapacity(w http.ResponseWriter, r *http.Request) {
+	manifestID, err := strconv.ParseInt(chi.URLParam(r, "manifestID"), 10, 64)
+	if err != nil || manifestID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid manifest id")
+		return
+	}
+
+	result := manifestCrewCapacity{ManifestID: manifestID}
+	if err := h.db.QueryRow(r.Context(), `
+		SELECT m.staff_slots, (SELECT COUNT(*) FROM crew_assignments ca WHERE ca.manifest_id = m.id)
+		FROM manifests m
+		WHERE m.id = $1`, manifestID,
+	).Scan(&result.StaffSlots, &result.Assigned); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			httpx.Error(w, http.StatusNotFound, "manifest not found")
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to load manifest capacity")
+		return
+	}
+	if result.StaffSlots != nil {
+		remaining := *result.StaffSlots - result.Assigned
+		if remaining < 0 {
+			remaining = 0
+		}
+		result.Remaining = &remaining
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, result)
+}
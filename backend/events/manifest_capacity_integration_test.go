@@ -0,0 +1,75 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestGetManifestCapacityCountsAssignedCrew(t *testing.T) {
+	db := openEventTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ensureEventTestSchema(t, ctx, db)
+
+	var seasonID, eventID, manifestID int64
+	if err := db.QueryRow(ctx, `INSERT INTO seasons (name, starts_on) VALUES ('Capacity Season', CURRENT_DATE) RETURNING id`).Scan(&seasonID); err != nil {
+		t.Fatalf("insert season failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM seasons WHERE id = $1`, seasonID)
+	if err := db.QueryRow(ctx, `INSERT INTO events (season_id, name, starts_at) VALUES ($1, 'Capacity Event', NOW()) RETURNING id`, seasonID).Scan(&eventID); err != nil {
+		t.Fatalf("insert event failed: %v", err)
+	}
+	if err := db.QueryRow(ctx, `INSERT INTO manifests (event_id, load_number, staff_slots) VALUES ($1, 1, 3) RETURNING id`, eventID).Scan(&manifestID); err != nil {
+		t.Fatalf("insert manifest failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		email := "capacity-" + strconv.Itoa(i) + "-" + strconv.FormatInt(time.Now().UnixNano(), 10) + "@example.com"
+		var participantID int64
+		if err := db.QueryRow(ctx, `INSERT INTO participant_profiles (full_name, email) VALUES ('Capacity Crew', $1) RETURNING id`, email).Scan(&participantID); err != nil {
+			t.Fatalf("insert participant failed: %v", err)
+		}
+		defer db.Exec(context.Background(), `DELETE FROM participant_profiles WHERE id = $1`, participantID)
+		if _, err := db.Exec(ctx, `INSERT INTO crew_assignments (manifest_id, participant_id, role) VALUES ($1, $2, 'Jump Master')`, manifestID, participantID); err != nil {
+			t.Fatalf("insert crew assignment failed: %v", err)
+		}
+	}
+
+	router := chi.NewRouter()
+	router.Get("/manifests/{manifestID}/capacity", NewHandler(db).getManifestCapacity)
+	capacity := func() manifestCrewCapacity {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/manifests/"+strconv.FormatInt(manifestID, 10)+"/capacity", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("capacity status = %d body=%s", rec.Code, rec.Body.String())
+		}
+		var result manifestCrewCapacity
+		if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+			t.Fatalf("decode capacity failed: %v", err)
+		}
+		return result
+	}
+
+	got := capacity()
+	if got.StaffSlots == nil || *got.StaffSlots != 3 || got.Assigned != 2 || got.Remaining == nil || *got.Remaining != 1 {
+		t.Fatalf("capacity = %+v, want 2 of 3 slots assigned and 1 remaining", got)
+	}
+
+	// Lowering the slots below the crew already assigned leaves none.
+	if _, err := db.Exec(ctx, `UPDATE manifests SET staff_slots = 1 WHERE id = $1`, manifestID); err != nil {
+		t.Fatalf("lower staff slots failed: %v", err)
+	}
+	got = capacity()
+	if got.Assigned != 2 || got.Remaining == nil || *got.Remaining != 0 {
+		t.Fatalf("capacity over the limit = %+v, want 2 assigned and 0 remaining", got)
+	}
+}
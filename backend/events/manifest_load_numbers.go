@@ -0,0 +1,35 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/innhopp/central/backend/httpx"
+)
+
+// manifestLoadNumberIndex is the unique index on (event_id, load_number) that
+// main creates once no event has two loads with the same number.
+const manifestLoadNumberIndex = "manifests_event_load_number_key"
+
+func writeLoadNumberConflict(w http.ResponseWriter, loadNumber int) {
+	httpx.Error(w, http.StatusConflict, fmt.Sprintf("load number %d already exists for this event", loadNumber))
+}
+
+// checkLoadNumber answers 409 when a manifest other than excludeID already has
+// loadNumber for eventID and reports whether the caller may carry on.
+func checkLoadNumber(ctx context.Context, w http.ResponseWriter, db rowQuerier, eventID int64, loadNumber int, excludeID int64) bool {
+	var taken bool
+	if err := db.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM manifests WHERE event_id = $1 AND load_number = $2 AND id <> $3)`,
+		eventID, loadNumber, excludeID,
+	).Scan(&taken); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to check load number")
+		return false
+	}
+	if taken {
+		writeLoadNumberConflict(w, loadNumber)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,122 @@
+package events
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/innhopp/central/backend/httpx"
+)
+
+// maxGeneratedManifests caps how many loads one generate request creates.
+const maxGeneratedManifests = 50
+
+type generateManifestsPayload struct {
+	Count           int    `json:"count"`
+	StartLoadNumber *int   `json:"start_load_number"`
+	Capacity        int    `json:"capacity"`
+	StaffSlots      *int   `json:"staff_slots"`
+	Notes           string `json:"notes"`
+}
+
+// generateManifests creates a run of loads for an event with sequential load
+// numbers. Numbering continues after the event's highest load unless
+// start_load_number is given, in which case none of the new numbers may be
+// taken already.
+func (h *Handler) generateManifests(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.ParseInt(chi.URLParam(r, "eventID"), 10, 64)
+	if err != nil || eventID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid event id")
+		return
+	}
+	var payload generateManifestsPayload
+	if err := httpx.DecodeJSON(r, &payload); err != nil {
+		httpx.PayloadError(w, err, "invalid request payload")
+		return
+	}
+	if payload.Count <= 0 || payload.Count > maxGeneratedManifests {
+		httpx.Error(w, http.StatusBadRequest, "count must be between 1 and "+strconv.Itoa(maxGeneratedManifests))
+		return
+	}
+	if payload.StartLoadNumber != nil && *payload.StartLoadNumber <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "start_load_number must be positive")
+		return
+	}
+	if payload.Capacity < 0 {
+		httpx.Error(w, http.StatusBadRequest, "capacity cannot be negative")
+		return
+	}
+	if payload.StaffSlots != nil && *payload.StaffSlots < 0 {
+		httpx.Error(w, http.StatusBadRequest, "staff_slots cannot be negative")
+		return
+	}
+
+	ctx := r.Context()
+	tx, err := h.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to generate manifests")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// Locking the event serializes load numbering for it, so two generate
+	// requests cannot hand out the same numbers.
+	if err := tx.QueryRow(ctx, `SELECT id FROM events WHERE id = $1 FOR UPDATE`, eventID).Scan(&eventID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			httpx.Error(w, http.StatusNotFound, "event not found")
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to generate manifests")
+		return
+	}
+
+	var start int
+	if payload.StartLoadNumber != nil {
+		start = *payload.StartLoadNumber
+		var taken int
+		if err := tx.QueryRow(ctx,
+			`SELECT COUNT(*) FROM manifests WHERE event_id = $1 AND load_number BETWEEN $2 AND $3`,
+			eventID, start, start+payload.Count-1,
+		).Scan(&taken); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to generate manifests")
+			return
+		}
+		if taken > 0 {
+			httpx.Error(w, http.StatusConflict, "load numbers "+strconv.Itoa(start)+"-"+strconv.Itoa(start+payload.Count-1)+" overlap existing manifests")
+			return
+		}
+	} else {
+		if err := tx.QueryRow(ctx, `SELECT COALESCE(MAX(load_number), 0) + 1 FROM manifests WHERE event_id = $1`, eventID).Scan(&start); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to generate manifests")
+			return
+		}
+	}
+
+	manifests := make([]Manifest, 0, payload.Count)
+	for i := 0; i < payload.Count; i++ {
+		manifest := Manifest{
+			EventID:        eventID,
+			LoadNumber:     start + i,
+			Capacity:       payload.Capacity,
+			StaffSlots:     payload.StaffSlots,
+			Notes:          payload.Notes,
+			ParticipantIDs: []int64{},
+		}
+		if err := tx.QueryRow(ctx,
+			`INSERT INTO manifests (event_id, load_number, capacity, staff_slots, notes) VALUES ($1, $2, $3, $4, $5)
+             RETURNING id, created_at`,
+			manifest.EventID, manifest.LoadNumber, manifest.Capacity, manifest.StaffSlots, manifest.Notes,
+		).Scan(&manifest.ID, &manifest.CreatedAt); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to generate manifests")
+			return
+		}
+		manifests = append(manifests, manifest)
+	}
+
+	if err := httpx.FinishTx(ctx, w, r, tx, http.StatusCreated, manifests); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to generate manifests")
+	}
+}
@@ -0,0 +1,92 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestGenerateManifestsNumbersLoadsAndRefusesTakenNumbers(t *testing.T) {
+	db := openEventTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ensureEventTestSchema(t, ctx, db)
+
+	var seasonID, eventID int64
+	if err := db.QueryRow(ctx, `INSERT INTO seasons (name, starts_on) VALUES ('Generate Season', CURRENT_DATE) RETURNING id`).Scan(&seasonID); err != nil {
+		t.Fatalf("insert season failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM seasons WHERE id = $1`, seasonID)
+	if err := db.QueryRow(ctx, `INSERT INTO events (season_id, name, starts_at) VALUES ($1, 'Generate Event', NOW()) RETURNING id`, seasonID).Scan(&eventID); err != nil {
+		t.Fatalf("insert event failed: %v", err)
+	}
+	for _, load := range []int{1, 3} {
+		if _, err := db.Exec(ctx, `INSERT INTO manifests (event_id, load_number) VALUES ($1, $2)`, eventID, load); err != nil {
+			t.Fatalf("insert manifest failed: %v", err)
+		}
+	}
+
+	router := chi.NewRouter()
+	router.Post("/events/{eventID}/manifests/generate", NewHandler(db).generateManifests)
+	generate := func(body string) *httptest.ResponseRecorder {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/events/"+strconv.FormatInt(eventID, 10)+"/manifests/generate", strings.NewReader(body)))
+		return rec
+	}
+	loads := func(rec *httptest.ResponseRecorder) []int {
+		t.Helper()
+		var manifests []Manifest
+		if err := json.NewDecoder(rec.Body).Decode(&manifests); err != nil {
+			t.Fatalf("decode manifests failed: %v", err)
+		}
+		numbers := make([]int, len(manifests))
+		for i, m := range manifests {
+			numbers[i] = m.LoadNumber
+			if m.EventID != eventID || m.Capacity != 12 || m.StaffSlots == nil || *m.StaffSlots != 2 {
+				t.Fatalf("generated manifest = %+v, want event %d, capacity 12, 2 staff slots", m, eventID)
+			}
+		}
+		return numbers
+	}
+
+	// Numbering continues after the highest load, not the first gap.
+	rec := generate(`{"count": 2, "capacity": 12, "staff_slots": 2}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("generate status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	if got := loads(rec); len(got) != 2 || got[0] != 4 || got[1] != 5 {
+		t.Fatalf("generated loads = %v, want [4 5]", got)
+	}
+
+	rec = generate(`{"count": 3, "start_load_number": 5, "capacity": 12, "staff_slots": 2}`)
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "5-7") {
+		t.Fatalf("generate over a taken number = %d %s, want 409 naming 5-7", rec.Code, rec.Body.String())
+	}
+
+	rec = generate(`{"count": 2, "start_load_number": 8, "capacity": 12, "staff_slots": 2}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("generate from a free start status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	if got := loads(rec); len(got) != 2 || got[0] != 8 || got[1] != 9 {
+		t.Fatalf("generated loads = %v, want [8 9]", got)
+	}
+
+	var stored []int
+	if err := db.QueryRow(ctx, `SELECT array_agg(load_number ORDER BY load_number) FROM manifests WHERE event_id = $1`, eventID).Scan(&stored); err != nil {
+		t.Fatalf("load manifests failed: %v", err)
+	}
+	if want := []int{1, 3, 4, 5, 8, 9}; !slices.Equal(stored, want) {
+		t.Fatalf("stored loads = %v, want %v with nothing from the refused request", stored, want)
+	}
+}
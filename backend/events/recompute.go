@@ -0,0 +1,76 @@
+package events
+
+import "context"
+
+// recomputeBatchSize is how many events RecomputeEventStatuses reads per
+// query.
+const recomputeBatchSize = 500
+
+// RecomputeEventStatuses applies the status derived from each event's dates to
+// every event, a batch at a time, calling progress with the running totals
+// after each batch. Listing events already does this for the events it
+// returns; this catches the rest after the derivation changes. Events whose
+// status is already right are left untouched, so it is safe to run again, and
+// events not ready to go live stay where they are, as they do when listed.
+func (h *Handler) RecomputeEventStatuses(ctx context.Context, progress func(processed, updated int)) error {
+	now := h.clock.Now().UTC()
+	var lastID int64
+	processed, updated := 0, 0
+	for {
+		rows, err := h.db.Query(ctx, `
+			SELECT id, status, all_day, starts_at, ends_at
+			FROM events
+			WHERE id > $1
+			ORDER BY id
+			LIMIT $2`, lastID, recomputeBatchSize)
+		if err != nil {
+			return err
+		}
+		var batch []Event
+		var ids []int64
+		var statuses []string
+		for rows.Next() {
+			var event Event
+			if err := rows.Scan(&event.ID, &event.Status, &event.AllDay, &event.StartsAt, &event.EndsAt); err != nil {
+				rows.Close()
+				return err
+			}
+			lastID = event.ID
+			batch = append(batch, event)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		for _, event := range batch {
+			next, err := h.nextEventStatus(ctx, h.db, event, now)
+			if err != nil {
+				return err
+			}
+			if next != event.Status {
+				ids = append(ids, event.ID)
+				statuses = append(statuses, next)
+			}
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if len(ids) > 0 {
+			if _, err := h.db.Exec(ctx, `
+				UPDATE events e SET status = s.status
+				FROM unnest($1::int[], $2::text[]) AS s(id, status)
+				WHERE e.id = s.id AND e.status <> s.status`, ids, statuses); err != nil {
+				return err
+			}
+		}
+		processed += len(batch)
+		updated += len(ids)
+		if progress != nil {
+			progress(processed, updated)
+		}
+		if len(batch) < recomputeBatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/innhopp/central/backend/internal/clock"
+)
+
+func TestRecomputeEventStatusesSkipsEventsAlreadyRight(t *testing.T) {
+	past := time.Now().Add(-72 * time.Hour)
+	future := time.Now().Add(72 * time.Hour)
+	db := &fakeDB{results: []fakeResult{{match: "FROM events", rows: [][]any{
+		{int64(1), "past", false, past, &past},
+		{int64(2), "draft", false, future, nil},
+	}}}}
+
+	var reports [][2]int
+	err := NewHandler(db).RecomputeEventStatuses(context.Background(), func(processed, updated int) {
+		reports = append(reports, [2]int{processed, updated})
+	})
+	if err != nil {
+		t.Fatalf("RecomputeEventStatuses: %v", err)
+	}
+	if len(reports) != 1 || reports[0] != [2]int{2, 0} {
+		t.Fatalf("progress = %v, want one report of 2 processed, 0 updated", reports)
+	}
+	for _, call := range db.calls {
+		if strings.Contains(call, "UPDATE") {
+			t.Fatalf("unexpected update: %s", call)
+		}
+	}
+}
+
+func TestRecomputeEventStatusesUpdatesStaleEvents(t *testing.T) {
+	startsAt := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	endsAt := startsAt.Add(72 * time.Hour)
+	db := &fakeDB{results: []fakeResult{{match: "FROM events", rows: [][]any{
+		{int64(1), "launched", false, startsAt, &endsAt},
+	}}}}
+
+	h := NewHandler(db)
+	h.SetClock(clock.Fixed{At: endsAt.Add(time.Hour)})
+	err := h.RecomputeEventStatuses(context.Background(), nil)
+	if !errors.Is(err, errFakeDBUnsupported) {
+		t.Fatalf("err = %v, want the update to reach the database", err)
+	}
+	if last := db.calls[len(db.calls)-1]; !strings.Contains(last, "UPDATE events") {
+		t.Fatalf("last call = %q, want the status update", last)
+	}
+}
+
+// fakeInnhoppRow is an event_innhopps row as queryInnhopps reads it, with a
+// named primary landing area and the given risk assessment.
+func fakeInnhoppRow(id, eventID int64, risk string) []any {
+	row := make([]any, 37)
+	row[0], row[1], row[2], row[3] = id, eventID, 1, "Innhopp"
+	row[10] = ""
+	row[18] = sql.NullString{String: "Beach", Valid: true}
+	if risk != "" {
+		row[26] = sql.NullString{String: risk, Valid: true}
+	}
+	row[35], row[36] = 1, time.Now()
+	return row
+}
+
+func TestAutomaticMoveToLiveChecksInnhoppRequirements(t *testing.T) {
+	started := time.Now().Add(-time.Hour)
+	ends := time.Now().Add(48 * time.Hour)
+	eventRow := []any{int64(7), "launched", false, started, &ends}
+
+	for _, tc := range []struct {
+		risk string
+		want bool
+	}{
+		{"", false},
+		{"Reviewed", true},
+	} {
+		db := &fakeDB{results: []fakeResult{
+			{match: "FROM event_innhopps", rows: [][]any{fakeInnhoppRow(1, 7, tc.risk)}},
+			{match: "FROM events", rows: [][]any{eventRow}},
+		}}
+		err := NewHandler(db).RecomputeEventStatuses(context.Background(), nil)
+		updated := strings.Contains(db.calls[len(db.calls)-1], "UPDATE events")
+		if updated != tc.want {
+			t.Fatalf("recompute with risk %q: updated = %v (err %v), want %v", tc.risk, updated, err, tc.want)
+		}
+
+		events := []Event{{ID: 7, Status: "launched", StartsAt: started, EndsAt: &ends}}
+		db.calls = nil
+		err = NewHandler(db).syncEventStatuses(context.Background(), events)
+		updated = len(db.calls) > 0 && strings.Contains(db.calls[len(db.calls)-1], "UPDATE events")
+		if updated != tc.want {
+			t.Fatalf("list with risk %q: updated = %v (err %v), want %v", tc.risk, updated, err, tc.want)
+		}
+		if !tc.want && (err != nil || events[0].Status != "launched") {
+			t.Fatalf("list with risk %q: status = %q, err %v; want it to stay launched", tc.risk, events[0].Status, err)
+		}
+	}
+}
+
+func TestAutomaticMoveToLiveChecksMinimumCrew(t *testing.T) {
+	started := time.Now().Add(-time.Hour)
+	ends := time.Now().Add(48 * time.Hour)
+	db := &fakeDB{results: []fakeResult{
+		{match: "FROM event_innhopps", rows: [][]any{fakeInnhoppRow(1, 7, "Reviewed")}},
+		{match: "FROM crew_assignments", rows: [][]any{{2}}},
+		{match: "FROM events", rows: [][]any{{int64(7), "launched", false, started, &ends}}},
+	}}
+	h := NewHandler(db)
+	h.minimumLiveCrew = 5
+
+	if err := h.RecomputeEventStatuses(context.Background(), nil); err != nil {
+		t.Fatalf("RecomputeEventStatuses: %v", err)
+	}
+	events := []Event{{ID: 7, Status: "launched", StartsAt: started, EndsAt: &ends}}
+	if err := h.syncEventStatuses(context.Background(), events); err != nil {
+		t.Fatalf("syncEventStatuses: %v", err)
+	}
+	for _, call := range db.calls {
+		if strings.Contains(call, "UPDATE events") {
+			t.Fatalf("event short of crew went live: %s", call)
+		}
+	}
+	if events[0].Status != "launched" {
+		t.Fatalf("status = %q, want launched", events[0].Status)
+	}
+}
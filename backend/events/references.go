@@ -0,0 +1,67 @@
+package events
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// rowsQuerier is implemented by DB and pgx.Tx for helpers that read many rows.
+type rowsQuerier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// unknownReferencesError lists ids in a payload field that do not match any
+// stored record. Handlers answer it with 422 rather than letting the insert
+// leave dangling references behind.
+type unknownReferencesError struct {
+	field string
+	ids   []int64
+}
+
+func (e *unknownReferencesError) Error() string {
+	ids := make([]string, len(e.ids))
+	for i, id := range e.ids {
+		ids[i] = strconv.FormatInt(id, 10)
+	}
+	return e.field + " reference unknown ids: " + strings.Join(ids, ", ")
+}
+
+// ensureReferencesExist returns an *unknownReferencesError naming every id in
+// ids that has no row in table. The ids must already be normalized; table is
+// always a constant from this package.
+func ensureReferencesExist(ctx context.Context, db rowsQuerier, table, field string, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	rows, err := db.Query(ctx, `SELECT id FROM `+table+` WHERE id = ANY($1)`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	found := make(map[int64]bool, len(ids))
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		found[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var missing []int64
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return &unknownReferencesError{field: field, ids: missing}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package events
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/innhopp/central/backend/httpx"
+	"github.com/innhopp/central/backend/participants"
+)
+
+// maxRosterEvents caps how many events one overview request may ask for.
+const maxRosterEvents = 200
+
+// EventRoster is an event's participants with their profiles.
+type EventRoster struct {
+	EventID      int64                  `json:"event_id"`
+	Participants []participants.Profile `json:"participants"`
+}
+
+// fetchRostersForEvents loads the rosters of eventIDs with two queries
+// however many events there are: one for the links and one for the profiles.
+// Each roster is ordered by name.
+func (h *Handler) fetchRostersForEvents(ctx context.Context, eventIDs []int64) (map[int64][]participants.Profile, error) {
+	links, err := h.fetchParticipantsForEvents(ctx, eventIDs)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[int64]bool)
+	var participantIDs []int64
+	for _, ids := range links {
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				participantIDs = append(participantIDs, id)
+			}
+		}
+	}
+	profiles, err := participants.FetchByIDs(ctx, h.db, participantIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64][]participants.Profile, len(eventIDs))
+	for _, eventID := range eventIDs {
+		roster := make([]participants.Profile, 0, len(links[eventID]))
+		for _, id := range links[eventID] {
+			if profile, ok := profiles[id]; ok {
+				roster = append(roster, profile)
+			}
+		}
+		sort.Slice(roster, func(i, j int) bool {
+			if a, b := strings.ToLower(roster[i].FullName), strings.ToLower(roster[j].FullName); a != b {
+				return a < b
+			}
+			return roster[i].ID < roster[j].ID
+		})
+		result[eventID] = roster
+	}
+	return result, nil
+}
+
+func (h *Handler) getEventRoster(w http.ResponseWriter, r *http.Request) {
+	eventID, err := strconv.ParseInt(chi.URLParam(r, "eventID"), 10, 64)
+	if err != nil || eventID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid event id")
+		return
+	}
+	ctx := r.Context()
+	exists, err := eventExists(ctx, h.db, eventID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to validate event")
+		return
+	}
+	if !exists {
+		httpx.Error(w, http.StatusNotFound, "event not found")
+		return
+	}
+
+	rosters, err := h.fetchRostersForEvents(ctx, []int64{eventID})
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load roster")
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, rosters[eventID])
+}
+
+// listEventRosters is the overview of several events' rosters, named by
+// event_id as a comma-separated or repeated parameter. Unknown events come
+// back with an empty roster.
+func (h *Handler) listEventRosters(w http.ResponseWriter, r *http.Request) {
+	var eventIDs []int64
+	seen := make(map[int64]bool)
+	for _, value := range r.URL.Query()["event_id"] {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			id, err := strconv.ParseInt(part, 10, 64)
+			if err != nil || id <= 0 {
+				httpx.Error(w, http.StatusBadRequest, "event_id must be a list of positive integers")
+				return
+			}
+			if !seen[id] {
+				seen[id] = true
+				eventIDs = append(eventIDs, id)
+			}
+		}
+	}
+	if len(eventIDs) == 0 {
+		httpx.Error(w, http.StatusBadRequest, "event_id is required")
+		return
+	}
+	if len(eventIDs) > maxRosterEvents {
+		httpx.Error(w, http.StatusBadRequest, "at most "+strconv.Itoa(maxRosterEvents)+" events per request")
+		return
+	}
+
+	rosters, err := h.fetchRostersForEvents(r.Context(), eventIDs)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load rosters")
+		return
+	}
+	result := make([]EventRoster, len(eventIDs))
+	for i, eventID := range eventIDs {
+		result[i] = EventRoster{EventID: eventID, Participants: rosters[eventID]}
+	}
+	httpx.WriteJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,46 @@
+package events
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchRostersForEventsQueriesProfilesOnce(t *testing.T) {
+	db := &fakeDB{results: []fakeResult{{match: "FROM event_participants", rows: [][]any{
+		{int64(1), int64(10)}, {int64(1), int64(11)},
+		{int64(2), int64(11)}, {int64(3), int64(12)},
+		{int64(4), int64(13)}, {int64(5), int64(10)},
+	}}}}
+	h := NewHandler(db)
+
+	rosters, err := h.fetchRostersForEvents(context.Background(), []int64{1, 2, 3, 4, 5})
+	if err != nil {
+		t.Fatalf("fetchRostersForEvents() returned error: %v", err)
+	}
+	if len(db.calls) != 2 {
+		t.Fatalf("fetchRostersForEvents() issued %d queries for 5 events, want 2: %v", len(db.calls), db.calls)
+	}
+	if !strings.Contains(db.calls[1], "FROM participant_profiles p") || !strings.Contains(db.calls[1], "= ANY($1)") {
+		t.Fatalf("profile query = %s, want one participant_profiles lookup by ANY", db.calls[1])
+	}
+	if len(rosters) != 5 {
+		t.Fatalf("rosters = %v, want one entry per event", rosters)
+	}
+}
+
+func TestListEventRostersRejectsBadIDsBeforeTouchingDB(t *testing.T) {
+	for _, query := range []string{"", "?event_id=", "?event_id=1,x", "?event_id=0"} {
+		db := &fakeDB{}
+		rec := httptest.NewRecorder()
+		NewHandler(db).listEventRosters(rec, httptest.NewRequest(http.MethodGet, "/events/rosters"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%q: status = %d, want %d", query, rec.Code, http.StatusBadRequest)
+		}
+		if len(db.calls) != 0 {
+			t.Fatalf("%q: ran %v, want no queries", query, db.calls)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestMergeSeasonOnlyTouchesTheMergedSeasons(t *testing.T) {
+	db := openEventTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ensureEventTestSchema(t, ctx, db)
+
+	insertSeason := func(name string) int64 {
+		t.Helper()
+		var id int64
+		if err := db.QueryRow(ctx, `INSERT INTO seasons (name, starts_on) VALUES ($1, CURRENT_DATE - 30) RETURNING id`, name).Scan(&id); err != nil {
+			t.Fatalf("insert season failed: %v", err)
+		}
+		return id
+	}
+	insertEvent := func(seasonID int64, name string) int64 {
+		t.Helper()
+		var id int64
+		if err := db.QueryRow(ctx, `INSERT INTO events (season_id, name, starts_at) VALUES ($1, $2, NOW()) RETURNING id`, seasonID, name).Scan(&id); err != nil {
+			t.Fatalf("insert event failed: %v", err)
+		}
+		return id
+	}
+	link := func(eventID, seasonID int64) {
+		t.Helper()
+		if _, err := db.Exec(ctx, `INSERT INTO event_seasons (event_id, season_id) VALUES ($1, $2)`, eventID, seasonID); err != nil {
+			t.Fatalf("link event failed: %v", err)
+		}
+	}
+	// archive moves an event into events_archive with the given links, the
+	// way archiving snapshots it.
+	archive := func(eventID int64, links ...int64) {
+		t.Helper()
+		entries := make([]string, len(links))
+		for i, seasonID := range links {
+			entries[i] = fmt.Sprintf(`{"event_id": %d, "season_id": %d}`, eventID, seasonID)
+		}
+		if _, err := db.Exec(ctx, `
+			WITH moved AS (DELETE FROM events WHERE id = $1 RETURNING *)
+			INSERT INTO events_archive (id, season_id, name, starts_at, event, relations)
+			SELECT id, season_id, name, starts_at, to_jsonb(moved), jsonb_build_object('event_seasons', $2::jsonb)
+			FROM moved`, eventID, "["+strings.Join(entries, ",")+"]"); err != nil {
+			t.Fatalf("archive event failed: %v", err)
+		}
+		t.Cleanup(func() { db.Exec(context.Background(), `DELETE FROM events_archive WHERE id = $1`, eventID) })
+	}
+
+	targetID := insertSeason("Merge Target")
+	sourceID := insertSeason("Merge Source")
+	otherID := insertSeason("Unrelated Season")
+	for _, id := range []int64{targetID, sourceID, otherID} {
+		defer db.Exec(context.Background(), `DELETE FROM seasons WHERE id = $1`, id)
+	}
+
+	// Moves into the target, which it is already linked to.
+	movedID := insertEvent(sourceID, "Moved")
+	link(movedID, targetID)
+	// Has nothing to do with the merge, including its stale self-link.
+	bystanderID := insertEvent(otherID, "Bystander")
+	link(bystanderID, otherID)
+	// Archived events in and linked to the source.
+	archivedInSourceID := insertEvent(sourceID, "Archived In Source")
+	archive(archivedInSourceID, otherID, targetID)
+	archivedLinkedID := insertEvent(otherID, "Archived Linked")
+	archive(archivedLinkedID, sourceID, targetID)
+
+	router := chi.NewRouter()
+	router.Post("/seasons/{seasonID}/merge", NewHandler(db).mergeSeason)
+	rec := httptest.NewRecorder()
+	body := `{"source_id": ` + strconv.FormatInt(sourceID, 10) + `, "skip_bounds_check": true}`
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/seasons/"+strconv.FormatInt(targetID, 10)+"/merge", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("mergeSeason() status = %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	links := func(eventID int64) []int64 {
+		t.Helper()
+		var ids []int64
+		if err := db.QueryRow(ctx, `SELECT COALESCE(array_agg(season_id), '{}') FROM event_seasons WHERE event_id = $1`, eventID).Scan(&ids); err != nil {
+			t.Fatalf("load links failed: %v", err)
+		}
+		return ids
+	}
+	if got := links(movedID); len(got) != 0 {
+		t.Fatalf("moved event links = %v, want none now the target is its primary season", got)
+	}
+	if got := links(bystanderID); len(got) != 1 || got[0] != otherID {
+		t.Fatalf("bystander links = %v, want [%d] left alone", got, otherID)
+	}
+
+	archived := func(eventID int64) (int64, int64, []int64) {
+		t.Helper()
+		var column, snapshot int64
+		var linked []int64
+		if err := db.QueryRow(ctx, `
+			SELECT season_id, (event->>'season_id')::bigint,
+			       COALESCE((SELECT array_agg((l->>'season_id')::bigint) FROM jsonb_array_elements(relations->'event_seasons') l), '{}')
+			FROM events_archive WHERE id = $1`, eventID).Scan(&column, &snapshot, &linked); err != nil {
+			t.Fatalf("load archived event failed: %v", err)
+		}
+		sort.Slice(linked, func(i, j int) bool { return linked[i] < linked[j] })
+		return column, snapshot, linked
+	}
+	if column, snapshot, linked := archived(archivedInSourceID); column != targetID || snapshot != targetID || len(linked) != 1 || linked[0] != otherID {
+		t.Fatalf("archived event in source = season %d, snapshot %d, links %v; want %d, %d, [%d]", column, snapshot, linked, targetID, targetID, otherID)
+	}
+	if column, _, linked := archived(archivedLinkedID); column != otherID || len(linked) != 1 || linked[0] != targetID {
+		t.Fatalf("archived event linked to source = season %d, links %v; want %d, [%d]", column, linked, otherID, targetID)
+	}
+}
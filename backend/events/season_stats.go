@@ -0,0 +1,178 @@
+package events
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/innhopp/central/backend/httpx"
+	"github.com/innhopp/central/backend/internal/timeutil"
+)
+
+const (
+	defaultSeasonStatsTop = 10
+	maxSeasonStatsTop     = 50
+)
+
+// seasonEventsCTE selects the events of the season in $1, including events
+// linked to it as a co-host, that start at or after $2 and before $3. A NULL
+// bound leaves that side open.
+const seasonEventsCTE = `
+	WITH season_events AS (
+		SELECT e.id FROM events e
+		WHERE (e.season_id = $1 OR e.id IN (SELECT event_id FROM event_seasons WHERE season_id = $1))
+		  AND ($2::timestamptz IS NULL OR e.starts_at >= $2)
+		  AND ($3::timestamptz IS NULL OR e.starts_at < $3)
+	)`
+
+// SeasonStats summarises crew activity across a season's events.
+type SeasonStats struct {
+	SeasonID          int64                 `json:"season_id"`
+	SeasonName        string                `json:"season_name"`
+	From              string                `json:"from,omitempty"`
+	To                string                `json:"to,omitempty"`
+	EventCount        int                   `json:"event_count"`
+	LoadCount         int                   `json:"load_count"`
+	AssignmentCount   int                   `json:"assignment_count"`
+	AssignmentsByRole []RoleAssignmentCount `json:"assignments_by_role"`
+	TopCrew           []CrewActivity        `json:"top_crew"`
+}
+
+// RoleAssignmentCount is the number of crew assignments for one role.
+type RoleAssignmentCount struct {
+	Role             string `json:"role"`
+	AssignmentCount  int    `json:"assignment_count"`
+	ParticipantCount int    `json:"participant_count"`
+}
+
+// CrewActivity is one entry of the season's crew leaderboard.
+type CrewActivity struct {
+	ParticipantID   int64    `json:"participant_id"`
+	FullName        string   `json:"full_name"`
+	AssignmentCount int      `json:"assignment_count"`
+	LoadCount       int      `json:"load_count"`
+	Roles           []string `json:"roles"`
+}
+
+// getSeasonStats reports how many loads ran in a season, crew assignments per
+// role and the most active crew. ?top= sets the leaderboard size. ?from= and
+// ?to= (YYYY-MM-DD, both inclusive) limit it to events starting in that range.
+func (h *Handler) getSeasonStats(w http.ResponseWriter, r *http.Request) {
+	seasonID, err := strconv.ParseInt(chi.URLParam(r, "seasonID"), 10, 64)
+	if err != nil || seasonID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid season id")
+		return
+	}
+	query := r.URL.Query()
+	top := defaultSeasonStatsTop
+	if raw := strings.TrimSpace(query.Get("top")); raw != "" {
+		top, err = strconv.Atoi(raw)
+		if err != nil || top <= 0 || top > maxSeasonStatsTop {
+			httpx.Error(w, http.StatusBadRequest, "top must be between 1 and "+strconv.Itoa(maxSeasonStatsTop))
+			return
+		}
+	}
+
+	from, err := timeutil.ParseOptionalEventDate(query.Get("from"))
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "from must be a date (YYYY-MM-DD)")
+		return
+	}
+	to, err := timeutil.ParseOptionalEventDate(query.Get("to"))
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, "to must be a date (YYYY-MM-DD)")
+		return
+	}
+	if from != nil && to != nil && to.Before(*from) {
+		httpx.Error(w, http.StatusBadRequest, "to must not be before from")
+		return
+	}
+
+	ctx := r.Context()
+	stats := SeasonStats{SeasonID: seasonID, AssignmentsByRole: []RoleAssignmentCount{}, TopCrew: []CrewActivity{}}
+	var before *time.Time
+	if from != nil {
+		stats.From = from.Format(time.DateOnly)
+	}
+	if to != nil {
+		stats.To = to.Format(time.DateOnly)
+		end := to.AddDate(0, 0, 1)
+		before = &end
+	}
+	err = h.db.QueryRow(ctx, seasonEventsCTE+`
+		SELECT s.name,
+		       (SELECT COUNT(*) FROM season_events),
+		       (SELECT COUNT(*) FROM manifests m WHERE m.event_id IN (SELECT id FROM season_events))
+		FROM seasons s
+		WHERE s.id = $1`, seasonID, from, before,
+	).Scan(&stats.SeasonName, &stats.EventCount, &stats.LoadCount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		httpx.Error(w, http.StatusNotFound, "season not found")
+		return
+	}
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load season stats")
+		return
+	}
+
+	rows, err := h.db.Query(ctx, seasonEventsCTE+`
+		SELECT ca.role, COUNT(*), COUNT(DISTINCT ca.participant_id)
+		FROM crew_assignments ca
+		JOIN manifests m ON m.id = ca.manifest_id
+		WHERE m.event_id IN (SELECT id FROM season_events)
+		GROUP BY ca.role
+		ORDER BY COUNT(*) DESC, ca.role`, seasonID, from, before)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load season stats")
+		return
+	}
+	for rows.Next() {
+		var c RoleAssignmentCount
+		if err := rows.Scan(&c.Role, &c.AssignmentCount, &c.ParticipantCount); err != nil {
+			rows.Close()
+			httpx.Error(w, http.StatusInternalServerError, "failed to parse season stats")
+			return
+		}
+		stats.AssignmentCount += c.AssignmentCount
+		stats.AssignmentsByRole = append(stats.AssignmentsByRole, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load season stats")
+		return
+	}
+
+	rows, err = h.db.Query(ctx, seasonEventsCTE+`
+		SELECT p.id, p.full_name, COUNT(*), COUNT(DISTINCT ca.manifest_id), array_agg(DISTINCT ca.role ORDER BY ca.role)
+		FROM crew_assignments ca
+		JOIN manifests m ON m.id = ca.manifest_id
+		JOIN participant_profiles p ON p.id = ca.participant_id
+		WHERE m.event_id IN (SELECT id FROM season_events)
+		GROUP BY p.id, p.full_name
+		ORDER BY COUNT(*) DESC, p.full_name, p.id
+		LIMIT $4`, seasonID, from, before, top)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load season stats")
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c CrewActivity
+		if err := rows.Scan(&c.ParticipantID, &c.FullName, &c.AssignmentCount, &c.LoadCount, &c.Roles); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to parse season stats")
+			return
+		}
+		stats.TopCrew = append(stats.TopCrew, c)
+	}
+	if err := rows.Err(); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load season stats")
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, stats)
+}
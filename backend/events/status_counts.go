@@ -0,0 +1,54 @@
+package events
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/innhopp/central/backend/httpx"
+)
+
+// listEventStatusCounts counts events per status, with every status present
+// so a board can render empty columns. season_id limits the count to events
+// in that season, primary or linked, as on the event list. Archived events
+// are not counted.
+func (h *Handler) listEventStatusCounts(w http.ResponseWriter, r *http.Request) {
+	var args []any
+	where := ""
+	if raw := strings.TrimSpace(r.URL.Query().Get("season_id")); raw != "" {
+		seasonID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || seasonID <= 0 {
+			httpx.Error(w, http.StatusBadRequest, "season_id must be a positive integer")
+			return
+		}
+		args = append(args, seasonID)
+		where = ` WHERE season_id = $1 OR EXISTS (SELECT 1 FROM event_seasons es WHERE es.event_id = events.id AND es.season_id = $1)`
+	}
+
+	rows, err := h.db.Query(r.Context(), `SELECT status, COUNT(*) FROM events`+where+` GROUP BY status`, args...)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to count events")
+		return
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int, len(eventStatusValues))
+	for _, status := range eventStatusValues {
+		counts[status] = 0
+	}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to count events")
+			return
+		}
+		counts[status] += count
+	}
+	if err := rows.Err(); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to count events")
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, counts)
+}
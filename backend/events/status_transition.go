@@ -0,0 +1,57 @@
+package events
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// statusBlockers is what stops an event from moving to a status.
+type statusBlockers struct {
+	Innhopps []innhoppGap
+	Crew     *crewShortfall
+}
+
+func (b statusBlockers) blocked() bool {
+	return len(b.Innhopps) > 0 || b.Crew != nil
+}
+
+// checkStatusTransition returns what stops eventID from moving to status.
+// Every move goes through it: createEvent and updateEvent refuse a blocked
+// move with 422 unless staff override a crew shortfall, and the automatic
+// moves as an event's dates pass leave the event where it is.
+func (h *Handler) checkStatusTransition(ctx context.Context, db querier, eventID int64, status string) (statusBlockers, error) {
+	gaps, err := h.checkInnhoppRequirements(ctx, db, eventID, status)
+	if err != nil {
+		return statusBlockers{}, err
+	}
+	shortfall, err := h.checkMinimumCrew(ctx, db, eventID, status)
+	if err != nil {
+		return statusBlockers{}, err
+	}
+	return statusBlockers{Innhopps: gaps, Crew: shortfall}, nil
+}
+
+// nextEventStatus returns the status event moves to on its own at now. That
+// is the one its dates give, except that an event not ready to go live keeps
+// its status until it is. Moves to past and back to draft are never blocked.
+func (h *Handler) nextEventStatus(ctx context.Context, db querier, event Event, now time.Time) (string, error) {
+	next := deriveEventStatus(event, now)
+	if next != "live" || next == event.Status {
+		return next, nil
+	}
+	blockers, err := h.checkStatusTransition(ctx, db, event.ID, next)
+	if err != nil {
+		return "", err
+	}
+	if blockers.blocked() {
+		if len(blockers.Innhopps) > 0 {
+			log.Printf("events: event %d stays %s: %d innhopps are missing fields required for live", event.ID, event.Status, len(blockers.Innhopps))
+		}
+		if blockers.Crew != nil {
+			log.Printf("events: event %d stays %s: %d of %d required crew assigned", event.ID, event.Status, blockers.Crew.Assigned, blockers.Crew.Required)
+		}
+		return event.Status, nil
+	}
+	return next, nil
+}
@@ -0,0 +1,62 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestListEventTagsCountsEventsPerTag(t *testing.T) {
+	db := openEventTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ensureEventTestSchema(t, ctx, db)
+
+	var seasonID int64
+	if err := db.QueryRow(ctx, `INSERT INTO seasons (name, starts_on) VALUES ('Tags Season', CURRENT_DATE) RETURNING id`).Scan(&seasonID); err != nil {
+		t.Fatalf("insert season failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM seasons WHERE id = $1`, seasonID)
+
+	// Tags unique to this run, so events left by other tests do not count.
+	suffix := "-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	common, rare := "common"+suffix, "rare"+suffix
+	for _, tags := range [][]string{{common, rare}, {common}, {common}, {}} {
+		if _, err := db.Exec(ctx, `INSERT INTO events (season_id, name, starts_at, tags) VALUES ($1, 'Tagged Event', NOW(), $2)`, seasonID, tags); err != nil {
+			t.Fatalf("insert event failed: %v", err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	NewHandler(db).listEventTags(rec, httptest.NewRequest(http.MethodGet, "/events/tags", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("listEventTags() status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	var tags []eventTagCount
+	if err := json.NewDecoder(rec.Body).Decode(&tags); err != nil {
+		t.Fatalf("decode tags failed: %v", err)
+	}
+	position := map[string]int{}
+	for i, tag := range tags {
+		if tag.Tag == common || tag.Tag == rare {
+			position[tag.Tag] = i
+		}
+	}
+	commonAt, hasCommon := position[common]
+	rareAt, hasRare := position[rare]
+	if !hasCommon || !hasRare {
+		t.Fatalf("tags = %v, want both %q and %q", tags, common, rare)
+	}
+	if tags[commonAt].EventCount != 3 || tags[rareAt].EventCount != 1 {
+		t.Fatalf("counts = %d and %d, want 3 and 1", tags[commonAt].EventCount, tags[rareAt].EventCount)
+	}
+	if commonAt > rareAt {
+		t.Fatalf("%q listed after %q, want the most used tag first", common, rare)
+	}
+}
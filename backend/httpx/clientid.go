@@ -0,0 +1,47 @@
+package httpx
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+var errInvalidClientID = errors.New("client_id must be a UUID")
+
+// ParseClientID validates the optional client_id that offline clients send
+// when creating a record, so they can map their local id to the server's. It
+// returns nil for an absent or blank value and the lowercased UUID otherwise.
+func ParseClientID(raw *string) (*string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	id := strings.ToLower(strings.TrimSpace(*raw))
+	if id == "" {
+		return nil, nil
+	}
+	if len(id) != 36 {
+		return nil, errInvalidClientID
+	}
+	for i, c := range id {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return nil, errInvalidClientID
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdef", c) {
+				return nil, errInvalidClientID
+			}
+		}
+	}
+	return &id, nil
+}
+
+// ClientIDConflict reports that client_id already belongs to the record with
+// existingID, so a client retrying a create can pick up the server id.
+func ClientIDConflict(w http.ResponseWriter, existingID int64) {
+	WriteJSON(w, http.StatusConflict, map[string]any{
+		"error": "client_id is already in use",
+		"id":    existingID,
+	})
+}
@@ -0,0 +1,196 @@
+package httpx
+
+import (
+	"encoding/csv"
+	"errors"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AcceptsCSV reports whether the Accept header prefers text/csv over JSON.
+// JSON stays the default when the header is absent, lists only wildcards, or
+// ranks JSON at least as high as CSV.
+func AcceptsCSV(r *http.Request) bool {
+	var csvQ, jsonQ, wildcardQ float64
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(name, "q") {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(mediaType)) {
+		case "text/csv", "text/*":
+			csvQ = max(csvQ, q)
+		case "application/json":
+			jsonQ = max(jsonQ, q)
+		case "*/*", "application/*":
+			wildcardQ = max(wildcardQ, q)
+		}
+	}
+	return csvQ > 0 && csvQ > jsonQ && csvQ >= wildcardQ
+}
+
+// errCSVRows is WriteCSV refusing rows before writing anything.
+var errCSVRows = errors.New("csv: rows must be a slice of structs")
+
+// WriteList writes rows as CSV when the request asks for it and as JSON
+// otherwise. rows must be a slice of structs for CSV.
+func WriteList(w http.ResponseWriter, r *http.Request, status int, rows any) {
+	w.Header().Add("Vary", "Accept")
+	if AcceptsCSV(r) {
+		err := WriteCSV(w, status, rows, nil)
+		if errors.Is(err, errCSVRows) {
+			Error(w, http.StatusInternalServerError, "failed to encode csv")
+		} else if err != nil {
+			// The status and some rows are already out, so an error
+			// response would only corrupt the file.
+			log.Printf("httpx: csv response for %s cut short: %v", r.URL.Path, err)
+		}
+		return
+	}
+	WriteJSON(w, status, rows)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+type csvColumn struct {
+	name  string
+	index int
+}
+
+// WriteCSV writes a slice of structs as CSV. Columns are the struct's JSON
+// field names in declaration order; keep, when set, selects which of them to
+// write. Fields holding nested structs or slices of structs have no flat
+// representation and are left out. Nil pointers render as empty cells, times
+// as RFC3339 and slices of scalars joined with "; ". Nothing is written when
+// rows is not a slice of structs.
+func WriteCSV(w http.ResponseWriter, status int, rows any, keep func(column string) bool) error {
+	value := reflect.ValueOf(rows)
+	if value.Kind() != reflect.Slice {
+		return errCSVRows
+	}
+	elemType := value.Type().Elem()
+	if elemType.Kind() == reflect.Pointer {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return errCSVRows
+	}
+
+	var columns []csvColumn
+	for i := 0; i < elemType.NumField(); i++ {
+		field := elemType.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" || !csvFlat(field.Type) {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if keep != nil && !keep(name) {
+			continue
+		}
+		columns = append(columns, csvColumn{name: name, index: i})
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.WriteHeader(status)
+	writer := csv.NewWriter(w)
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		record[i] = column.name
+	}
+	if err := writer.Write(record); err != nil {
+		return err
+	}
+	for i := 0; i < value.Len(); i++ {
+		row := value.Index(i)
+		if row.Kind() == reflect.Pointer {
+			if row.IsNil() {
+				continue
+			}
+			row = row.Elem()
+		}
+		for j, column := range columns {
+			record[j] = csvCell(row.Field(column.index))
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func csvFlat(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return true
+	}
+	if t.Kind() == reflect.Slice {
+		return t.Elem().Kind() != reflect.Uint8 && csvScalar(t.Elem())
+	}
+	return csvScalar(t)
+}
+
+func csvScalar(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return t == timeType
+}
+
+func csvCell(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).Format(time.RFC3339)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return csvSafeString(v.String())
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Slice:
+		parts := make([]string, v.Len())
+		for i := range parts {
+			parts[i] = csvCell(v.Index(i))
+		}
+		return strings.Join(parts, "; ")
+	}
+	return ""
+}
+
+// csvSafeString stops spreadsheets from evaluating user-entered text as a
+// formula.
+func csvSafeString(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,55 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// DryRunHeader is set to "true" on responses to a ?dry_run=true request.
+const DryRunHeader = "X-Dry-Run"
+
+// Tx is the part of a database transaction FinishTx needs; pgx.Tx satisfies
+// it.
+type Tx interface {
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
+
+// dryRunResponse wraps the would-be body of a dry run.
+type dryRunResponse struct {
+	DryRun bool `json:"dry_run"`
+	Result any  `json:"result"`
+}
+
+// DryRun reports whether the request asked for ?dry_run=true.
+func DryRun(r *http.Request) bool {
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+	return dryRun
+}
+
+// FinishTx ends a mutating request whose work ran inside tx. Normally it
+// commits and writes body with status. For a dry run it rolls tx back and
+// writes {"dry_run": true, "result": body} with 200 and DryRunHeader, so the
+// caller sees what would have happened and nothing is kept. Any ids in the
+// result of a dry run were never persisted. Only a failed commit is returned;
+// the caller reports it.
+func FinishTx(ctx context.Context, w http.ResponseWriter, r *http.Request, tx Tx, status int, body any) error {
+	if DryRun(r) {
+		_ = tx.Rollback(ctx)
+		WriteDryRun(w, body)
+		return nil
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	WriteJSON(w, status, body)
+	return nil
+}
+
+// WriteDryRun writes the preview response for a dry run whose changes the
+// caller has already rolled back.
+func WriteDryRun(w http.ResponseWriter, body any) {
+	w.Header().Set(DryRunHeader, "true")
+	WriteJSON(w, http.StatusOK, dryRunResponse{DryRun: true, Result: body})
+}
@@ -0,0 +1,37 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// FlexibleBool is a boolean payload field that also accepts the strings and
+// numbers form serializers send: "true"/"false", "yes"/"no", "y"/"n",
+// "on"/"off" and "1"/"0" in any case, and the numbers 1 and 0. Use it as
+// *FlexibleBool for optional fields; null leaves the pointer nil. Anything
+// else, including "", is rejected rather than guessed.
+type FlexibleBool bool
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *FlexibleBool) UnmarshalJSON(data []byte) error {
+	raw := bytes.TrimSpace(data)
+	var value string
+	if len(raw) > 0 && raw[0] == '"' {
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+	} else {
+		value = string(raw)
+	}
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "yes", "y", "on", "1":
+		*b = true
+	case "false", "no", "n", "off", "0":
+		*b = false
+	default:
+		return fmt.Errorf("cannot read %s as a boolean", raw)
+	}
+	return nil
+}
@@ -3,17 +3,59 @@ package httpx
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 )
 
+// ErrEmptyBody is returned by DecodeJSON and DecodeJSONLenient when the body
+// is empty or only whitespace. A client that means "no fields" sends {}.
+var ErrEmptyBody = errors.New("request body is required")
+
 // DecodeJSON decodes the request body into dest enforcing strict JSON handling.
+// Unknown fields are rejected; use it for security-sensitive endpoints and as
+// the default.
 func DecodeJSON(r *http.Request, dest any) error {
+	return decodeJSON(r, dest, true)
+}
+
+// DecodeJSONLenient decodes the request body into dest, ignoring fields dest
+// does not declare. Use it for payloads that the frontend may extend ahead of
+// a backend deploy.
+func DecodeJSONLenient(r *http.Request, dest any) error {
+	return decodeJSON(r, dest, false)
+}
+
+// DecodeOptionalJSON is DecodeJSON for actions whose parameters are all
+// optional: an empty body leaves dest untouched and is not an error.
+func DecodeOptionalJSON(r *http.Request, dest any) error {
+	if err := decodeJSON(r, dest, true); err != nil && !errors.Is(err, ErrEmptyBody) {
+		return err
+	}
+	return nil
+}
+
+// PayloadError writes the 400 for a body the decoders rejected. An empty body
+// is reported as ErrEmptyBody so every endpoint answers it the same way;
+// anything else gets message.
+func PayloadError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, ErrEmptyBody) {
+		message = ErrEmptyBody.Error()
+	}
+	Error(w, http.StatusBadRequest, message)
+}
+
+func decodeJSON(r *http.Request, dest any, strict bool) error {
 	defer r.Body.Close()
 
 	decoder := json.NewDecoder(r.Body)
-	decoder.DisallowUnknownFields()
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
 
 	if err := decoder.Decode(dest); err != nil {
+		if errors.Is(err, io.EOF) {
+			return ErrEmptyBody
+		}
 		return err
 	}
 
@@ -0,0 +1,445 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestDecodeJSONRejectsUnknownFieldsUnlessLenient(t *testing.T) {
+	type payload struct {
+		Name string `json:"name"`
+	}
+	body := `{"name":"Voss","added_later":true}`
+
+	var strict payload
+	if err := DecodeJSON(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)), &strict); err == nil {
+		t.Fatal("DecodeJSON() expected error for unknown field")
+	}
+
+	var lenient payload
+	if err := DecodeJSONLenient(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)), &lenient); err != nil {
+		t.Fatalf("DecodeJSONLenient() returned error: %v", err)
+	}
+	if lenient.Name != "Voss" {
+		t.Fatalf("DecodeJSONLenient() name = %q, want Voss", lenient.Name)
+	}
+
+	if err := DecodeJSONLenient(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"a"}{}`)), &lenient); err == nil {
+		t.Fatal("DecodeJSONLenient() expected error for trailing data")
+	}
+}
+
+func TestDecodeJSONEmptyBodies(t *testing.T) {
+	type payload struct {
+		Days *int `json:"days"`
+	}
+	newRequest := func(body string) *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	}
+
+	for _, body := range []string{"", " \n\t "} {
+		var dest payload
+		if err := DecodeJSON(newRequest(body), &dest); !errors.Is(err, ErrEmptyBody) {
+			t.Errorf("DecodeJSON(%q) = %v, want ErrEmptyBody", body, err)
+		}
+		if err := DecodeJSONLenient(newRequest(body), &dest); !errors.Is(err, ErrEmptyBody) {
+			t.Errorf("DecodeJSONLenient(%q) = %v, want ErrEmptyBody", body, err)
+		}
+		if err := DecodeOptionalJSON(newRequest(body), &dest); err != nil || dest.Days != nil {
+			t.Errorf("DecodeOptionalJSON(%q) = %v with %+v, want nil and untouched", body, err, dest)
+		}
+	}
+
+	var dest payload
+	if err := DecodeJSON(newRequest("{}"), &dest); err != nil {
+		t.Errorf("DecodeJSON({}) = %v, want nil", err)
+	}
+	if err := DecodeJSON(newRequest(`{"days":`), &dest); err == nil || errors.Is(err, ErrEmptyBody) {
+		t.Errorf("DecodeJSON(truncated) = %v, want a syntax error", err)
+	}
+	if err := DecodeOptionalJSON(newRequest(`{"days":"x"}`), &dest); err == nil {
+		t.Error("DecodeOptionalJSON(bad) = nil, want error")
+	}
+
+	rec := httptest.NewRecorder()
+	PayloadError(rec, ErrEmptyBody, "invalid request payload")
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "request body is required") {
+		t.Errorf("PayloadError(ErrEmptyBody) = %d %s", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	PayloadError(rec, errors.New("bad"), "invalid request payload")
+	if !strings.Contains(rec.Body.String(), "invalid request payload") {
+		t.Errorf("PayloadError(other) = %s", rec.Body.String())
+	}
+}
+
+func TestFlexibleBoolAcceptsCommonForms(t *testing.T) {
+	type payload struct {
+		RescueBoat *FlexibleBool `json:"rescue_boat"`
+	}
+	for raw, want := range map[string]bool{
+		`true`: true, `false`: false,
+		`"true"`: true, `"FALSE"`: false,
+		`"yes"`: true, `"No"`: false,
+		`"y"`: true, `"n"`: false,
+		`"on"`: true, `"off"`: false,
+		`"1"`: true, `"0"`: false,
+		`1`: true, `0`: false,
+		`" Yes "`: true,
+	} {
+		var got payload
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"rescue_boat":`+raw+`}`))
+		if err := DecodeJSON(r, &got); err != nil {
+			t.Errorf("DecodeJSON(%s) returned error: %v", raw, err)
+			continue
+		}
+		if got.RescueBoat == nil || bool(*got.RescueBoat) != want {
+			t.Errorf("DecodeJSON(%s) = %v, want %v", raw, got.RescueBoat, want)
+		}
+	}
+
+	var absent payload
+	if err := DecodeJSON(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"rescue_boat":null}`)), &absent); err != nil || absent.RescueBoat != nil {
+		t.Fatalf("DecodeJSON(null) = %v, %v; want nil", absent.RescueBoat, err)
+	}
+
+	for _, raw := range []string{`""`, `"maybe"`, `2`, `1.0`, `[]`, `{}`} {
+		var got payload
+		if err := DecodeJSON(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"rescue_boat":`+raw+`}`)), &got); err == nil {
+			t.Errorf("DecodeJSON(%s) accepted a value that is not a boolean", raw)
+		}
+	}
+}
+
+func TestAcceptsCSV(t *testing.T) {
+	cases := map[string]bool{
+		"":                                 false,
+		"*/*":                              false,
+		"application/json":                 false,
+		"text/csv":                         true,
+		"text/csv, */*;q=0.8":              true,
+		"text/csv;q=0.5, */*":              false,
+		"application/json, text/csv":       false,
+		"application/json;q=0.9, text/csv": true,
+		"text/csv;q=0":                     false,
+	}
+	for accept, want := range cases {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", accept)
+		if got := AcceptsCSV(r); got != want {
+			t.Fatalf("AcceptsCSV(%q) = %v, want %v", accept, got, want)
+		}
+	}
+}
+
+func TestWriteListRendersCSV(t *testing.T) {
+	type nested struct{ ID int }
+	type row struct {
+		ID        int64      `json:"id"`
+		Name      string     `json:"name"`
+		Tags      []string   `json:"tags"`
+		Inspected *time.Time `json:"inspected_at,omitempty"`
+		Nested    []nested   `json:"nested"`
+		Secret    string     `json:"-"`
+	}
+	at := time.Date(2025, 6, 14, 9, 0, 0, 0, time.UTC)
+	rows := []row{
+		{ID: 1, Name: "Rig, main", Tags: []string{"a", "b"}, Inspected: &at, Nested: []nested{{1}}, Secret: "x"},
+		{ID: 2, Name: "=HYPERLINK()"},
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", "text/csv")
+	rec := httptest.NewRecorder()
+	WriteList(rec, r, http.StatusOK, rows)
+
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/csv") {
+		t.Fatalf("Content-Type = %q, want text/csv", got)
+	}
+	want := "id,name,tags,inspected_at\n1,\"Rig, main\",a; b,2025-06-14T09:00:00Z\n2,'=HYPERLINK(),,\n"
+	if rec.Body.String() != want {
+		t.Fatalf("body = %q, want %q", rec.Body.String(), want)
+	}
+
+	rec = httptest.NewRecorder()
+	WriteList(rec, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, rows)
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Fatalf("default Content-Type = %q, want application/json", got)
+	}
+}
+
+// failingWriter accepts the first write, fails the second and counts any
+// attempted after that.
+type failingWriter struct {
+	*httptest.ResponseRecorder
+	writes int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	if w.writes > 1 {
+		return 0, errors.New("connection reset")
+	}
+	return w.ResponseRecorder.Write(p)
+}
+
+func TestWriteListStopsWhenCSVFailsPartWay(t *testing.T) {
+	type row struct {
+		Name string `json:"name"`
+	}
+	rows := make([]row, 2000)
+	for i := range rows {
+		rows[i].Name = strings.Repeat("x", 64)
+	}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", "text/csv")
+
+	w := &failingWriter{ResponseRecorder: httptest.NewRecorder()}
+	WriteList(w, r, http.StatusOK, rows)
+	if w.writes != 2 {
+		t.Fatalf("writes = %d, want nothing written after the failed one", w.writes)
+	}
+
+	rec := httptest.NewRecorder()
+	WriteList(rec, r, http.StatusOK, []string{"not", "structs"})
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status for rows that are not structs = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestParseClientID(t *testing.T) {
+	raw := " 3F2504E0-4F89-11D3-9A0C-0305E82C3301 "
+	got, err := ParseClientID(&raw)
+	if err != nil || got == nil || *got != "3f2504e0-4f89-11d3-9a0c-0305e82c3301" {
+		t.Fatalf("ParseClientID(%q) = %v, %v", raw, got, err)
+	}
+	blank := ""
+	if got, err := ParseClientID(&blank); got != nil || err != nil {
+		t.Fatalf("ParseClientID(blank) = %v, %v, want nil, nil", got, err)
+	}
+	for _, bad := range []string{"local-1", "3f2504e0-4f89-11d3-9a0c-0305e82c330z", "3f2504e04f8911d39a0c0305e82c3301"} {
+		if _, err := ParseClientID(&bad); err == nil {
+			t.Fatalf("ParseClientID(%q) accepted an invalid id", bad)
+		}
+	}
+}
+
+func newMultipartRequest(t *testing.T, files map[string][]byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("caption", "Voss"); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		part, err := writer.CreateFormFile("images", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := part.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestDecodeMultipart(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n0000")
+	req := newMultipartRequest(t, map[string][]byte{`C:\photos\drop.png`: png})
+	form, err := DecodeMultipart(httptest.NewRecorder(), req, 1<<20)
+	if err != nil {
+		t.Fatalf("DecodeMultipart() returned error: %v", err)
+	}
+	defer form.Close()
+
+	if got := form.Value("caption"); got != "Voss" {
+		t.Fatalf("Value(caption) = %q, want Voss", got)
+	}
+	files := form.Files("")
+	if len(files) != 1 || files[0].Field != "images" || files[0].Name != "drop.png" {
+		t.Fatalf("Files() = %+v, want one images/drop.png", files)
+	}
+	data, mimeType, err := files[0].ReadAll(1 << 10)
+	if err != nil {
+		t.Fatalf("ReadAll() returned error: %v", err)
+	}
+	if !bytes.Equal(data, png) || mimeType != "image/png" {
+		t.Fatalf("ReadAll() = %q, %q, want png data", data, mimeType)
+	}
+	if _, _, err := files[0].ReadAll(4); !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("ReadAll() over limit error = %v, want ErrBodyTooLarge", err)
+	}
+}
+
+func TestDecodeMultipartRejectsBadRequests(t *testing.T) {
+	jsonReq := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	jsonReq.Header.Set("Content-Type", "application/json")
+	if _, err := DecodeMultipart(httptest.NewRecorder(), jsonReq, 1<<20); !errors.Is(err, ErrNotMultipart) {
+		t.Fatalf("DecodeMultipart() JSON error = %v, want ErrNotMultipart", err)
+	}
+
+	big := newMultipartRequest(t, map[string][]byte{"big.bin": bytes.Repeat([]byte("x"), 4096)})
+	if _, err := DecodeMultipart(httptest.NewRecorder(), big, 1024); !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("DecodeMultipart() oversized error = %v, want ErrBodyTooLarge", err)
+	}
+}
+
+func TestParsePage(t *testing.T) {
+	page, err := ParsePage(httptest.NewRequest(http.MethodGet, "/?limit=900&offset=20", nil), 100)
+	if err != nil || page.Limit != 100 || page.Offset != 20 {
+		t.Fatalf("ParsePage() = %+v, %v; want limit 100 offset 20", page, err)
+	}
+	clause, args := page.SQL(3)
+	if clause != " LIMIT $3 OFFSET $4" || len(args) != 2 {
+		t.Fatalf("SQL() = %q %v", clause, args)
+	}
+
+	page, err = ParsePage(httptest.NewRequest(http.MethodGet, "/?cursor=40", nil), 100)
+	if err != nil || page.Offset != 40 {
+		t.Fatalf("ParsePage(cursor) = %+v, %v; want offset 40", page, err)
+	}
+	if clause, args := (Page{}).SQL(1); clause != "" || args != nil {
+		t.Fatalf("unlimited SQL() = %q %v, want empty", clause, args)
+	}
+
+	for _, query := range []string{"limit=0", "limit=x", "offset=-1", "cursor=abc"} {
+		if _, err := ParsePage(httptest.NewRequest(http.MethodGet, "/?"+query, nil), 100); err == nil {
+			t.Fatalf("ParsePage(%s) expected error", query)
+		}
+	}
+}
+
+func TestWritePageEnvelopeIsOptIn(t *testing.T) {
+	rows := []string{"a", "b"}
+	page := Page{Limit: 2, Offset: 0, Total: 5}
+
+	rec := httptest.NewRecorder()
+	WritePage(rec, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, rows, page)
+	if got := strings.TrimSpace(rec.Body.String()); got != `["a","b"]` {
+		t.Fatalf("bare body = %s", got)
+	}
+	if got := rec.Header().Get("X-Total-Count"); got != "5" {
+		t.Fatalf("X-Total-Count = %q, want 5", got)
+	}
+
+	rec = httptest.NewRecorder()
+	WritePage(rec, httptest.NewRequest(http.MethodGet, "/?envelope=true", nil), http.StatusOK, rows, page)
+	want := `{"data":["a","b"],"page":{"limit":2,"offset":0,"total":5,"next_cursor":"2"}}`
+	if got := strings.TrimSpace(rec.Body.String()); got != want {
+		t.Fatalf("envelope body = %s, want %s", got, want)
+	}
+
+	rec = httptest.NewRecorder()
+	WritePage(rec, httptest.NewRequest(http.MethodGet, "/?envelope=true", nil), http.StatusOK, rows, Page{Limit: 2, Offset: 4, Total: 5})
+	if strings.Contains(rec.Body.String(), "next_cursor") {
+		t.Fatalf("last page body = %s, want no next_cursor", rec.Body.String())
+	}
+}
+
+func TestWriteCursorPageEnvelopeIsOptIn(t *testing.T) {
+	rows := []string{"a", "b"}
+	page := CursorPage{Limit: 2, NextCursor: "abc"}
+
+	rec := httptest.NewRecorder()
+	WriteCursorPage(rec, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, rows, page)
+	if got := strings.TrimSpace(rec.Body.String()); got != `["a","b"]` {
+		t.Fatalf("bare body = %s", got)
+	}
+	if got := rec.Header().Get("X-Next-Cursor"); got != "abc" {
+		t.Fatalf("X-Next-Cursor = %q, want abc", got)
+	}
+
+	rec = httptest.NewRecorder()
+	WriteCursorPage(rec, httptest.NewRequest(http.MethodGet, "/?envelope=true", nil), http.StatusOK, rows, page)
+	want := `{"data":["a","b"],"page":{"limit":2,"next_cursor":"abc"}}`
+	if got := strings.TrimSpace(rec.Body.String()); got != want {
+		t.Fatalf("envelope body = %s, want %s", got, want)
+	}
+
+	rec = httptest.NewRecorder()
+	WriteCursorPage(rec, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, rows, CursorPage{Limit: 2})
+	if _, ok := rec.Header()["X-Next-Cursor"]; ok {
+		t.Fatalf("last page sent X-Next-Cursor %q", rec.Header().Get("X-Next-Cursor"))
+	}
+}
+
+type fakeTx struct {
+	committed, rolledBack bool
+	commitErr             error
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	tx.committed = true
+	return tx.commitErr
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	tx.rolledBack = true
+	return nil
+}
+
+func TestFinishTxCommitsUnlessDryRun(t *testing.T) {
+	tx := &fakeTx{}
+	rec := httptest.NewRecorder()
+	if err := FinishTx(context.Background(), rec, httptest.NewRequest(http.MethodPost, "/", nil), tx, http.StatusCreated, []int{7}); err != nil {
+		t.Fatalf("FinishTx() error = %v", err)
+	}
+	if !tx.committed || tx.rolledBack {
+		t.Fatalf("committed=%v rolledBack=%v, want commit only", tx.committed, tx.rolledBack)
+	}
+	if rec.Code != http.StatusCreated || strings.TrimSpace(rec.Body.String()) != "[7]" {
+		t.Fatalf("response = %d %s", rec.Code, rec.Body.String())
+	}
+
+	tx = &fakeTx{}
+	rec = httptest.NewRecorder()
+	if err := FinishTx(context.Background(), rec, httptest.NewRequest(http.MethodPost, "/?dry_run=true", nil), tx, http.StatusCreated, []int{7}); err != nil {
+		t.Fatalf("FinishTx() dry run error = %v", err)
+	}
+	if tx.committed || !tx.rolledBack {
+		t.Fatalf("dry run committed=%v rolledBack=%v, want rollback only", tx.committed, tx.rolledBack)
+	}
+	if rec.Code != http.StatusOK || rec.Header().Get(DryRunHeader) != "true" {
+		t.Fatalf("dry run status = %d, %s = %q", rec.Code, DryRunHeader, rec.Header().Get(DryRunHeader))
+	}
+	if got := strings.TrimSpace(rec.Body.String()); got != `{"dry_run":true,"result":[7]}` {
+		t.Fatalf("dry run body = %s", got)
+	}
+
+	tx = &fakeTx{commitErr: errors.New("serialization failure")}
+	rec = httptest.NewRecorder()
+	if err := FinishTx(context.Background(), rec, httptest.NewRequest(http.MethodPost, "/", nil), tx, http.StatusOK, nil); err == nil {
+		t.Fatal("FinishTx() error = nil, want commit error")
+	}
+	if rec.Body.Len() != 0 {
+		t.Fatalf("body after failed commit = %s, want empty", rec.Body.String())
+	}
+}
+
+func TestUniqueViolation(t *testing.T) {
+	duplicate := fmt.Errorf("insert: %w", &pgconn.PgError{Code: "23505", ConstraintName: "things_name_key"})
+	if !UniqueViolation(duplicate, "things_name_key") || !UniqueViolation(duplicate, "") {
+		t.Fatal("UniqueViolation() = false for a wrapped duplicate key error")
+	}
+	if UniqueViolation(duplicate, "things_code_key") {
+		t.Fatal("UniqueViolation() = true for another constraint")
+	}
+	if UniqueViolation(&pgconn.PgError{Code: "23503"}, "") || UniqueViolation(errors.New("boom"), "") {
+		t.Fatal("UniqueViolation() = true for an error that is not a unique violation")
+	}
+}
@@ -0,0 +1,137 @@
+package httpx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+)
+
+// multipartMemory is how much of a multipart body is kept in memory; larger
+// file parts spill to temporary files that MultipartForm.Close removes.
+const multipartMemory = 8 << 20
+
+var (
+	// ErrNotMultipart is returned when the request is not multipart/form-data.
+	ErrNotMultipart = errors.New("request must be multipart/form-data")
+	// ErrBodyTooLarge is returned when a body exceeds the allowed size.
+	ErrBodyTooLarge = errors.New("request body is too large")
+)
+
+// MultipartForm is a parsed multipart/form-data request. Call Close when done
+// so temporary files are removed.
+type MultipartForm struct {
+	form *multipart.Form
+}
+
+// UploadedFile is one file part of a multipart request.
+type UploadedFile struct {
+	// Field is the form field the file was sent under.
+	Field string
+	// Name is the client's file name without any directory part.
+	Name   string
+	Size   int64
+	header *multipart.FileHeader
+}
+
+// DecodeMultipart parses a multipart/form-data body of at most maxBytes.
+// Oversized bodies fail with ErrBodyTooLarge and other content types with
+// ErrNotMultipart.
+func DecodeMultipart(w http.ResponseWriter, r *http.Request, maxBytes int64) (*MultipartForm, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		return nil, ErrNotMultipart
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+	if err := r.ParseMultipartForm(min(maxBytes, multipartMemory)); err != nil {
+		if r.MultipartForm != nil {
+			_ = r.MultipartForm.RemoveAll()
+		}
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, ErrBodyTooLarge
+		}
+		return nil, ErrNotMultipart
+	}
+	return &MultipartForm{form: r.MultipartForm}, nil
+}
+
+// Value returns the first value of a non-file field.
+func (f *MultipartForm) Value(name string) string {
+	if values := f.form.Value[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Files returns the files sent under field, in request order. An empty field
+// returns every file, ordered by field name.
+func (f *MultipartForm) Files(field string) []*UploadedFile {
+	fields := []string{field}
+	if field == "" {
+		fields = fields[:0]
+		for name := range f.form.File {
+			fields = append(fields, name)
+		}
+		sort.Strings(fields)
+	}
+	var files []*UploadedFile
+	for _, name := range fields {
+		for _, header := range f.form.File[name] {
+			files = append(files, &UploadedFile{
+				Field:  name,
+				Name:   baseFileName(header.Filename),
+				Size:   header.Size,
+				header: header,
+			})
+		}
+	}
+	return files
+}
+
+// baseFileName strips directories, including Windows ones, from a client
+// file name.
+func baseFileName(name string) string {
+	base := path.Base(strings.ReplaceAll(strings.TrimSpace(name), `\`, "/"))
+	if base == "." || base == "/" {
+		return ""
+	}
+	return base
+}
+
+// Close removes any temporary files backing the form.
+func (f *MultipartForm) Close() error {
+	return f.form.RemoveAll()
+}
+
+// Open returns a reader over the file's contents.
+func (u *UploadedFile) Open() (multipart.File, error) {
+	return u.header.Open()
+}
+
+// ReadAll reads a file of at most maxBytes and sniffs its content type from
+// the data rather than trusting the client's header. Larger files fail with
+// ErrBodyTooLarge.
+func (u *UploadedFile) ReadAll(maxBytes int64) (data []byte, mimeType string, err error) {
+	if u.Size > maxBytes {
+		return nil, "", fmt.Errorf("%s: %w", u.Name, ErrBodyTooLarge)
+	}
+	file, err := u.Open()
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+	data, err = io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, "", fmt.Errorf("%s: %w", u.Name, ErrBodyTooLarge)
+	}
+	return data, http.DetectContentType(data), nil
+}
@@ -0,0 +1,129 @@
+package httpx
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// TotalCountColumn is the select expression list queries add to learn the
+// unpaginated row count in the same query as the page.
+const TotalCountColumn = "COUNT(*) OVER()"
+
+// Page describes the slice of a list a response holds. Limit is 0 when the
+// request did not ask for a limit. NextCursor is set while rows remain and is
+// passed back as ?cursor= for the following page.
+type Page struct {
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	Total      int    `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// pageEnvelope is the ?envelope=true shape of a list response.
+type pageEnvelope struct {
+	Data any  `json:"data"`
+	Page Page `json:"page"`
+}
+
+// ParsePage reads limit and offset, or the cursor returned by a previous page
+// in place of offset, from the query string. limit is capped at maxLimit.
+func ParsePage(r *http.Request, maxLimit int) (Page, error) {
+	query := r.URL.Query()
+	var page Page
+	if raw := strings.TrimSpace(query.Get("limit")); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return Page{}, errors.New("limit must be a positive integer")
+		}
+		page.Limit = min(limit, maxLimit)
+	}
+	offsetParam := "offset"
+	raw := strings.TrimSpace(query.Get("offset"))
+	if cursor := strings.TrimSpace(query.Get("cursor")); cursor != "" {
+		offsetParam, raw = "cursor", cursor
+	}
+	if raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return Page{}, errors.New(offsetParam + " must be a non-negative integer")
+		}
+		page.Offset = offset
+	}
+	return page, nil
+}
+
+// SQL returns the LIMIT/OFFSET clause for the page, using placeholders
+// numbered from next. args holds their values; both are empty for an
+// unlimited first page.
+func (p Page) SQL(next int) (clause string, args []any) {
+	if p.Limit > 0 {
+		clause += " LIMIT $" + strconv.Itoa(next)
+		args = append(args, p.Limit)
+		next++
+	}
+	if p.Offset > 0 {
+		clause += " OFFSET $" + strconv.Itoa(next)
+		args = append(args, p.Offset)
+	}
+	return clause, args
+}
+
+// NeedsCount reports whether a query that returned rows rows for the page
+// must count the total separately: a page past the end has no rows to carry
+// the windowed count.
+func (p Page) NeedsCount(rows int) bool {
+	return rows == 0 && p.Offset > 0
+}
+
+// WantsEnvelope reports whether the request asked for ?envelope=true.
+func WantsEnvelope(r *http.Request) bool {
+	wants, _ := strconv.ParseBool(r.URL.Query().Get("envelope"))
+	return wants
+}
+
+// WritePage writes one page of a list. The total is always sent as
+// X-Total-Count. With ?envelope=true the rows are wrapped as
+// {data, page}; otherwise they go out as WriteList would send them. Callers
+// count the total themselves when NeedsCount says the page had no rows to
+// carry it.
+func WritePage(w http.ResponseWriter, r *http.Request, status int, rows any, page Page) {
+	if page.Limit > 0 && page.Offset+page.Limit < page.Total {
+		page.NextCursor = strconv.Itoa(page.Offset + page.Limit)
+	}
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	if WantsEnvelope(r) && !AcceptsCSV(r) {
+		WriteJSON(w, status, pageEnvelope{Data: rows, Page: page})
+		return
+	}
+	WriteList(w, r, status, rows)
+}
+
+// CursorPage describes a page of a keyset-paged list, which has no offset or
+// total to report. NextCursor is set while rows remain.
+type CursorPage struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// cursorEnvelope is the ?envelope=true shape of a keyset-paged list.
+type cursorEnvelope struct {
+	Data any        `json:"data"`
+	Page CursorPage `json:"page"`
+}
+
+// WriteCursorPage writes one page of a keyset-paged list. The cursor for the
+// following page, if any, is always sent as X-Next-Cursor. With
+// ?envelope=true the rows are wrapped as {data, page}; otherwise they go out
+// as WriteList would send them.
+func WriteCursorPage(w http.ResponseWriter, r *http.Request, status int, rows any, page CursorPage) {
+	if page.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+	}
+	if WantsEnvelope(r) && !AcceptsCSV(r) {
+		WriteJSON(w, status, cursorEnvelope{Data: rows, Page: page})
+		return
+	}
+	WriteList(w, r, status, rows)
+}
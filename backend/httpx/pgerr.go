@@ -0,0 +1,17 @@
+package httpx
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// UniqueViolation reports whether err is Postgres refusing a duplicate key
+// (SQLSTATE 23505) on constraint, or on any constraint when constraint is "".
+func UniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.SQLState() != "23505" {
+		return false
+	}
+	return constraint == "" || pgErr.ConstraintName == constraint
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/innhopp/central/backend/airfields"
 	"github.com/innhopp/central/backend/httpx"
 	"github.com/innhopp/central/backend/internal/timeutil"
 	"github.com/innhopp/central/backend/logistics"
@@ -22,10 +23,13 @@ import (
 
 type Handler struct {
 	db *pgxpool.Pool
+	// takeoffSuggestRadiusKm is how near an airfield must be to a saved
+	// innhopp to be suggested as its takeoff airfield; zero disables it.
+	takeoffSuggestRadiusKm float64
 }
 
 func NewHandler(db *pgxpool.Pool) *Handler {
-	return &Handler{db: db}
+	return &Handler{db: db, takeoffSuggestRadiusKm: airfields.SuggestRadiusKmFromEnv()}
 }
 
 type LandingArea struct {
@@ -48,36 +52,39 @@ type InnhoppImage struct {
 }
 
 type Innhopp struct {
-	ID                    int64          `json:"id"`
-	EventID               int64          `json:"event_id"`
-	Sequence              int            `json:"sequence"`
-	Name                  string         `json:"name"`
-	AircraftID            *int64         `json:"aircraft_id,omitempty"`
-	Coordinates           string         `json:"coordinates,omitempty"`
-	TakeoffAirfieldID     *int64         `json:"takeoff_airfield_id,omitempty"`
-	LandingAirfieldID     *int64         `json:"landing_airfield_id,omitempty"`
-	ScheduledAt           *time.Time     `json:"scheduled_at,omitempty"`
-	Elevation             *int           `json:"elevation,omitempty"`
-	Notes                 string         `json:"notes,omitempty"`
-	ReasonForChoice       string         `json:"reason_for_choice,omitempty"`
-	AdjustAltimeterAAD    string         `json:"adjust_altimeter_aad,omitempty"`
-	Notam                 string         `json:"notam,omitempty"`
-	DistanceByAir         *float64       `json:"distance_by_air,omitempty"`
-	DistanceByRoad        *float64       `json:"distance_by_road,omitempty"`
-	LandingDistanceByAir  *float64       `json:"landing_distance_by_air,omitempty"`
-	LandingDistanceByRoad *float64       `json:"landing_distance_by_road,omitempty"`
-	PrimaryLandingArea    LandingArea    `json:"primary_landing_area"`
-	SecondaryLandingArea  LandingArea    `json:"secondary_landing_area"`
-	RiskAssessment        string         `json:"risk_assessment,omitempty"`
-	SafetyPrecautions     string         `json:"safety_precautions,omitempty"`
-	Jumprun               string         `json:"jumprun,omitempty"`
-	Hospital              string         `json:"hospital,omitempty"`
-	RescueBoat            *bool          `json:"rescue_boat,omitempty"`
-	MinimumRequirements   string         `json:"minimum_requirements,omitempty"`
-	LandOwners            []LandOwner    `json:"land_owners,omitempty"`
-	LandOwnerPermission   *bool          `json:"land_owner_permission,omitempty"`
-	ImageFiles            []InnhoppImage `json:"image_files,omitempty"`
-	CreatedAt             time.Time      `json:"created_at"`
+	ID                int64  `json:"id"`
+	EventID           int64  `json:"event_id"`
+	Sequence          int    `json:"sequence"`
+	Name              string `json:"name"`
+	AircraftID        *int64 `json:"aircraft_id,omitempty"`
+	Coordinates       string `json:"coordinates,omitempty"`
+	TakeoffAirfieldID *int64 `json:"takeoff_airfield_id,omitempty"`
+	LandingAirfieldID *int64 `json:"landing_airfield_id,omitempty"`
+	// SuggestedTakeoffAirfieldID is only sent in update responses.
+	SuggestedTakeoffAirfieldID *int64         `json:"suggested_takeoff_airfield_id,omitempty"`
+	ScheduledAt                *time.Time     `json:"scheduled_at,omitempty"`
+	Elevation                  *int           `json:"elevation,omitempty"`
+	Notes                      string         `json:"notes,omitempty"`
+	ReasonForChoice            string         `json:"reason_for_choice,omitempty"`
+	AdjustAltimeterAAD         string         `json:"adjust_altimeter_aad,omitempty"`
+	Notam                      string         `json:"notam,omitempty"`
+	DistanceByAir              *float64       `json:"distance_by_air,omitempty"`
+	DistanceByRoad             *float64       `json:"distance_by_road,omitempty"`
+	LandingDistanceByAir       *float64       `json:"landing_distance_by_air,omitempty"`
+	LandingDistanceByRoad      *float64       `json:"landing_distance_by_road,omitempty"`
+	PrimaryLandingArea         LandingArea    `json:"primary_landing_area"`
+	SecondaryLandingArea       LandingArea    `json:"secondary_landing_area"`
+	RiskAssessment             string         `json:"risk_assessment,omitempty"`
+	SafetyPrecautions          string         `json:"safety_precautions,omitempty"`
+	Jumprun                    string         `json:"jumprun,omitempty"`
+	Hospital                   string         `json:"hospital,omitempty"`
+	RescueBoat                 *bool          `json:"rescue_boat,omitempty"`
+	MinimumRequirements        string         `json:"minimum_requirements,omitempty"`
+	LandOwners                 []LandOwner    `json:"land_owners,omitempty"`
+	LandOwnerPermission        *bool          `json:"land_owner_permission,omitempty"`
+	ImageFiles                 []InnhoppImage `json:"image_files,omitempty"`
+	Version                    int            `json:"version"`
+	CreatedAt                  time.Time      `json:"created_at"`
 }
 
 type landingAreaPayload struct {
@@ -94,33 +101,34 @@ type landOwnerPayload struct {
 }
 
 type payload struct {
-	Sequence              *int               `json:"sequence"`
-	Name                  string             `json:"name"`
-	AircraftID            *int64             `json:"aircraft_id"`
-	Coordinates           string             `json:"coordinates"`
-	ScheduledAt           string             `json:"scheduled_at"`
-	Elevation             *int               `json:"elevation"`
-	Notes                 string             `json:"notes"`
-	TakeoffAirfieldID     *int64             `json:"takeoff_airfield_id"`
-	LandingAirfieldID     *int64             `json:"landing_airfield_id"`
-	ReasonForChoice       string             `json:"reason_for_choice"`
-	AdjustAltimeterAAD    string             `json:"adjust_altimeter_aad"`
-	Notam                 string             `json:"notam"`
-	DistanceByAir         *float64           `json:"distance_by_air"`
-	DistanceByRoad        *float64           `json:"distance_by_road"`
-	LandingDistanceByAir  *float64           `json:"landing_distance_by_air"`
-	LandingDistanceByRoad *float64           `json:"landing_distance_by_road"`
-	PrimaryLandingArea    landingAreaPayload `json:"primary_landing_area"`
-	SecondaryLandingArea  landingAreaPayload `json:"secondary_landing_area"`
-	RiskAssessment        string             `json:"risk_assessment"`
-	SafetyPrecautions     string             `json:"safety_precautions"`
-	Jumprun               string             `json:"jumprun"`
-	Hospital              string             `json:"hospital"`
-	RescueBoat            *bool              `json:"rescue_boat"`
-	MinimumRequirements   string             `json:"minimum_requirements"`
-	LandOwners            []landOwnerPayload `json:"land_owners"`
-	LandOwnerPermission   *bool              `json:"land_owner_permission"`
-	ImageFiles            *[]InnhoppImage    `json:"image_files"`
+	Sequence              *int                `json:"sequence"`
+	Name                  string              `json:"name"`
+	AircraftID            *int64              `json:"aircraft_id"`
+	Coordinates           string              `json:"coordinates"`
+	ScheduledAt           string              `json:"scheduled_at"`
+	Elevation             *int                `json:"elevation"`
+	Notes                 string              `json:"notes"`
+	TakeoffAirfieldID     *int64              `json:"takeoff_airfield_id"`
+	LandingAirfieldID     *int64              `json:"landing_airfield_id"`
+	ReasonForChoice       string              `json:"reason_for_choice"`
+	AdjustAltimeterAAD    string              `json:"adjust_altimeter_aad"`
+	Notam                 string              `json:"notam"`
+	DistanceByAir         *float64            `json:"distance_by_air"`
+	DistanceByRoad        *float64            `json:"distance_by_road"`
+	LandingDistanceByAir  *float64            `json:"landing_distance_by_air"`
+	LandingDistanceByRoad *float64            `json:"landing_distance_by_road"`
+	PrimaryLandingArea    landingAreaPayload  `json:"primary_landing_area"`
+	SecondaryLandingArea  landingAreaPayload  `json:"secondary_landing_area"`
+	RiskAssessment        string              `json:"risk_assessment"`
+	SafetyPrecautions     string              `json:"safety_precautions"`
+	Jumprun               string              `json:"jumprun"`
+	Hospital              string              `json:"hospital"`
+	RescueBoat            *httpx.FlexibleBool `json:"rescue_boat"`
+	MinimumRequirements   string              `json:"minimum_requirements"`
+	LandOwners            []landOwnerPayload  `json:"land_owners"`
+	LandOwnerPermission   *httpx.FlexibleBool `json:"land_owner_permission"`
+	ImageFiles            *[]InnhoppImage     `json:"image_files"`
+	Version               *int                `json:"version"`
 }
 
 func normalizeLandingAreaPayload(p landingAreaPayload) LandingArea {
@@ -222,9 +230,23 @@ func (h *Handler) Routes(enforcer *rbac.Enforcer) chi.Router {
 	r.With(enforcer.Authorize(rbac.PermissionViewEvents)).Get("/{innhoppID}", h.getInnhopp)
 	r.With(enforcer.Authorize(rbac.PermissionManageEvents)).Put("/{innhoppID}", h.updateInnhopp)
 	r.With(enforcer.Authorize(rbac.PermissionManageEvents)).Delete("/{innhoppID}", h.deleteInnhopp)
+	r.With(enforcer.Authorize(rbac.PermissionManageEvents)).Post("/{innhoppID}/images", h.uploadImages)
+	r.With(enforcer.Authorize(rbac.PermissionManageEvents)).Delete("/{innhoppID}/images/{name}", h.deleteImage)
+	r.With(enforcer.Authorize(rbac.PermissionViewEvents)).Get("/{innhoppID}/revisions", h.listRevisions)
+	r.With(enforcer.Authorize(rbac.PermissionViewEvents)).Get("/{innhoppID}/revisions/diff", h.diffRevisions)
+	r.With(enforcer.Authorize(rbac.PermissionViewEvents)).Get("/{innhoppID}/revisions/{revisionID}", h.getRevision)
 	return r
 }
 
+// innhoppColumns lists the event_innhopps columns in the order scanInnhopp
+// expects them.
+const innhoppColumns = `id, event_id, sequence, name, aircraft_id, coordinates, takeoff_airfield_id, landing_airfield_id, elevation, scheduled_at, notes,
+       reason_for_choice, adjust_altimeter_aad, notam, distance_by_air, distance_by_road, landing_distance_by_air, landing_distance_by_road,
+       primary_landing_area_name, primary_landing_area_description, primary_landing_area_size, primary_landing_area_obstacles,
+       secondary_landing_area_name, secondary_landing_area_description, secondary_landing_area_size, secondary_landing_area_obstacles,
+       risk_assessment, safety_precautions, jumprun, hospital, rescue_boat, minimum_requirements, image_files, land_owners, land_owner_permission,
+       version, created_at`
+
 func scanInnhopp(row pgx.Row) (Innhopp, error) {
 	var innhopp Innhopp
 	var scheduled sql.NullTime
@@ -289,6 +311,7 @@ func scanInnhopp(row pgx.Row) (Innhopp, error) {
 		&imageFilesRaw,
 		&landOwnersRaw,
 		&landOwnerPermission,
+		&innhopp.Version,
 		&innhopp.CreatedAt,
 	); err != nil {
 		return innhopp, err
@@ -373,16 +396,7 @@ func (h *Handler) getInnhopp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	row := h.db.QueryRow(r.Context(),
-		`SELECT id, event_id, sequence, name, aircraft_id, coordinates, takeoff_airfield_id, landing_airfield_id, elevation, scheduled_at, notes,
-                reason_for_choice, adjust_altimeter_aad, notam, distance_by_air, distance_by_road, landing_distance_by_air, landing_distance_by_road,
-                primary_landing_area_name, primary_landing_area_description, primary_landing_area_size, primary_landing_area_obstacles,
-                secondary_landing_area_name, secondary_landing_area_description, secondary_landing_area_size, secondary_landing_area_obstacles,
-                risk_assessment, safety_precautions, jumprun, hospital, rescue_boat, minimum_requirements, image_files, land_owners, land_owner_permission,
-                created_at
-         FROM event_innhopps WHERE id = $1`,
-		innhoppID,
-	)
+	row := h.db.QueryRow(r.Context(), `SELECT `+innhoppColumns+` FROM event_innhopps WHERE id = $1`, innhoppID)
 	innhopp, scanErr := scanInnhopp(row)
 	if scanErr != nil {
 		if errors.Is(scanErr, pgx.ErrNoRows) {
@@ -406,7 +420,11 @@ func (h *Handler) updateInnhopp(w http.ResponseWriter, r *http.Request) {
 
 	var p payload
 	if err := httpx.DecodeJSON(r, &p); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
+		return
+	}
+	if p.Version == nil {
+		httpx.Error(w, http.StatusBadRequest, "version is required")
 		return
 	}
 
@@ -552,7 +570,30 @@ func (h *Handler) updateInnhopp(w http.ResponseWriter, r *http.Request) {
 	hospital := strings.TrimSpace(p.Hospital)
 	minimum := strings.TrimSpace(p.MinimumRequirements)
 
-	row := h.db.QueryRow(r.Context(),
+	tx, err := h.db.Begin(r.Context())
+	if err != nil {
+		logUpdateFailure(innhoppID, p, err, "begin")
+		httpx.Error(w, http.StatusInternalServerError, "failed to update innhopp")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	previous, err := LoadForUpdate(r.Context(), tx, innhoppID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			httpx.Error(w, http.StatusNotFound, "innhopp not found")
+			return
+		}
+		logUpdateFailure(innhoppID, p, err, "load_previous")
+		httpx.Error(w, http.StatusInternalServerError, "failed to update innhopp")
+		return
+	}
+	if previous.Version != *p.Version {
+		httpx.Error(w, http.StatusConflict, "innhopp was modified by someone else; reload and try again")
+		return
+	}
+
+	row := tx.QueryRow(r.Context(),
 		`UPDATE event_innhopps
          SET sequence = $1, name = $2, aircraft_id = $3, coordinates = $4, takeoff_airfield_id = $5, elevation = $6, scheduled_at = $7, notes = $8,
              reason_for_choice = $9, adjust_altimeter_aad = $10, notam = $11, distance_by_air = $12, distance_by_road = $13,
@@ -560,32 +601,35 @@ func (h *Handler) updateInnhopp(w http.ResponseWriter, r *http.Request) {
              primary_landing_area_name = $17, primary_landing_area_description = $18, primary_landing_area_size = $19, primary_landing_area_obstacles = $20,
              secondary_landing_area_name = $21, secondary_landing_area_description = $22, secondary_landing_area_size = $23, secondary_landing_area_obstacles = $24,
              risk_assessment = $25, safety_precautions = $26, jumprun = $27, hospital = $28, rescue_boat = $29, minimum_requirements = $30,
-             image_files = COALESCE($31::jsonb, image_files), land_owners = $32::jsonb, land_owner_permission = $33
-         WHERE id = $34
-         RETURNING id, event_id, sequence, name, aircraft_id, coordinates, takeoff_airfield_id, landing_airfield_id, elevation, scheduled_at, notes,
-                   reason_for_choice, adjust_altimeter_aad, notam, distance_by_air, distance_by_road, landing_distance_by_air, landing_distance_by_road,
-                   primary_landing_area_name, primary_landing_area_description, primary_landing_area_size, primary_landing_area_obstacles,
-                   secondary_landing_area_name, secondary_landing_area_description, secondary_landing_area_size, secondary_landing_area_obstacles,
-                   risk_assessment, safety_precautions, jumprun, hospital, rescue_boat, minimum_requirements, image_files, land_owners, land_owner_permission,
-                   created_at`,
+             image_files = COALESCE($31::jsonb, image_files), land_owners = $32::jsonb, land_owner_permission = $33,
+             version = version + 1
+         WHERE id = $34 AND version = $35
+         RETURNING `+innhoppColumns,
 		seq, name, p.AircraftID, coords, p.TakeoffAirfieldID, elevation, scheduled, strings.TrimSpace(p.Notes),
 		reason, adjust, notam, distanceByAir, distanceByRoad, p.LandingAirfieldID, landingDistanceByAir, landingDistanceByRoad,
 		primaryLanding.Name, primaryLanding.Description, primaryLanding.Size, primaryLanding.Obstacles,
 		secondaryLanding.Name, secondaryLanding.Description, secondaryLanding.Size, secondaryLanding.Obstacles,
-		risk, safety, jumprun, hospital, p.RescueBoat, minimum, imageFilesJSONText, ownersJSONText, p.LandOwnerPermission, innhoppID,
+		risk, safety, jumprun, hospital, (*bool)(p.RescueBoat), minimum, imageFilesJSONText, ownersJSONText, (*bool)(p.LandOwnerPermission), innhoppID, *p.Version,
 	)
 
 	innhopp, scanErr := scanInnhopp(row)
 	if scanErr != nil {
-		if errors.Is(scanErr, pgx.ErrNoRows) {
-			httpx.Error(w, http.StatusNotFound, "innhopp not found")
-			return
-		}
 		logUpdateFailure(innhoppID, p, scanErr, "scan_updated_row")
 		httpx.Error(w, http.StatusInternalServerError, "failed to update innhopp")
 		return
 	}
 
+	if err := RecordRevision(r.Context(), tx, previous); err != nil {
+		logUpdateFailure(innhoppID, p, err, "insert_revision")
+		httpx.Error(w, http.StatusInternalServerError, "failed to record innhopp revision")
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		logUpdateFailure(innhoppID, p, err, "commit")
+		httpx.Error(w, http.StatusInternalServerError, "failed to update innhopp")
+		return
+	}
+
 	if innhopp.TakeoffAirfieldID != nil {
 		if _, err := h.db.Exec(
 			r.Context(),
@@ -616,6 +660,12 @@ func (h *Handler) updateInnhopp(w http.ResponseWriter, r *http.Request) {
 		logUpdateFailure(innhoppID, p, err, "recalculate_route_durations")
 	}
 
+	// A failed lookup only loses the suggestion.
+	suggested, err := airfields.SuggestTakeoff(r.Context(), h.db, innhopp.Coordinates, innhopp.TakeoffAirfieldID, h.takeoffSuggestRadiusKm)
+	if err != nil {
+		log.Printf("innhopps: takeoff airfield suggestion for innhopp %d failed: %v", innhopp.ID, err)
+	}
+	innhopp.SuggestedTakeoffAirfieldID = suggested
 	httpx.WriteJSON(w, http.StatusOK, innhopp)
 }
 
@@ -0,0 +1,202 @@
+package innhopps
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestUpdateInnhoppChecksVersionAndRecordsRevision(t *testing.T) {
+	db := openInnhoppTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ensureInnhoppTestSchema(t, ctx, db)
+
+	var seasonID, eventID, innhoppID int64
+	if err := db.QueryRow(ctx, `INSERT INTO seasons (name, starts_on) VALUES ('Version Season', CURRENT_DATE) RETURNING id`).Scan(&seasonID); err != nil {
+		t.Fatalf("insert season failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM seasons WHERE id = $1`, seasonID)
+	if err := db.QueryRow(ctx, `INSERT INTO events (season_id, name, starts_at) VALUES ($1, 'Version Event', NOW()) RETURNING id`, seasonID).Scan(&eventID); err != nil {
+		t.Fatalf("insert event failed: %v", err)
+	}
+	if err := db.QueryRow(ctx, `INSERT INTO event_innhopps (event_id, sequence, name) VALUES ($1, 1, 'Original') RETURNING id`, eventID).Scan(&innhoppID); err != nil {
+		t.Fatalf("insert innhopp failed: %v", err)
+	}
+
+	h := NewHandler(db)
+	router := chi.NewRouter()
+	router.Put("/{innhoppID}", h.updateInnhopp)
+	router.Get("/{innhoppID}/revisions", h.listRevisions)
+	router.Get("/{innhoppID}/revisions/{revisionID}", h.getRevision)
+	path := "/" + strconv.FormatInt(innhoppID, 10)
+	put := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := put(`{"name":"No version"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("update without version status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	rec = put(`{"name":"First edit","version":1}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("first update status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	var updated Innhopp
+	if err := json.NewDecoder(rec.Body).Decode(&updated); err != nil {
+		t.Fatalf("decode innhopp failed: %v", err)
+	}
+	if updated.Version != 2 {
+		t.Fatalf("version after update = %d, want 2", updated.Version)
+	}
+
+	rec = put(`{"name":"Stale edit","version":1}`)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("stale update status = %d, want %d body=%s", rec.Code, http.StatusConflict, rec.Body.String())
+	}
+
+	var name string
+	if err := db.QueryRow(ctx, `SELECT name FROM event_innhopps WHERE id = $1`, innhoppID).Scan(&name); err != nil {
+		t.Fatalf("reload innhopp failed: %v", err)
+	}
+	if name != "First edit" {
+		t.Fatalf("stale update overwrote name: got %q", name)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path+"/revisions", nil))
+	var revisions []Revision
+	if err := json.NewDecoder(rec.Body).Decode(&revisions); err != nil || len(revisions) != 1 || revisions[0].Version != 1 {
+		t.Fatalf("revisions = %+v, %v", revisions, err)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path+"/revisions/"+strconv.FormatInt(revisions[0].ID, 10), nil))
+	var detail struct {
+		Before Innhopp `json:"before"`
+		After  Innhopp `json:"after"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&detail); err != nil {
+		t.Fatalf("decode revision detail failed: %v", err)
+	}
+	if detail.Before.Name != "Original" || detail.After.Name != "First edit" {
+		t.Fatalf("revision before/after = %q/%q", detail.Before.Name, detail.After.Name)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/999999999", strings.NewReader(`{"name":"Ghost","version":1}`)))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("update of missing innhopp status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func openInnhoppTestDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Skip("DATABASE_URL not set; skipping integration innhopp tests")
+	}
+	db, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatalf("connect db failed: %v", err)
+	}
+	return db
+}
+
+func ensureInnhoppTestSchema(t *testing.T, ctx context.Context, db *pgxpool.Pool) {
+	t.Helper()
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS seasons (
+            id SERIAL PRIMARY KEY,
+            name TEXT NOT NULL,
+            starts_on DATE NOT NULL,
+            ends_on DATE,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE TABLE IF NOT EXISTS events (
+            id SERIAL PRIMARY KEY,
+            season_id INTEGER NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+            name TEXT NOT NULL,
+            location TEXT,
+            slots INTEGER NOT NULL DEFAULT 0,
+            status TEXT NOT NULL DEFAULT 'draft',
+            starts_at TIMESTAMPTZ NOT NULL,
+            ends_at TIMESTAMPTZ,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE TABLE IF NOT EXISTS event_innhopps (
+            id SERIAL PRIMARY KEY,
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            sequence INTEGER NOT NULL,
+            name TEXT NOT NULL,
+            aircraft_id INTEGER,
+            takeoff_airfield_id INTEGER,
+            landing_airfield_id INTEGER,
+            elevation INTEGER,
+            scheduled_at TIMESTAMPTZ,
+            notes TEXT,
+            coordinates TEXT,
+            reason_for_choice TEXT,
+            adjust_altimeter_aad TEXT,
+            notam TEXT,
+            distance_by_air NUMERIC,
+            distance_by_road NUMERIC,
+            landing_distance_by_air NUMERIC,
+            landing_distance_by_road NUMERIC,
+            primary_landing_area_name TEXT,
+            primary_landing_area_description TEXT,
+            primary_landing_area_size TEXT,
+            primary_landing_area_obstacles TEXT,
+            secondary_landing_area_name TEXT,
+            secondary_landing_area_description TEXT,
+            secondary_landing_area_size TEXT,
+            secondary_landing_area_obstacles TEXT,
+            risk_assessment TEXT,
+            safety_precautions TEXT,
+            jumprun TEXT,
+            hospital TEXT,
+            rescue_boat BOOLEAN,
+            minimum_requirements TEXT,
+            image_files JSONB DEFAULT '[]'::jsonb,
+            land_owners JSONB,
+            land_owner_permission BOOLEAN,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`ALTER TABLE event_innhopps ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1`,
+		`CREATE TABLE IF NOT EXISTS accounts (
+            id SERIAL PRIMARY KEY,
+            subject TEXT NOT NULL UNIQUE,
+            email TEXT NOT NULL,
+            full_name TEXT,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE TABLE IF NOT EXISTS event_innhopp_revisions (
+            id SERIAL PRIMARY KEY,
+            innhopp_id INTEGER NOT NULL REFERENCES event_innhopps(id) ON DELETE CASCADE,
+            version INTEGER NOT NULL,
+            snapshot JSONB NOT NULL,
+            actor_account_id INTEGER REFERENCES accounts(id) ON DELETE SET NULL,
+            actor_name TEXT,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(ctx, stmt); err != nil {
+			t.Fatalf("schema setup failed: %v", err)
+		}
+	}
+}
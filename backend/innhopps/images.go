@@ -0,0 +1,218 @@
+package innhopps
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/innhopp/central/backend/httpx"
+)
+
+const (
+	// maxImageBytes caps one uploaded image.
+	maxImageBytes = 5 << 20
+	// maxImageUploadBytes caps the whole multipart request.
+	maxImageUploadBytes = 25 << 20
+	// maxInnhoppImages caps how many images one innhopp keeps.
+	maxInnhoppImages = 20
+)
+
+// allowedImageTypes are the sniffed content types accepted for upload.
+var allowedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// imageListResponse is returned by the image endpoints. Version is the
+// innhopp's new version, which a later PUT must send.
+type imageListResponse struct {
+	Version int            `json:"version"`
+	Images  []InnhoppImage `json:"images"`
+}
+
+// readUploadedImage validates one uploaded file and returns it as an image
+// entry with an inline data URL, the format image_files already uses.
+func readUploadedImage(file *httpx.UploadedFile) (InnhoppImage, error) {
+	data, mimeType, err := file.ReadAll(maxImageBytes)
+	if errors.Is(err, httpx.ErrBodyTooLarge) {
+		return InnhoppImage{}, fmt.Errorf("%s is larger than %d MB", file.Name, maxImageBytes>>20)
+	}
+	if err != nil {
+		return InnhoppImage{}, err
+	}
+	if !allowedImageTypes[mimeType] {
+		return InnhoppImage{}, fmt.Errorf("%s must be a JPEG, PNG, GIF or WebP image", file.Name)
+	}
+	return InnhoppImage{
+		Name:     file.Name,
+		MimeType: mimeType,
+		Data:     "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data),
+	}, nil
+}
+
+// uniqueImageName keeps image names distinct so they can address deletes,
+// appending -2, -3, ... before the extension when name is taken.
+func uniqueImageName(name string, taken map[string]bool) string {
+	if name == "" {
+		name = "image"
+	}
+	candidate := name
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for n := 2; taken[candidate]; n++ {
+		candidate = base + "-" + strconv.Itoa(n) + ext
+	}
+	taken[candidate] = true
+	return candidate
+}
+
+// uploadImages appends the files of a multipart request to an innhopp's
+// images without touching the rest of the innhopp.
+func (h *Handler) uploadImages(w http.ResponseWriter, r *http.Request) {
+	innhoppID, err := strconv.ParseInt(chi.URLParam(r, "innhoppID"), 10, 64)
+	if err != nil || innhoppID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid innhopp id")
+		return
+	}
+	form, err := httpx.DecodeMultipart(w, r, maxImageUploadBytes)
+	if errors.Is(err, httpx.ErrBodyTooLarge) {
+		httpx.Error(w, http.StatusRequestEntityTooLarge, "upload is larger than "+strconv.Itoa(maxImageUploadBytes>>20)+" MB")
+		return
+	}
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer form.Close()
+
+	var uploads []InnhoppImage
+	for _, file := range form.Files("") {
+		image, err := readUploadedImage(file)
+		if err != nil {
+			httpx.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		uploads = append(uploads, image)
+	}
+	if len(uploads) == 0 {
+		httpx.Error(w, http.StatusBadRequest, "at least one image file is required")
+		return
+	}
+
+	h.changeImages(w, r, innhoppID, func(images []InnhoppImage) ([]InnhoppImage, error) {
+		if len(images)+len(uploads) > maxInnhoppImages {
+			return nil, fmt.Errorf("an innhopp can hold at most %d images", maxInnhoppImages)
+		}
+		taken := make(map[string]bool, len(images)+len(uploads))
+		for _, image := range images {
+			taken[image.Name] = true
+		}
+		for _, image := range uploads {
+			image.Name = uniqueImageName(image.Name, taken)
+			images = append(images, image)
+		}
+		return images, nil
+	})
+}
+
+// deleteImage removes the images named {name} from an innhopp.
+func (h *Handler) deleteImage(w http.ResponseWriter, r *http.Request) {
+	innhoppID, err := strconv.ParseInt(chi.URLParam(r, "innhoppID"), 10, 64)
+	if err != nil || innhoppID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid innhopp id")
+		return
+	}
+	name, err := url.PathUnescape(chi.URLParam(r, "name"))
+	if err != nil || strings.TrimSpace(name) == "" {
+		httpx.Error(w, http.StatusBadRequest, "image name is required")
+		return
+	}
+
+	h.changeImages(w, r, innhoppID, func(images []InnhoppImage) ([]InnhoppImage, error) {
+		kept := make([]InnhoppImage, 0, len(images))
+		for _, image := range images {
+			if image.Name != name {
+				kept = append(kept, image)
+			}
+		}
+		if len(kept) == len(images) {
+			return nil, errImageNotFound
+		}
+		return kept, nil
+	})
+}
+
+var errImageNotFound = errors.New("image not found")
+
+// changeImages applies change to an innhopp's images under a row lock,
+// bumping its version and recording a revision like any other edit.
+func (h *Handler) changeImages(w http.ResponseWriter, r *http.Request, innhoppID int64, change func([]InnhoppImage) ([]InnhoppImage, error)) {
+	ctx := r.Context()
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to update images")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	previous, err := LoadForUpdate(ctx, tx, innhoppID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			httpx.Error(w, http.StatusNotFound, "innhopp not found")
+			return
+		}
+		log.Printf("innhopps.changeImages id=%d load failed: %v", innhoppID, err)
+		httpx.Error(w, http.StatusInternalServerError, "failed to update images")
+		return
+	}
+
+	images, err := change(append([]InnhoppImage{}, previous.ImageFiles...))
+	if errors.Is(err, errImageNotFound) {
+		httpx.Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	images = normalizeImageFiles(images)
+	encoded, err := encodeImageFiles(images)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to update images")
+		return
+	}
+
+	var version int
+	if err := tx.QueryRow(ctx,
+		`UPDATE event_innhopps SET image_files = $1::jsonb, version = version + 1 WHERE id = $2 RETURNING version`,
+		string(encoded), innhoppID,
+	).Scan(&version); err != nil {
+		log.Printf("innhopps.changeImages id=%d update failed: %v", innhoppID, err)
+		httpx.Error(w, http.StatusInternalServerError, "failed to update images")
+		return
+	}
+	if err := RecordRevision(ctx, tx, previous); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to record innhopp revision")
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to update images")
+		return
+	}
+
+	if images == nil {
+		images = []InnhoppImage{}
+	}
+	httpx.WriteJSON(w, http.StatusOK, imageListResponse{Version: version, Images: images})
+}
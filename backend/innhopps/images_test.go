@@ -0,0 +1,65 @@
+package innhopps
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/innhopp/central/backend/httpx"
+)
+
+func multipartFiles(t *testing.T, files map[string][]byte) []*httpx.UploadedFile {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, data := range files {
+		part, err := writer.CreateFormFile("files", name)
+		if err != nil {
+			t.Fatalf("CreateFormFile: %v", err)
+		}
+		part.Write(data)
+	}
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	form, err := httpx.DecodeMultipart(httptest.NewRecorder(), req, maxImageUploadBytes)
+	if err != nil {
+		t.Fatalf("DecodeMultipart: %v", err)
+	}
+	t.Cleanup(func() { form.Close() })
+	return form.Files("files")
+}
+
+func TestReadUploadedImageSniffsTypeAndBuildsDataURL(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	image, err := readUploadedImage(multipartFiles(t, map[string][]byte{`C:\photos\landing.png`: png})[0])
+	if err != nil {
+		t.Fatalf("readUploadedImage() error: %v", err)
+	}
+	if image.Name != "landing.png" || image.MimeType != "image/png" || !strings.HasPrefix(image.Data, "data:image/png;base64,") {
+		t.Fatalf("image = %+v, want landing.png as a PNG data URL", image)
+	}
+
+	if _, err := readUploadedImage(multipartFiles(t, map[string][]byte{"notes.txt": []byte("not an image")})[0]); err == nil {
+		t.Fatal("readUploadedImage() accepted a text file")
+	}
+	if _, err := readUploadedImage(multipartFiles(t, map[string][]byte{"huge.png": append(png, make([]byte, maxImageBytes)...)})[0]); err == nil {
+		t.Fatal("readUploadedImage() accepted an oversized file")
+	}
+}
+
+func TestUniqueImageNameSuffixesTakenNames(t *testing.T) {
+	taken := map[string]bool{"landing.jpg": true, "landing-2.jpg": true}
+	if got := uniqueImageName("landing.jpg", taken); got != "landing-3.jpg" {
+		t.Fatalf("uniqueImageName() = %q, want landing-3.jpg", got)
+	}
+	if got := uniqueImageName("", taken); got != "image" {
+		t.Fatalf("uniqueImageName(\"\") = %q, want image", got)
+	}
+	if got := uniqueImageName("", taken); got != "image-2" {
+		t.Fatalf("second unnamed image = %q, want image-2", got)
+	}
+}
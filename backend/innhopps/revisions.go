@@ -0,0 +1,373 @@
+package innhopps
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/innhopp/central/backend/auth"
+	"github.com/innhopp/central/backend/httpx"
+)
+
+// Revision records who replaced an innhopp's state and when. Version is the
+// version of the snapshot, i.e. the state before the edit.
+type Revision struct {
+	ID             int64     `json:"id"`
+	InnhoppID      int64     `json:"innhopp_id"`
+	Version        int       `json:"version"`
+	ActorAccountID *int64    `json:"actor_account_id,omitempty"`
+	ActorName      string    `json:"actor_name"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// RevisionDetail pairs a revision with the innhopp as it was before and
+// after the edit so clients can render a side-by-side diff.
+type RevisionDetail struct {
+	Revision
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+type revisionActor struct {
+	accountID *int64
+	name      string
+}
+
+func revisionActorFromContext(ctx context.Context) revisionActor {
+	var actor revisionActor
+	if claims := auth.FromContext(ctx); claims != nil {
+		if claims.AccountID > 0 {
+			id := claims.AccountID
+			actor.accountID = &id
+		}
+		actor.name = strings.TrimSpace(claims.FullName)
+		if actor.name == "" {
+			actor.name = claims.Email
+		}
+	}
+	return actor
+}
+
+// revisionSnapshot encodes an innhopp for the revision table. Image payloads
+// are dropped because they are large and not part of the safety review; the
+// file names still show when images were added or removed.
+func revisionSnapshot(innhopp Innhopp) ([]byte, error) {
+	if len(innhopp.ImageFiles) > 0 {
+		images := make([]InnhoppImage, len(innhopp.ImageFiles))
+		for i, img := range innhopp.ImageFiles {
+			images[i] = InnhoppImage{Name: img.Name, MimeType: img.MimeType}
+		}
+		innhopp.ImageFiles = images
+	}
+	return json.Marshal(innhopp)
+}
+
+// LoadForUpdate locks the innhopp with id for the rest of tx and returns it,
+// for callers outside this package that edit it and then record a revision.
+func LoadForUpdate(ctx context.Context, tx pgx.Tx, id int64) (Innhopp, error) {
+	return scanInnhopp(tx.QueryRow(ctx, `SELECT `+innhoppColumns+` FROM event_innhopps WHERE id = $1 FOR UPDATE`, id))
+}
+
+// RecordRevision stores previous, the state an edit replaced, in the
+// innhopp's revision history with the caller in ctx as the actor.
+func RecordRevision(ctx context.Context, tx pgx.Tx, previous Innhopp) error {
+	return insertRevision(ctx, tx, previous, revisionActorFromContext(ctx))
+}
+
+func insertRevision(ctx context.Context, tx pgx.Tx, previous Innhopp, actor revisionActor) error {
+	snapshot, err := revisionSnapshot(previous)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO event_innhopp_revisions (innhopp_id, version, snapshot, actor_account_id, actor_name)
+		VALUES ($1, $2, $3::jsonb, $4, $5)
+	`, previous.ID, previous.Version, string(snapshot), actor.accountID, actor.name)
+	return err
+}
+
+func parseInnhoppIDParam(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	innhoppID, err := strconv.ParseInt(chi.URLParam(r, "innhoppID"), 10, 64)
+	if err != nil || innhoppID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid innhopp id")
+		return 0, false
+	}
+	return innhoppID, true
+}
+
+func (h *Handler) listRevisions(w http.ResponseWriter, r *http.Request) {
+	innhoppID, ok := parseInnhoppIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var exists bool
+	if err := h.db.QueryRow(r.Context(), `SELECT EXISTS(SELECT 1 FROM event_innhopps WHERE id = $1)`, innhoppID).Scan(&exists); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load innhopp")
+		return
+	}
+	if !exists {
+		httpx.Error(w, http.StatusNotFound, "innhopp not found")
+		return
+	}
+
+	rows, err := h.db.Query(r.Context(), `
+		SELECT id, innhopp_id, version, actor_account_id, COALESCE(actor_name, ''), created_at
+		FROM event_innhopp_revisions
+		WHERE innhopp_id = $1
+		ORDER BY id DESC
+	`, innhoppID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to list revisions")
+		return
+	}
+	defer rows.Close()
+
+	revisions := []Revision{}
+	for rows.Next() {
+		var rev Revision
+		if err := rows.Scan(&rev.ID, &rev.InnhoppID, &rev.Version, &rev.ActorAccountID, &rev.ActorName, &rev.CreatedAt); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to parse revision")
+			return
+		}
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to list revisions")
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, revisions)
+}
+
+func (h *Handler) getRevision(w http.ResponseWriter, r *http.Request) {
+	innhoppID, ok := parseInnhoppIDParam(w, r)
+	if !ok {
+		return
+	}
+	revisionID, err := strconv.ParseInt(chi.URLParam(r, "revisionID"), 10, 64)
+	if err != nil || revisionID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid revision id")
+		return
+	}
+
+	var detail RevisionDetail
+	var before []byte
+	err = h.db.QueryRow(r.Context(), `
+		SELECT id, innhopp_id, version, actor_account_id, COALESCE(actor_name, ''), created_at, snapshot
+		FROM event_innhopp_revisions
+		WHERE id = $1 AND innhopp_id = $2
+	`, revisionID, innhoppID).Scan(
+		&detail.ID, &detail.InnhoppID, &detail.Version, &detail.ActorAccountID, &detail.ActorName, &detail.CreatedAt, &before,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			httpx.Error(w, http.StatusNotFound, "revision not found")
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to load revision")
+		return
+	}
+	detail.Before = before
+
+	// The state after this edit is the next snapshot, or the live row when
+	// this is the most recent revision.
+	var after []byte
+	err = h.db.QueryRow(r.Context(), `
+		SELECT snapshot FROM event_innhopp_revisions
+		WHERE innhopp_id = $1 AND id > $2
+		ORDER BY id
+		LIMIT 1
+	`, innhoppID, revisionID).Scan(&after)
+	switch {
+	case err == nil:
+	case errors.Is(err, pgx.ErrNoRows):
+		after, err = h.currentSnapshot(r.Context(), innhoppID)
+		if err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to load innhopp")
+			return
+		}
+	default:
+		httpx.Error(w, http.StatusInternalServerError, "failed to load revision")
+		return
+	}
+	detail.After = after
+
+	httpx.WriteJSON(w, http.StatusOK, detail)
+}
+
+// currentSnapshot encodes the live innhopp row like a revision snapshot.
+func (h *Handler) currentSnapshot(ctx context.Context, innhoppID int64) ([]byte, error) {
+	current, err := scanInnhopp(h.db.QueryRow(ctx, `SELECT `+innhoppColumns+` FROM event_innhopps WHERE id = $1`, innhoppID))
+	if err != nil {
+		return nil, err
+	}
+	return revisionSnapshot(current)
+}
+
+// FieldChange is one field that differs between two innhopp states. Nested
+// objects such as landing areas are compared per field, named with a dot
+// (primary_landing_area.size); lists are compared as a whole. A field that is
+// absent on one side is null there.
+type FieldChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old"`
+	New   json.RawMessage `json:"new"`
+}
+
+// RevisionDiff compares the innhopp as stored in revision From with revision
+// To, or with the live innhopp when To is nil.
+type RevisionDiff struct {
+	InnhoppID int64         `json:"innhopp_id"`
+	From      int64         `json:"from"`
+	To        *int64        `json:"to"`
+	Changes   []FieldChange `json:"changes"`
+}
+
+// diffIgnoredFields change on every edit and would drown the safety data.
+var diffIgnoredFields = map[string]struct{}{"version": {}}
+
+var jsonNull = json.RawMessage("null")
+
+// diffSnapshots returns the fields that differ between two JSON snapshots,
+// sorted by name.
+func diffSnapshots(before, after []byte) ([]FieldChange, error) {
+	var old, updated map[string]json.RawMessage
+	if err := json.Unmarshal(before, &old); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(after, &updated); err != nil {
+		return nil, err
+	}
+	changes := []FieldChange{}
+	diffObjects("", old, updated, &changes)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+func diffObjects(prefix string, old, updated map[string]json.RawMessage, changes *[]FieldChange) {
+	keys := map[string]struct{}{}
+	for key := range old {
+		keys[key] = struct{}{}
+	}
+	for key := range updated {
+		keys[key] = struct{}{}
+	}
+	for key := range keys {
+		if _, ignored := diffIgnoredFields[key]; ignored && prefix == "" {
+			continue
+		}
+		field := prefix + key
+		before, after := old[key], updated[key]
+		if before == nil {
+			before = jsonNull
+		}
+		if after == nil {
+			after = jsonNull
+		}
+		var beforeObject, afterObject map[string]json.RawMessage
+		if json.Unmarshal(before, &beforeObject) == nil && json.Unmarshal(after, &afterObject) == nil &&
+			(beforeObject != nil || afterObject != nil) {
+			diffObjects(field+".", beforeObject, afterObject, changes)
+			continue
+		}
+		if !jsonEqual(before, after) {
+			*changes = append(*changes, FieldChange{Field: field, Old: before, New: after})
+		}
+	}
+}
+
+// jsonEqual compares two JSON values independent of formatting and key order.
+func jsonEqual(a, b json.RawMessage) bool {
+	var left, right any
+	if json.Unmarshal(a, &left) != nil || json.Unmarshal(b, &right) != nil {
+		return string(a) == string(b)
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+func parseRevisionQueryID(r *http.Request, name string) (*int64, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get(name))
+	if raw == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		return nil, errors.New(name + " must be a revision id")
+	}
+	return &id, nil
+}
+
+// diffRevisions compares two stored revisions of an innhopp field by field.
+// ?from= is required; without ?to= the revision is compared with the live
+// innhopp.
+func (h *Handler) diffRevisions(w http.ResponseWriter, r *http.Request) {
+	innhoppID, ok := parseInnhoppIDParam(w, r)
+	if !ok {
+		return
+	}
+	from, err := parseRevisionQueryID(r, "from")
+	if err == nil && from == nil {
+		err = errors.New("from is required")
+	}
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	to, err := parseRevisionQueryID(r, "to")
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	loadSnapshot := func(revisionID int64) ([]byte, bool) {
+		var snapshot []byte
+		err := h.db.QueryRow(ctx, `
+			SELECT snapshot FROM event_innhopp_revisions
+			WHERE id = $1 AND innhopp_id = $2
+		`, revisionID, innhoppID).Scan(&snapshot)
+		if errors.Is(err, pgx.ErrNoRows) {
+			httpx.Error(w, http.StatusNotFound, "revision "+strconv.FormatInt(revisionID, 10)+" not found")
+			return nil, false
+		}
+		if err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to load revision")
+			return nil, false
+		}
+		return snapshot, true
+	}
+
+	before, ok := loadSnapshot(*from)
+	if !ok {
+		return
+	}
+	var after []byte
+	if to != nil {
+		if after, ok = loadSnapshot(*to); !ok {
+			return
+		}
+	} else {
+		after, err = h.currentSnapshot(ctx, innhoppID)
+		if err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to load innhopp")
+			return
+		}
+	}
+
+	changes, err := diffSnapshots(before, after)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to compare revisions")
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, RevisionDiff{InnhoppID: innhoppID, From: *from, To: to, Changes: changes})
+}
@@ -0,0 +1,81 @@
+package innhopps
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRevisionSnapshotDropsImageData(t *testing.T) {
+	snapshot, err := revisionSnapshot(Innhopp{
+		ID:             7,
+		Name:           "Glacier",
+		RiskAssessment: "Crevasses west of the target",
+		ImageFiles:     []InnhoppImage{{Name: "target.jpg", MimeType: "image/jpeg", Data: "aGVsbG8="}},
+	})
+	if err != nil {
+		t.Fatalf("revisionSnapshot() returned error: %v", err)
+	}
+	if strings.Contains(string(snapshot), "aGVsbG8=") {
+		t.Fatalf("snapshot kept image data: %s", snapshot)
+	}
+
+	var decoded Innhopp
+	if err := json.Unmarshal(snapshot, &decoded); err != nil {
+		t.Fatalf("unmarshal snapshot failed: %v", err)
+	}
+	if decoded.RiskAssessment != "Crevasses west of the target" {
+		t.Fatalf("risk assessment = %q", decoded.RiskAssessment)
+	}
+	if len(decoded.ImageFiles) != 1 || decoded.ImageFiles[0].Name != "target.jpg" {
+		t.Fatalf("image files = %+v", decoded.ImageFiles)
+	}
+}
+
+func TestDiffSnapshotsReportsChangedSafetyFields(t *testing.T) {
+	before, err := revisionSnapshot(Innhopp{
+		ID:                 7,
+		Name:               "Glacier",
+		Version:            3,
+		RiskAssessment:     "Crevasses west of the target",
+		PrimaryLandingArea: LandingArea{Name: "Moraine", Size: "50x200"},
+		LandOwners:         []LandOwner{{Name: "Hansen"}},
+	})
+	if err != nil {
+		t.Fatalf("revisionSnapshot(before) returned error: %v", err)
+	}
+	after, err := revisionSnapshot(Innhopp{
+		ID:                 7,
+		Name:               "Glacier",
+		Version:            4,
+		RiskAssessment:     "Crevasses west and north of the target",
+		PrimaryLandingArea: LandingArea{Name: "Moraine", Size: "50x150"},
+		LandOwners:         []LandOwner{{Name: "Hansen"}},
+		Hospital:           "Voss",
+	})
+	if err != nil {
+		t.Fatalf("revisionSnapshot(after) returned error: %v", err)
+	}
+
+	changes, err := diffSnapshots(before, after)
+	if err != nil {
+		t.Fatalf("diffSnapshots() returned error: %v", err)
+	}
+	want := []FieldChange{
+		{Field: "hospital", Old: json.RawMessage(`null`), New: json.RawMessage(`"Voss"`)},
+		{Field: "primary_landing_area.size", Old: json.RawMessage(`"50x200"`), New: json.RawMessage(`"50x150"`)},
+		{Field: "risk_assessment", Old: json.RawMessage(`"Crevasses west of the target"`), New: json.RawMessage(`"Crevasses west and north of the target"`)},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("diffSnapshots() = %+v, want %d changes", changes, len(want))
+	}
+	for i, change := range changes {
+		if change.Field != want[i].Field || string(change.Old) != string(want[i].Old) || string(change.New) != string(want[i].New) {
+			t.Errorf("change[%d] = {%s %s %s}, want {%s %s %s}", i, change.Field, change.Old, change.New, want[i].Field, want[i].Old, want[i].New)
+		}
+	}
+
+	if same, err := diffSnapshots(after, after); err != nil || len(same) != 0 {
+		t.Fatalf("diffSnapshots(same) = %+v, %v; want no changes", same, err)
+	}
+}
@@ -0,0 +1,28 @@
+// Package clock provides an injectable source of the current time so that
+// expiry and scheduling logic can be exercised deterministically in tests.
+package clock
+
+import "time"
+
+// Clock reports the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// Real is the wall clock backed by time.Now.
+type Real struct{}
+
+// Now returns the current wall-clock time.
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+// Fixed always reports the same instant.
+type Fixed struct {
+	At time.Time
+}
+
+// Now returns the fixed instant.
+func (f Fixed) Now() time.Time {
+	return f.At
+}
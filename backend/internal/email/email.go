@@ -0,0 +1,63 @@
+// Package email normalizes addresses so accounts, participant profiles and
+// registrations store and compare them the same way.
+package email
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+)
+
+// Policy decides how much of an address Normalize lowercases.
+type Policy int32
+
+const (
+	// PolicyLowercase lowercases the whole address. It is the default.
+	PolicyLowercase Policy = iota
+	// PolicyStrict lowercases only the domain and keeps the local part,
+	// including any +tag, exactly as given, for providers whose mailboxes are
+	// case-sensitive.
+	PolicyStrict
+)
+
+var policy atomic.Int32
+
+// ParsePolicy reads an EMAIL_NORMALIZATION value: "lowercase" (or empty) or
+// "strict".
+func ParsePolicy(raw string) (Policy, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", "lowercase":
+		return PolicyLowercase, nil
+	case "strict":
+		return PolicyStrict, nil
+	default:
+		return PolicyLowercase, errors.New("email normalization must be lowercase or strict")
+	}
+}
+
+// SetPolicy changes the policy Normalize applies. It is meant to be called
+// once at startup.
+func SetPolicy(p Policy) {
+	policy.Store(int32(p))
+}
+
+// CurrentPolicy returns the policy Normalize applies.
+func CurrentPolicy() Policy {
+	return Policy(policy.Load())
+}
+
+// Normalize trims address and lowercases it according to the current policy.
+func Normalize(address string) string {
+	return CurrentPolicy().Normalize(address)
+}
+
+// Normalize trims address and lowercases it according to p. Addresses without
+// an @ are lowercased whole under either policy.
+func (p Policy) Normalize(address string) string {
+	address = strings.TrimSpace(address)
+	at := strings.LastIndex(address, "@")
+	if p != PolicyStrict || at < 0 {
+		return strings.ToLower(address)
+	}
+	return address[:at+1] + strings.ToLower(address[at+1:])
+}
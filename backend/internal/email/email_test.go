@@ -0,0 +1,45 @@
+package email
+
+import "testing"
+
+func TestPolicyNormalize(t *testing.T) {
+	cases := []struct {
+		policy Policy
+		in     string
+		want   string
+	}{
+		{PolicyLowercase, "  Kari.Nordmann+Voss@Example.COM ", "kari.nordmann+voss@example.com"},
+		{PolicyStrict, "  Kari.Nordmann+Voss@Example.COM ", "Kari.Nordmann+Voss@example.com"},
+		{PolicyStrict, "NoDomain", "nodomain"},
+		{PolicyLowercase, "", ""},
+	}
+	for _, tc := range cases {
+		if got := tc.policy.Normalize(tc.in); got != tc.want {
+			t.Errorf("Policy(%d).Normalize(%q) = %q, want %q", tc.policy, tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	for raw, want := range map[string]Policy{"": PolicyLowercase, "lowercase": PolicyLowercase, " Strict ": PolicyStrict} {
+		got, err := ParsePolicy(raw)
+		if err != nil || got != want {
+			t.Errorf("ParsePolicy(%q) = %d, %v; want %d", raw, got, err, want)
+		}
+	}
+	if _, err := ParsePolicy("casefold"); err == nil {
+		t.Error("ParsePolicy(casefold) expected error")
+	}
+}
+
+func TestSetPolicyChangesNormalize(t *testing.T) {
+	t.Cleanup(func() { SetPolicy(PolicyLowercase) })
+
+	if got := Normalize("Ada@Example.com"); got != "ada@example.com" {
+		t.Fatalf("Normalize() under lowercase policy = %q, want ada@example.com", got)
+	}
+	SetPolicy(PolicyStrict)
+	if got := Normalize("Ada@Example.com"); got != "Ada@example.com" {
+		t.Fatalf("Normalize() = %q, want Ada@example.com", got)
+	}
+}
@@ -0,0 +1,118 @@
+// Package geo reads the coordinate strings people type for airfields and
+// route waypoints.
+package geo
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var dmsTokenPattern = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)\s*°\s*(\d+(?:\.\d+)?)?\s*['’′]?\s*(\d+(?:\.\d+)?)?\s*(?:["”″])?\s*([NSEW])`)
+
+func dmsToDecimal(degrees, minutes, seconds float64, hemi string) float64 {
+	val := degrees + (minutes / 60.0) + (seconds / 3600.0)
+	h := strings.ToUpper(strings.TrimSpace(hemi))
+	if h == "S" || h == "W" {
+		return -val
+	}
+	return val
+}
+
+// ParseCoordinates reads a latitude and longitude from value, either as
+// degrees-minutes-seconds with hemisphere letters in either order, such as
+// 60°23'N 5°19'E, or as two decimals separated by a comma, semicolon or
+// whitespace, latitude first. ok is false when value is neither or is out of
+// range.
+func ParseCoordinates(value string) (lat, lng float64, ok bool) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return 0, 0, false
+	}
+
+	matches := dmsTokenPattern.FindAllStringSubmatch(trimmed, 2)
+	if len(matches) == 2 {
+		parseDMSPart := func(m []string) (float64, string, bool) {
+			deg, err := strconv.ParseFloat(m[1], 64)
+			if err != nil {
+				return 0, "", false
+			}
+			mins := 0.0
+			if strings.TrimSpace(m[2]) != "" {
+				mins, err = strconv.ParseFloat(m[2], 64)
+				if err != nil {
+					return 0, "", false
+				}
+			}
+			secs := 0.0
+			if strings.TrimSpace(m[3]) != "" {
+				secs, err = strconv.ParseFloat(m[3], 64)
+				if err != nil {
+					return 0, "", false
+				}
+			}
+			return dmsToDecimal(deg, mins, secs, m[4]), strings.ToUpper(strings.TrimSpace(m[4])), true
+		}
+		firstVal, firstHemi, ok := parseDMSPart(matches[0])
+		if !ok {
+			return 0, 0, false
+		}
+		secondVal, secondHemi, ok := parseDMSPart(matches[1])
+		if !ok {
+			return 0, 0, false
+		}
+		if (firstHemi == "N" || firstHemi == "S") && (secondHemi == "E" || secondHemi == "W") {
+			return inRange(firstVal, secondVal)
+		}
+		if (firstHemi == "E" || firstHemi == "W") && (secondHemi == "N" || secondHemi == "S") {
+			return inRange(secondVal, firstVal)
+		}
+	}
+
+	normalized := strings.NewReplacer(",", " ", ";", " ", "\t", " ").Replace(trimmed)
+	parts := strings.Fields(normalized)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	lat, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	lng, err = strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return inRange(lat, lng)
+}
+
+func inRange(lat, lng float64) (float64, float64, bool) {
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return 0, 0, false
+	}
+	return lat, lng, true
+}
+
+// FormatDegrees renders a coordinate in decimal degrees to six places, about
+// 10 cm, without trailing zeros.
+func FormatDegrees(value float64) string {
+	rounded := math.Round(value*1e6) / 1e6
+	if rounded == 0 {
+		rounded = 0 // drop the sign of -0
+	}
+	return strconv.FormatFloat(rounded, 'f', -1, 64)
+}
+
+// earthRadiusKm is the mean radius of the Earth.
+const earthRadiusKm = 6371.0
+
+// DistanceKm is the great-circle distance in kilometres between two points
+// given in decimal degrees.
+func DistanceKm(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
@@ -0,0 +1,60 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseCoordinates(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		lat, lng float64
+		ok       bool
+	}{
+		{value: "60.3913, 5.3221", lat: 60.3913, lng: 5.3221, ok: true},
+		{value: "60.3913;5.3221", lat: 60.3913, lng: 5.3221, ok: true},
+		{value: "  -33.9\t18.6 ", lat: -33.9, lng: 18.6, ok: true},
+		{value: `60°23'29"N 5°19'20"E`, lat: 60.391389, lng: 5.322222, ok: true},
+		{value: `5°19'20"W 60°23'29"S`, lat: -60.391389, lng: -5.322222, ok: true},
+		{value: "90 180", lat: 90, lng: 180, ok: true},
+		{value: "90.1 5"},
+		{value: "60 -180.5"},
+		{value: "60"},
+		{value: "60 5 7"},
+		{value: "north 5"},
+		{value: ""},
+	} {
+		lat, lng, ok := ParseCoordinates(tc.value)
+		if ok != tc.ok {
+			t.Errorf("ParseCoordinates(%q) ok = %v, want %v", tc.value, ok, tc.ok)
+			continue
+		}
+		if ok && (math.Abs(lat-tc.lat) > 1e-6 || math.Abs(lng-tc.lng) > 1e-6) {
+			t.Errorf("ParseCoordinates(%q) = %v, %v; want %v, %v", tc.value, lat, lng, tc.lat, tc.lng)
+		}
+	}
+}
+
+func TestFormatDegrees(t *testing.T) {
+	for value, want := range map[float64]string{
+		60.3913:         "60.3913",
+		5.32222222:      "5.322222",
+		-0.0000001:      "0",
+		-18:             "-18",
+		179.99999999999: "180",
+	} {
+		if got := FormatDegrees(value); got != want {
+			t.Errorf("FormatDegrees(%v) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestDistanceKm(t *testing.T) {
+	// Bergen to Oslo is about 305 km as the crow flies.
+	if got := DistanceKm(60.3913, 5.3221, 59.9139, 10.7522); math.Abs(got-305) > 5 {
+		t.Fatalf("DistanceKm(Bergen, Oslo) = %.1f, want about 305", got)
+	}
+	if got := DistanceKm(60, 5, 60, 5); got != 0 {
+		t.Fatalf("DistanceKm(same point) = %v, want 0", got)
+	}
+}
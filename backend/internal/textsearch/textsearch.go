@@ -0,0 +1,18 @@
+// Package textsearch builds substring search filters that Postgres can serve
+// from pg_trgm GIN indexes.
+package textsearch
+
+import "strings"
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ContainsPattern turns a user query into an ILIKE pattern matching any value
+// that contains it. LIKE wildcards in the query are escaped so they match
+// literally. ok is false when the query is blank and no filter should apply.
+func ContainsPattern(query string) (pattern string, ok bool) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return "", false
+	}
+	return "%" + likeEscaper.Replace(query) + "%", true
+}
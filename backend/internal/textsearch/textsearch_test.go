@@ -0,0 +1,22 @@
+package textsearch
+
+import "testing"
+
+func TestContainsPatternEscapesWildcards(t *testing.T) {
+	tests := []struct {
+		query  string
+		want   string
+		wantOK bool
+	}{
+		{query: "  ", want: "", wantOK: false},
+		{query: " Voss ", want: "%Voss%", wantOK: true},
+		{query: "100%_sure", want: `%100\%\_sure%`, wantOK: true},
+		{query: `back\slash`, want: `%back\\slash%`, wantOK: true},
+	}
+	for _, tt := range tests {
+		got, ok := ContainsPattern(tt.query)
+		if got != tt.want || ok != tt.wantOK {
+			t.Fatalf("ContainsPattern(%q) = %q, %t; want %q, %t", tt.query, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
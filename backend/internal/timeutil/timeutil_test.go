@@ -0,0 +1,35 @@
+package timeutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseEventTimestampAcceptsSupportedFormats(t *testing.T) {
+	want := time.Date(2025, 6, 14, 9, 30, 0, 0, time.UTC)
+	cases := []string{
+		"2025-06-14T09:30",
+		"2025-06-14T09:30:00",
+		"2025-06-14T09:30:00.000",
+		"2025-06-14T09:30:00Z",
+		"2025-06-14T09:30:00+02:00",
+		" 2025-06-14T09:30 ",
+	}
+	for _, input := range cases {
+		got, err := ParseEventTimestamp(input)
+		if err != nil {
+			t.Fatalf("ParseEventTimestamp(%q) returned error: %v", input, err)
+		}
+		if !got.Equal(want) {
+			t.Fatalf("ParseEventTimestamp(%q) = %s, want %s", input, got, want)
+		}
+	}
+}
+
+func TestParseEventTimestampRejectsInvalidInput(t *testing.T) {
+	for _, input := range []string{"", "tomorrow", "2025-06-14", "14/06/2025 09:30"} {
+		if _, err := ParseEventTimestamp(input); err == nil {
+			t.Fatalf("ParseEventTimestamp(%q) expected error", input)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package logistics
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	qrcode "github.com/skip2/go-qrcode"
+
+	"github.com/innhopp/central/backend/httpx"
+)
+
+const (
+	defaultGearQRSize = 256
+	minGearQRSize     = 64
+	maxGearQRSize     = 1024
+
+	defaultGearAssetPath = "/logistics/gear-assets/{id}"
+)
+
+// gearAssetURLTemplate returns the deep link encoded into gear QR codes.
+// GEAR_ASSET_URL_TEMPLATE takes precedence; otherwise the asset page under
+// FRONTEND_URL is used. The template's {id} placeholder is replaced with the
+// asset id.
+func gearAssetURLTemplate() string {
+	if template := strings.TrimSpace(os.Getenv("GEAR_ASSET_URL_TEMPLATE")); template != "" {
+		return template
+	}
+	return strings.TrimRight(strings.TrimSpace(os.Getenv("FRONTEND_URL")), "/") + defaultGearAssetPath
+}
+
+func gearAssetLink(template string, assetID int64) string {
+	return strings.ReplaceAll(template, "{id}", strconv.FormatInt(assetID, 10))
+}
+
+func parseGearQRSize(raw string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return defaultGearQRSize, nil
+	}
+	size, err := strconv.Atoi(raw)
+	if err != nil || size < minGearQRSize || size > maxGearQRSize {
+		return 0, errors.New("size must be an integer between " + strconv.Itoa(minGearQRSize) + " and " + strconv.Itoa(maxGearQRSize))
+	}
+	return size, nil
+}
+
+func (h *Handler) gearAssetQRCode(w http.ResponseWriter, r *http.Request) {
+	assetID, err := strconv.ParseInt(chi.URLParam(r, "assetID"), 10, 64)
+	if err != nil || assetID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid gear asset id")
+		return
+	}
+	size, err := parseGearQRSize(r.URL.Query().Get("size"))
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var exists bool
+	if err := h.db.QueryRow(r.Context(), `SELECT TRUE FROM gear_assets WHERE id = $1`, assetID).Scan(&exists); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			httpx.Error(w, http.StatusNotFound, "gear asset not found")
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to load gear asset")
+		return
+	}
+
+	png, err := qrcode.Encode(gearAssetLink(h.gearAssetURLTemplate, assetID), qrcode.Medium, size)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to generate qr code")
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(png)
+}
@@ -0,0 +1,175 @@
+package logistics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/innhopp/central/backend/httpx"
+)
+
+const maxBulkGearAssets = 200
+
+// gearStatusTransitions lists the statuses each gear status may move to.
+var gearStatusTransitions = map[string][]string{
+	"available":      {"checked_out", "in_maintenance", "retired"},
+	"checked_out":    {"available", "in_maintenance"},
+	"in_maintenance": {"available", "retired"},
+	"retired":        {},
+}
+
+var gearStatusValues = []string{"available", "checked_out", "in_maintenance", "retired"}
+
+// GearStatusValues lists the accepted gear asset statuses.
+func GearStatusValues() []string {
+	return append([]string(nil), gearStatusValues...)
+}
+
+func normalizeGearStatus(raw string) (string, error) {
+	status := strings.ToLower(strings.TrimSpace(raw))
+	if _, ok := gearStatusTransitions[status]; !ok {
+		return "", errors.New("status must be one of: " + strings.Join(gearStatusValues, ", "))
+	}
+	return status, nil
+}
+
+// validateGearStatusTransition reports whether an asset may move from one
+// status to another. Assets carrying a status from before the state machine
+// existed may move to any known status.
+func validateGearStatusTransition(from, to string) error {
+	from = strings.ToLower(strings.TrimSpace(from))
+	if from == to {
+		return nil
+	}
+	allowed, known := gearStatusTransitions[from]
+	if !known {
+		return nil
+	}
+	for _, next := range allowed {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot change status from %s to %s", from, to)
+}
+
+type bulkGearStatusResult struct {
+	ID             int64  `json:"id"`
+	Updated        bool   `json:"updated"`
+	PreviousStatus string `json:"previous_status,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// updateGearAssetStatuses applies one status to many assets in a single
+// transaction. Assets that are missing or cannot make the transition are
+// reported as rejected and left untouched; the rest are updated.
+func (h *Handler) updateGearAssetStatuses(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		AssetIDs []int64 `json:"asset_ids"`
+		Status   string  `json:"status"`
+	}
+	if err := httpx.DecodeJSON(r, &payload); err != nil {
+		httpx.PayloadError(w, err, "invalid request payload")
+		return
+	}
+
+	status, err := normalizeGearStatus(payload.Status)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ids, err := normalizeBulkGearAssetIDs(payload.AssetIDs)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	tx, err := h.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to update gear assets")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `SELECT id, status FROM gear_assets WHERE id = ANY($1) FOR UPDATE`, ids)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load gear assets")
+		return
+	}
+	current := make(map[int64]string, len(ids))
+	for rows.Next() {
+		var id int64
+		var previous string
+		if err := rows.Scan(&id, &previous); err != nil {
+			rows.Close()
+			httpx.Error(w, http.StatusInternalServerError, "failed to parse gear asset")
+			return
+		}
+		current[id] = previous
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load gear assets")
+		return
+	}
+
+	results := make([]bulkGearStatusResult, 0, len(ids))
+	var accepted []int64
+	for _, id := range ids {
+		previous, ok := current[id]
+		if !ok {
+			results = append(results, bulkGearStatusResult{ID: id, Error: "gear asset not found"})
+			continue
+		}
+		if err := validateGearStatusTransition(previous, status); err != nil {
+			results = append(results, bulkGearStatusResult{ID: id, PreviousStatus: previous, Error: err.Error()})
+			continue
+		}
+		results = append(results, bulkGearStatusResult{ID: id, Updated: true, PreviousStatus: previous})
+		accepted = append(accepted, id)
+	}
+
+	if len(accepted) > 0 {
+		if _, err := tx.Exec(ctx, `UPDATE gear_assets SET status = $1 WHERE id = ANY($2)`, status, accepted); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to update gear assets")
+			return
+		}
+	}
+	body := map[string]any{
+		"status":   status,
+		"updated":  len(accepted),
+		"rejected": len(ids) - len(accepted),
+		"results":  results,
+	}
+	if err := httpx.FinishTx(ctx, w, r, tx, http.StatusOK, body); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to update gear assets")
+	}
+}
+
+func normalizeBulkGearAssetIDs(raw []int64) ([]int64, error) {
+	if len(raw) == 0 {
+		return nil, errors.New("asset_ids is required")
+	}
+	seen := make(map[int64]struct{}, len(raw))
+	ids := make([]int64, 0, len(raw))
+	for _, id := range raw {
+		if id <= 0 {
+			return nil, errors.New("asset_ids must contain positive ids")
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	if len(ids) > maxBulkGearAssets {
+		return nil, fmt.Errorf("asset_ids cannot contain more than %d ids", maxBulkGearAssets)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
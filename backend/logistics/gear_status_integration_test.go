@@ -0,0 +1,134 @@
+package logistics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestUpdateGearAssetStatusesUpdatesLegalMovesAndReportsTheRest(t *testing.T) {
+	db := openLogisticsTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ensureLogisticsTestSchema(t, ctx, db)
+
+	insertAsset := func(status string) int64 {
+		t.Helper()
+		serial := "bulk-status-" + status + "-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+		var id int64
+		if err := db.QueryRow(ctx, `INSERT INTO gear_assets (name, serial_number, status) VALUES ('Bulk Rig', $1, $2) RETURNING id`, serial, status).Scan(&id); err != nil {
+			t.Fatalf("insert gear asset failed: %v", err)
+		}
+		t.Cleanup(func() { db.Exec(context.Background(), `DELETE FROM gear_assets WHERE id = $1`, id) })
+		return id
+	}
+	checkedOut := insertAsset("checked_out")
+	maintenance := insertAsset("in_maintenance")
+	retired := insertAsset("retired")
+	legacy := insertAsset("lent out")
+	missing := legacy + 1000000
+
+	body, err := json.Marshal(map[string]any{
+		"asset_ids": []int64{retired, checkedOut, missing, maintenance, legacy, checkedOut},
+		"status":    " Available ",
+	})
+	if err != nil {
+		t.Fatalf("encode payload failed: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	NewHandler(db).updateGearAssetStatuses(rec, httptest.NewRequest(http.MethodPost, "/gear-assets/status-bulk", strings.NewReader(string(body))))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("updateGearAssetStatuses() status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	var result struct {
+		Status   string                 `json:"status"`
+		Updated  int                    `json:"updated"`
+		Rejected int                    `json:"rejected"`
+		Results  []bulkGearStatusResult `json:"results"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decode result failed: %v", err)
+	}
+	if result.Status != "available" || result.Updated != 3 || result.Rejected != 2 {
+		t.Fatalf("result = %s updated %d rejected %d, want available, 3 and 2", result.Status, result.Updated, result.Rejected)
+	}
+
+	byID := make(map[int64]bulkGearStatusResult, len(result.Results))
+	for _, item := range result.Results {
+		byID[item.ID] = item
+	}
+	if len(byID) != 5 {
+		t.Fatalf("results = %+v, want one per distinct id", result.Results)
+	}
+	for _, id := range []int64{checkedOut, maintenance, legacy} {
+		if !byID[id].Updated {
+			t.Fatalf("asset %d result = %+v, want updated", id, byID[id])
+		}
+	}
+	if got := byID[retired]; got.Updated || got.PreviousStatus != "retired" || got.Error == "" {
+		t.Fatalf("retired asset result = %+v, want a rejected transition", got)
+	}
+	if got := byID[missing]; got.Updated || got.Error != "gear asset not found" {
+		t.Fatalf("missing asset result = %+v, want not found", got)
+	}
+
+	status := func(id int64) string {
+		t.Helper()
+		var value string
+		if err := db.QueryRow(ctx, `SELECT status FROM gear_assets WHERE id = $1`, id).Scan(&value); err != nil {
+			t.Fatalf("load gear asset failed: %v", err)
+		}
+		return value
+	}
+	for _, id := range []int64{checkedOut, maintenance, legacy} {
+		if got := status(id); got != "available" {
+			t.Fatalf("asset %d status = %q, want available", id, got)
+		}
+	}
+	if got := status(retired); got != "retired" {
+		t.Fatalf("retired asset status = %q, want it left alone", got)
+	}
+}
+
+func openLogisticsTestDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Skip("DATABASE_URL not set; skipping integration logistics tests")
+	}
+	db, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatalf("connect db failed: %v", err)
+	}
+	return db
+}
+
+func ensureLogisticsTestSchema(t *testing.T, ctx context.Context, db *pgxpool.Pool) {
+	t.Helper()
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS gear_assets (
+            id SERIAL PRIMARY KEY,
+            name TEXT NOT NULL,
+            serial_number TEXT NOT NULL UNIQUE,
+            status TEXT NOT NULL,
+            location TEXT,
+            inspected_at TIMESTAMPTZ,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(ctx, stmt); err != nil {
+			t.Fatalf("schema setup failed: %v", err)
+		}
+	}
+}
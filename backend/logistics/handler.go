@@ -10,7 +10,6 @@ import (
 	"log"
 	"net/http"
 	"os"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -21,6 +20,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/innhopp/central/backend/httpx"
+	"github.com/innhopp/central/backend/internal/geo"
 	"github.com/innhopp/central/backend/internal/timeutil"
 	"github.com/innhopp/central/backend/rbac"
 )
@@ -30,6 +30,9 @@ type Handler struct {
 	db         *pgxpool.Pool
 	httpClient *http.Client
 	mapsAPIKey string
+
+	// gearAssetURLTemplate is the deep link encoded into gear QR codes.
+	gearAssetURLTemplate string
 }
 
 var validLocationReferenceTypes = map[string]struct{}{
@@ -61,14 +64,16 @@ func normalizeLocationReference(refType string, refID *int64) (*string, *int64,
 	return &trimmedType, &id, nil
 }
 
-func (h *Handler) resolveWriteLocationReference(ctx context.Context, q dbQuerier, eventID int64, label string, refType string, refID *int64) (*string, *int64, error) {
+func (h *Handler) resolveWriteLocationReference(ctx context.Context, q Querier, eventID int64, label string, refType string, refID *int64) (*string, *int64, error) {
 	if refID == nil {
 		return h.resolveLocationReference(ctx, q, eventID, label, refType)
 	}
 	return normalizeLocationReference(refType, refID)
 }
 
-type dbQuerier interface {
+// Querier is the subset of pgx the location and route helpers use. It is
+// satisfied by *pgxpool.Pool, pgx.Tx and other packages' narrow DB types.
+type Querier interface {
 	Query(context.Context, string, ...any) (pgx.Rows, error)
 	QueryRow(context.Context, string, ...any) pgx.Row
 	Exec(context.Context, string, ...any) (pgconn.CommandTag, error)
@@ -107,7 +112,7 @@ func selectUniqueLocationMatch(matches []locationReferenceMatch) (*string, *int6
 	return &refType, &refID, nil
 }
 
-func (h *Handler) locationLabelByReference(ctx context.Context, q dbQuerier, refType *string, refID *int64, fallback string) (string, error) {
+func (h *Handler) locationLabelByReference(ctx context.Context, q Querier, refType *string, refID *int64, fallback string) (string, error) {
 	if refType == nil || refID == nil || *refID <= 0 {
 		return fallback, nil
 	}
@@ -164,7 +169,7 @@ func (h *Handler) locationLabelByReference(ctx context.Context, q dbQuerier, ref
 	}
 }
 
-func (h *Handler) hydrateTransportLabels(ctx context.Context, q dbQuerier, transport *Transport) error {
+func (h *Handler) hydrateTransportLabels(ctx context.Context, q Querier, transport *Transport) error {
 	pickup, err := h.locationLabelByReference(ctx, q, transport.PickupLocationType, transport.PickupLocationID, transport.PickupLocation)
 	if err != nil {
 		return err
@@ -178,7 +183,7 @@ func (h *Handler) hydrateTransportLabels(ctx context.Context, q dbQuerier, trans
 	return nil
 }
 
-func (h *Handler) routeWaypointByReference(ctx context.Context, q dbQuerier, refType *string, refID *int64, fallback string) (string, error) {
+func (h *Handler) routeWaypointByReference(ctx context.Context, q Querier, refType *string, refID *int64, fallback string) (string, error) {
 	fallback = strings.TrimSpace(fallback)
 	if refType == nil || refID == nil || *refID <= 0 {
 		return fallback, nil
@@ -239,7 +244,7 @@ func (h *Handler) routeWaypointByReference(ctx context.Context, q dbQuerier, ref
 	return fallback, nil
 }
 
-func (h *Handler) resolveLocationReference(ctx context.Context, q dbQuerier, eventID int64, label string, preferredType string) (*string, *int64, error) {
+func (h *Handler) resolveLocationReference(ctx context.Context, q Querier, eventID int64, label string, preferredType string) (*string, *int64, error) {
 	normalizedLabel := normalizeLegacyLabel(label)
 	if normalizedLabel == "" || eventID <= 0 {
 		return nil, nil, nil
@@ -402,7 +407,7 @@ func normalizeTransportVehicle(v TransportVehicle) TransportVehicle {
 	return v
 }
 
-func (h *Handler) resolveEventVehicleID(ctx context.Context, q dbQuerier, eventID int64, vehicle TransportVehicle) (*int64, error) {
+func (h *Handler) resolveEventVehicleID(ctx context.Context, q Querier, eventID int64, vehicle TransportVehicle) (*int64, error) {
 	if eventID <= 0 {
 		return nil, nil
 	}
@@ -479,7 +484,7 @@ func (h *Handler) resolveEventVehicleID(ctx context.Context, q dbQuerier, eventI
 	return &id, nil
 }
 
-func (h *Handler) resolveLegacyVehicleSelections(ctx context.Context, q dbQuerier, eventID int64, vehicleIDs []int64, vehicles []TransportVehicle) ([]TransportVehicle, error) {
+func (h *Handler) resolveLegacyVehicleSelections(ctx context.Context, q Querier, eventID int64, vehicleIDs []int64, vehicles []TransportVehicle) ([]TransportVehicle, error) {
 	if len(vehicleIDs) > 0 {
 		return h.loadEventVehiclesForEvent(ctx, q, eventID, vehicleIDs)
 	}
@@ -540,7 +545,7 @@ type Meal struct {
 }
 
 func (h *Handler) listOthers(w http.ResponseWriter, r *http.Request) {
-	rows, err := h.db.Query(r.Context(), `SELECT id, name, coordinates, scheduled_at, description, notes, event_id, season_id, created_at FROM logistics_other ORDER BY created_at DESC`)
+	rows, err := h.db.Query(r.Context(), `SELECT id, name, coordinates, scheduled_at, description, notes, event_id, season_id, created_at FROM logistics_other ORDER BY created_at DESC, id DESC`)
 	if err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to list other logistics")
 		return
@@ -640,7 +645,7 @@ func (h *Handler) createOther(w http.ResponseWriter, r *http.Request) {
 		EventID     int64  `json:"event_id"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	name := strings.TrimSpace(payload.Name)
@@ -739,7 +744,7 @@ func (h *Handler) updateOther(w http.ResponseWriter, r *http.Request) {
 		EventID     int64  `json:"event_id"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	name := strings.TrimSpace(payload.Name)
@@ -857,7 +862,7 @@ func (h *Handler) deleteOther(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) listMeals(w http.ResponseWriter, r *http.Request) {
-	rows, err := h.db.Query(r.Context(), `SELECT id, name, location, location_type, location_id, scheduled_at, notes, event_id, season_id, created_at FROM logistics_meals ORDER BY created_at DESC`)
+	rows, err := h.db.Query(r.Context(), `SELECT id, name, location, location_type, location_id, scheduled_at, notes, event_id, season_id, created_at FROM logistics_meals ORDER BY created_at DESC, id DESC`)
 	if err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to list meals")
 		return
@@ -981,7 +986,7 @@ func (h *Handler) createMeal(w http.ResponseWriter, r *http.Request) {
 		EventID      int64  `json:"event_id"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	name := strings.TrimSpace(payload.Name)
@@ -1063,7 +1068,7 @@ func (h *Handler) updateMeal(w http.ResponseWriter, r *http.Request) {
 		EventID      int64  `json:"event_id"`
 	}
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	name := strings.TrimSpace(payload.Name)
@@ -1300,7 +1305,7 @@ func (h *Handler) updateTransport(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
@@ -1487,7 +1492,7 @@ func (h *Handler) attachEventVehiclesToGroundCrew(ctx context.Context, tx pgx.Tx
 }
 
 func (h *Handler) listGroundCrews(w http.ResponseWriter, r *http.Request) {
-	rows, err := h.db.Query(r.Context(), `SELECT id, pickup_location, pickup_location_type, pickup_location_id, destination, destination_type, destination_id, passenger_count, duration_minutes, scheduled_at, notes, event_id, season_id, created_at FROM logistics_ground_crews ORDER BY created_at DESC`)
+	rows, err := h.db.Query(r.Context(), `SELECT id, pickup_location, pickup_location_type, pickup_location_id, destination, destination_type, destination_id, passenger_count, duration_minutes, scheduled_at, notes, event_id, season_id, created_at FROM logistics_ground_crews ORDER BY created_at DESC, id DESC`)
 	if err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to list ground crews")
 		return
@@ -1620,7 +1625,7 @@ func (h *Handler) createGroundCrew(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
@@ -1864,7 +1869,7 @@ func (h *Handler) updateGroundCrew(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
@@ -2028,6 +2033,8 @@ func NewHandler(db *pgxpool.Pool) *Handler {
 		db:         db,
 		httpClient: &http.Client{Timeout: 8 * time.Second},
 		mapsAPIKey: strings.TrimSpace(os.Getenv("GOOGLE_MAPS_API_KEY")),
+
+		gearAssetURLTemplate: gearAssetURLTemplate(),
 	}
 }
 
@@ -2394,12 +2401,12 @@ func BackfillMissingRouteDurations(ctx context.Context, db *pgxpool.Pool) error
 
 // RecalculateRouteDurationsForLocationReference recomputes duration_minutes for any
 // transport/ground-crew route that references the given location entity.
-func RecalculateRouteDurationsForLocationReference(ctx context.Context, db *pgxpool.Pool, refType string, refID int64) error {
+func RecalculateRouteDurationsForLocationReference(ctx context.Context, db Querier, refType string, refID int64) error {
 	refType = strings.TrimSpace(refType)
 	if refType == "" || refID <= 0 {
 		return errors.New("invalid location reference")
 	}
-	h := NewHandler(db)
+	h := NewHandler(nil)
 	if h.mapsAPIKey == "" {
 		return nil
 	}
@@ -2422,7 +2429,7 @@ func RecalculateRouteDurationsForLocationReference(ctx context.Context, db *pgxp
                 OR (destination_type = $1 AND destination_id = $2)`,
 			table,
 		)
-		rows, err := h.db.Query(ctx, query, refType, refID)
+		rows, err := db.Query(ctx, query, refType, refID)
 		if err != nil {
 			return err
 		}
@@ -2470,12 +2477,12 @@ func RecalculateRouteDurationsForLocationReference(ctx context.Context, db *pgxp
 				destinationID = &val
 			}
 
-			originWaypoint, waypointErr := h.routeWaypointByReference(ctx, h.db, originType, originID, item.originLabel)
+			originWaypoint, waypointErr := h.routeWaypointByReference(ctx, db, originType, originID, item.originLabel)
 			if waypointErr != nil {
 				log.Printf("route duration recalc origin waypoint resolve failed (%s id=%d): %v", table, item.id, waypointErr)
 				originWaypoint = item.originLabel
 			}
-			destinationWaypoint, waypointErr := h.routeWaypointByReference(ctx, h.db, destinationType, destinationID, item.destinationLabel)
+			destinationWaypoint, waypointErr := h.routeWaypointByReference(ctx, db, destinationType, destinationID, item.destinationLabel)
 			if waypointErr != nil {
 				log.Printf("route duration recalc destination waypoint resolve failed (%s id=%d): %v", table, item.id, waypointErr)
 				destinationWaypoint = item.destinationLabel
@@ -2487,7 +2494,7 @@ func RecalculateRouteDurationsForLocationReference(ctx context.Context, db *pgxp
 				continue
 			}
 			updateQuery := fmt.Sprintf(`UPDATE %s SET duration_minutes = $1 WHERE id = $2`, table)
-			if _, err := h.db.Exec(ctx, updateQuery, minutes, item.id); err != nil {
+			if _, err := db.Exec(ctx, updateQuery, minutes, item.id); err != nil {
 				log.Printf("route duration recalc update failed (%s id=%d): %v", table, item.id, err)
 				continue
 			}
@@ -2509,6 +2516,11 @@ func (h *Handler) Routes(enforcer *rbac.Enforcer) chi.Router {
 	r := chi.NewRouter()
 	r.With(enforcer.Authorize(rbac.PermissionViewLogistics)).Get("/gear-assets", h.listGearAssets)
 	r.With(enforcer.Authorize(rbac.PermissionManageLogistics)).Post("/gear-assets", h.createGearAsset)
+	r.With(enforcer.Authorize(rbac.PermissionManageLogistics)).Post("/gear-assets/status-bulk", h.updateGearAssetStatuses)
+	r.With(enforcer.Authorize(rbac.PermissionViewLogistics)).Get("/gear-assets/{assetID}/qr.png", h.gearAssetQRCode)
+	r.With(enforcer.Authorize(rbac.PermissionViewLogistics)).Get("/participants/{participantID}/gear", h.listParticipantGear)
+	r.With(enforcer.Authorize(rbac.PermissionManageLogistics)).Post("/participants/{participantID}/gear", h.assignParticipantGear)
+	r.With(enforcer.Authorize(rbac.PermissionManageLogistics)).Delete("/participants/{participantID}/gear/{assetID}", h.unassignParticipantGear)
 	r.With(enforcer.Authorize(rbac.PermissionViewLogistics)).Get("/transports", h.listTransports)
 	r.With(enforcer.Authorize(rbac.PermissionManageLogistics)).Post("/transports", h.createTransport)
 	r.With(enforcer.Authorize(rbac.PermissionViewLogistics)).Get("/transports/{transportID}", h.getTransport)
@@ -2547,8 +2559,20 @@ type GearAsset struct {
 	CreatedAt    time.Time  `json:"created_at"`
 }
 
+// maxGearAssetPageSize caps ?limit= on the gear asset list.
+const maxGearAssetPageSize = 500
+
 func (h *Handler) listGearAssets(w http.ResponseWriter, r *http.Request) {
-	rows, err := h.db.Query(r.Context(), `SELECT id, name, serial_number, status, location, inspected_at, created_at FROM gear_assets ORDER BY created_at DESC`)
+	page, err := httpx.ParsePage(r, maxGearAssetPageSize)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	pageClause, pageArgs := page.SQL(1)
+	rows, err := h.db.Query(r.Context(), `
+		SELECT id, name, serial_number, status, location, inspected_at, created_at, `+httpx.TotalCountColumn+`
+		FROM gear_assets
+		ORDER BY created_at DESC, id DESC`+pageClause, pageArgs...)
 	if err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to list gear assets")
 		return
@@ -2558,14 +2582,51 @@ func (h *Handler) listGearAssets(w http.ResponseWriter, r *http.Request) {
 	var assets []GearAsset
 	for rows.Next() {
 		var g GearAsset
-		if err := rows.Scan(&g.ID, &g.Name, &g.SerialNumber, &g.Status, &g.Location, &g.InspectedAt, &g.CreatedAt); err != nil {
+		var location sql.NullString
+		var inspectedAt sql.NullTime
+		if err := rows.Scan(&g.ID, &g.Name, &g.SerialNumber, &g.Status, &location, &inspectedAt, &g.CreatedAt, &page.Total); err != nil {
 			httpx.Error(w, http.StatusInternalServerError, "failed to parse gear asset")
 			return
 		}
-		assets = append(assets, g)
+		assets = append(assets, g.withNullable(location, inspectedAt))
+	}
+	if err := rows.Err(); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to list gear assets")
+		return
 	}
+	rows.Close()
+	if page.NeedsCount(len(assets)) {
+		if err := h.db.QueryRow(r.Context(), `SELECT COUNT(*) FROM gear_assets`).Scan(&page.Total); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to count gear assets")
+			return
+		}
+	}
+
+	httpx.WritePage(w, r, http.StatusOK, assets, page)
+}
+
+// scanGearAsset reads a gear_assets row, tolerating NULL location and
+// inspected_at values.
+func scanGearAsset(row pgx.Row) (GearAsset, error) {
+	var g GearAsset
+	var location sql.NullString
+	var inspectedAt sql.NullTime
+	if err := row.Scan(&g.ID, &g.Name, &g.SerialNumber, &g.Status, &location, &inspectedAt, &g.CreatedAt); err != nil {
+		return GearAsset{}, err
+	}
+	return g.withNullable(location, inspectedAt), nil
+}
 
-	httpx.WriteJSON(w, http.StatusOK, assets)
+// withNullable fills in the location and inspection time scanned alongside g.
+func (g GearAsset) withNullable(location sql.NullString, inspectedAt sql.NullTime) GearAsset {
+	if location.Valid {
+		g.Location = location.String
+	}
+	if inspectedAt.Valid {
+		t := inspectedAt.Time
+		g.InspectedAt = &t
+	}
+	return g
 }
 
 func (h *Handler) createGearAsset(w http.ResponseWriter, r *http.Request) {
@@ -2578,7 +2639,7 @@ func (h *Handler) createGearAsset(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
@@ -2600,18 +2661,19 @@ func (h *Handler) createGearAsset(w http.ResponseWriter, r *http.Request) {
 		inspectedAt = &t
 	}
 
+	location := strings.TrimSpace(payload.Location)
 	row := h.db.QueryRow(r.Context(),
 		`INSERT INTO gear_assets (name, serial_number, status, location, inspected_at)
-         VALUES ($1, $2, $3, $4, $5)
+         VALUES ($1, $2, $3, NULLIF($4, ''), $5)
          RETURNING id, created_at`,
-		name, serial, status, payload.Location, inspectedAt,
+		name, serial, status, location, inspectedAt,
 	)
 
 	var asset GearAsset
 	asset.Name = name
 	asset.SerialNumber = serial
 	asset.Status = status
-	asset.Location = payload.Location
+	asset.Location = location
 	asset.InspectedAt = inspectedAt
 
 	if err := row.Scan(&asset.ID, &asset.CreatedAt); err != nil {
@@ -2640,7 +2702,7 @@ type EventVehicle struct {
 	CreatedAt         time.Time `json:"created_at"`
 }
 
-func (h *Handler) loadEventVehiclesForEvent(ctx context.Context, q dbQuerier, eventID int64, vehicleIDs []int64) ([]TransportVehicle, error) {
+func (h *Handler) loadEventVehiclesForEvent(ctx context.Context, q Querier, eventID int64, vehicleIDs []int64) ([]TransportVehicle, error) {
 	if len(vehicleIDs) == 0 {
 		return nil, nil
 	}
@@ -2750,84 +2812,9 @@ type routesAPIErrorResponse struct {
 	} `json:"error"`
 }
 
-var dmsTokenPattern = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)\s*°\s*(\d+(?:\.\d+)?)?\s*['’′]?\s*(\d+(?:\.\d+)?)?\s*(?:["”″])?\s*([NSEW])`)
-
-func dmsToDecimal(degrees, minutes, seconds float64, hemi string) float64 {
-	val := degrees + (minutes / 60.0) + (seconds / 3600.0)
-	h := strings.ToUpper(strings.TrimSpace(hemi))
-	if h == "S" || h == "W" {
-		return -val
-	}
-	return val
-}
-
-func parseCoordinateWaypoint(value string) (float64, float64, bool) {
-	trimmed := strings.TrimSpace(value)
-	if trimmed == "" {
-		return 0, 0, false
-	}
-
-	matches := dmsTokenPattern.FindAllStringSubmatch(trimmed, 2)
-	if len(matches) == 2 {
-		parseDMSPart := func(m []string) (float64, string, bool) {
-			deg, err := strconv.ParseFloat(m[1], 64)
-			if err != nil {
-				return 0, "", false
-			}
-			mins := 0.0
-			if strings.TrimSpace(m[2]) != "" {
-				mins, err = strconv.ParseFloat(m[2], 64)
-				if err != nil {
-					return 0, "", false
-				}
-			}
-			secs := 0.0
-			if strings.TrimSpace(m[3]) != "" {
-				secs, err = strconv.ParseFloat(m[3], 64)
-				if err != nil {
-					return 0, "", false
-				}
-			}
-			return dmsToDecimal(deg, mins, secs, m[4]), strings.ToUpper(strings.TrimSpace(m[4])), true
-		}
-		firstVal, firstHemi, ok := parseDMSPart(matches[0])
-		if !ok {
-			return 0, 0, false
-		}
-		secondVal, secondHemi, ok := parseDMSPart(matches[1])
-		if !ok {
-			return 0, 0, false
-		}
-		if (firstHemi == "N" || firstHemi == "S") && (secondHemi == "E" || secondHemi == "W") {
-			return firstVal, secondVal, true
-		}
-		if (firstHemi == "E" || firstHemi == "W") && (secondHemi == "N" || secondHemi == "S") {
-			return secondVal, firstVal, true
-		}
-	}
-
-	normalized := strings.NewReplacer(",", " ", ";", " ", "\t", " ").Replace(trimmed)
-	parts := strings.Fields(normalized)
-	if len(parts) != 2 {
-		return 0, 0, false
-	}
-	lat, err := strconv.ParseFloat(parts[0], 64)
-	if err != nil {
-		return 0, 0, false
-	}
-	lng, err := strconv.ParseFloat(parts[1], 64)
-	if err != nil {
-		return 0, 0, false
-	}
-	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
-		return 0, 0, false
-	}
-	return lat, lng, true
-}
-
 func buildRoutesWaypoint(value string) routesAPIWaypoint {
 	trimmed := strings.TrimSpace(value)
-	if lat, lng, ok := parseCoordinateWaypoint(trimmed); ok {
+	if lat, lng, ok := geo.ParseCoordinates(trimmed); ok {
 		return routesAPIWaypoint{
 			Location: &routesAPILocation{
 				LatLng: routesAPILatLng{
@@ -2915,7 +2902,7 @@ func (h *Handler) calculateRouteDurationMinutes(ctx context.Context, origin, des
 }
 
 func (h *Handler) listTransports(w http.ResponseWriter, r *http.Request) {
-	rows, err := h.db.Query(r.Context(), `SELECT id, pickup_location, pickup_location_type, pickup_location_id, destination, destination_type, destination_id, passenger_count, duration_minutes, scheduled_at, notes, event_id, season_id, created_at FROM logistics_transports ORDER BY created_at DESC`)
+	rows, err := h.db.Query(r.Context(), `SELECT id, pickup_location, pickup_location_type, pickup_location_id, destination, destination_type, destination_id, passenger_count, duration_minutes, scheduled_at, notes, event_id, season_id, created_at FROM logistics_transports ORDER BY created_at DESC, id DESC`)
 	if err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to list transports")
 		return
@@ -3048,7 +3035,7 @@ func (h *Handler) createTransport(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
@@ -3167,7 +3154,7 @@ func (h *Handler) createTransport(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) listVehicles(w http.ResponseWriter, r *http.Request) {
-	rows, err := h.db.Query(r.Context(), `SELECT id, event_id, name, driver, passenger_capacity, notes, created_at FROM logistics_event_vehicles ORDER BY created_at DESC`)
+	rows, err := h.db.Query(r.Context(), `SELECT id, event_id, name, driver, passenger_capacity, notes, created_at FROM logistics_event_vehicles ORDER BY created_at DESC, id DESC`)
 	if err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to list vehicles")
 		return
@@ -3197,7 +3184,7 @@ func (h *Handler) createVehicle(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
@@ -3275,7 +3262,7 @@ func (h *Handler) updateVehicle(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
@@ -0,0 +1,137 @@
+package logistics
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// valuesRow scans fixed values, honouring sql.Scanner destinations the way
+// pgx does for NULL columns.
+type valuesRow []any
+
+func (r valuesRow) Scan(dest ...any) error {
+	for i, value := range r {
+		if scanner, ok := dest[i].(sql.Scanner); ok {
+			if err := scanner.Scan(value); err != nil {
+				return err
+			}
+			continue
+		}
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(value))
+	}
+	return nil
+}
+
+func TestScanGearAssetAllowsNullLocationAndInspection(t *testing.T) {
+	createdAt := time.Date(2025, 6, 14, 9, 0, 0, 0, time.UTC)
+
+	asset, err := scanGearAsset(valuesRow{int64(1), "Main Rig", "RIG-001", "available", nil, nil, createdAt})
+	if err != nil {
+		t.Fatalf("scanGearAsset() returned error: %v", err)
+	}
+	if asset.Location != "" || asset.InspectedAt != nil {
+		t.Fatalf("scanGearAsset() = %+v, want empty location and inspection", asset)
+	}
+
+	inspectedAt := createdAt.Add(-time.Hour)
+	asset, err = scanGearAsset(valuesRow{int64(2), "Reserve", "RES-001", "available", "Hangar", inspectedAt, createdAt})
+	if err != nil {
+		t.Fatalf("scanGearAsset() returned error: %v", err)
+	}
+	if asset.Location != "Hangar" || asset.InspectedAt == nil || !asset.InspectedAt.Equal(inspectedAt) {
+		t.Fatalf("scanGearAsset() = %+v, want Hangar inspected at %s", asset, inspectedAt)
+	}
+}
+
+func TestValidateGearStatusTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		ok       bool
+	}{
+		{from: "available", to: "checked_out", ok: true},
+		{from: "checked_out", to: "available", ok: true},
+		{from: "in_maintenance", to: "retired", ok: true},
+		{from: "available", to: "available", ok: true},
+		{from: "retired", to: "available", ok: false},
+		{from: "checked_out", to: "retired", ok: false},
+		{from: "Needs Repack", to: "in_maintenance", ok: true},
+	}
+	for _, tt := range tests {
+		err := validateGearStatusTransition(tt.from, tt.to)
+		if (err == nil) != tt.ok {
+			t.Errorf("validateGearStatusTransition(%q, %q) error = %v, want ok=%v", tt.from, tt.to, err, tt.ok)
+		}
+	}
+}
+
+func TestNormalizeBulkGearAssetIDs(t *testing.T) {
+	got, err := normalizeBulkGearAssetIDs([]int64{7, 3, 7, 1})
+	if err != nil {
+		t.Fatalf("normalizeBulkGearAssetIDs() returned error: %v", err)
+	}
+	if want := []int64{1, 3, 7}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("normalizeBulkGearAssetIDs() = %v, want %v", got, want)
+	}
+	if _, err := normalizeBulkGearAssetIDs(nil); err == nil {
+		t.Fatal("normalizeBulkGearAssetIDs() expected error for empty ids")
+	}
+	if _, err := normalizeBulkGearAssetIDs([]int64{2, 0}); err == nil {
+		t.Fatal("normalizeBulkGearAssetIDs() expected error for non-positive id")
+	}
+}
+
+func TestUpdateGearAssetStatusesRejectsInvalidPayloadBeforeTheDatabase(t *testing.T) {
+	// A nil pool panics if the handler gets as far as a query.
+	h := NewHandler(nil)
+	tests := []string{
+		`{"asset_ids": [1], "status": "lost"}`,
+		`{"asset_ids": [], "status": "available"}`,
+		`{"asset_ids": [1, -2], "status": "available"}`,
+		`{"asset_ids": [1], "status": "available", "note": "x"}`,
+	}
+	for _, body := range tests {
+		rec := httptest.NewRecorder()
+		h.updateGearAssetStatuses(rec, httptest.NewRequest(http.MethodPost, "/gear-assets/status-bulk", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("updateGearAssetStatuses(%s) status = %d, want %d", body, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestParseGearQRSize(t *testing.T) {
+	if size, err := parseGearQRSize(""); err != nil || size != defaultGearQRSize {
+		t.Fatalf("parseGearQRSize(\"\") = %d, %v; want %d", size, err, defaultGearQRSize)
+	}
+	if size, err := parseGearQRSize("512"); err != nil || size != 512 {
+		t.Fatalf("parseGearQRSize(\"512\") = %d, %v; want 512", size, err)
+	}
+	for _, raw := range []string{"abc", "10", "4096"} {
+		if _, err := parseGearQRSize(raw); err == nil {
+			t.Errorf("parseGearQRSize(%q) expected error", raw)
+		}
+	}
+}
+
+func TestGearAssetLinkSubstitutesID(t *testing.T) {
+	if got := gearAssetLink("https://central.example.com/gear/{id}?scan=1", 42); got != "https://central.example.com/gear/42?scan=1" {
+		t.Fatalf("gearAssetLink() = %q", got)
+	}
+}
+
+func TestScanParticipantGearReadsAssetAndAssignment(t *testing.T) {
+	createdAt := time.Date(2025, 6, 14, 9, 0, 0, 0, time.UTC)
+	assignedAt := createdAt.Add(24 * time.Hour)
+
+	gear, err := scanParticipantGear(valuesRow{int64(3), "Main Rig", "RIG-003", "available", nil, nil, createdAt, int64(9), true, assignedAt})
+	if err != nil {
+		t.Fatalf("scanParticipantGear() returned error: %v", err)
+	}
+	if gear.ID != 3 || gear.SerialNumber != "RIG-003" || gear.ParticipantID != 9 || !gear.Primary || !gear.AssignedAt.Equal(assignedAt) {
+		t.Fatalf("scanParticipantGear() = %+v", gear)
+	}
+}
@@ -0,0 +1,221 @@
+package logistics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/innhopp/central/backend/httpx"
+)
+
+// ParticipantGear is a gear asset a participant owns or holds long term, as
+// opposed to gear checked out for one event. A participant has at most one
+// primary rig.
+type ParticipantGear struct {
+	GearAsset
+	ParticipantID int64     `json:"participant_id"`
+	Primary       bool      `json:"primary"`
+	AssignedAt    time.Time `json:"assigned_at"`
+}
+
+const participantGearSelect = `
+	SELECT g.id, g.name, g.serial_number, g.status, g.location, g.inspected_at, g.created_at,
+	       pg.participant_id, pg.is_primary, pg.assigned_at
+	FROM participant_gear pg
+	JOIN gear_assets g ON g.id = pg.gear_asset_id`
+
+func scanParticipantGear(row pgx.Row) (ParticipantGear, error) {
+	var pg ParticipantGear
+	asset, err := scanGearAsset(gearRowWithExtras{row: row, extras: []any{&pg.ParticipantID, &pg.Primary, &pg.AssignedAt}})
+	if err != nil {
+		return ParticipantGear{}, err
+	}
+	pg.GearAsset = asset
+	return pg, nil
+}
+
+// gearRowWithExtras lets scanGearAsset read the asset columns of a row that
+// carries more columns after them.
+type gearRowWithExtras struct {
+	row    pgx.Row
+	extras []any
+}
+
+func (r gearRowWithExtras) Scan(dest ...any) error {
+	return r.row.Scan(append(dest, r.extras...)...)
+}
+
+func parseParticipantIDParam(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	participantID, err := strconv.ParseInt(chi.URLParam(r, "participantID"), 10, 64)
+	if err != nil || participantID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid participant id")
+		return 0, false
+	}
+	return participantID, true
+}
+
+func (h *Handler) listParticipantGear(w http.ResponseWriter, r *http.Request) {
+	participantID, ok := parseParticipantIDParam(w, r)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	var exists bool
+	if err := h.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM participant_profiles WHERE id = $1)`, participantID).Scan(&exists); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load participant")
+		return
+	}
+	if !exists {
+		httpx.Error(w, http.StatusNotFound, "participant not found")
+		return
+	}
+
+	rows, err := h.db.Query(ctx, participantGearSelect+`
+		WHERE pg.participant_id = $1
+		ORDER BY pg.is_primary DESC, pg.assigned_at, g.id`, participantID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to list participant gear")
+		return
+	}
+	defer rows.Close()
+
+	gear := make([]ParticipantGear, 0)
+	for rows.Next() {
+		item, err := scanParticipantGear(rows)
+		if err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to parse participant gear")
+			return
+		}
+		gear = append(gear, item)
+	}
+	if err := rows.Err(); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to list participant gear")
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, gear)
+}
+
+// assignParticipantGear gives a gear asset to a participant. Retired assets
+// and assets already held by someone else are refused with 409. Assigning an
+// asset the participant already holds only updates its primary flag, and
+// making an asset primary demotes the participant's previous primary rig.
+func (h *Handler) assignParticipantGear(w http.ResponseWriter, r *http.Request) {
+	participantID, ok := parseParticipantIDParam(w, r)
+	if !ok {
+		return
+	}
+	var payload struct {
+		GearAssetID int64 `json:"gear_asset_id"`
+		Primary     bool  `json:"primary"`
+	}
+	if err := httpx.DecodeJSON(r, &payload); err != nil {
+		httpx.PayloadError(w, err, "invalid request payload")
+		return
+	}
+	if payload.GearAssetID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "gear_asset_id is required")
+		return
+	}
+
+	ctx := r.Context()
+	tx, err := h.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to assign gear")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM participant_profiles WHERE id = $1)`, participantID).Scan(&exists); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load participant")
+		return
+	}
+	if !exists {
+		httpx.Error(w, http.StatusNotFound, "participant not found")
+		return
+	}
+
+	var assetStatus string
+	var holderID *int64
+	err = tx.QueryRow(ctx, `
+		SELECT g.status, pg.participant_id
+		FROM gear_assets g
+		LEFT JOIN participant_gear pg ON pg.gear_asset_id = g.id
+		WHERE g.id = $1
+		FOR UPDATE OF g`, payload.GearAssetID).Scan(&assetStatus, &holderID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		httpx.Error(w, http.StatusNotFound, "gear asset not found")
+		return
+	}
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to assign gear")
+		return
+	}
+	if normalized, _ := normalizeGearStatus(assetStatus); normalized == "retired" {
+		httpx.Error(w, http.StatusConflict, "retired gear cannot be assigned")
+		return
+	}
+	if holderID != nil && *holderID != participantID {
+		httpx.WriteJSON(w, http.StatusConflict, map[string]any{
+			"error":          "gear asset is assigned to another participant",
+			"participant_id": *holderID,
+		})
+		return
+	}
+
+	if payload.Primary {
+		if _, err := tx.Exec(ctx, `UPDATE participant_gear SET is_primary = FALSE WHERE participant_id = $1 AND is_primary AND gear_asset_id <> $2`, participantID, payload.GearAssetID); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to assign gear")
+			return
+		}
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO participant_gear (gear_asset_id, participant_id, is_primary)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (gear_asset_id) DO UPDATE SET is_primary = EXCLUDED.is_primary`,
+		payload.GearAssetID, participantID, payload.Primary); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to assign gear")
+		return
+	}
+	assigned, err := scanParticipantGear(tx.QueryRow(ctx, participantGearSelect+` WHERE pg.gear_asset_id = $1`, payload.GearAssetID))
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load participant gear")
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to assign gear")
+		return
+	}
+
+	status := http.StatusCreated
+	if holderID != nil {
+		status = http.StatusOK
+	}
+	httpx.WriteJSON(w, status, assigned)
+}
+
+func (h *Handler) unassignParticipantGear(w http.ResponseWriter, r *http.Request) {
+	participantID, ok := parseParticipantIDParam(w, r)
+	if !ok {
+		return
+	}
+	assetID, err := strconv.ParseInt(chi.URLParam(r, "assetID"), 10, 64)
+	if err != nil || assetID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid gear asset id")
+		return
+	}
+	tag, err := h.db.Exec(r.Context(), `DELETE FROM participant_gear WHERE participant_id = $1 AND gear_asset_id = $2`, participantID, assetID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to unassign gear")
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		httpx.Error(w, http.StatusNotFound, "gear asset is not assigned to this participant")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
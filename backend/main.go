@@ -2,10 +2,15 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -14,15 +19,26 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/innhopp/central/backend/accounting"
+	"github.com/innhopp/central/backend/adminaudit"
 	"github.com/innhopp/central/backend/auth"
+	"github.com/innhopp/central/backend/batch"
 	"github.com/innhopp/central/backend/budgets"
 	"github.com/innhopp/central/backend/comms"
+	"github.com/innhopp/central/backend/cors"
+	"github.com/innhopp/central/backend/debuglog"
+	"github.com/innhopp/central/backend/enums"
 	"github.com/innhopp/central/backend/events"
 	"github.com/innhopp/central/backend/innhopps"
+	"github.com/innhopp/central/backend/internal/clock"
+	"github.com/innhopp/central/backend/internal/email"
 	"github.com/innhopp/central/backend/logistics"
 	"github.com/innhopp/central/backend/participants"
+	"github.com/innhopp/central/backend/querybudget"
+	"github.com/innhopp/central/backend/querytag"
 	"github.com/innhopp/central/backend/rbac"
+	"github.com/innhopp/central/backend/recompute"
 	"github.com/innhopp/central/backend/registrations"
+	"github.com/innhopp/central/backend/syncapi"
 )
 
 func main() {
@@ -38,6 +54,13 @@ func main() {
 		log.Fatalf("failed to parse database config: %v", err)
 	}
 	poolConfig.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+	poolConfig.ConnConfig.Tracer = querybudget.Tracer{}
+	if strings.EqualFold(strings.TrimSpace(os.Getenv("DB_TAG_REQUESTS")), "true") {
+		poolConfig.ConnConfig.Tracer = querytag.Tracer{
+			BaseName: poolConfig.ConnConfig.RuntimeParams["application_name"],
+			Next:     querybudget.Tracer{},
+		}
+	}
 	poolConfig.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
 		_, err := conn.Exec(ctx, "SET TIME ZONE 'UTC'")
 		return err
@@ -65,9 +88,29 @@ func main() {
 	if err := registrations.BackfillStaffRegistrations(backfillCtx, pool); err != nil {
 		log.Printf("staff registration backfill failed: %v", err)
 	}
+	if err := participants.BackfillCanonicalRoles(backfillCtx, pool); err != nil {
+		log.Printf("participant role backfill failed: %v", err)
+	}
+	if err := events.BackfillAirfieldCoordinates(backfillCtx, pool, nil); err != nil {
+		log.Printf("airfield coordinate backfill failed: %v", err)
+	}
 	cancelBackfill()
-	runRegistrationExpirySweep(pool)
-	go startRegistrationExpiryWorker(pool)
+	systemClock := clock.Real{}
+	runRegistrationExpirySweep(pool, systemClock)
+	go startRegistrationExpiryWorker(pool, systemClock)
+	tombstoneRetention := parseDurationEnv("SYNC_TOMBSTONE_RETENTION", syncapi.DefaultTombstoneRetention)
+	adminActionRetention := parseDurationEnv("ADMIN_ACTION_RETENTION", adminaudit.DefaultRetention)
+	if adminActionRetention < adminaudit.MinRetention {
+		log.Printf("ADMIN_ACTION_RETENTION %v is below the minimum; keeping admin actions for %v", adminActionRetention, adminaudit.MinRetention)
+	}
+	workersCtx, stopWorkers := context.WithCancel(ctx)
+	defer stopWorkers()
+	tombstonePruneDone := startPruneWorker(workersCtx, "tombstone", func(ctx context.Context) (int64, error) {
+		return syncapi.PruneTombstones(ctx, pool, tombstoneRetention)
+	})
+	adminActionPruneDone := startPruneWorker(workersCtx, "admin action", func(ctx context.Context) (int64, error) {
+		return adminaudit.Prune(ctx, pool, adminActionRetention)
+	})
 
 	sessionSecret := os.Getenv("SESSION_SECRET")
 	if sessionSecret == "" {
@@ -80,17 +123,61 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to configure sessions: %v", err)
 	}
+	// SESSION_PREVIOUS_SECRETS lists retired secrets, newest first, that keep
+	// verifying existing sessions while SESSION_SECRET is rotated.
+	sessionManager.SetPreviousSecrets(strings.Split(os.Getenv("SESSION_PREVIOUS_SECRETS"), ","))
+	sessionManager.SetDeviceBinding(strings.EqualFold(strings.TrimSpace(os.Getenv("SESSION_DEVICE_BINDING")), "true"))
+	sessionManager.SetDownloadTokenTTL(parseDurationEnv("DOWNLOAD_TOKEN_TTL", auth.DefaultDownloadTokenTTL))
+	sessionManager.SetDownloadRoutes(downloadRoutes)
 
 	authConfig := auth.Config{
-		Issuer:       os.Getenv("OIDC_ISSUER"),
-		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
-		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
-		RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
-		FrontendURL:  os.Getenv("FRONTEND_URL"),
-		DevAllowAll:  strings.EqualFold(os.Getenv("DEV_ALLOW_ALL"), "true"),
+		Issuer:                os.Getenv("OIDC_ISSUER"),
+		ClientID:              os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret:          os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:           os.Getenv("OIDC_REDIRECT_URL"),
+		FrontendURL:           os.Getenv("FRONTEND_URL"),
+		DevAllowAll:           strings.EqualFold(os.Getenv("DEV_ALLOW_ALL"), "true"),
+		RequireApproval:       strings.EqualFold(strings.TrimSpace(os.Getenv("ACCOUNT_APPROVAL_REQUIRED")), "true"),
+		LinkProfilesBySubject: !strings.EqualFold(strings.TrimSpace(os.Getenv("ACCOUNT_LINK_BY_SUBJECT")), "false"),
+	}
+	authConfig.DefaultRole, err = auth.ParseDefaultRolePolicy(os.Getenv("OIDC_DEFAULT_ROLE"), os.Getenv("OIDC_DEFAULT_ROLE_RULES"))
+	if err != nil {
+		log.Fatalf("invalid default role configuration: %v", err)
+	}
+	authConfig.LandingRoutes, err = auth.ParseLandingRoutes(os.Getenv("LANDING_ROUTES"))
+	if err != nil {
+		log.Fatalf("invalid landing route configuration: %v", err)
 	}
+	emailPolicy, err := email.ParsePolicy(os.Getenv("EMAIL_NORMALIZATION"))
+	if err != nil {
+		log.Fatalf("invalid EMAIL_NORMALIZATION: %v", err)
+	}
+	email.SetPolicy(emailPolicy)
+	authConfig.MaxRoles = parsePositiveIntEnv("MAX_ACCOUNT_ROLES", auth.DefaultMaxRoles)
 	logMissingOIDCConfig(authConfig)
+	corsConfig := cors.Config{
+		AllowedOrigins:   cors.ParseList(os.Getenv("CORS_ALLOWED_ORIGINS"), nil),
+		AllowedMethods:   cors.DefaultAllowedMethods,
+		AllowedHeaders:   cors.ParseList(os.Getenv("CORS_ALLOWED_HEADERS"), cors.DefaultAllowedHeaders),
+		ExposedHeaders:   cors.ParseList(os.Getenv("CORS_EXPOSED_HEADERS"), cors.DefaultExposedHeaders),
+		MaxAge:           parseDurationEnv("CORS_MAX_AGE", cors.DefaultMaxAge),
+		AllowCredentials: strings.EqualFold(strings.TrimSpace(os.Getenv("CORS_ALLOW_CREDENTIALS")), "true"),
+	}
+	if err := corsConfig.Validate(); err != nil {
+		log.Fatalf("invalid CORS configuration: %v", err)
+	}
+	if len(corsConfig.AllowedOrigins) > 0 {
+		log.Printf("CORS allowed origins: %v (credentials %t)", corsConfig.EffectiveOrigins(), corsConfig.AllowCredentials)
+	}
+
 	budgetsV1Enabled := !strings.EqualFold(strings.TrimSpace(os.Getenv("BUDGETS_V1")), "false")
+	bodyLogger := debuglog.NewBodyLogger(
+		strings.EqualFold(strings.TrimSpace(os.Getenv("DEBUG_BODY_LOGGING")), "true"),
+		parsePositiveIntEnv("DEBUG_BODY_LOG_MAX_BYTES", debuglog.DefaultMaxBytes),
+	)
+	if bodyLogger.Enabled() {
+		log.Printf("debug body logging enabled")
+	}
 
 	authHandler, err := auth.NewHandler(pool, sessionManager, authConfig)
 	if err != nil {
@@ -141,6 +228,141 @@ func main() {
 		}
 	}
 
+	recomputeHandler := recompute.NewHandler(recomputeTargets(pool, systemClock)...)
+	recomputeHandler.SetClock(systemClock)
+	devBypass := authConfig.DevAllowAll
+	router := newRouter(routerDeps{
+		pool:        pool,
+		sessions:    sessionManager,
+		authHandler: authHandler,
+		roleResolver: func(r *http.Request) []rbac.Role {
+			if devBypass {
+				return []rbac.Role{rbac.RoleAdmin}
+			}
+			claims := auth.FromContext(r.Context())
+			if claims == nil {
+				return nil
+			}
+			roles := make([]rbac.Role, 0, len(claims.Roles))
+			for _, role := range claims.Roles {
+				roles = append(roles, rbac.Role(role))
+			}
+			return roles
+		},
+		frontendURL:   authConfig.FrontendURL,
+		emailSender:   emailSender,
+		budgetsV1:     budgetsV1Enabled,
+		bodyLogger:    bodyLogger,
+		rolesCacheTTL: parseDurationEnv("ROLES_CACHE_TTL", rbac.DefaultRolesCacheTTL),
+		cors:          corsConfig,
+		adminGuard: adminaudit.NewGuard(pool, adminActor,
+			parsePositiveIntEnv("ADMIN_ACTION_RATE_LIMIT", adminaudit.DefaultLimit),
+			parseDurationEnv("ADMIN_ACTION_RATE_WINDOW", adminaudit.DefaultWindow),
+		),
+		queryBudget:        parsePositiveIntEnv("QUERY_BUDGET", querybudget.DefaultLimit),
+		tombstoneRetention: tombstoneRetention,
+		clock:              systemClock,
+		recompute:          recomputeHandler,
+	})
+
+	addr := ":8080"
+	if port := os.Getenv("PORT"); port != "" {
+		addr = ":" + port
+	}
+
+	// On SIGINT/SIGTERM, finish in-flight requests and stop any recompute
+	// runs and the prune workers before stopping the auth cache sweeper and
+	// closing the pool.
+	server := &http.Server{Addr: addr, Handler: router}
+	shutdownCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		<-shutdownCtx.Done()
+		drainCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		if err := server.Shutdown(drainCtx); err != nil {
+			log.Printf("server shutdown: %v", err)
+		}
+	}()
+
+	log.Printf("listening on %s", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("server error: %v", err)
+	}
+	<-drained
+	recomputeCtx, cancelRecompute := context.WithTimeout(context.Background(), 15*time.Second)
+	if err := recomputeHandler.Shutdown(recomputeCtx); err != nil {
+		log.Printf("recompute shutdown: %v", err)
+	}
+	cancelRecompute()
+	stopWorkers()
+	<-tombstonePruneDone
+	<-adminActionPruneDone
+	authHandler.Close()
+	log.Printf("server stopped")
+}
+
+// downloadRoutes are the binary endpoints that accept a short-lived
+// ?token= from POST /api/downloads/token instead of a session.
+var downloadRoutes = []string{
+	"/api/participants/profiles/{profileID}/photo",
+	"/api/logistics/gear-assets/{assetID}/qr.png",
+}
+
+// adminActor identifies the session behind an audited admin action.
+func adminActor(r *http.Request) adminaudit.Actor {
+	claims := auth.FromContext(r.Context())
+	if claims == nil {
+		return adminaudit.Actor{}
+	}
+	actor := adminaudit.Actor{AccountID: claims.AccountID, Email: claims.Email}
+	if claims.Impersonator != nil {
+		actor.ImpersonatorID = &claims.Impersonator.AccountID
+	}
+	return actor
+}
+
+// routerDeps collects what newRouter needs to assemble the API.
+type routerDeps struct {
+	pool         *pgxpool.Pool
+	sessions     *auth.SessionManager
+	authHandler  *auth.Handler
+	roleResolver rbac.RoleResolver
+	frontendURL  string
+	emailSender  comms.EmailSender
+	budgetsV1    bool
+	bodyLogger   *debuglog.BodyLogger
+	// rolesCacheTTL bounds how long the roles catalog is served from memory.
+	rolesCacheTTL time.Duration
+	cors          cors.Config
+	// adminGuard audits and rate-limits sensitive admin routes; nil disables
+	// both.
+	adminGuard *adminaudit.Guard
+	// queryBudget caps the database queries one request, or one whole batch,
+	// may run; 0 uses querybudget.DefaultLimit.
+	queryBudget int
+	// tombstoneRetention is how long deletes stay visible to /api/sync.
+	tombstoneRetention time.Duration
+	// recompute serves /api/admin/recompute; nil builds one over pool.
+	recompute *recompute.Handler
+	// clock is the time source the handlers read; nil uses the wall clock.
+	clock clock.Clock
+}
+
+// newRouter builds the HTTP router with middleware and every API mount.
+func newRouter(deps routerDeps) chi.Router {
+	pool := deps.pool
+	clk := deps.clock
+	if clk == nil {
+		clk = clock.Real{}
+	}
+	bodyLogger := deps.bodyLogger
+	if bodyLogger == nil {
+		bodyLogger = debuglog.NewBodyLogger(false, 0)
+	}
+
 	router := chi.NewRouter()
 	router.Use(
 		middleware.RequestID,
@@ -148,8 +370,11 @@ func main() {
 		middleware.Logger,
 		middleware.Recoverer,
 		middleware.Timeout(60*time.Second),
+		cors.Middleware(deps.cors),
+		querybudget.Middleware(deps.queryBudget),
+		bodyLogger.Middleware,
 	)
-	router.Use(sessionManager.Middleware)
+	router.Use(deps.sessions.Middleware, auth.RequireApproval)
 
 	router.Get("/api/health", func(w http.ResponseWriter, _ *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -157,48 +382,110 @@ func main() {
 		_, _ = w.Write([]byte(`{"status":"ok"}`))
 	})
 
-	devBypass := authConfig.DevAllowAll
+	router.Get("/api/enums", enums.NewHandler(pool).ServeHTTP)
 
-	enforcer := rbac.NewEnforcer(func(r *http.Request) []rbac.Role {
-		if devBypass {
-			return []rbac.Role{rbac.RoleAdmin}
-		}
-		claims := auth.FromContext(r.Context())
-		if claims == nil {
-			return nil
-		}
-		roles := make([]rbac.Role, 0, len(claims.Roles))
-		for _, role := range claims.Roles {
-			roles = append(roles, rbac.Role(role))
-		}
-		return roles
-	})
+	enforcer := rbac.NewEnforcer(deps.roleResolver)
+	enforcer.SetAdminGuard(deps.adminGuard)
 
-	router.Mount("/api/auth", authHandler.Routes())
-	if budgetsV1Enabled {
+	router.With(enforcer.Authorize(rbac.PermissionViewSession)).Post(batch.Path, batch.NewHandler(router).ServeHTTP)
+
+	router.Mount("/api/auth", deps.authHandler.Routes(enforcer))
+	router.Mount("/api/downloads", deps.authHandler.DownloadRoutes(enforcer))
+	if deps.budgetsV1 {
 		router.Mount("/api/events/{eventID}/budget", budgets.NewHandler(pool).EventBudgetRoutes(enforcer))
 	}
-	router.Mount("/api/events", events.NewHandler(pool).Routes(enforcer))
-	router.Mount("/api/participants", participants.NewHandler(pool).Routes(enforcer))
+	eventsHandler := events.NewHandler(pool)
+	eventsHandler.SetClock(clk)
+	router.Mount("/api/events", eventsHandler.Routes(enforcer))
+	participantsHandler := participants.NewHandler(pool)
+	participantsHandler.SetClock(clk)
+	router.Mount("/api/participants", participantsHandler.Routes(enforcer))
 	router.Mount("/api/registrations", registrations.NewHandler(pool).Routes(enforcer))
-	router.Mount("/api/comms", comms.NewHandler(pool, authConfig.FrontendURL, emailSender).Routes(enforcer))
-	router.Mount("/api/rbac", rbac.NewHandler(pool).Routes(enforcer))
+	router.Mount("/api/comms", comms.NewHandler(pool, deps.frontendURL, deps.emailSender).Routes(enforcer))
+	rbacHandler := rbac.NewHandler(pool)
+	rbacHandler.SetRolesCacheTTL(deps.rolesCacheTTL)
+	rbacHandler.SetClock(clk)
+	router.Mount("/api/rbac", rbacHandler.Routes(enforcer))
 	router.Mount("/api/logistics", logistics.NewHandler(pool).Routes(enforcer))
 	router.Mount("/api/innhopps", innhopps.NewHandler(pool).Routes(enforcer))
-	if budgetsV1Enabled {
+	router.Mount("/api/debug", debuglog.NewHandler(bodyLogger).Routes(enforcer))
+	syncHandler := syncapi.NewHandler(pool)
+	syncHandler.SetTombstoneRetention(deps.tombstoneRetention)
+	router.Mount("/api/sync", syncHandler.Routes(enforcer))
+	recomputeHandler := deps.recompute
+	if recomputeHandler == nil {
+		recomputeHandler = recompute.NewHandler(recomputeTargets(pool, clk)...)
+		recomputeHandler.SetClock(clk)
+	}
+	router.Mount("/api/admin", recomputeHandler.Routes(enforcer))
+	if deps.budgetsV1 {
 		router.Mount("/api/budgets", budgets.NewHandler(pool).Routes(enforcer))
 		router.Mount("/api/accounting", accounting.NewHandler(pool).Routes(enforcer))
 	}
+	return router
+}
 
-	addr := ":8080"
-	if port := os.Getenv("PORT"); port != "" {
-		addr = ":" + port
+// recomputeTargets are the stored derivations an admin can rebuild through
+// POST /api/admin/recompute.
+func recomputeTargets(pool *pgxpool.Pool, clk clock.Clock) []recompute.Target {
+	eventsHandler := events.NewHandler(pool)
+	eventsHandler.SetClock(clk)
+	return []recompute.Target{
+		{
+			Name:        "event_status",
+			Description: "Move events to draft, live or past from their dates",
+			Run: func(ctx context.Context, progress func(processed, updated int)) error {
+				return eventsHandler.RecomputeEventStatuses(ctx, progress)
+			},
+		},
+		{
+			Name:        "participant_roles",
+			Description: "Rewrite participant and account roles stored under older spellings",
+			Run: func(ctx context.Context, _ func(processed, updated int)) error {
+				return participants.BackfillCanonicalRoles(ctx, pool)
+			},
+		},
+		{
+			Name:        "event_rosters",
+			Description: "Sync event rosters with active registrations",
+			Run: func(ctx context.Context, _ func(processed, updated int)) error {
+				return registrations.BackfillEventRosterSync(ctx, pool)
+			},
+		},
+		{
+			Name:        "airfield_coordinates",
+			Description: "Rewrite airfield latitude and longitude in canonical decimal degrees",
+			Run: func(ctx context.Context, progress func(processed, updated int)) error {
+				return events.BackfillAirfieldCoordinates(ctx, pool, progress)
+			},
+		},
 	}
+}
 
-	log.Printf("listening on %s", addr)
-	if err := http.ListenAndServe(addr, router); err != nil {
-		log.Fatalf("server error: %v", err)
+func parsePositiveIntEnv(name string, fallback int) int {
+	raw := strings.TrimSpace(os.Getenv(name))
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value <= 0 {
+		log.Printf("ignoring invalid %s=%q; using %d", name, raw, fallback)
+		return fallback
 	}
+	return value
+}
+
+func parseDurationEnv(name string, fallback time.Duration) time.Duration {
+	raw := strings.TrimSpace(os.Getenv(name))
+	if raw == "" {
+		return fallback
+	}
+	value, err := time.ParseDuration(raw)
+	if err != nil || value < 0 {
+		log.Printf("ignoring invalid %s=%q; using %s", name, raw, fallback)
+		return fallback
+	}
+	return value
 }
 
 func logMissingOIDCConfig(cfg auth.Config) {
@@ -227,10 +514,10 @@ func logMissingOIDCConfig(cfg auth.Config) {
 	}
 }
 
-func runRegistrationExpirySweep(pool *pgxpool.Pool) {
+func runRegistrationExpirySweep(pool *pgxpool.Pool, clk clock.Clock) {
 	sweepCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
-	rows, err := registrations.ExpireOverdueRegistrations(sweepCtx, pool)
+	rows, err := registrations.ExpireOverdueRegistrations(sweepCtx, pool, clk.Now())
 	if err != nil {
 		log.Printf("registration expiry sweep failed: %v", err)
 		return
@@ -240,19 +527,46 @@ func runRegistrationExpirySweep(pool *pgxpool.Pool) {
 	}
 }
 
-func startRegistrationExpiryWorker(pool *pgxpool.Pool) {
+func startRegistrationExpiryWorker(pool *pgxpool.Pool, clk clock.Clock) {
 	for {
-		now := time.Now().UTC()
+		now := clk.Now().UTC()
 		next := time.Date(now.Year(), now.Month(), now.Day(), 0, 1, 0, 0, time.UTC)
 		if !now.Before(next) {
 			next = next.Add(24 * time.Hour)
 		}
-		timer := time.NewTimer(time.Until(next))
+		timer := time.NewTimer(next.Sub(now))
 		<-timer.C
-		runRegistrationExpirySweep(pool)
+		runRegistrationExpirySweep(pool, clk)
 	}
 }
 
+// startPruneWorker runs prune once at startup and then daily until ctx is
+// done, logging under name. The returned channel is closed once it stops.
+func startPruneWorker(ctx context.Context, name string, prune func(context.Context) (int64, error)) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(24 * time.Hour)
+		defer ticker.Stop()
+		for {
+			pruneCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			rows, err := prune(pruneCtx)
+			cancel()
+			if err != nil && ctx.Err() == nil {
+				log.Printf("%s prune failed: %v", name, err)
+			} else if rows > 0 {
+				log.Printf("%s prune removed %d rows", name, rows)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return done
+}
+
 func ensureSchema(ctx context.Context, pool *pgxpool.Pool) error {
 	stmts := []string{
 		`CREATE TABLE IF NOT EXISTS seasons (
@@ -284,6 +598,10 @@ func ensureSchema(ctx context.Context, pool *pgxpool.Pool) error {
 		`ALTER TABLE events ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'EUR'`,
 		`ALTER TABLE events ADD COLUMN IF NOT EXISTS minimum_deposit_count INTEGER NOT NULL DEFAULT 0`,
 		`ALTER TABLE events ADD COLUMN IF NOT EXISTS commercial_status TEXT NOT NULL DEFAULT 'draft'`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS all_day BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}'`,
+		`CREATE INDEX IF NOT EXISTS events_tags_idx ON events USING GIN (tags)`,
+		`CREATE INDEX IF NOT EXISTS events_season_starts_at_idx ON events (season_id, starts_at)`,
 		`DO $$
 		BEGIN
 			IF EXISTS (
@@ -312,6 +630,8 @@ func ensureSchema(ctx context.Context, pool *pgxpool.Pool) error {
 		`ALTER TABLE events DROP COLUMN IF EXISTS balance_amount`,
 		`ALTER TABLE events DROP COLUMN IF EXISTS deposit_deadline`,
 		`ALTER TABLE events DROP COLUMN IF EXISTS registration_close_at`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS briefing TEXT`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS notes TEXT`,
 		`CREATE UNIQUE INDEX IF NOT EXISTS events_public_registration_slug_idx
             ON events ((lower(public_registration_slug)))
             WHERE public_registration_slug IS NOT NULL AND btrim(public_registration_slug) <> ''`,
@@ -519,6 +839,7 @@ func ensureSchema(ctx context.Context, pool *pgxpool.Pool) error {
 		`ALTER TABLE event_innhopps ADD COLUMN IF NOT EXISTS image_files JSONB DEFAULT '[]'::jsonb`,
 		`ALTER TABLE event_innhopps ADD COLUMN IF NOT EXISTS land_owners JSONB DEFAULT '[]'::jsonb`,
 		`ALTER TABLE event_innhopps ADD COLUMN IF NOT EXISTS land_owner_permission BOOLEAN`,
+		`ALTER TABLE event_innhopps ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1`,
 		`CREATE TABLE IF NOT EXISTS manifest_participants (
     manifest_id INTEGER NOT NULL REFERENCES manifests(id) ON DELETE CASCADE,
     participant_id INTEGER NOT NULL REFERENCES participant_profiles(id) ON DELETE CASCADE,
@@ -532,12 +853,21 @@ func ensureSchema(ctx context.Context, pool *pgxpool.Pool) error {
             role TEXT NOT NULL,
             assigned_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
         )`,
+		`CREATE INDEX IF NOT EXISTS crew_assignments_assigned_at_idx ON crew_assignments (assigned_at DESC, id DESC)`,
 		`CREATE TABLE IF NOT EXISTS event_airfields (
             event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
             airfield_id INTEGER NOT NULL REFERENCES airfields(id) ON DELETE CASCADE,
             added_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
             PRIMARY KEY (event_id, airfield_id)
         )`,
+		`CREATE TABLE IF NOT EXISTS event_seasons (
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            season_id INTEGER NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+            added_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+            PRIMARY KEY (event_id, season_id)
+        )`,
+		`CREATE INDEX IF NOT EXISTS event_seasons_season_idx ON event_seasons (season_id)`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS base_airfield_id INTEGER REFERENCES airfields(id) ON DELETE SET NULL`,
 		`CREATE TABLE IF NOT EXISTS event_accommodation (
             id SERIAL PRIMARY KEY,
             event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
@@ -561,6 +891,23 @@ func ensureSchema(ctx context.Context, pool *pgxpool.Pool) error {
             inspected_at TIMESTAMPTZ,
             created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
         )`,
+		`CREATE TABLE IF NOT EXISTS participant_gear (
+            gear_asset_id INTEGER PRIMARY KEY REFERENCES gear_assets(id) ON DELETE CASCADE,
+            participant_id INTEGER NOT NULL REFERENCES participant_profiles(id) ON DELETE CASCADE,
+            is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+            assigned_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE INDEX IF NOT EXISTS participant_gear_participant_idx ON participant_gear (participant_id)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS participant_gear_primary_idx ON participant_gear (participant_id) WHERE is_primary`,
+		`CREATE TABLE IF NOT EXISTS event_gear_checkouts (
+            id SERIAL PRIMARY KEY,
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            gear_asset_id INTEGER NOT NULL REFERENCES gear_assets(id) ON DELETE CASCADE,
+            checked_out_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+            returned_at TIMESTAMPTZ
+        )`,
+		`CREATE INDEX IF NOT EXISTS event_gear_checkouts_event_idx ON event_gear_checkouts (event_id)`,
+		`CREATE INDEX IF NOT EXISTS event_gear_checkouts_open_idx ON event_gear_checkouts (gear_asset_id) WHERE returned_at IS NULL`,
 		`CREATE TABLE IF NOT EXISTS logistics_transports (
             id SERIAL PRIMARY KEY,
             pickup_location TEXT NOT NULL,
@@ -941,10 +1288,34 @@ func ensureSchema(ctx context.Context, pool *pgxpool.Pool) error {
             full_name TEXT,
             created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
         )`,
+		`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMPTZ`,
+		`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMPTZ`,
+		`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS approved BOOLEAN NOT NULL DEFAULT TRUE`,
+		`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS rejected_at TIMESTAMPTZ`,
+		`CREATE TABLE IF NOT EXISTS admin_actions (
+            id BIGSERIAL PRIMARY KEY,
+            actor_account_id INTEGER REFERENCES accounts(id) ON DELETE SET NULL,
+            actor_email TEXT NOT NULL DEFAULT '',
+            impersonator_account_id INTEGER REFERENCES accounts(id) ON DELETE SET NULL,
+            action TEXT NOT NULL,
+            method TEXT NOT NULL,
+            path TEXT NOT NULL,
+            status INTEGER NOT NULL,
+            remote_addr TEXT,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE INDEX IF NOT EXISTS admin_actions_created_at_idx ON admin_actions (created_at DESC)`,
 		`CREATE TABLE IF NOT EXISTS roles (
             name TEXT PRIMARY KEY,
             description TEXT,
             created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE TABLE IF NOT EXISTS reference_values (
+            kind TEXT NOT NULL,
+            value TEXT NOT NULL,
+            position INTEGER NOT NULL DEFAULT 0,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+            PRIMARY KEY (kind, value)
         )`,
 		`CREATE TABLE IF NOT EXISTS account_roles (
             account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
@@ -953,6 +1324,41 @@ func ensureSchema(ctx context.Context, pool *pgxpool.Pool) error {
             UNIQUE (account_id, role_name)
         )`,
 		`ALTER TABLE participant_profiles ADD COLUMN IF NOT EXISTS account_id INTEGER UNIQUE REFERENCES accounts(id) ON DELETE SET NULL`,
+		`ALTER TABLE participant_profiles ADD COLUMN IF NOT EXISTS account_subject TEXT`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS participant_profiles_account_subject_key ON participant_profiles (account_subject) WHERE account_subject IS NOT NULL`,
+		// Existing links get their account's subject so they survive email
+		// changes at the identity provider.
+		`UPDATE participant_profiles p SET account_subject = a.subject
+         FROM accounts a
+         WHERE p.account_id = a.id AND p.account_subject IS NULL`,
+		`CREATE TABLE IF NOT EXISTS participant_notes (
+            id SERIAL PRIMARY KEY,
+            participant_id INTEGER NOT NULL REFERENCES participant_profiles(id) ON DELETE CASCADE,
+            body TEXT NOT NULL,
+            author_account_id INTEGER REFERENCES accounts(id) ON DELETE SET NULL,
+            author_name TEXT,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE INDEX IF NOT EXISTS participant_notes_participant_idx ON participant_notes (participant_id, created_at DESC)`,
+		`CREATE TABLE IF NOT EXISTS participant_photos (
+            participant_id INTEGER PRIMARY KEY REFERENCES participant_profiles(id) ON DELETE CASCADE,
+            mime_type TEXT NOT NULL,
+            image BYTEA NOT NULL,
+            thumbnail BYTEA NOT NULL,
+            width INTEGER NOT NULL,
+            height INTEGER NOT NULL,
+            updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE TABLE IF NOT EXISTS event_innhopp_revisions (
+            id SERIAL PRIMARY KEY,
+            innhopp_id INTEGER NOT NULL REFERENCES event_innhopps(id) ON DELETE CASCADE,
+            version INTEGER NOT NULL,
+            snapshot JSONB NOT NULL,
+            actor_account_id INTEGER REFERENCES accounts(id) ON DELETE SET NULL,
+            actor_name TEXT,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE INDEX IF NOT EXISTS event_innhopp_revisions_innhopp_idx ON event_innhopp_revisions (innhopp_id, id)`,
 		`CREATE TABLE IF NOT EXISTS event_registrations (
             id SERIAL PRIMARY KEY,
             event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
@@ -1239,6 +1645,67 @@ func ensureSchema(ctx context.Context, pool *pgxpool.Pool) error {
 		`ALTER TABLE email_deliveries ADD COLUMN IF NOT EXISTS sent_at TIMESTAMPTZ`,
 		`ALTER TABLE email_deliveries ADD COLUMN IF NOT EXISTS failed_at TIMESTAMPTZ`,
 		`ALTER TABLE email_deliveries ADD COLUMN IF NOT EXISTS error_message TEXT`,
+		`ALTER TABLE email_deliveries ADD COLUMN IF NOT EXISTS request_id TEXT`,
+		// Archived events keep their own row and every related row as JSON
+		// snapshots, so the archive needs no changes when those tables do.
+		`CREATE TABLE IF NOT EXISTS events_archive (
+            id INTEGER PRIMARY KEY,
+            season_id INTEGER NOT NULL,
+            name TEXT NOT NULL,
+            starts_at TIMESTAMPTZ NOT NULL,
+            event JSONB NOT NULL,
+            relations JSONB NOT NULL DEFAULT '{}'::jsonb,
+            archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		// Rows served by /api/sync carry an updated_at kept current by a
+		// trigger, so no write path can forget to bump it. Inserts are
+		// stamped too so restored rows reach clients again.
+		`CREATE OR REPLACE FUNCTION touch_updated_at() RETURNS trigger AS $$
+		BEGIN
+			NEW.updated_at = NOW();
+			RETURN NEW;
+		END
+		$$ LANGUAGE plpgsql`,
+		// Deletes of synced rows, including cascaded ones, leave a tombstone
+		// for /api/sync; a row inserted again with the same id clears it.
+		`CREATE TABLE IF NOT EXISTS tombstones (
+            id BIGSERIAL PRIMARY KEY,
+            resource TEXT NOT NULL,
+            resource_id INTEGER NOT NULL,
+            deleted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE INDEX IF NOT EXISTS tombstones_resource_deleted_at_idx ON tombstones (resource, deleted_at, id)`,
+		`CREATE OR REPLACE FUNCTION record_tombstone() RETURNS trigger AS $$
+		BEGIN
+			INSERT INTO tombstones (resource, resource_id) VALUES (TG_ARGV[0], OLD.id);
+			RETURN OLD;
+		END
+		$$ LANGUAGE plpgsql`,
+		`CREATE OR REPLACE FUNCTION clear_tombstone() RETURNS trigger AS $$
+		BEGIN
+			DELETE FROM tombstones WHERE resource = TG_ARGV[0] AND resource_id = NEW.id;
+			RETURN NEW;
+		END
+		$$ LANGUAGE plpgsql`,
+	}
+	for _, synced := range []struct{ table, resource string }{
+		{"events", "events"},
+		{"participant_profiles", "participants"},
+		{"manifests", "manifests"},
+	} {
+		table := synced.table
+		stmts = append(stmts,
+			`ALTER TABLE `+table+` ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()`,
+			`CREATE INDEX IF NOT EXISTS `+table+`_updated_at_idx ON `+table+` (updated_at, id)`,
+			`DROP TRIGGER IF EXISTS `+table+`_touch_updated_at ON `+table,
+			`CREATE TRIGGER `+table+`_touch_updated_at BEFORE INSERT OR UPDATE ON `+table+` FOR EACH ROW EXECUTE FUNCTION touch_updated_at()`,
+			`DROP TRIGGER IF EXISTS `+table+`_record_tombstone ON `+table,
+			`CREATE TRIGGER `+table+`_record_tombstone AFTER DELETE ON `+table+` FOR EACH ROW EXECUTE FUNCTION record_tombstone('`+synced.resource+`')`,
+			`DROP TRIGGER IF EXISTS `+table+`_clear_tombstone ON `+table,
+			`CREATE TRIGGER `+table+`_clear_tombstone AFTER INSERT ON `+table+` FOR EACH ROW EXECUTE FUNCTION clear_tombstone('`+synced.resource+`')`,
+			`ALTER TABLE `+table+` ADD COLUMN IF NOT EXISTS client_id UUID`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS `+table+`_client_id_key ON `+table+` (client_id)`,
+		)
 	}
 
 	for _, stmt := range stmts {
@@ -1250,38 +1717,182 @@ func ensureSchema(ctx context.Context, pool *pgxpool.Pool) error {
 	if err := seedRoles(ctx, pool); err != nil {
 		return err
 	}
+	if err := enums.Seed(ctx, pool); err != nil {
+		return err
+	}
+
+	if err := ensureSearchIndexes(ctx, pool); err != nil {
+		return err
+	}
+	if err := ensureAirfieldNameIndex(ctx, pool); err != nil {
+		return err
+	}
+	if err := ensureManifestLoadNumberIndex(ctx, pool); err != nil {
+		return err
+	}
 
 	return nil
 }
 
-func seedRoles(ctx context.Context, pool *pgxpool.Pool) error {
-	type roleSeed struct {
-		name        string
-		description string
+// ensureSearchIndexes adds pg_trgm GIN indexes so the ILIKE searches on
+// participants and events avoid full table scans. Managed databases may not
+// allow the extension; search then still works, just without an index.
+func ensureSearchIndexes(ctx context.Context, pool *pgxpool.Pool) error {
+	if _, err := pool.Exec(ctx, `CREATE EXTENSION IF NOT EXISTS pg_trgm`); err != nil {
+		log.Printf("pg_trgm unavailable, search will scan tables: %v", err)
+		return nil
+	}
+	stmts := []string{
+		`CREATE INDEX IF NOT EXISTS participant_profiles_full_name_trgm_idx ON participant_profiles USING GIN (full_name gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS participant_profiles_email_trgm_idx ON participant_profiles USING GIN (email gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS events_name_trgm_idx ON events USING GIN (name gin_trgm_ops)`,
+		`CREATE INDEX IF NOT EXISTS events_location_trgm_idx ON events USING GIN (location gin_trgm_ops)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := pool.Exec(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureAirfieldNameIndex makes airfield names unique ignoring case. The
+// index cannot be built while duplicates exist, so those are logged for an
+// admin to merge and the index follows on the first start after.
+func ensureAirfieldNameIndex(ctx context.Context, pool *pgxpool.Pool) error {
+	rows, err := pool.Query(ctx, `
+		SELECT lower(name), array_agg(id ORDER BY id)
+		FROM airfields
+		GROUP BY lower(name)
+		HAVING COUNT(*) > 1
+		ORDER BY lower(name)`)
+	if err != nil {
+		return err
+	}
+	duplicates := 0
+	for rows.Next() {
+		var name string
+		var ids []int64
+		if err := rows.Scan(&name, &ids); err != nil {
+			rows.Close()
+			return err
+		}
+		duplicates++
+		log.Printf("airfields %v share the name %q; merge them with POST /api/events/airfields/{id}/merge", ids, name)
 	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if duplicates > 0 {
+		log.Printf("airfield names are not unique yet: %d duplicate names", duplicates)
+		return nil
+	}
+	_, err = pool.Exec(ctx, `CREATE UNIQUE INDEX IF NOT EXISTS airfields_name_key ON airfields (lower(name))`)
+	return err
+}
 
-	seeds := []roleSeed{
-		{name: string(rbac.RoleAdmin), description: "Full platform administration"},
-		{name: string(rbac.RoleStaff), description: "Dropzone staff operations"},
-		{name: string(rbac.RoleJumpMaster), description: "Jump master manifest authority"},
-		{name: string(rbac.RoleJumpLeader), description: "Jump leader manifest visibility"},
-		{name: string(rbac.RoleGroundCrew), description: "Ground crew logistics"},
-		{name: string(rbac.RoleDriver), description: "Driver logistics coordination"},
-		{name: string(rbac.RolePacker), description: "Rigging and packing responsibilities"},
-		{name: string(rbac.RoleParticipant), description: "Participant self-service access"},
+// ensureManifestLoadNumberIndex makes load numbers unique within an event.
+// As with airfield names, loads that already share a number are logged for
+// an admin to renumber and the index follows once none are left.
+func ensureManifestLoadNumberIndex(ctx context.Context, pool *pgxpool.Pool) error {
+	rows, err := pool.Query(ctx, `
+		SELECT event_id, load_number, array_agg(id ORDER BY id)
+		FROM manifests
+		GROUP BY event_id, load_number
+		HAVING COUNT(*) > 1
+		ORDER BY event_id, load_number`)
+	if err != nil {
+		return err
 	}
+	duplicates := 0
+	for rows.Next() {
+		var eventID int64
+		var loadNumber int
+		var ids []int64
+		if err := rows.Scan(&eventID, &loadNumber, &ids); err != nil {
+			rows.Close()
+			return err
+		}
+		duplicates++
+		log.Printf("manifests %v of event %d share load number %d; renumber them", ids, eventID, loadNumber)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if duplicates > 0 {
+		log.Printf("manifest load numbers are not unique yet: %d duplicate load numbers", duplicates)
+		return nil
+	}
+	if _, err := pool.Exec(ctx, `CREATE UNIQUE INDEX IF NOT EXISTS manifests_event_load_number_key ON manifests (event_id, load_number)`); err != nil {
+		return err
+	}
+	// The unique index also serves the load-ordered manifest listings, so the
+	// plain index earlier releases created for them is no longer needed.
+	_, err = pool.Exec(ctx, `DROP INDEX IF EXISTS manifests_event_load_idx`)
+	return err
+}
 
+// seedRoles inserts every rbac role into the roles table, then moves account
+// roles stored under a legacy spelling such as "Jump Master" onto the
+// canonical name and drops the legacy row.
+func seedRoles(ctx context.Context, pool *pgxpool.Pool) error {
 	batch := &pgx.Batch{}
-	for _, seed := range seeds {
-		batch.Queue(`INSERT INTO roles (name, description) VALUES ($1, $2) ON CONFLICT (name) DO NOTHING`, seed.name, seed.description)
+	for _, role := range rbac.Roles {
+		batch.Queue(`INSERT INTO roles (name, description) VALUES ($1, $2) ON CONFLICT (name) DO NOTHING`, string(role), role.Description())
 	}
-
 	br := pool.SendBatch(ctx, batch)
-	defer br.Close()
-	for range seeds {
+	for range rbac.Roles {
 		if _, err := br.Exec(); err != nil {
+			br.Close()
+			return err
+		}
+	}
+	if err := br.Close(); err != nil {
+		return err
+	}
+
+	rows, err := pool.Query(ctx, `SELECT name FROM roles`)
+	if err != nil {
+		return err
+	}
+	legacy := map[string]rbac.Role{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
 			return err
 		}
+		if role, ok := rbac.ParseRole(name); ok && string(role) != name {
+			legacy[name] = role
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for name, role := range legacy {
+		tx, err := pool.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO account_roles (account_id, role_name, created_at)
+			SELECT account_id, $2, created_at FROM account_roles WHERE role_name = $1
+			ON CONFLICT (account_id, role_name) DO NOTHING`, name, string(role))
+		if err == nil {
+			_, err = tx.Exec(ctx, `DELETE FROM roles WHERE name = $1`, name)
+		}
+		if err == nil {
+			err = tx.Commit(ctx)
+		}
+		if err != nil {
+			tx.Rollback(ctx)
+			return fmt.Errorf("migrate role %q to %q: %w", name, role, err)
+		}
+		log.Printf("migrated legacy role %q to %q", name, role)
 	}
 	return nil
 }
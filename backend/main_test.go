@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/innhopp/central/backend/auth"
+	"github.com/innhopp/central/backend/rbac"
+)
+
+// publicRoutes lists routes that are intentionally reachable without an RBAC
+// guard. Anything not listed here must reject anonymous and unprivileged
+// callers.
+var publicRoutes = map[string]struct{}{
+	"GET /api/health":                                       {},
+	"GET /api/enums":                                        {},
+	"GET /api/auth/login":                                   {},
+	"GET /api/auth/callback":                                {},
+	"GET /api/auth/session":                                 {},
+	"POST /api/auth/impersonate":                            {},
+	"POST /api/auth/impersonate-new-user":                   {},
+	"POST /api/auth/stop-impersonation":                     {},
+	"POST /api/auth/logout":                                 {},
+	"GET /api/participants/profiles/me":                     {},
+	"PUT /api/participants/profiles/me":                     {},
+	"GET /api/participants/profiles/me/obligations":         {},
+	"GET /api/registrations/public/events/{slug}":           {},
+	"POST /api/registrations/public/events/{slug}/register": {},
+	"POST /api/registrations/public/events/{slug}/claim":    {},
+	"GET /api/registrations/me":                             {},
+}
+
+func newRBACTestRouter(t *testing.T, roles []rbac.Role) chi.Router {
+	t.Helper()
+	sessions, err := auth.NewSessionManager("test-secret", false)
+	if err != nil {
+		t.Fatalf("NewSessionManager() returned error: %v", err)
+	}
+	authHandler, err := auth.NewHandler(nil, sessions, auth.Config{})
+	if err != nil {
+		t.Fatalf("auth.NewHandler() returned error: %v", err)
+	}
+	t.Cleanup(authHandler.Close)
+	return newRouter(routerDeps{
+		sessions:     sessions,
+		authHandler:  authHandler,
+		roleResolver: func(*http.Request) []rbac.Role { return roles },
+		budgetsV1:    true,
+	})
+}
+
+func TestEveryRouteIsGuardedByRBAC(t *testing.T) {
+	cases := []struct {
+		name  string
+		roles []rbac.Role
+		want  int
+	}{
+		{name: "anonymous", roles: nil, want: http.StatusUnauthorized},
+		{name: "role without permissions", roles: []rbac.Role{"no_permissions"}, want: http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := newRBACTestRouter(t, tc.roles)
+			seen := 0
+			err := chi.Walk(router, func(method, route string, handler http.Handler, middlewares ...chi.Middleware) error {
+				key := method + " " + route
+				if _, ok := publicRoutes[key]; ok {
+					return nil
+				}
+				seen++
+
+				guarded := handler
+				for i := len(middlewares) - 1; i >= 0; i-- {
+					guarded = middlewares[i](guarded)
+				}
+				rec := httptest.NewRecorder()
+				guarded.ServeHTTP(rec, httptest.NewRequest(method, route, nil))
+				if rec.Code != tc.want {
+					t.Errorf("%s returned %d, want %d; add an enforcer guard or list it in publicRoutes", key, rec.Code, tc.want)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("chi.Walk() returned error: %v", err)
+			}
+			if seen == 0 {
+				t.Fatal("chi.Walk() visited no guarded routes")
+			}
+		})
+	}
+}
+
+func TestPruneWorkerRunsAtStartupAndStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := make(chan struct{}, 1)
+	done := startPruneWorker(ctx, "test", func(context.Context) (int64, error) {
+		ran <- struct{}{}
+		return 0, nil
+	})
+
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("prune did not run at startup")
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("worker did not stop after its context was cancelled")
+	}
+}
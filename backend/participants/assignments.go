@@ -0,0 +1,147 @@
+package participants
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/innhopp/central/backend/httpx"
+)
+
+// AffectedEvent is an event whose loads lost a crew member.
+type AffectedEvent struct {
+	ID          int64   `json:"id"`
+	Name        string  `json:"name"`
+	ManifestIDs []int64 `json:"manifest_ids"`
+}
+
+// futureAssignmentsResult reports what removeFutureAssignments changed.
+// Removed counts every assignment taken from the participant; Replaced is the
+// subset handed to the replacement.
+type futureAssignmentsResult struct {
+	Removed  int             `json:"removed"`
+	Replaced int             `json:"replaced"`
+	Events   []AffectedEvent `json:"events"`
+}
+
+// removeFutureAssignments pulls a participant from the crew of every load on
+// an event that has not ended yet by the handler's clock. An event under way
+// counts as not ended, and all of its loads are included: manifests carry no
+// time, so loads already flown cannot be told apart. With ?replacement_id=
+// the replacement takes over each assignment in the same role, except on
+// loads they already crew.
+func (h *Handler) removeFutureAssignments(w http.ResponseWriter, r *http.Request) {
+	profileID, ok := parseProfileIDParam(w, r)
+	if !ok {
+		return
+	}
+	var replacementID int64
+	if raw := strings.TrimSpace(r.URL.Query().Get("replacement_id")); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			httpx.Error(w, http.StatusBadRequest, "invalid replacement_id")
+			return
+		}
+		if id == profileID {
+			httpx.Error(w, http.StatusBadRequest, "replacement_id must be a different participant")
+			return
+		}
+		replacementID = id
+	}
+
+	if !h.ensureProfileExists(w, r, profileID) {
+		return
+	}
+	if replacementID > 0 {
+		var exists bool
+		if err := h.db.QueryRow(r.Context(), `SELECT EXISTS (SELECT 1 FROM participant_profiles WHERE id = $1)`, replacementID).Scan(&exists); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to load participant")
+			return
+		}
+		if !exists {
+			httpx.Error(w, http.StatusNotFound, "replacement participant not found")
+			return
+		}
+	}
+
+	ctx := r.Context()
+	now := h.clock.Now()
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to remove assignments")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT ca.id, ca.manifest_id, e.id, e.name
+		FROM crew_assignments ca
+		JOIN manifests m ON m.id = ca.manifest_id
+		JOIN events e ON e.id = m.event_id
+		WHERE ca.participant_id = $1
+		  AND COALESCE(e.ends_at, e.starts_at) > $2
+		ORDER BY e.starts_at, e.id, m.load_number, ca.id
+		FOR UPDATE OF ca`, profileID, now)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to remove assignments")
+		return
+	}
+	result := futureAssignmentsResult{Events: []AffectedEvent{}}
+	var assignmentIDs []int64
+	eventIndex := map[int64]int{}
+	for rows.Next() {
+		var assignmentID, manifestID, eventID int64
+		var eventName string
+		if err := rows.Scan(&assignmentID, &manifestID, &eventID, &eventName); err != nil {
+			rows.Close()
+			httpx.Error(w, http.StatusInternalServerError, "failed to remove assignments")
+			return
+		}
+		assignmentIDs = append(assignmentIDs, assignmentID)
+		i, seen := eventIndex[eventID]
+		if !seen {
+			i = len(result.Events)
+			eventIndex[eventID] = i
+			result.Events = append(result.Events, AffectedEvent{ID: eventID, Name: eventName, ManifestIDs: []int64{}})
+		}
+		if ids := result.Events[i].ManifestIDs; len(ids) == 0 || ids[len(ids)-1] != manifestID {
+			result.Events[i].ManifestIDs = append(ids, manifestID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to remove assignments")
+		return
+	}
+	result.Removed = len(assignmentIDs)
+	if result.Removed == 0 {
+		httpx.WriteJSON(w, http.StatusOK, result)
+		return
+	}
+
+	if replacementID > 0 {
+		tag, err := tx.Exec(ctx, `
+			UPDATE crew_assignments ca
+			SET participant_id = $2, assigned_at = $3
+			WHERE ca.id = ANY($1)
+			  AND NOT EXISTS (
+			      SELECT 1 FROM crew_assignments other
+			      WHERE other.manifest_id = ca.manifest_id AND other.participant_id = $2
+			  )`, assignmentIDs, replacementID, now)
+		if err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to reassign crew")
+			return
+		}
+		result.Replaced = int(tag.RowsAffected())
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM crew_assignments WHERE id = ANY($1) AND participant_id = $2`, assignmentIDs, profileID); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to remove assignments")
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to remove assignments")
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,151 @@
+package participants
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/innhopp/central/backend/internal/clock"
+)
+
+func TestRemoveFutureAssignmentsHandsOverUnfinishedEvents(t *testing.T) {
+	db := openParticipantTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ensureParticipantTestSchema(t, ctx, db)
+
+	// The handler's clock runs a month ahead of the database, so an event
+	// judged by NOW() instead would land on the wrong side of the cutoff.
+	now := time.Now().UTC().Add(30 * 24 * time.Hour).Truncate(time.Second)
+
+	var seasonID int64
+	if err := db.QueryRow(ctx, `INSERT INTO seasons (name, starts_on) VALUES ('Assignments Season', CURRENT_DATE) RETURNING id`).Scan(&seasonID); err != nil {
+		t.Fatalf("insert season failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM seasons WHERE id = $1`, seasonID)
+	insertEvent := func(name string, startsAt time.Time, endsAt *time.Time) int64 {
+		t.Helper()
+		var id int64
+		if err := db.QueryRow(ctx, `INSERT INTO events (season_id, name, starts_at, ends_at) VALUES ($1, $2, $3, $4) RETURNING id`, seasonID, name, startsAt, endsAt).Scan(&id); err != nil {
+			t.Fatalf("insert event failed: %v", err)
+		}
+		return id
+	}
+	insertManifest := func(eventID int64, load int) int64 {
+		t.Helper()
+		var id int64
+		if err := db.QueryRow(ctx, `INSERT INTO manifests (event_id, load_number) VALUES ($1, $2) RETURNING id`, eventID, load).Scan(&id); err != nil {
+			t.Fatalf("insert manifest failed: %v", err)
+		}
+		return id
+	}
+	insertProfile := func(name string) int64 {
+		t.Helper()
+		email := "assignments-" + name + "-" + strconv.FormatInt(time.Now().UnixNano(), 10) + "@example.com"
+		var id int64
+		if err := db.QueryRow(ctx, `INSERT INTO participant_profiles (full_name, email) VALUES ($1, $2) RETURNING id`, name, email).Scan(&id); err != nil {
+			t.Fatalf("insert participant failed: %v", err)
+		}
+		t.Cleanup(func() { db.Exec(context.Background(), `DELETE FROM participant_profiles WHERE id = $1`, id) })
+		return id
+	}
+	assign := func(manifestID, participantID int64, role string) {
+		t.Helper()
+		if _, err := db.Exec(ctx, `INSERT INTO crew_assignments (manifest_id, participant_id, role, assigned_at) VALUES ($1, $2, $3, NOW())`, manifestID, participantID, role); err != nil {
+			t.Fatalf("insert crew assignment failed: %v", err)
+		}
+	}
+
+	pastEnd := now.Add(-24 * time.Hour)
+	liveEnd := now.Add(6 * time.Hour)
+	pastID := insertEvent("Finished", now.Add(-48*time.Hour), &pastEnd)
+	liveID := insertEvent("Under Way", now.Add(-2*time.Hour), &liveEnd)
+	upcomingID := insertEvent("Upcoming", now.Add(5*24*time.Hour), nil)
+	pastLoad := insertManifest(pastID, 1)
+	liveLoad1 := insertManifest(liveID, 1)
+	liveLoad2 := insertManifest(liveID, 2)
+	upcomingLoad := insertManifest(upcomingID, 1)
+
+	leaving := insertProfile("Leaving")
+	replacement := insertProfile("Replacement")
+	for _, manifestID := range []int64{pastLoad, liveLoad1, liveLoad2, upcomingLoad} {
+		assign(manifestID, leaving, "Jump Master")
+	}
+	// The replacement already crews the second live load, so that assignment
+	// is only removed.
+	assign(liveLoad2, replacement, "Driver")
+
+	handler := NewHandler(db)
+	handler.SetClock(clock.Fixed{At: now})
+	router := chi.NewRouter()
+	router.Delete("/profiles/{profileID}/assignments/future", handler.removeFutureAssignments)
+	rec := httptest.NewRecorder()
+	target := "/profiles/" + strconv.FormatInt(leaving, 10) + "/assignments/future?replacement_id=" + strconv.FormatInt(replacement, 10)
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, target, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("removeFutureAssignments() status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	var result futureAssignmentsResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decode result failed: %v", err)
+	}
+
+	if result.Removed != 3 || result.Replaced != 2 {
+		t.Fatalf("removed = %d, replaced = %d; want 3 and 2", result.Removed, result.Replaced)
+	}
+	if len(result.Events) != 2 {
+		t.Fatalf("events = %+v, want the live and upcoming events", result.Events)
+	}
+	if live := result.Events[0]; live.ID != liveID || len(live.ManifestIDs) != 2 || live.ManifestIDs[0] != liveLoad1 || live.ManifestIDs[1] != liveLoad2 {
+		t.Fatalf("first event = %+v, want %d with loads [%d %d]", live, liveID, liveLoad1, liveLoad2)
+	}
+	if upcoming := result.Events[1]; upcoming.ID != upcomingID || len(upcoming.ManifestIDs) != 1 || upcoming.ManifestIDs[0] != upcomingLoad {
+		t.Fatalf("second event = %+v, want %d with load [%d]", upcoming, upcomingID, upcomingLoad)
+	}
+
+	crew := func(manifestID int64) map[int64]string {
+		t.Helper()
+		rows, err := db.Query(ctx, `SELECT participant_id, role FROM crew_assignments WHERE manifest_id = $1`, manifestID)
+		if err != nil {
+			t.Fatalf("load crew failed: %v", err)
+		}
+		defer rows.Close()
+		members := map[int64]string{}
+		for rows.Next() {
+			var id int64
+			var role string
+			if err := rows.Scan(&id, &role); err != nil {
+				t.Fatalf("scan crew failed: %v", err)
+			}
+			members[id] = role
+		}
+		return members
+	}
+	if got := crew(pastLoad); len(got) != 1 || got[leaving] != "Jump Master" {
+		t.Fatalf("finished event crew = %v, want the leaving participant kept", got)
+	}
+	for _, manifestID := range []int64{liveLoad1, upcomingLoad} {
+		if got := crew(manifestID); len(got) != 1 || got[replacement] != "Jump Master" {
+			t.Fatalf("load %d crew = %v, want only the replacement as Jump Master", manifestID, got)
+		}
+	}
+	if got := crew(liveLoad2); len(got) != 1 || got[replacement] != "Driver" {
+		t.Fatalf("already crewed load = %v, want the replacement's own Driver assignment only", got)
+	}
+
+	var handedOver int
+	if err := db.QueryRow(ctx, `SELECT COUNT(*) FROM crew_assignments WHERE participant_id = $1 AND assigned_at = $2`, replacement, now).Scan(&handedOver); err != nil {
+		t.Fatalf("count handed over assignments failed: %v", err)
+	}
+	if handedOver != 2 {
+		t.Fatalf("assignments stamped with the handler's clock = %d, want 2", handedOver)
+	}
+}
@@ -0,0 +1,27 @@
+package participants
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestRemoveFutureAssignmentsValidatesReplacementBeforeTouchingDatabase(t *testing.T) {
+	router := chi.NewRouter()
+	router.Delete("/profiles/{profileID}/assignments/future", NewHandler(nil).removeFutureAssignments)
+
+	for _, target := range []string{
+		"/profiles/abc/assignments/future",
+		"/profiles/7/assignments/future?replacement_id=abc",
+		"/profiles/7/assignments/future?replacement_id=0",
+		"/profiles/7/assignments/future?replacement_id=7",
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("DELETE %s status = %d, want %d", target, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
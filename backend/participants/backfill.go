@@ -0,0 +1,77 @@
+package participants
+
+import (
+	"context"
+	"log"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/innhopp/central/backend/rbac"
+)
+
+// BackfillCanonicalRoles rewrites participant profile roles stored under an
+// older spelling, such as "jump_master" or "COP", to the labels in
+// rbac.ProfileRoles, and account roles stored as labels, such as "Jump
+// Master", to the rbac role names. Values that match no role are kept so
+// nothing is lost; they are dropped the next time the profile is saved.
+func BackfillCanonicalRoles(ctx context.Context, db *pgxpool.Pool) error {
+	rows, err := db.Query(ctx, `SELECT id, roles, account_roles FROM participant_profiles`)
+	if err != nil {
+		return err
+	}
+	type update struct {
+		id           int64
+		roles        []string
+		accountRoles []string
+	}
+	var updates []update
+	for rows.Next() {
+		var id int64
+		var roles, accountRoles []string
+		if err := rows.Scan(&id, &roles, &accountRoles); err != nil {
+			rows.Close()
+			return err
+		}
+		canonicalRoles := canonicalizeStoredRoles(roles, rbac.CanonicalProfileRole)
+		canonicalAccountRoles := canonicalizeStoredRoles(accountRoles, func(value string) string {
+			role, _ := rbac.ParseRole(value)
+			return string(role)
+		})
+		if !slices.Equal(roles, canonicalRoles) || !slices.Equal(accountRoles, canonicalAccountRoles) {
+			updates = append(updates, update{id: id, roles: canonicalRoles, accountRoles: canonicalAccountRoles})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, u := range updates {
+		if _, err := db.Exec(ctx, `UPDATE participant_profiles SET roles = $2, account_roles = $3 WHERE id = $1`, u.id, u.roles, u.accountRoles); err != nil {
+			return err
+		}
+	}
+	if len(updates) > 0 {
+		log.Printf("canonicalized roles on %d participant profiles", len(updates))
+	}
+	return nil
+}
+
+// canonicalizeStoredRoles maps each value through canonical, keeping values it
+// does not recognise, and drops duplicates while keeping the first order.
+func canonicalizeStoredRoles(values []string, canonical func(string) string) []string {
+	out := make([]string, 0, len(values))
+	seen := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		if mapped := canonical(value); mapped != "" {
+			value = mapped
+		}
+		if _, dup := seen[value]; dup {
+			continue
+		}
+		seen[value] = struct{}{}
+		out = append(out, value)
+	}
+	return out
+}
@@ -0,0 +1,24 @@
+package participants
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/innhopp/central/backend/rbac"
+)
+
+func TestCanonicalizeStoredRolesKeepsUnknownValues(t *testing.T) {
+	got := canonicalizeStoredRoles([]string{"jump_master", "COP", "Jump Master", "Tandem"}, rbac.CanonicalProfileRole)
+	want := []string{"Jump Master", "POC", "Tandem"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("canonicalizeStoredRoles() = %v, want %v", got, want)
+	}
+}
+
+func TestNormalizeRolesUsesCanonicalLabels(t *testing.T) {
+	got := normalizeRoles([]string{"ground_crew", "Ground Crew", "cop", "unknown"})
+	want := []string{"Ground Crew", "POC"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("normalizeRoles() = %v, want %v", got, want)
+	}
+}
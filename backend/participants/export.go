@@ -0,0 +1,158 @@
+package participants
+
+import (
+	"encoding/csv"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/innhopp/central/backend/httpx"
+)
+
+// exportColumns is the header row of the roster export.
+var exportColumns = []string{
+	"id", "full_name", "email", "phone", "whatsapp", "emergency_contact", "citizenship",
+	"roles", "license", "jump_count", "recent_jump_count", "level", "event_count", "last_event_at",
+}
+
+// exportRow is one participant in the roster export.
+type exportRow struct {
+	ID               int64
+	FullName         string
+	Email            string
+	Phone            string
+	Whatsapp         string
+	EmergencyContact string
+	Citizenship      string
+	Roles            []string
+	License          string
+	JumpCount        *int
+	RecentJumpCount  *int
+	ExperienceLevel  string
+	EventCount       int
+	LastEventAt      *time.Time
+}
+
+func (row exportRow) record() []string {
+	return []string{
+		strconv.FormatInt(row.ID, 10),
+		row.FullName,
+		row.Email,
+		row.Phone,
+		row.Whatsapp,
+		row.EmergencyContact,
+		row.Citizenship,
+		strings.Join(row.Roles, "; "),
+		row.License,
+		formatOptionalInt(row.JumpCount),
+		formatOptionalInt(row.RecentJumpCount),
+		derivedLevel(row.ExperienceLevel, row.JumpCount),
+		strconv.Itoa(row.EventCount),
+		formatOptionalTime(row.LastEventAt),
+	}
+}
+
+// derivedLevel is the level the participant gave, or one derived from their
+// jump count when they left it blank. It is empty when neither is known.
+func derivedLevel(experienceLevel string, jumps *int) string {
+	if level := strings.TrimSpace(experienceLevel); level != "" {
+		return level
+	}
+	switch {
+	case jumps == nil:
+		return ""
+	case *jumps < 200:
+		return LevelNovice
+	case *jumps < 500:
+		return LevelIntermediate
+	case *jumps < 1000:
+		return LevelExperienced
+	default:
+		return LevelExpert
+	}
+}
+
+func formatOptionalInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
+func formatOptionalTime(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.UTC().Format(time.RFC3339)
+}
+
+// exportProfiles streams every participant profile as CSV with their event
+// count and most recent event. Contact fields are included, so the route is
+// limited to participant managers.
+func (h *Handler) exportProfiles(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rows, err := h.db.Query(ctx, `
+		SELECT p.id, p.full_name, p.email, COALESCE(p.phone, ''), COALESCE(p.whatsapp, ''),
+		       COALESCE(p.emergency_contact, ''), COALESCE(p.citizenship, ''), COALESCE(p.roles, ARRAY[]::TEXT[]),
+		       COALESCE(p.license, ''), p.jump_count, p.recent_jump_count, COALESCE(p.experience_level, ''),
+		       COALESCE(ev.event_count, 0), ev.last_event_at
+		FROM participant_profiles p
+		LEFT JOIN (
+			SELECT ep.participant_id, COUNT(*) AS event_count, MAX(e.starts_at) AS last_event_at
+			FROM event_participants ep
+			JOIN events e ON e.id = ep.event_id
+			GROUP BY ep.participant_id
+		) ev ON ev.participant_id = p.id
+		ORDER BY lower(p.full_name), p.id`)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to export participants")
+		return
+	}
+	defer rows.Close()
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="participants.csv"`)
+	w.WriteHeader(http.StatusOK)
+	if err := writeProfileExport(w, func() (exportRow, bool, error) {
+		if !rows.Next() {
+			return exportRow{}, false, rows.Err()
+		}
+		var row exportRow
+		err := rows.Scan(&row.ID, &row.FullName, &row.Email, &row.Phone, &row.Whatsapp,
+			&row.EmergencyContact, &row.Citizenship, &row.Roles, &row.License, &row.JumpCount,
+			&row.RecentJumpCount, &row.ExperienceLevel, &row.EventCount, &row.LastEventAt)
+		return row, err == nil, err
+	}); err != nil {
+		// The status line is already sent; a short file is all the client sees.
+		log.Printf("participants: export stopped: %v", err)
+	}
+}
+
+// writeProfileExport writes the header and then each row next returns until
+// it reports no more, flushing as it goes so large rosters are not buffered.
+func writeProfileExport(out io.Writer, next func() (exportRow, bool, error)) error {
+	writer := csv.NewWriter(out)
+	if err := writer.Write(exportColumns); err != nil {
+		return err
+	}
+	for written := 1; ; written++ {
+		row, ok, err := next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		if err := writer.Write(row.record()); err != nil {
+			return err
+		}
+		if written%500 == 0 {
+			writer.Flush()
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,67 @@
+package participants
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteProfileExport(t *testing.T) {
+	jumps := 350
+	last := time.Date(2026, 6, 14, 9, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	rows := []exportRow{
+		{ID: 1, FullName: "Ada, Aviator", Email: "ada@example.com", Roles: []string{"Skydiver", "Jump Master"}, JumpCount: &jumps, EventCount: 3, LastEventAt: &last},
+		{ID: 2, FullName: "New Person", Email: "new@example.com", ExperienceLevel: "Student"},
+	}
+	var out strings.Builder
+	err := writeProfileExport(&out, func() (exportRow, bool, error) {
+		if len(rows) == 0 {
+			return exportRow{}, false, nil
+		}
+		row := rows[0]
+		rows = rows[1:]
+		return row, true, nil
+	})
+	if err != nil {
+		t.Fatalf("writeProfileExport: %v", err)
+	}
+	want := "id,full_name,email,phone,whatsapp,emergency_contact,citizenship,roles,license,jump_count,recent_jump_count,level,event_count,last_event_at\n" +
+		"1,\"Ada, Aviator\",ada@example.com,,,,,Skydiver; Jump Master,,350,,Intermediate,3,2026-06-14T07:00:00Z\n" +
+		"2,New Person,new@example.com,,,,,,,,,Student,0,\n"
+	if out.String() != want {
+		t.Fatalf("export =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestWriteProfileExportStopsOnError(t *testing.T) {
+	failure := errors.New("connection reset")
+	var out strings.Builder
+	err := writeProfileExport(&out, func() (exportRow, bool, error) {
+		return exportRow{}, false, failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("err = %v, want %v", err, failure)
+	}
+}
+
+func TestDerivedLevel(t *testing.T) {
+	count := func(n int) *int { return &n }
+	cases := []struct {
+		given string
+		jumps *int
+		want  string
+	}{
+		{"Coach", count(20), "Coach"},
+		{"", nil, ""},
+		{"", count(0), "Novice"},
+		{"", count(200), "Intermediate"},
+		{"", count(999), "Experienced"},
+		{"  ", count(1000), "Expert"},
+	}
+	for _, c := range cases {
+		if got := derivedLevel(c.given, c.jumps); got != c.want {
+			t.Errorf("derivedLevel(%q, %v) = %q, want %q", c.given, c.jumps, got, c.want)
+		}
+	}
+}
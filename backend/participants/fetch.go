@@ -0,0 +1,72 @@
+package participants
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Querier is the read-only subset of pgx used to load profiles in bulk.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// linkedAccountRolesColumn resolves the roles granted to the account linked to
+// a profile (by account_id, falling back to a matching email) so that profiles
+// can be enriched without a query per row.
+const linkedAccountRolesColumn = `
+	COALESCE(ARRAY(
+		SELECT ar.role_name
+		FROM account_roles ar
+		WHERE ar.account_id = COALESCE(
+			p.account_id,
+			(SELECT a.id FROM accounts a WHERE lower(a.email) = lower(p.email) ORDER BY a.id ASC LIMIT 1)
+		)
+		ORDER BY ar.role_name ASC
+	), ARRAY[]::TEXT[])`
+
+// FetchByIDs loads the profiles for ids with a single query and returns them
+// keyed by id. Unknown ids are absent from the result.
+func FetchByIDs(ctx context.Context, db Querier, ids []int64) (map[int64]Profile, error) {
+	result := make(map[int64]Profile, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT `+profileSelectColumns+`, `+linkedAccountRolesColumn+`
+		FROM participant_profiles p
+		WHERE p.id = ANY($1)
+	`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		profile, err := scanProfileWithAccountRoles(rows)
+		if err != nil {
+			return nil, err
+		}
+		result[profile.ID] = *profile
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// scanProfileWithAccountRoles scans a profile row followed by the
+// linkedAccountRolesColumn and any extra columns, and merges both role
+// sources.
+func scanProfileWithAccountRoles(scanner interface{ Scan(dest ...any) error }, extra ...any) (*Profile, error) {
+	var linkedRoles []string
+	profile, err := scanProfile(scanner, append([]any{&linkedRoles}, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+	merged := append([]string{}, profile.AccountRoles...)
+	merged = append(merged, linkedRoles...)
+	profile.AccountRoles = normalizeAccountRoles(merged)
+	return profile, nil
+}
@@ -0,0 +1,66 @@
+package participants
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type countingQuerier struct {
+	queries []string
+	args    [][]any
+}
+
+func (q *countingQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	q.queries = append(q.queries, sql)
+	q.args = append(q.args, args)
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Close()                                       {}
+func (emptyRows) Err() error                                   { return nil }
+func (emptyRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (emptyRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (emptyRows) Next() bool                                   { return false }
+func (emptyRows) Scan(dest ...any) error                       { return nil }
+func (emptyRows) Values() ([]any, error)                       { return nil, nil }
+func (emptyRows) RawValues() [][]byte                          { return nil }
+func (emptyRows) Conn() *pgx.Conn                              { return nil }
+
+func TestFetchByIDsIssuesSingleQuery(t *testing.T) {
+	q := &countingQuerier{}
+	ids := []int64{3, 8, 13, 21, 34}
+
+	got, err := FetchByIDs(context.Background(), q, ids)
+	if err != nil {
+		t.Fatalf("FetchByIDs() returned error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("FetchByIDs() returned %d profiles, want 0", len(got))
+	}
+	if len(q.queries) != 1 {
+		t.Fatalf("FetchByIDs() issued %d queries, want 1", len(q.queries))
+	}
+	if !strings.Contains(q.queries[0], "= ANY($1)") {
+		t.Fatalf("FetchByIDs() query does not filter with ANY: %s", q.queries[0])
+	}
+	if !reflect.DeepEqual(q.args[0], []any{ids}) {
+		t.Fatalf("FetchByIDs() args = %v, want [%v]", q.args[0], ids)
+	}
+}
+
+func TestFetchByIDsSkipsQueryForNoIDs(t *testing.T) {
+	q := &countingQuerier{}
+	if _, err := FetchByIDs(context.Background(), q, nil); err != nil {
+		t.Fatalf("FetchByIDs() returned error: %v", err)
+	}
+	if len(q.queries) != 0 {
+		t.Fatalf("FetchByIDs() issued %d queries for no ids, want 0", len(q.queries))
+	}
+}
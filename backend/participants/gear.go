@@ -0,0 +1,36 @@
+package participants
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PrimaryRig is the gear asset marked as a participant's primary rig in
+// participant_gear. The full list lives under
+// /api/logistics/participants/{id}/gear.
+type PrimaryRig struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	SerialNumber string `json:"serial_number"`
+	Status       string `json:"status"`
+}
+
+// setPrimaryRig fills PrimaryRig when the participant has one.
+func (h *Handler) setPrimaryRig(ctx context.Context, profile *Profile) error {
+	var rig PrimaryRig
+	err := h.db.QueryRow(ctx, `
+		SELECT g.id, g.name, g.serial_number, g.status
+		FROM participant_gear pg
+		JOIN gear_assets g ON g.id = pg.gear_asset_id
+		WHERE pg.participant_id = $1 AND pg.is_primary`, profile.ID).Scan(&rig.ID, &rig.Name, &rig.SerialNumber, &rig.Status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	profile.PrimaryRig = &rig
+	return nil
+}
@@ -15,18 +15,31 @@ import (
 
 	"github.com/innhopp/central/backend/auth"
 	"github.com/innhopp/central/backend/httpx"
+	"github.com/innhopp/central/backend/internal/clock"
+	"github.com/innhopp/central/backend/internal/email"
+	"github.com/innhopp/central/backend/internal/textsearch"
 	"github.com/innhopp/central/backend/rbac"
 	"github.com/innhopp/central/backend/registrations"
 )
 
 // Handler exposes participant profile endpoints.
 type Handler struct {
-	db *pgxpool.Pool
+	db    *pgxpool.Pool
+	clock clock.Clock
 }
 
 // NewHandler creates a participants handler.
 func NewHandler(db *pgxpool.Pool) *Handler {
-	return &Handler{db: db}
+	return &Handler{db: db, clock: clock.Real{}}
+}
+
+// SetClock replaces the time source that decides which events are still
+// ahead.
+func (h *Handler) SetClock(c clock.Clock) {
+	if c == nil {
+		c = clock.Real{}
+	}
+	h.clock = c
 }
 
 // Routes registers participant routes.
@@ -34,46 +47,57 @@ func (h *Handler) Routes(enforcer *rbac.Enforcer) chi.Router {
 	r := chi.NewRouter()
 	r.Get("/profiles/me", h.getOwnProfile)
 	r.Put("/profiles/me", h.upsertOwnProfile)
+	r.Get("/profiles/me/obligations", h.listOwnObligations)
+	r.With(enforcer.Authorize(rbac.PermissionManageParticipants)).Get("/export.csv", h.exportProfiles)
 	r.With(enforcer.Authorize(rbac.PermissionViewParticipants)).Get("/profiles", h.listProfiles)
-	r.With(enforcer.Authorize(rbac.PermissionManageParticipants)).Post("/profiles", h.createProfile)
+	r.With(enforcer.Authorize(rbac.PermissionManageParticipants), enforcer.AdminAction("participants.create")).Post("/profiles", h.createProfile)
 	r.With(enforcer.Authorize(rbac.PermissionViewParticipants)).Get("/profiles/{profileID}", h.getProfile)
-	r.With(enforcer.Authorize(rbac.PermissionManageParticipants)).Put("/profiles/{profileID}", h.updateProfile)
+	r.With(enforcer.Authorize(rbac.PermissionManageParticipants), enforcer.AdminAction("participants.update")).Put("/profiles/{profileID}", h.updateProfile)
 	r.With(enforcer.Authorize(rbac.PermissionManageParticipants)).Delete("/profiles/{profileID}", h.deleteProfile)
+	r.With(enforcer.Authorize(rbac.PermissionManageParticipants)).Get("/profiles/{profileID}/notes", h.listNotes)
+	r.With(enforcer.Authorize(rbac.PermissionManageParticipants)).Post("/profiles/{profileID}/notes", h.createNote)
+	r.With(enforcer.Authorize(rbac.PermissionManageParticipants)).Delete("/profiles/{profileID}/notes/{noteID}", h.deleteNote)
+	r.With(enforcer.Authorize(rbac.PermissionViewParticipants)).Get("/profiles/{profileID}/photo", h.getPhoto)
+	r.With(enforcer.Authorize(rbac.PermissionManageParticipants)).Post("/profiles/{profileID}/photo", h.uploadPhoto)
+	r.With(enforcer.Authorize(rbac.PermissionManageCrewAssignments)).Delete("/profiles/{profileID}/assignments/future", h.removeFutureAssignments)
 	return r
 }
 
 type Profile struct {
-	ID                    int64     `json:"id"`
-	FullName              string    `json:"full_name"`
-	Email                 string    `json:"email"`
-	Phone                 string    `json:"phone,omitempty"`
-	ExperienceLevel       string    `json:"experience_level,omitempty"`
-	EmergencyContact      string    `json:"emergency_contact,omitempty"`
-	Whatsapp              string    `json:"whatsapp,omitempty"`
-	Instagram             string    `json:"instagram,omitempty"`
-	Citizenship           string    `json:"citizenship,omitempty"`
-	DateOfBirth           string    `json:"date_of_birth,omitempty"`
-	Jumper                bool      `json:"jumper"`
-	YearsInSport          *int      `json:"years_in_sport,omitempty"`
-	JumpCount             *int      `json:"jump_count,omitempty"`
-	RecentJumpCount       *int      `json:"recent_jump_count,omitempty"`
-	MainCanopy            string    `json:"main_canopy,omitempty"`
-	Wingload              string    `json:"wingload,omitempty"`
-	License               string    `json:"license,omitempty"`
-	Roles                 []string  `json:"roles"`
-	Ratings               []string  `json:"ratings"`
-	Disciplines           []string  `json:"disciplines"`
-	OtherAirSports        []string  `json:"other_air_sports"`
-	CanopyCourse          string    `json:"canopy_course,omitempty"`
-	LandingAreaPreference string    `json:"landing_area_preference,omitempty"`
-	TshirtSize            string    `json:"tshirt_size,omitempty"`
-	TshirtGender          string    `json:"tshirt_gender,omitempty"`
-	DietaryRestrictions   []string  `json:"dietary_restrictions"`
-	MedicalConditions     string    `json:"medical_conditions,omitempty"`
-	MedicalExpertise      []string  `json:"medical_expertise"`
-	HSSQualities          []string  `json:"hss_qualities"`
-	AccountRoles          []string  `json:"account_roles"`
-	CreatedAt             time.Time `json:"created_at"`
+	ID                    int64       `json:"id"`
+	FullName              string      `json:"full_name"`
+	Email                 string      `json:"email"`
+	Phone                 string      `json:"phone,omitempty"`
+	ExperienceLevel       string      `json:"experience_level,omitempty"`
+	EmergencyContact      string      `json:"emergency_contact,omitempty"`
+	Whatsapp              string      `json:"whatsapp,omitempty"`
+	Instagram             string      `json:"instagram,omitempty"`
+	Citizenship           string      `json:"citizenship,omitempty"`
+	DateOfBirth           string      `json:"date_of_birth,omitempty"`
+	Jumper                bool        `json:"jumper"`
+	YearsInSport          *int        `json:"years_in_sport,omitempty"`
+	JumpCount             *int        `json:"jump_count,omitempty"`
+	RecentJumpCount       *int        `json:"recent_jump_count,omitempty"`
+	MainCanopy            string      `json:"main_canopy,omitempty"`
+	Wingload              string      `json:"wingload,omitempty"`
+	License               string      `json:"license,omitempty"`
+	Roles                 []string    `json:"roles"`
+	Ratings               []string    `json:"ratings"`
+	Disciplines           []string    `json:"disciplines"`
+	OtherAirSports        []string    `json:"other_air_sports"`
+	CanopyCourse          string      `json:"canopy_course,omitempty"`
+	LandingAreaPreference string      `json:"landing_area_preference,omitempty"`
+	TshirtSize            string      `json:"tshirt_size,omitempty"`
+	TshirtGender          string      `json:"tshirt_gender,omitempty"`
+	DietaryRestrictions   []string    `json:"dietary_restrictions"`
+	MedicalConditions     string      `json:"medical_conditions,omitempty"`
+	MedicalExpertise      []string    `json:"medical_expertise"`
+	HSSQualities          []string    `json:"hss_qualities"`
+	AccountRoles          []string    `json:"account_roles"`
+	PhotoURL              string      `json:"photo_url,omitempty"`
+	PrimaryRig            *PrimaryRig `json:"primary_rig,omitempty"`
+	ClientID              string      `json:"client_id,omitempty"`
+	CreatedAt             time.Time   `json:"created_at"`
 }
 
 type profilePayload struct {
@@ -106,6 +130,7 @@ type profilePayload struct {
 	MedicalExpertise      []string `json:"medical_expertise"`
 	HSSQualities          []string `json:"hss_qualities"`
 	AccountRoles          []string `json:"account_roles"`
+	ClientID              *string  `json:"client_id"`
 }
 
 const profileSelectColumns = `
@@ -142,44 +167,41 @@ const profileSelectColumns = `
 	created_at
 `
 
-var allowedRoles = map[string]struct{}{
-	"Participant": {},
-	"Skydiver":    {},
-	"Staff":       {},
-	"Ground Crew": {},
-	"Jump Master": {},
-	"Jump Leader": {},
-	"Driver":      {},
-	"Pilot":       {},
-	"POC":         {},
-	"Photo":       {},
+// RoleValues lists the roles a participant profile may carry.
+func RoleValues() []string {
+	return append([]string(nil), rbac.ProfileRoles...)
 }
 
-var allowedAccountRoles = map[string]struct{}{
-	string(rbac.RoleAdmin):       {},
-	string(rbac.RoleStaff):       {},
-	string(rbac.RoleJumpMaster):  {},
-	string(rbac.RoleJumpLeader):  {},
-	string(rbac.RoleGroundCrew):  {},
-	string(rbac.RoleDriver):      {},
-	string(rbac.RolePacker):      {},
-	string(rbac.RoleParticipant): {},
+// Experience levels offered for a profile, least experienced first. The
+// profile field itself stays free text.
+const (
+	LevelNovice       = "Novice"
+	LevelIntermediate = "Intermediate"
+	LevelExperienced  = "Experienced"
+	LevelExpert       = "Expert"
+)
+
+// ExperienceLevelValues lists the experience levels in order.
+func ExperienceLevelValues() []string {
+	return []string{LevelNovice, LevelIntermediate, LevelExperienced, LevelExpert}
 }
 
+var allowedAccountRoles = func() map[string]struct{} {
+	set := make(map[string]struct{}, len(rbac.Roles))
+	for _, role := range rbac.Roles {
+		set[string(role)] = struct{}{}
+	}
+	return set
+}()
+
 func normalizeRoles(input []string) []string {
 	seen := make(map[string]struct{})
 	var roles []string
 	for _, r := range input {
-		trimmed := strings.TrimSpace(r)
+		trimmed := rbac.CanonicalProfileRole(r)
 		if trimmed == "" {
 			continue
 		}
-		if trimmed == "COP" {
-			trimmed = "POC"
-		}
-		if _, ok := allowedRoles[trimmed]; !ok {
-			continue
-		}
 		if _, exists := seen[trimmed]; exists {
 			continue
 		}
@@ -358,9 +380,9 @@ func canManageAccountRoles(ctx context.Context) bool {
 	return false
 }
 
-func scanProfile(scanner interface{ Scan(dest ...any) error }) (*Profile, error) {
+func scanProfile(scanner interface{ Scan(dest ...any) error }, extra ...any) (*Profile, error) {
 	var profile Profile
-	if err := scanner.Scan(
+	dest := []any{
 		&profile.ID,
 		&profile.FullName,
 		&profile.Email,
@@ -392,11 +414,12 @@ func scanProfile(scanner interface{ Scan(dest ...any) error }) (*Profile, error)
 		&profile.MedicalExpertise,
 		&profile.HSSQualities,
 		&profile.CreatedAt,
-	); err != nil {
+	}
+	if err := scanner.Scan(append(dest, extra...)...); err != nil {
 		return nil, err
 	}
 
-	profile.Email = strings.ToLower(strings.TrimSpace(profile.Email))
+	profile.Email = email.Normalize(profile.Email)
 	profile.Roles = normalizeRoles(profile.Roles)
 	profile.Ratings = normalizeStringList(profile.Ratings)
 	profile.Disciplines = normalizeStringList(profile.Disciplines)
@@ -483,9 +506,9 @@ func sanitizePayload(payload *profilePayload, defaultName, defaultEmail string)
 		fullName = strings.TrimSpace(defaultName)
 	}
 
-	email := strings.ToLower(strings.TrimSpace(payload.Email))
-	if email == "" {
-		email = strings.ToLower(strings.TrimSpace(defaultEmail))
+	normalizedEmail := email.Normalize(payload.Email)
+	if normalizedEmail == "" {
+		normalizedEmail = email.Normalize(defaultEmail)
 	}
 
 	payload.Phone = normalizeOptionalString(payload.Phone)
@@ -514,7 +537,7 @@ func sanitizePayload(payload *profilePayload, defaultName, defaultEmail string)
 	payload.HSSQualities = normalizeHSSQualities(payload.HSSQualities)
 	payload.AccountRoles = normalizeAccountRoles(payload.AccountRoles)
 
-	return fullName, email, syncParticipantRolesWithAccountRoles(payload.Roles, payload.AccountRoles)
+	return fullName, normalizedEmail, syncParticipantRolesWithAccountRoles(payload.Roles, payload.AccountRoles)
 }
 
 func (h *Handler) loadProfileByID(ctx context.Context, profileID int64) (*Profile, error) {
@@ -533,39 +556,76 @@ func (h *Handler) loadProfileByID(ctx context.Context, profileID int64) (*Profil
 	return profile, nil
 }
 
+// maxProfilePageSize caps ?limit= on the profile list.
+const maxProfilePageSize = 500
+
 func (h *Handler) listProfiles(w http.ResponseWriter, r *http.Request) {
+	page, err := httpx.ParsePage(r, maxProfilePageSize)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// The search clause is only added when q is set so the planner can use
+	// the trigram indexes on full_name and email.
+	var args []any
+	where := ""
+	if pattern, ok := textsearch.ContainsPattern(r.URL.Query().Get("q")); ok {
+		args = append(args, pattern)
+		where = `WHERE p.full_name ILIKE $1 OR p.email ILIKE $1`
+	}
+	filterArgs := args
+	pageClause, pageArgs := page.SQL(len(args) + 1)
+	args = append(args, pageArgs...)
+
 	rows, err := h.db.Query(r.Context(), `
-		SELECT `+profileSelectColumns+`
-		FROM participant_profiles
-		ORDER BY created_at DESC
-	`)
+		SELECT `+profileSelectColumns+`, `+linkedAccountRolesColumn+`, `+httpx.TotalCountColumn+`
+		FROM participant_profiles p
+		`+where+`
+		ORDER BY created_at DESC, id DESC`+pageClause, args...)
 	if err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to list participants")
 		return
 	}
 	defer rows.Close()
 
-	var profiles []Profile
+	profiles := make([]Profile, 0)
 	for rows.Next() {
-		profile, scanErr := scanProfile(rows)
+		profile, scanErr := scanProfileWithAccountRoles(rows, &page.Total)
 		if scanErr != nil {
 			httpx.Error(w, http.StatusInternalServerError, "failed to parse participant")
 			return
 		}
-		if err := h.enrichAccountRoles(r.Context(), profile); err != nil {
-			httpx.Error(w, http.StatusInternalServerError, "failed to load participant roles")
+		profiles = append(profiles, *profile)
+	}
+	if err := rows.Err(); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to list participants")
+		return
+	}
+	rows.Close()
+	if page.NeedsCount(len(profiles)) {
+		if err := h.db.QueryRow(r.Context(), `SELECT COUNT(*) FROM participant_profiles p `+where, filterArgs...).Scan(&page.Total); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to count participants")
 			return
 		}
-		profiles = append(profiles, *profile)
 	}
 
-	httpx.WriteJSON(w, http.StatusOK, profiles)
+	withPhotos := make([]*Profile, len(profiles))
+	for i := range profiles {
+		withPhotos[i] = &profiles[i]
+	}
+	if err := h.setPhotoURLs(r.Context(), withPhotos...); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to list participants")
+		return
+	}
+
+	httpx.WritePage(w, r, http.StatusOK, profiles, page)
 }
 
 func (h *Handler) createProfile(w http.ResponseWriter, r *http.Request) {
 	var payload profilePayload
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
@@ -574,6 +634,11 @@ func (h *Handler) createProfile(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, http.StatusBadRequest, "full_name and email are required")
 		return
 	}
+	clientID, err := httpx.ParseClientID(payload.ClientID)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	row := h.db.QueryRow(r.Context(), `
 		INSERT INTO participant_profiles (
@@ -606,7 +671,8 @@ func (h *Handler) createProfile(w http.ResponseWriter, r *http.Request) {
 			dietary_restrictions,
 			medical_conditions,
 			medical_expertise,
-			hss_qualities
+			hss_qualities,
+			client_id
 		)
 		VALUES (
 			$1,
@@ -638,7 +704,8 @@ func (h *Handler) createProfile(w http.ResponseWriter, r *http.Request) {
 			$26,
 			$27,
 			$28,
-			$29
+			$29,
+			$30
 		)
 		RETURNING `+profileSelectColumns,
 		fullName,
@@ -670,12 +737,22 @@ func (h *Handler) createProfile(w http.ResponseWriter, r *http.Request) {
 		payload.MedicalConditions,
 		payload.MedicalExpertise,
 		payload.HSSQualities,
+		clientID,
 	)
 
 	profile, err := scanProfile(row)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if ok := errors.As(err, &pgErr); ok && pgErr.Code == "23505" {
+			if pgErr.ConstraintName == "participant_profiles_client_id_key" {
+				var existingID int64
+				if err := h.db.QueryRow(r.Context(), `SELECT id FROM participant_profiles WHERE client_id = $1`, *clientID).Scan(&existingID); err != nil {
+					httpx.Error(w, http.StatusConflict, "client_id is already in use")
+					return
+				}
+				httpx.ClientIDConflict(w, existingID)
+				return
+			}
 			httpx.Error(w, http.StatusConflict, "a participant with that email already exists")
 			return
 		}
@@ -698,6 +775,9 @@ func (h *Handler) createProfile(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, http.StatusInternalServerError, "failed to load account roles")
 		return
 	}
+	if clientID != nil {
+		profile.ClientID = *clientID
+	}
 
 	httpx.WriteJSON(w, http.StatusCreated, profile)
 }
@@ -714,6 +794,14 @@ func (h *Handler) getProfile(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, http.StatusNotFound, "participant not found")
 		return
 	}
+	if err := h.setPhotoURLs(r.Context(), profile); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load participant")
+		return
+	}
+	if err := h.setPrimaryRig(r.Context(), profile); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load participant")
+		return
+	}
 
 	httpx.WriteJSON(w, http.StatusOK, profile)
 }
@@ -725,8 +813,8 @@ func (h *Handler) getOwnProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	email := strings.ToLower(strings.TrimSpace(claims.Email))
-	if email == "" {
+	normalizedEmail := email.Normalize(claims.Email)
+	if normalizedEmail == "" {
 		httpx.Error(w, http.StatusBadRequest, "email claim missing")
 		return
 	}
@@ -734,10 +822,10 @@ func (h *Handler) getOwnProfile(w http.ResponseWriter, r *http.Request) {
 	row := h.db.QueryRow(r.Context(), `
 		SELECT `+profileSelectColumns+`
 		FROM participant_profiles
-		WHERE ($1 > 0 AND account_id = $1) OR lower(email) = $2
+		WHERE ($1 > 0 AND account_id = $1) OR lower(email) = lower($2)
 		ORDER BY CASE WHEN $1 > 0 AND account_id = $1 THEN 0 ELSE 1 END, id ASC
 		LIMIT 1
-	`, claims.AccountID, email)
+	`, claims.AccountID, normalizedEmail)
 
 	profile, err := scanProfile(row)
 	if err != nil {
@@ -748,6 +836,10 @@ func (h *Handler) getOwnProfile(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, http.StatusInternalServerError, "failed to load account roles")
 		return
 	}
+	if err := h.setPrimaryRig(r.Context(), profile); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load participant profile")
+		return
+	}
 
 	httpx.WriteJSON(w, http.StatusOK, profile)
 }
@@ -761,12 +853,12 @@ func (h *Handler) upsertOwnProfile(w http.ResponseWriter, r *http.Request) {
 
 	var payload profilePayload
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
-	fullName, email, _ := sanitizePayload(&payload, claims.FullName, claims.Email)
-	if fullName == "" || email == "" {
+	fullName, profileEmail, _ := sanitizePayload(&payload, claims.FullName, claims.Email)
+	if fullName == "" || profileEmail == "" {
 		httpx.Error(w, http.StatusBadRequest, "full_name and email are required")
 		return
 	}
@@ -777,10 +869,10 @@ func (h *Handler) upsertOwnProfile(w http.ResponseWriter, r *http.Request) {
 	err := h.db.QueryRow(r.Context(), `
 		SELECT id, roles, COALESCE(account_roles, ARRAY[]::TEXT[])
 		FROM participant_profiles
-		WHERE ($1 > 0 AND account_id = $1) OR lower(email) = $2
+		WHERE ($1 > 0 AND account_id = $1) OR lower(email) = lower($2)
 		ORDER BY CASE WHEN $1 > 0 AND account_id = $1 THEN 0 ELSE 1 END, id ASC
 		LIMIT 1
-	`, claims.AccountID, strings.ToLower(strings.TrimSpace(claims.Email))).Scan(&existingID, &existingRoles, &existingAccountRoles)
+	`, claims.AccountID, email.Normalize(claims.Email)).Scan(&existingID, &existingRoles, &existingAccountRoles)
 
 	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		httpx.Error(w, http.StatusInternalServerError, "failed to load participant profile")
@@ -838,7 +930,7 @@ func (h *Handler) upsertOwnProfile(w http.ResponseWriter, r *http.Request) {
 			)
 			RETURNING `+profileSelectColumns,
 			fullName,
-			email,
+			profileEmail,
 			nullableAccountID(claims.AccountID),
 			payload.Phone,
 			payload.ExperienceLevel,
@@ -934,7 +1026,7 @@ func (h *Handler) upsertOwnProfile(w http.ResponseWriter, r *http.Request) {
 		WHERE id = $30
 	`,
 		fullName,
-		email,
+		profileEmail,
 		payload.Phone,
 		payload.ExperienceLevel,
 		payload.EmergencyContact,
@@ -978,7 +1070,7 @@ func (h *Handler) upsertOwnProfile(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, http.StatusNotFound, "participant profile not found")
 		return
 	}
-	if err := h.syncAccountRoles(r.Context(), existingID, email, accountRoles); err != nil {
+	if err := h.syncAccountRoles(r.Context(), existingID, profileEmail, accountRoles); err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to save account roles")
 		return
 	}
@@ -1007,7 +1099,7 @@ func (h *Handler) updateProfile(w http.ResponseWriter, r *http.Request) {
 
 	var payload profilePayload
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
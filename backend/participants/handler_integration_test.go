@@ -0,0 +1,305 @@
+package participants
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestListProfilesToleratesNullOptionalFields(t *testing.T) {
+	db := openParticipantTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ensureParticipantTestSchema(t, ctx, db)
+
+	email := "null-optionals-" + strconv.FormatInt(time.Now().UnixNano(), 10) + "@example.com"
+	var profileID int64
+	if err := db.QueryRow(ctx, `
+		INSERT INTO participant_profiles (full_name, email, phone, experience_level, emergency_contact, medical_conditions)
+		VALUES ('Null Optionals', $1, NULL, NULL, NULL, NULL)
+		RETURNING id
+	`, email).Scan(&profileID); err != nil {
+		t.Fatalf("insert profile failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM participant_profiles WHERE id = $1`, profileID)
+
+	router := chi.NewRouter()
+	router.Get("/profiles", NewHandler(db).listProfiles)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/profiles", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list status mismatch: got %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	var profiles []Profile
+	if err := json.NewDecoder(rec.Body).Decode(&profiles); err != nil {
+		t.Fatalf("decode profiles failed: %v", err)
+	}
+	var found *Profile
+	for i := range profiles {
+		if profiles[i].ID == profileID {
+			found = &profiles[i]
+		}
+	}
+	if found == nil {
+		t.Fatalf("profile %d missing from list", profileID)
+	}
+	if found.Phone != "" || found.ExperienceLevel != "" || found.EmergencyContact != "" || found.MedicalConditions != "" {
+		t.Fatalf("expected empty optional fields, got %+v", *found)
+	}
+
+	byID, err := FetchByIDs(ctx, db, []int64{profileID})
+	if err != nil {
+		t.Fatalf("FetchByIDs() returned error: %v", err)
+	}
+	if byID[profileID].Email != email {
+		t.Fatalf("FetchByIDs() email = %q, want %q", byID[profileID].Email, email)
+	}
+}
+
+func TestParticipantNotesLifecycleStaysOutOfProfile(t *testing.T) {
+	db := openParticipantTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ensureParticipantTestSchema(t, ctx, db)
+
+	email := "notes-" + strconv.FormatInt(time.Now().UnixNano(), 10) + "@example.com"
+	var profileID int64
+	if err := db.QueryRow(ctx, `
+		INSERT INTO participant_profiles (full_name, email) VALUES ('Noted Jumper', $1) RETURNING id
+	`, email).Scan(&profileID); err != nil {
+		t.Fatalf("insert profile failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM participant_profiles WHERE id = $1`, profileID)
+
+	h := NewHandler(db)
+	router := chi.NewRouter()
+	router.Get("/profiles/{profileID}", h.getProfile)
+	router.Get("/profiles/{profileID}/notes", h.listNotes)
+	router.Post("/profiles/{profileID}/notes", h.createNote)
+	router.Delete("/profiles/{profileID}/notes/{noteID}", h.deleteNote)
+	base := "/profiles/" + strconv.FormatInt(profileID, 10)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, base+"/notes", strings.NewReader(`{"body":"Safety watch: hard openings"}`)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create note status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	var note Note
+	if err := json.NewDecoder(rec.Body).Decode(&note); err != nil {
+		t.Fatalf("decode note failed: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, base+"/notes", nil))
+	var notes []Note
+	if err := json.NewDecoder(rec.Body).Decode(&notes); err != nil || len(notes) != 1 || notes[0].Body != "Safety watch: hard openings" {
+		t.Fatalf("list notes = %+v, %v", notes, err)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, base, nil))
+	if strings.Contains(rec.Body.String(), "Safety watch") {
+		t.Fatalf("profile response leaked a staff note: %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, base+"/notes/"+strconv.FormatInt(note.ID, 10), nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("delete note status = %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/profiles/999999999/notes", strings.NewReader(`{"body":"orphan"}`)))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("create note for missing profile status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestProfileSearchUsesTrigramIndex(t *testing.T) {
+	db := openParticipantTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ensureParticipantTestSchema(t, ctx, db)
+
+	if _, err := db.Exec(ctx, `CREATE EXTENSION IF NOT EXISTS pg_trgm`); err != nil {
+		t.Skipf("pg_trgm unavailable: %v", err)
+	}
+	if _, err := db.Exec(ctx, `CREATE INDEX IF NOT EXISTS participant_profiles_full_name_trgm_idx ON participant_profiles USING GIN (full_name gin_trgm_ops)`); err != nil {
+		t.Fatalf("create index failed: %v", err)
+	}
+	if _, err := db.Exec(ctx, `CREATE INDEX IF NOT EXISTS participant_profiles_email_trgm_idx ON participant_profiles USING GIN (email gin_trgm_ops)`); err != nil {
+		t.Fatalf("create index failed: %v", err)
+	}
+
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("acquire connection failed: %v", err)
+	}
+	defer conn.Release()
+	// Small test tables are cheaper to scan, so rule that out to see whether
+	// the query shape can use the index at all.
+	if _, err := conn.Exec(ctx, `SET enable_seqscan = off`); err != nil {
+		t.Fatalf("disable seqscan failed: %v", err)
+	}
+	defer conn.Exec(context.Background(), `RESET enable_seqscan`)
+
+	rows, err := conn.Query(ctx, `EXPLAIN SELECT p.id FROM participant_profiles p WHERE p.full_name ILIKE $1 OR p.email ILIKE $1`, "%jumper%")
+	if err != nil {
+		t.Fatalf("explain failed: %v", err)
+	}
+	defer rows.Close()
+	var plan strings.Builder
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			t.Fatalf("scan plan failed: %v", err)
+		}
+		plan.WriteString(line + "\n")
+	}
+	if !strings.Contains(plan.String(), "_trgm_idx") {
+		t.Fatalf("search plan does not use a trigram index:\n%s", plan.String())
+	}
+}
+
+func openParticipantTestDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Skip("DATABASE_URL not set; skipping integration participant tests")
+	}
+	db, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatalf("connect db failed: %v", err)
+	}
+	return db
+}
+
+func ensureParticipantTestSchema(t *testing.T, ctx context.Context, db *pgxpool.Pool) {
+	t.Helper()
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS accounts (
+            id SERIAL PRIMARY KEY,
+            subject TEXT NOT NULL UNIQUE,
+            email TEXT NOT NULL,
+            full_name TEXT,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE TABLE IF NOT EXISTS roles (
+            name TEXT PRIMARY KEY,
+            description TEXT,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE TABLE IF NOT EXISTS account_roles (
+            account_id INTEGER NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+            role_name TEXT NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+            UNIQUE (account_id, role_name)
+        )`,
+		`CREATE TABLE IF NOT EXISTS participant_profiles (
+            id SERIAL PRIMARY KEY,
+            full_name TEXT NOT NULL,
+            email TEXT NOT NULL UNIQUE,
+            phone TEXT,
+            experience_level TEXT,
+            emergency_contact TEXT,
+            whatsapp TEXT,
+            instagram TEXT,
+            citizenship TEXT,
+            date_of_birth TEXT,
+            jumper BOOLEAN NOT NULL DEFAULT FALSE,
+            years_in_sport INTEGER,
+            jump_count INTEGER,
+            recent_jump_count INTEGER,
+            main_canopy TEXT,
+            wingload TEXT,
+            license TEXT,
+            roles TEXT[] NOT NULL DEFAULT ARRAY['Participant'],
+            ratings TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],
+            disciplines TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],
+            other_air_sports TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],
+            canopy_course TEXT,
+            landing_area_preference TEXT,
+            tshirt_size TEXT,
+            tshirt_gender TEXT,
+            account_roles TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],
+            dietary_restrictions TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],
+            medical_conditions TEXT,
+            medical_expertise TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],
+            hss_qualities TEXT[] NOT NULL DEFAULT ARRAY[]::TEXT[],
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`ALTER TABLE participant_profiles ADD COLUMN IF NOT EXISTS account_id INTEGER UNIQUE REFERENCES accounts(id) ON DELETE SET NULL`,
+		`CREATE TABLE IF NOT EXISTS participant_notes (
+            id SERIAL PRIMARY KEY,
+            participant_id INTEGER NOT NULL REFERENCES participant_profiles(id) ON DELETE CASCADE,
+            body TEXT NOT NULL,
+            author_account_id INTEGER REFERENCES accounts(id) ON DELETE SET NULL,
+            author_name TEXT,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE TABLE IF NOT EXISTS gear_assets (
+            id SERIAL PRIMARY KEY,
+            name TEXT NOT NULL,
+            serial_number TEXT NOT NULL UNIQUE,
+            status TEXT NOT NULL,
+            location TEXT,
+            inspected_at TIMESTAMPTZ,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE TABLE IF NOT EXISTS participant_gear (
+            gear_asset_id INTEGER PRIMARY KEY REFERENCES gear_assets(id) ON DELETE CASCADE,
+            participant_id INTEGER NOT NULL REFERENCES participant_profiles(id) ON DELETE CASCADE,
+            is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+            assigned_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE TABLE IF NOT EXISTS seasons (
+            id SERIAL PRIMARY KEY,
+            name TEXT NOT NULL,
+            starts_on DATE NOT NULL,
+            ends_on DATE,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE TABLE IF NOT EXISTS events (
+            id SERIAL PRIMARY KEY,
+            season_id INTEGER NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+            name TEXT NOT NULL,
+            starts_at TIMESTAMPTZ NOT NULL,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`ALTER TABLE events ADD COLUMN IF NOT EXISTS ends_at TIMESTAMPTZ`,
+		`CREATE TABLE IF NOT EXISTS manifests (
+            id SERIAL PRIMARY KEY,
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            load_number INTEGER NOT NULL,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE TABLE IF NOT EXISTS crew_assignments (
+            id SERIAL PRIMARY KEY,
+            manifest_id INTEGER NOT NULL REFERENCES manifests(id) ON DELETE CASCADE,
+            participant_id INTEGER NOT NULL REFERENCES participant_profiles(id) ON DELETE CASCADE,
+            role TEXT NOT NULL,
+            assigned_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(ctx, stmt); err != nil {
+			t.Fatalf("schema setup failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package participants
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestProfileWritesRejectUnknownFields(t *testing.T) {
+	router := chi.NewRouter()
+	h := NewHandler(nil)
+	router.Post("/profiles", h.createProfile)
+	router.Put("/profiles/{profileID}", h.updateProfile)
+
+	body := `{"full_name":"Kari","email":"kari@example.com","nickname":"K"}`
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/profiles", strings.NewReader(body)),
+		httptest.NewRequest(http.MethodPut, "/profiles/7", strings.NewReader(body)),
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s %s status = %d, want %d", req.Method, req.URL.Path, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package participants
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/innhopp/central/backend/auth"
+	"github.com/innhopp/central/backend/httpx"
+)
+
+const maxNoteLength = 4000
+
+// Note is a staff-only annotation on a participant profile. Notes live in
+// their own table so they are never part of a profile response.
+type Note struct {
+	ID              int64     `json:"id"`
+	ParticipantID   int64     `json:"participant_id"`
+	Body            string    `json:"body"`
+	AuthorAccountID *int64    `json:"author_account_id,omitempty"`
+	AuthorName      string    `json:"author_name"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func (h *Handler) listNotes(w http.ResponseWriter, r *http.Request) {
+	profileID, ok := parseProfileIDParam(w, r)
+	if !ok {
+		return
+	}
+	if !h.ensureProfileExists(w, r, profileID) {
+		return
+	}
+
+	rows, err := h.db.Query(r.Context(), `
+		SELECT id, participant_id, body, author_account_id, COALESCE(author_name, ''), created_at
+		FROM participant_notes
+		WHERE participant_id = $1
+		ORDER BY created_at DESC, id DESC`, profileID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to list notes")
+		return
+	}
+	defer rows.Close()
+
+	notes := []Note{}
+	for rows.Next() {
+		var n Note
+		if err := rows.Scan(&n.ID, &n.ParticipantID, &n.Body, &n.AuthorAccountID, &n.AuthorName, &n.CreatedAt); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to parse note")
+			return
+		}
+		notes = append(notes, n)
+	}
+	if err := rows.Err(); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to list notes")
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, notes)
+}
+
+func (h *Handler) createNote(w http.ResponseWriter, r *http.Request) {
+	profileID, ok := parseProfileIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var payload struct {
+		Body string `json:"body"`
+	}
+	if err := httpx.DecodeJSON(r, &payload); err != nil {
+		httpx.PayloadError(w, err, "invalid request payload")
+		return
+	}
+	body := strings.TrimSpace(payload.Body)
+	if body == "" {
+		httpx.Error(w, http.StatusBadRequest, "body is required")
+		return
+	}
+	if len(body) > maxNoteLength {
+		httpx.Error(w, http.StatusBadRequest, "body must be at most "+strconv.Itoa(maxNoteLength)+" characters")
+		return
+	}
+
+	var authorAccountID *int64
+	var authorName string
+	if claims := auth.FromContext(r.Context()); claims != nil {
+		if claims.AccountID > 0 {
+			id := claims.AccountID
+			authorAccountID = &id
+		}
+		authorName = strings.TrimSpace(claims.FullName)
+		if authorName == "" {
+			authorName = claims.Email
+		}
+	}
+
+	note := Note{ParticipantID: profileID, Body: body, AuthorAccountID: authorAccountID, AuthorName: authorName}
+	err := h.db.QueryRow(r.Context(), `
+		INSERT INTO participant_notes (participant_id, body, author_account_id, author_name)
+		SELECT p.id, $2, $3, NULLIF($4, '')
+		FROM participant_profiles p
+		WHERE p.id = $1
+		RETURNING id, created_at`,
+		profileID, body, authorAccountID, authorName,
+	).Scan(&note.ID, &note.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			httpx.Error(w, http.StatusNotFound, "participant not found")
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to create note")
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusCreated, note)
+}
+
+func (h *Handler) deleteNote(w http.ResponseWriter, r *http.Request) {
+	profileID, ok := parseProfileIDParam(w, r)
+	if !ok {
+		return
+	}
+	noteID, err := strconv.ParseInt(chi.URLParam(r, "noteID"), 10, 64)
+	if err != nil || noteID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid note id")
+		return
+	}
+
+	tag, err := h.db.Exec(r.Context(), `DELETE FROM participant_notes WHERE id = $1 AND participant_id = $2`, noteID, profileID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to delete note")
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		httpx.Error(w, http.StatusNotFound, "note not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func parseProfileIDParam(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	profileID, err := strconv.ParseInt(chi.URLParam(r, "profileID"), 10, 64)
+	if err != nil || profileID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid profile id")
+		return 0, false
+	}
+	return profileID, true
+}
+
+func (h *Handler) ensureProfileExists(w http.ResponseWriter, r *http.Request, profileID int64) bool {
+	var exists bool
+	if err := h.db.QueryRow(r.Context(), `SELECT EXISTS (SELECT 1 FROM participant_profiles WHERE id = $1)`, profileID).Scan(&exists); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load participant")
+		return false
+	}
+	if !exists {
+		httpx.Error(w, http.StatusNotFound, "participant not found")
+		return false
+	}
+	return true
+}
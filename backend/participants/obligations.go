@@ -0,0 +1,127 @@
+package participants
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/innhopp/central/backend/auth"
+	"github.com/innhopp/central/backend/httpx"
+	"github.com/innhopp/central/backend/internal/email"
+)
+
+// Obligation types returned by listOwnObligations.
+const (
+	ObligationEvent          = "event"
+	ObligationCrewAssignment = "crew_assignment"
+	ObligationDepositDue     = "deposit_due"
+	ObligationMainInvoiceDue = "main_invoice_due"
+)
+
+// Obligation is one thing expected of a participant. At is when an event or
+// load is scheduled, or when a payment is due; payment deadlines are dates
+// and come back as midnight UTC.
+type Obligation struct {
+	Type           string    `json:"type"`
+	At             time.Time `json:"at"`
+	EventID        int64     `json:"event_id"`
+	EventName      string    `json:"event_name"`
+	ManifestID     *int64    `json:"manifest_id,omitempty"`
+	LoadNumber     *int      `json:"load_number,omitempty"`
+	Role           string    `json:"role,omitempty"`
+	RegistrationID *int64    `json:"registration_id,omitempty"`
+}
+
+// obligationsQuery lists, for participant $1, the events they attend and the
+// loads they crew on events that have not ended, and the deposit and main
+// invoice deadlines of their live registrations that are still unpaid.
+// Overdue payments stay in the list until they are paid.
+const obligationsQuery = `
+	SELECT type, at, event_id, event_name, manifest_id, load_number, role, registration_id
+	FROM (
+		SELECT '` + ObligationEvent + `' AS type, e.starts_at AS at, e.id AS event_id, e.name AS event_name,
+		       NULL::BIGINT AS manifest_id, NULL::INTEGER AS load_number, '' AS role, NULL::BIGINT AS registration_id
+		FROM event_participants ep
+		JOIN events e ON e.id = ep.event_id
+		WHERE ep.participant_id = $1 AND COALESCE(e.ends_at, e.starts_at) >= NOW()
+		UNION ALL
+		SELECT '` + ObligationCrewAssignment + `', e.starts_at, e.id, e.name,
+		       m.id::BIGINT, m.load_number, ca.role, NULL::BIGINT
+		FROM crew_assignments ca
+		JOIN manifests m ON m.id = ca.manifest_id
+		JOIN events e ON e.id = m.event_id
+		WHERE ca.participant_id = $1 AND COALESCE(e.ends_at, e.starts_at) >= NOW()
+		UNION ALL
+		SELECT '` + ObligationDepositDue + `', r.deposit_due_at::TIMESTAMPTZ, e.id, e.name,
+		       NULL::BIGINT, NULL::INTEGER, '', r.id::BIGINT
+		FROM event_registrations r
+		JOIN events e ON e.id = r.event_id
+		WHERE r.participant_id = $1 AND r.cancelled_at IS NULL AND r.expired_at IS NULL
+		  AND r.deposit_due_at IS NOT NULL AND r.deposit_paid_at IS NULL
+		UNION ALL
+		SELECT '` + ObligationMainInvoiceDue + `', r.main_invoice_due_at::TIMESTAMPTZ, e.id, e.name,
+		       NULL::BIGINT, NULL::INTEGER, '', r.id::BIGINT
+		FROM event_registrations r
+		JOIN events e ON e.id = r.event_id
+		WHERE r.participant_id = $1 AND r.cancelled_at IS NULL AND r.expired_at IS NULL
+		  AND r.main_invoice_due_at IS NOT NULL AND r.main_invoice_paid_at IS NULL
+	) obligations
+	ORDER BY at, event_id, type, load_number NULLS FIRST`
+
+// listOwnObligations returns everything expected of the caller's participant
+// profile in one chronological list. The profile is found through the
+// account link, falling back to the login email as getOwnProfile does.
+func (h *Handler) listOwnObligations(w http.ResponseWriter, r *http.Request) {
+	claims := auth.FromContext(r.Context())
+	if claims == nil {
+		httpx.Error(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	normalizedEmail := email.Normalize(claims.Email)
+	if normalizedEmail == "" {
+		httpx.Error(w, http.StatusBadRequest, "email claim missing")
+		return
+	}
+
+	ctx := r.Context()
+	var profileID int64
+	err := h.db.QueryRow(ctx, `
+		SELECT id
+		FROM participant_profiles
+		WHERE ($1 > 0 AND account_id = $1) OR lower(email) = lower($2)
+		ORDER BY CASE WHEN $1 > 0 AND account_id = $1 THEN 0 ELSE 1 END, id ASC
+		LIMIT 1
+	`, claims.AccountID, normalizedEmail).Scan(&profileID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		httpx.Error(w, http.StatusNotFound, "participant profile not found")
+		return
+	}
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load participant profile")
+		return
+	}
+
+	rows, err := h.db.Query(ctx, obligationsQuery, profileID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to list obligations")
+		return
+	}
+	defer rows.Close()
+
+	obligations := make([]Obligation, 0)
+	for rows.Next() {
+		var o Obligation
+		if err := rows.Scan(&o.Type, &o.At, &o.EventID, &o.EventName, &o.ManifestID, &o.LoadNumber, &o.Role, &o.RegistrationID); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to parse obligations")
+			return
+		}
+		obligations = append(obligations, o)
+	}
+	if err := rows.Err(); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to list obligations")
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, obligations)
+}
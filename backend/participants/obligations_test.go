@@ -0,0 +1,15 @@
+package participants
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListOwnObligationsRequiresSession(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewHandler(nil).listOwnObligations(rec, httptest.NewRequest(http.MethodGet, "/profiles/me/obligations", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
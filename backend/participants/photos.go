@@ -0,0 +1,277 @@
+package participants
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/innhopp/central/backend/httpx"
+)
+
+const (
+	maxPhotoBytes     = 5 << 20
+	maxPhotoDimension = 4096
+	thumbnailSize     = 256
+	thumbnailQuality  = 80
+)
+
+var photoMimeTypes = map[string]string{
+	"jpeg": "image/jpeg",
+	"png":  "image/png",
+}
+
+type photoPayload struct {
+	MimeType string `json:"mime_type"`
+	Data     string `json:"data"`
+}
+
+// processedPhoto is a validated upload together with its thumbnail.
+type processedPhoto struct {
+	MimeType  string
+	Image     []byte
+	Thumbnail []byte
+	Width     int
+	Height    int
+}
+
+func (h *Handler) uploadPhoto(w http.ResponseWriter, r *http.Request) {
+	profileID, ok := parseProfileIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var payload photoPayload
+	if err := httpx.DecodeJSON(r, &payload); err != nil {
+		httpx.PayloadError(w, err, "invalid request payload")
+		return
+	}
+	raw, err := decodePhotoData(payload.Data)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	photo, err := processPhoto(raw)
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !h.ensureProfileExists(w, r, profileID) {
+		return
+	}
+	if _, err := h.db.Exec(r.Context(), `
+		INSERT INTO participant_photos (participant_id, mime_type, image, thumbnail, width, height, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (participant_id) DO UPDATE SET
+			mime_type = EXCLUDED.mime_type,
+			image = EXCLUDED.image,
+			thumbnail = EXCLUDED.thumbnail,
+			width = EXCLUDED.width,
+			height = EXCLUDED.height,
+			updated_at = NOW()`,
+		profileID, photo.MimeType, photo.Image, photo.Thumbnail, photo.Width, photo.Height,
+	); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to save photo")
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, map[string]any{
+		"photo_url": photoURL(profileID),
+		"width":     photo.Width,
+		"height":    photo.Height,
+	})
+}
+
+// getPhoto serves the stored photo, or its thumbnail with ?size=thumb.
+func (h *Handler) getPhoto(w http.ResponseWriter, r *http.Request) {
+	profileID, ok := parseProfileIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	column := "image"
+	if r.URL.Query().Get("size") == "thumb" {
+		column = "thumbnail"
+	}
+	var mimeType string
+	var data []byte
+	err := h.db.QueryRow(r.Context(), `SELECT `+column+`, mime_type FROM participant_photos WHERE participant_id = $1`, profileID).Scan(&data, &mimeType)
+	if errors.Is(err, pgx.ErrNoRows) {
+		httpx.Error(w, http.StatusNotFound, "photo not found")
+		return
+	}
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to load photo")
+		return
+	}
+	if column == "thumbnail" {
+		mimeType = "image/jpeg"
+	}
+
+	w.Header().Set("Content-Type", mimeType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+// setPhotoURLs fills PhotoURL on the profiles that have a photo. It is only
+// called from routes that require participant view access, so other
+// responses never reference a photo.
+func (h *Handler) setPhotoURLs(ctx context.Context, profiles ...*Profile) error {
+	if len(profiles) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(profiles))
+	for i, p := range profiles {
+		ids[i] = p.ID
+	}
+	rows, err := h.db.Query(ctx, `SELECT participant_id FROM participant_photos WHERE participant_id = ANY($1)`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	withPhoto := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		withPhoto[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, p := range profiles {
+		if withPhoto[p.ID] {
+			p.PhotoURL = photoURL(p.ID)
+		}
+	}
+	return nil
+}
+
+func photoURL(profileID int64) string {
+	return fmt.Sprintf("/api/participants/profiles/%d/photo", profileID)
+}
+
+// decodePhotoData accepts plain base64 or a data URL as produced by the
+// browser's FileReader.
+func decodePhotoData(data string) ([]byte, error) {
+	data = strings.TrimSpace(data)
+	if strings.HasPrefix(data, "data:") {
+		_, encoded, ok := strings.Cut(data, ",")
+		if !ok {
+			return nil, errors.New("invalid photo data")
+		}
+		data = encoded
+	}
+	if data == "" {
+		return nil, errors.New("photo data is required")
+	}
+	if base64.StdEncoding.DecodedLen(len(data)) > maxPhotoBytes+3 {
+		return nil, fmt.Errorf("photo must be at most %d MB", maxPhotoBytes>>20)
+	}
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, errors.New("invalid photo data")
+	}
+	if len(raw) > maxPhotoBytes {
+		return nil, fmt.Errorf("photo must be at most %d MB", maxPhotoBytes>>20)
+	}
+	return raw, nil
+}
+
+// processPhoto checks that raw is a JPEG or PNG within the dimension cap and
+// renders its thumbnail. The header is read before the full decode so that
+// oversized images are rejected without allocating their pixels.
+func processPhoto(raw []byte) (*processedPhoto, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err != nil {
+		return nil, errors.New("photo must be a JPEG or PNG image")
+	}
+	mimeType, ok := photoMimeTypes[format]
+	if !ok {
+		return nil, errors.New("photo must be a JPEG or PNG image")
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width > maxPhotoDimension || config.Height > maxPhotoDimension {
+		return nil, fmt.Errorf("photo must be at most %dx%d pixels", maxPhotoDimension, maxPhotoDimension)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		return nil, errors.New("photo must be a JPEG or PNG image")
+	}
+	var thumb bytes.Buffer
+	if err := jpeg.Encode(&thumb, thumbnail(img, thumbnailSize), &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, err
+	}
+
+	return &processedPhoto{
+		MimeType:  mimeType,
+		Image:     raw,
+		Thumbnail: thumb.Bytes(),
+		Width:     config.Width,
+		Height:    config.Height,
+	}, nil
+}
+
+// thumbnail scales img to fit within size x size, keeping its aspect ratio.
+// Each output pixel is the average of the source pixels it covers; images
+// already small enough are only flattened onto an opaque background.
+func thumbnail(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	dstW, dstH := srcW, srcH
+	if srcW > size || srcH > size {
+		if srcW >= srcH {
+			dstW, dstH = size, max(1, srcH*size/srcW)
+		} else {
+			dstW, dstH = max(1, srcW*size/srcH), size
+		}
+	}
+
+	// JPEG has no alpha channel, so transparent PNG areas become white.
+	src := image.NewRGBA(image.Rect(0, 0, srcW, srcH))
+	draw.Draw(src, src.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Over)
+	if dstW == srcW && dstH == srcH {
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0, y1 := y*srcH/dstH, max((y+1)*srcH/dstH, y*srcH/dstH+1)
+		for x := 0; x < dstW; x++ {
+			x0, x1 := x*srcW/dstW, max((x+1)*srcW/dstW, x*srcW/dstW+1)
+			var r, g, b, n int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					r += int(row[sx*4])
+					g += int(row[sx*4+1])
+					b += int(row[sx*4+2])
+					n++
+				}
+			}
+			i := y*dst.Stride + x*4
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = 0xff
+		}
+	}
+	return dst
+}
@@ -0,0 +1,69 @@
+package participants
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0x80
+	}
+	img.Set(0, 0, color.RGBA{R: 0xff, A: 0xff})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcessPhotoGeneratesBoundedJPEGThumbnail(t *testing.T) {
+	photo, err := processPhoto(encodePNG(t, 1024, 512))
+	if err != nil {
+		t.Fatalf("processPhoto: %v", err)
+	}
+	if photo.MimeType != "image/png" || photo.Width != 1024 || photo.Height != 512 {
+		t.Fatalf("photo = %s %dx%d, want image/png 1024x512", photo.MimeType, photo.Width, photo.Height)
+	}
+
+	thumb, err := jpeg.Decode(bytes.NewReader(photo.Thumbnail))
+	if err != nil {
+		t.Fatalf("thumbnail is not a JPEG: %v", err)
+	}
+	if got := thumb.Bounds().Size(); got != image.Pt(thumbnailSize, thumbnailSize/2) {
+		t.Fatalf("thumbnail size = %v, want %dx%d", got, thumbnailSize, thumbnailSize/2)
+	}
+}
+
+func TestProcessPhotoRejectsNonImagesAndOversizedImages(t *testing.T) {
+	if _, err := processPhoto([]byte("GIF89a not really")); err == nil {
+		t.Fatal("expected non-image upload to be rejected")
+	}
+	if _, err := processPhoto(encodePNG(t, maxPhotoDimension+1, 1)); err == nil {
+		t.Fatal("expected oversized image to be rejected")
+	}
+}
+
+func TestDecodePhotoDataAcceptsDataURLs(t *testing.T) {
+	raw := encodePNG(t, 2, 2)
+	encoded := base64.StdEncoding.EncodeToString(raw)
+	for _, data := range []string{encoded, "data:image/png;base64," + encoded} {
+		got, err := decodePhotoData(data)
+		if err != nil {
+			t.Fatalf("decodePhotoData(%.30q): %v", data, err)
+		}
+		if !bytes.Equal(got, raw) {
+			t.Fatalf("decodePhotoData(%.30q) returned different bytes", data)
+		}
+	}
+	if _, err := decodePhotoData("not base64!"); err == nil {
+		t.Fatal("expected invalid base64 to be rejected")
+	}
+}
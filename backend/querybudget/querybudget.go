@@ -0,0 +1,128 @@
+// Package querybudget caps how many database queries a single API request may
+// run. A Tracer installed on the pgx connection config counts every query
+// issued with a request's context; once the count passes the budget further
+// queries fail before reaching the database and the response is replaced with
+// a 429. Batch sub-requests share their parent's context, so a whole batch is
+// held to one budget.
+package querybudget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/innhopp/central/backend/httpx"
+)
+
+// DefaultLimit is used when QUERY_BUDGET is unset. It leaves ample room for a
+// full batch of list requests.
+const DefaultLimit = 500
+
+// ErrExceeded is the cancellation cause of queries issued past the budget.
+var ErrExceeded = errors.New("query budget exceeded")
+
+type contextKey struct{}
+
+type counter struct {
+	limit int64
+	count atomic.Int64
+}
+
+func (c *counter) exceeded() bool {
+	return c.count.Load() > c.limit
+}
+
+func fromContext(ctx context.Context) *counter {
+	c, _ := ctx.Value(contextKey{}).(*counter)
+	return c
+}
+
+// Count returns how many queries have run under ctx's budget so far, or 0 when
+// ctx carries none.
+func Count(ctx context.Context) int {
+	if c := fromContext(ctx); c != nil {
+		return int(min(c.count.Load(), c.limit+1))
+	}
+	return 0
+}
+
+// Tracer counts queries against the budget in their context. Queries run
+// without one, such as startup migrations and background workers, are not
+// limited.
+type Tracer struct{}
+
+// TraceQueryStart counts the query and, once the budget is spent, hands pgx a
+// cancelled context so the query is abandoned before it is sent.
+func (Tracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	c := fromContext(ctx)
+	if c == nil || c.count.Add(1) <= c.limit {
+		return ctx
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	cancel(ErrExceeded)
+	return ctx
+}
+
+// TraceQueryEnd is a no-op.
+func (Tracer) TraceQueryEnd(context.Context, *pgx.Conn, pgx.TraceQueryEndData) {}
+
+// Middleware gives each request a budget of limit queries. Requests that
+// already carry one, such as batch sub-requests, keep it. A response written
+// after the budget ran out is replaced with 429.
+func Middleware(limit int) func(http.Handler) http.Handler {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c := fromContext(r.Context())
+			if c == nil {
+				c = &counter{limit: int64(limit)}
+				r = r.WithContext(context.WithValue(r.Context(), contextKey{}, c))
+			}
+			next.ServeHTTP(&budgetWriter{ResponseWriter: w, counter: c, request: r}, r)
+		})
+	}
+}
+
+// budgetWriter swaps the handler's response for a 429 when the budget ran out
+// before the response was started. Handlers report the failed query as a 500,
+// which would hide the cause from the client.
+type budgetWriter struct {
+	http.ResponseWriter
+	counter     *counter
+	request     *http.Request
+	wroteHeader bool
+	rejected    bool
+}
+
+func (b *budgetWriter) WriteHeader(status int) {
+	if b.wroteHeader {
+		return
+	}
+	b.wroteHeader = true
+	if b.counter.exceeded() {
+		b.rejected = true
+		log.Printf("querybudget: rejected %s %s: more than %d queries", b.request.Method, b.request.URL.Path, b.counter.limit)
+		b.Header().Del("Content-Length")
+		httpx.Error(b.ResponseWriter, http.StatusTooManyRequests,
+			fmt.Sprintf("request exceeded the limit of %d database queries; narrow it or split it into smaller requests", b.counter.limit))
+		return
+	}
+	b.ResponseWriter.WriteHeader(status)
+}
+
+func (b *budgetWriter) Write(p []byte) (int, error) {
+	if !b.wroteHeader {
+		b.WriteHeader(http.StatusOK)
+	}
+	if b.rejected {
+		return len(p), nil
+	}
+	return b.ResponseWriter.Write(p)
+}
@@ -0,0 +1,77 @@
+package querybudget
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// queryingHandler simulates a handler that runs n queries and reports a
+// failed one as a 500, the way the API handlers do.
+func queryingHandler(n int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < n; i++ {
+			ctx := Tracer{}.TraceQueryStart(r.Context(), nil, pgx.TraceQueryStartData{})
+			if ctx.Err() != nil {
+				if !errors.Is(context.Cause(ctx), ErrExceeded) {
+					w.WriteHeader(http.StatusTeapot)
+					return
+				}
+				http.Error(w, "failed to list events", http.StatusInternalServerError)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestMiddlewareRejectsRequestsOverBudget(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Middleware(3)(queryingHandler(3)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/events/events", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status within budget = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	rec = httptest.NewRecorder()
+	Middleware(3)(queryingHandler(4)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/events/events", nil))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status over budget = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if !strings.Contains(rec.Body.String(), "limit of 3 database queries") {
+		t.Fatalf("body = %q, want budget message", rec.Body.String())
+	}
+}
+
+func TestNestedRequestsShareTheOuterBudget(t *testing.T) {
+	budget := Middleware(5)
+	var counts []int
+	outer := budget(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 2; i++ {
+			sub := httptest.NewRequest(http.MethodGet, "/api/events/events", nil).WithContext(r.Context())
+			budget(queryingHandler(2)).ServeHTTP(httptest.NewRecorder(), sub)
+			counts = append(counts, Count(r.Context()))
+		}
+		budget(queryingHandler(2)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(r.Context()))
+	}))
+
+	rec := httptest.NewRecorder()
+	outer.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/batch", nil))
+	if len(counts) != 2 || counts[0] != 2 || counts[1] != 4 {
+		t.Fatalf("counts after sub-requests = %v, want [2 4]", counts)
+	}
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+}
+
+func TestTracerIgnoresContextsWithoutBudget(t *testing.T) {
+	ctx := Tracer{}.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{})
+	if ctx.Err() != nil || Count(ctx) != 0 {
+		t.Fatalf("context without budget was limited: err=%v count=%d", ctx.Err(), Count(ctx))
+	}
+}
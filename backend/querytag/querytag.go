@@ -0,0 +1,86 @@
+// Package querytag sets the Postgres application_name of a connection to the
+// ID of the API request running a query, so a slow query in the database logs
+// or pg_stat_activity can be matched to the request that issued it. It costs
+// an extra round trip whenever a connection changes hands between requests,
+// so it is off unless DB_TAG_REQUESTS is set.
+package querytag
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxNameLength is the longest application_name Postgres keeps; longer values
+// are truncated by the server, which would make the name never match.
+const maxNameLength = 63
+
+type skipKey struct{}
+
+// Tracer tags connections before each query and then hands the query to Next.
+// BaseName is the application_name used for queries outside a request.
+type Tracer struct {
+	BaseName string
+	Next     pgx.QueryTracer
+}
+
+// TraceQueryStart points the connection's application_name at the request in
+// ctx, or back at BaseName when there is none. The server reports the current
+// value after every change, so a connection already carrying the right name
+// costs nothing.
+func (t Tracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if ctx.Value(skipKey{}) != nil {
+		return ctx
+	}
+	if t.Next != nil {
+		ctx = t.Next.TraceQueryStart(ctx, conn, data)
+	}
+	if conn == nil || ctx.Err() != nil {
+		return ctx
+	}
+	name := ApplicationName(t.BaseName, middleware.GetReqID(ctx))
+	if conn.PgConn().ParameterStatus("application_name") == name {
+		return ctx
+	}
+	// A failed tag must not fail the query; inside an aborted transaction the
+	// query reports the real error itself.
+	_, _ = conn.Exec(context.WithValue(ctx, skipKey{}, true), "SELECT set_config('application_name', $1, false)", name)
+	return ctx
+}
+
+// TraceQueryEnd passes the end of the query on to Next.
+func (t Tracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	if ctx.Value(skipKey{}) != nil {
+		return
+	}
+	if t.Next != nil {
+		t.Next.TraceQueryEnd(ctx, conn, data)
+	}
+}
+
+// ApplicationName returns the application_name for a query run by requestID:
+// base followed by "req=" and the ID, or base alone without one. Request IDs
+// may come from the client, so anything but letters, digits, '-', '_' and
+// '.' is dropped, and the result is cut to what Postgres keeps.
+func ApplicationName(base, requestID string) string {
+	id := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return -1
+	}, requestID)
+	if id == "" {
+		return base
+	}
+	name := "req=" + id
+	if base != "" {
+		name = base + " " + name
+	}
+	if len(name) > maxNameLength {
+		name = name[:maxNameLength]
+	}
+	return name
+}
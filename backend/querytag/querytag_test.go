@@ -0,0 +1,57 @@
+package querytag
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestApplicationName(t *testing.T) {
+	cases := []struct {
+		base, requestID, want string
+	}{
+		{"innhopp", "", "innhopp"},
+		{"innhopp", "3f2a9c", "innhopp req=3f2a9c"},
+		{"", "3f2a9c", "req=3f2a9c"},
+		{"innhopp", "abc'; DROP TABLE events;--", "innhopp req=abcDROPTABLEevents--"},
+		{"innhopp", "ünïcode", "innhopp req=ncode"},
+		{"innhopp", strings.Repeat("a", 80), "innhopp req=" + strings.Repeat("a", maxNameLength-len("innhopp req="))},
+	}
+	for _, c := range cases {
+		if got := ApplicationName(c.base, c.requestID); got != c.want {
+			t.Errorf("ApplicationName(%q, %q) = %q, want %q", c.base, c.requestID, got, c.want)
+		}
+	}
+}
+
+type recordingTracer struct {
+	starts, ends int
+}
+
+func (r *recordingTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	r.starts++
+	return ctx
+}
+
+func (r *recordingTracer) TraceQueryEnd(context.Context, *pgx.Conn, pgx.TraceQueryEndData) {
+	r.ends++
+}
+
+func TestTracerSkipsItsOwnTaggingQuery(t *testing.T) {
+	next := &recordingTracer{}
+	tracer := Tracer{BaseName: "innhopp", Next: next}
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+	if next.starts != 1 || next.ends != 1 {
+		t.Fatalf("next saw %d starts and %d ends, want 1 and 1", next.starts, next.ends)
+	}
+
+	tagging := context.WithValue(context.Background(), skipKey{}, true)
+	tracer.TraceQueryEnd(tracer.TraceQueryStart(tagging, nil, pgx.TraceQueryStartData{}), nil, pgx.TraceQueryEndData{})
+	if next.starts != 1 || next.ends != 1 {
+		t.Fatalf("tagging query reached next: %d starts and %d ends", next.starts, next.ends)
+	}
+}
@@ -0,0 +1,196 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/innhopp/central/backend/httpx"
+)
+
+// maxBulkAssignments caps the number of entries in one bulk request.
+const maxBulkAssignments = 100
+
+// Reasons reported for bulk entries that were not assigned.
+const (
+	rejectDuplicate          = "duplicate"
+	rejectOverCapacity       = "over_capacity"
+	rejectUnknownParticipant = "unknown_participant"
+	rejectInvalidRole        = "invalid_role"
+)
+
+type bulkAssignmentEntry struct {
+	ParticipantID int64  `json:"participant_id"`
+	Role          string `json:"role"`
+}
+
+type bulkAssignmentRejection struct {
+	ParticipantID int64  `json:"participant_id"`
+	Role          string `json:"role"`
+	Reason        string `json:"reason"`
+}
+
+// bulkAssignmentResult lists the assignments that now exist for the
+// submitted entries and the entries that were turned away. Entries that were
+// already assigned with the same role are reported as assigned, so a retried
+// request returns the same result instead of a wall of duplicates.
+type bulkAssignmentResult struct {
+	Assigned []CrewAssignment          `json:"assigned"`
+	Rejected []bulkAssignmentRejection `json:"rejected"`
+}
+
+// crewCapacity locks the manifest row and reports how many crew it may hold
+// and how many are assigned. A nil limit means the manifest has no staff slot
+// limit. Callers must hold tx until their inserts are done so concurrent
+// assignments cannot both pass the check.
+func crewCapacity(ctx context.Context, tx pgx.Tx, manifestID int64) (limit *int, assigned int, err error) {
+	if err = tx.QueryRow(ctx, `SELECT staff_slots FROM manifests WHERE id = $1 FOR UPDATE`, manifestID).Scan(&limit); err != nil {
+		return nil, 0, err
+	}
+	if err = tx.QueryRow(ctx, `SELECT COUNT(*) FROM crew_assignments WHERE manifest_id = $1`, manifestID).Scan(&assigned); err != nil {
+		return nil, 0, err
+	}
+	return limit, assigned, nil
+}
+
+func (h *Handler) bulkAssignCrew(w http.ResponseWriter, r *http.Request) {
+	manifestID, err := strconv.ParseInt(chi.URLParam(r, "manifestID"), 10, 64)
+	if err != nil || manifestID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid manifest id")
+		return
+	}
+	var payload struct {
+		Assignments []bulkAssignmentEntry `json:"assignments"`
+	}
+	if err := httpx.DecodeJSON(r, &payload); err != nil {
+		httpx.PayloadError(w, err, "invalid request payload")
+		return
+	}
+	if len(payload.Assignments) == 0 {
+		httpx.Error(w, http.StatusBadRequest, "at least one assignment is required")
+		return
+	}
+	if len(payload.Assignments) > maxBulkAssignments {
+		httpx.Error(w, http.StatusBadRequest, "at most "+strconv.Itoa(maxBulkAssignments)+" assignments are allowed")
+		return
+	}
+
+	ctx := r.Context()
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to assign crew")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	limit, assigned, err := crewCapacity(ctx, tx, manifestID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			httpx.Error(w, http.StatusNotFound, "manifest not found")
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to assign crew")
+		return
+	}
+
+	existing, err := manifestCrew(ctx, tx, manifestID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to assign crew")
+		return
+	}
+
+	result := bulkAssignmentResult{Assigned: []CrewAssignment{}, Rejected: []bulkAssignmentRejection{}}
+	seen := make(map[int64]bool, len(payload.Assignments))
+	for _, entry := range payload.Assignments {
+		role := strings.TrimSpace(entry.Role)
+		reject := func(reason string) {
+			result.Rejected = append(result.Rejected, bulkAssignmentRejection{ParticipantID: entry.ParticipantID, Role: role, Reason: reason})
+		}
+		switch {
+		case role == "":
+			reject(rejectInvalidRole)
+			continue
+		case entry.ParticipantID <= 0:
+			reject(rejectUnknownParticipant)
+			continue
+		case seen[entry.ParticipantID]:
+			reject(rejectDuplicate)
+			continue
+		}
+		seen[entry.ParticipantID] = true
+
+		if current, ok := existing[entry.ParticipantID]; ok {
+			if current.Role == role {
+				result.Assigned = append(result.Assigned, current)
+			} else {
+				reject(rejectDuplicate)
+			}
+			continue
+		}
+		if limit != nil && assigned >= *limit {
+			reject(rejectOverCapacity)
+			continue
+		}
+
+		assignment := CrewAssignment{ManifestID: manifestID, ParticipantID: entry.ParticipantID, Role: role}
+		err := tx.QueryRow(ctx,
+			`WITH participant AS (
+                SELECT id, full_name FROM participant_profiles WHERE id = $2
+            ), inserted AS (
+                INSERT INTO crew_assignments (manifest_id, participant_id, role)
+                SELECT $1, participant.id, $3 FROM participant
+                RETURNING id, participant_id, assigned_at
+            )
+            SELECT inserted.id, inserted.assigned_at, participant.full_name
+            FROM inserted
+            JOIN participant ON participant.id = inserted.participant_id`,
+			manifestID, entry.ParticipantID, role,
+		).Scan(&assignment.ID, &assignment.AssignedAt, &assignment.ParticipantName)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				reject(rejectUnknownParticipant)
+				continue
+			}
+			log.Printf("rbac.bulkAssignCrew manifest=%d participant=%d failed: %v", manifestID, entry.ParticipantID, err)
+			httpx.Error(w, http.StatusInternalServerError, "failed to assign crew")
+			return
+		}
+		assigned++
+		result.Assigned = append(result.Assigned, assignment)
+	}
+
+	if err := httpx.FinishTx(ctx, w, r, tx, http.StatusOK, result); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to assign crew")
+	}
+}
+
+// manifestCrew returns the manifest's current crew keyed by participant.
+func manifestCrew(ctx context.Context, tx pgx.Tx, manifestID int64) (map[int64]CrewAssignment, error) {
+	rows, err := tx.Query(ctx, `SELECT ca.id, ca.manifest_id, ca.participant_id, pp.full_name, ca.role, ca.assigned_at
+        FROM crew_assignments ca
+        JOIN participant_profiles pp ON pp.id = ca.participant_id
+        WHERE ca.manifest_id = $1
+        ORDER BY ca.id`, manifestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	crew := make(map[int64]CrewAssignment)
+	for rows.Next() {
+		var ca CrewAssignment
+		if err := rows.Scan(&ca.ID, &ca.ManifestID, &ca.ParticipantID, &ca.ParticipantName, &ca.Role, &ca.AssignedAt); err != nil {
+			return nil, err
+		}
+		if _, ok := crew[ca.ParticipantID]; !ok {
+			crew[ca.ParticipantID] = ca
+		}
+	}
+	return crew, rows.Err()
+}
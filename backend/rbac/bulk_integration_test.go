@@ -0,0 +1,118 @@
+package rbac
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestBulkAssignCrewReportsRejectionsAndFillsSlots(t *testing.T) {
+	db := openRBACTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ensureRBACTestSchema(t, ctx, db)
+
+	var seasonID, eventID, manifestID int64
+	if err := db.QueryRow(ctx, `INSERT INTO seasons (name, starts_on) VALUES ('Bulk Season', CURRENT_DATE) RETURNING id`).Scan(&seasonID); err != nil {
+		t.Fatalf("insert season failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM seasons WHERE id = $1`, seasonID)
+	if err := db.QueryRow(ctx, `INSERT INTO events (season_id, name, starts_at) VALUES ($1, 'Bulk Event', NOW()) RETURNING id`, seasonID).Scan(&eventID); err != nil {
+		t.Fatalf("insert event failed: %v", err)
+	}
+	if err := db.QueryRow(ctx, `INSERT INTO manifests (event_id, load_number, staff_slots) VALUES ($1, 1, 4) RETURNING id`, eventID).Scan(&manifestID); err != nil {
+		t.Fatalf("insert manifest failed: %v", err)
+	}
+	// a and f are already crew; c and d fill the remaining two slots.
+	names := []string{"a", "b", "c", "d", "e", "f"}
+	ids := make(map[string]int64, len(names))
+	for _, name := range names {
+		email := "crew-bulk-" + name + "-" + strconv.FormatInt(time.Now().UnixNano(), 10) + "@example.com"
+		var id int64
+		if err := db.QueryRow(ctx, `INSERT INTO participant_profiles (full_name, email) VALUES ($1, $2) RETURNING id`, "Bulk "+name, email).Scan(&id); err != nil {
+			t.Fatalf("insert participant failed: %v", err)
+		}
+		defer db.Exec(context.Background(), `DELETE FROM participant_profiles WHERE id = $1`, id)
+		ids[name] = id
+	}
+	for name, role := range map[string]string{"a": "Jump Master", "f": "Driver"} {
+		if _, err := db.Exec(ctx, `INSERT INTO crew_assignments (manifest_id, participant_id, role) VALUES ($1, $2, $3)`, manifestID, ids[name], role); err != nil {
+			t.Fatalf("insert crew assignment failed: %v", err)
+		}
+	}
+	missingID := ids["f"] + 1000000
+
+	entries := []bulkAssignmentEntry{
+		{ParticipantID: ids["a"], Role: "Jump Master"},
+		{ParticipantID: ids["f"], Role: "Jump Master"},
+		{ParticipantID: ids["b"], Role: " "},
+		{ParticipantID: 0, Role: "Driver"},
+		{ParticipantID: missingID, Role: "Driver"},
+		{ParticipantID: ids["c"], Role: "Driver"},
+		{ParticipantID: ids["c"], Role: "Ground Crew"},
+		{ParticipantID: ids["d"], Role: "Ground Crew"},
+		{ParticipantID: ids["e"], Role: "Driver"},
+	}
+	body, err := json.Marshal(map[string]any{"assignments": entries})
+	if err != nil {
+		t.Fatalf("encode payload failed: %v", err)
+	}
+	router := chi.NewRouter()
+	router.Post("/manifests/{manifestID}/crew/bulk", NewHandler(db).bulkAssignCrew)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/manifests/"+strconv.FormatInt(manifestID, 10)+"/crew/bulk", strings.NewReader(string(body))))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("bulk status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	var result bulkAssignmentResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decode result failed: %v", err)
+	}
+
+	var assigned []string
+	for _, a := range result.Assigned {
+		assigned = append(assigned, fmt.Sprintf("%d:%s", a.ParticipantID, a.Role))
+	}
+	wantAssigned := []string{
+		fmt.Sprintf("%d:Jump Master", ids["a"]),
+		fmt.Sprintf("%d:Driver", ids["c"]),
+		fmt.Sprintf("%d:Ground Crew", ids["d"]),
+	}
+	if strings.Join(assigned, ",") != strings.Join(wantAssigned, ",") {
+		t.Fatalf("assigned = %v, want %v", assigned, wantAssigned)
+	}
+
+	var rejected []string
+	for _, r := range result.Rejected {
+		rejected = append(rejected, fmt.Sprintf("%d:%s", r.ParticipantID, r.Reason))
+	}
+	wantRejected := []string{
+		fmt.Sprintf("%d:%s", ids["f"], rejectDuplicate),
+		fmt.Sprintf("%d:%s", ids["b"], rejectInvalidRole),
+		fmt.Sprintf("0:%s", rejectUnknownParticipant),
+		fmt.Sprintf("%d:%s", missingID, rejectUnknownParticipant),
+		fmt.Sprintf("%d:%s", ids["c"], rejectDuplicate),
+		fmt.Sprintf("%d:%s", ids["e"], rejectOverCapacity),
+	}
+	if strings.Join(rejected, ",") != strings.Join(wantRejected, ",") {
+		t.Fatalf("rejected = %v, want %v", rejected, wantRejected)
+	}
+
+	var crew int
+	if err := db.QueryRow(ctx, `SELECT COUNT(*) FROM crew_assignments WHERE manifest_id = $1`, manifestID).Scan(&crew); err != nil {
+		t.Fatalf("count assignments failed: %v", err)
+	}
+	if crew != 4 {
+		t.Fatalf("crew on manifest = %d, want the 4 staff slots filled", crew)
+	}
+}
@@ -3,6 +3,7 @@ package rbac
 import (
 	"net/http"
 
+	"github.com/innhopp/central/backend/adminaudit"
 	"github.com/innhopp/central/backend/httpx"
 )
 
@@ -12,6 +13,7 @@ type RoleResolver func(r *http.Request) []Role
 // Enforcer coordinates RBAC evaluation for HTTP handlers.
 type Enforcer struct {
 	resolve RoleResolver
+	admin   *adminaudit.Guard
 }
 
 // NewEnforcer constructs an RBAC enforcer with the provided resolver.
@@ -19,10 +21,24 @@ func NewEnforcer(resolver RoleResolver) *Enforcer {
 	return &Enforcer{resolve: resolver}
 }
 
+// SetAdminGuard installs the guard used by AdminAction.
+func (e *Enforcer) SetAdminGuard(guard *adminaudit.Guard) {
+	e.admin = guard
+}
+
+// AdminAction rate-limits and audits a sensitive admin route. Use it after
+// Authorize. Without a guard it does nothing.
+func (e *Enforcer) AdminAction(name string) func(http.Handler) http.Handler {
+	return e.admin.Action(name)
+}
+
 // Authorize ensures the caller has one of the roles mapped to the supplied
 // permission. If no user is present the request is rejected with 401.
 func (e *Enforcer) Authorize(permission Permission) func(http.Handler) http.Handler {
-	allowed := RoleMatrix[permission]
+	allowed := make(map[Role]struct{}, len(RoleMatrix[permission]))
+	for _, role := range RoleMatrix[permission] {
+		allowed[role] = struct{}{}
+	}
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			roles := e.resolve(r)
@@ -41,16 +57,24 @@ func (e *Enforcer) Authorize(permission Permission) func(http.Handler) http.Hand
 	}
 }
 
-func hasIntersection(userRoles, allowed []Role) bool {
-	if len(userRoles) == 0 || len(allowed) == 0 {
-		return false
+// Allows reports whether the caller holds one of the roles mapped to
+// permission. Handlers use it to trim responses for callers who passed a
+// weaker route check.
+func (e *Enforcer) Allows(r *http.Request, permission Permission) bool {
+	roles := e.resolve(r)
+	for _, role := range roles {
+		for _, allowed := range RoleMatrix[permission] {
+			if role == allowed {
+				return true
+			}
+		}
 	}
-	roleSet := make(map[Role]struct{}, len(userRoles))
+	return false
+}
+
+func hasIntersection(userRoles []Role, allowed map[Role]struct{}) bool {
 	for _, role := range userRoles {
-		roleSet[role] = struct{}{}
-	}
-	for _, required := range allowed {
-		if _, ok := roleSet[required]; ok {
+		if _, ok := allowed[role]; ok {
 			return true
 		}
 	}
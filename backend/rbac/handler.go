@@ -1,31 +1,62 @@
 package rbac
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/innhopp/central/backend/httpx"
+	"github.com/innhopp/central/backend/internal/clock"
 )
 
 // Handler exposes crew assignment operations.
 type Handler struct {
-	db *pgxpool.Pool
+	db    *pgxpool.Pool
+	roles *rolesCache
 }
 
 // NewHandler creates an RBAC handler.
 func NewHandler(db *pgxpool.Pool) *Handler {
-	return &Handler{db: db}
+	return &Handler{db: db, roles: &rolesCache{ttl: DefaultRolesCacheTTL, clock: clock.Real{}}}
+}
+
+// SetClock replaces the time source the roles cache expires against.
+func (h *Handler) SetClock(c clock.Clock) {
+	if c == nil {
+		c = clock.Real{}
+	}
+	h.roles.mu.Lock()
+	defer h.roles.mu.Unlock()
+	h.roles.clock = c
+}
+
+// SetRolesCacheTTL changes how long the roles catalog is cached. A zero TTL
+// disables caching.
+func (h *Handler) SetRolesCacheTTL(ttl time.Duration) {
+	h.roles.mu.Lock()
+	defer h.roles.mu.Unlock()
+	h.roles.ttl = ttl
+	h.roles.roles = nil
 }
 
 // Routes registers crew assignment routes.
 func (h *Handler) Routes(enforcer *Enforcer) chi.Router {
 	r := chi.NewRouter()
+	r.With(enforcer.Authorize(PermissionViewSession)).Get("/roles", h.listRoles)
 	r.With(enforcer.Authorize(PermissionViewCrewAssignments)).Get("/crew-assignments", h.listAssignments)
 	r.With(enforcer.Authorize(PermissionManageCrewAssignments)).Post("/crew-assignments", h.createAssignment)
+	r.With(enforcer.Authorize(PermissionManageCrewAssignments)).Delete("/crew-assignments/{assignmentID}", h.deleteAssignment)
+	r.With(enforcer.Authorize(PermissionManageCrewAssignments)).Post("/manifests/{manifestID}/crew/bulk", h.bulkAssignCrew)
+	r.With(enforcer.Authorize(PermissionViewCrewAssignments)).Get("/manifests/{manifestID}/crew/suggestions", h.suggestCrew)
 	return r
 }
 
@@ -38,18 +69,66 @@ type CrewAssignment struct {
 	AssignedAt      time.Time `json:"assigned_at"`
 }
 
+func (h *Handler) listRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.roles.get(r.Context(), h.db)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to list roles")
+		return
+	}
+	httpx.WriteJSON(w, http.StatusOK, roles)
+}
+
+const (
+	defaultAssignmentPageSize = 50
+	maxAssignmentPageSize     = 200
+)
+
 func (h *Handler) listAssignments(w http.ResponseWriter, r *http.Request) {
+	limit := defaultAssignmentPageSize
+	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			httpx.Error(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = min(parsed, maxAssignmentPageSize)
+	}
+
+	var afterAssignedAt *time.Time
+	var afterID int64
+	if raw := strings.TrimSpace(r.URL.Query().Get("cursor")); raw != "" {
+		assignedAt, id, err := decodeAssignmentCursor(raw)
+		if err != nil {
+			httpx.Error(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+		afterAssignedAt, afterID = &assignedAt, id
+	}
+
+	var manifestID *int64
+	if raw := strings.TrimSpace(r.URL.Query().Get("manifest_id")); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || parsed <= 0 {
+			httpx.Error(w, http.StatusBadRequest, "manifest_id must be a positive integer")
+			return
+		}
+		manifestID = &parsed
+	}
+
 	rows, err := h.db.Query(r.Context(), `SELECT ca.id, ca.manifest_id, ca.participant_id, pp.full_name, ca.role, ca.assigned_at
         FROM crew_assignments ca
         JOIN participant_profiles pp ON pp.id = ca.participant_id
-        ORDER BY ca.assigned_at DESC`)
+        WHERE ($1::timestamptz IS NULL OR (ca.assigned_at, ca.id) < ($1::timestamptz, $2::integer))
+          AND ($4::integer IS NULL OR ca.manifest_id = $4)
+        ORDER BY ca.assigned_at DESC, ca.id DESC
+        LIMIT $3`, afterAssignedAt, afterID, limit+1, manifestID)
 	if err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to list crew assignments")
 		return
 	}
 	defer rows.Close()
 
-	var assignments []CrewAssignment
+	assignments := make([]CrewAssignment, 0, limit)
 	for rows.Next() {
 		var ca CrewAssignment
 		if err := rows.Scan(&ca.ID, &ca.ManifestID, &ca.ParticipantID, &ca.ParticipantName, &ca.Role, &ca.AssignedAt); err != nil {
@@ -58,8 +137,64 @@ func (h *Handler) listAssignments(w http.ResponseWriter, r *http.Request) {
 		}
 		assignments = append(assignments, ca)
 	}
+	if err := rows.Err(); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to list crew assignments")
+		return
+	}
+
+	// The extra row only tells whether another page follows; its cursor is
+	// passed back as ?cursor= to fetch it.
+	page := httpx.CursorPage{Limit: limit}
+	if len(assignments) > limit {
+		assignments = assignments[:limit]
+		last := assignments[limit-1]
+		page.NextCursor = encodeAssignmentCursor(last.AssignedAt, last.ID)
+	}
 
-	httpx.WriteJSON(w, http.StatusOK, assignments)
+	httpx.WriteCursorPage(w, r, http.StatusOK, assignments, page)
+}
+
+func (h *Handler) deleteAssignment(w http.ResponseWriter, r *http.Request) {
+	assignmentID, err := strconv.ParseInt(chi.URLParam(r, "assignmentID"), 10, 64)
+	if err != nil || assignmentID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid crew assignment id")
+		return
+	}
+	tag, err := h.db.Exec(r.Context(), `DELETE FROM crew_assignments WHERE id = $1`, assignmentID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to delete crew assignment")
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		httpx.Error(w, http.StatusNotFound, "crew assignment not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func encodeAssignmentCursor(assignedAt time.Time, id int64) string {
+	raw := assignedAt.UTC().Format(time.RFC3339Nano) + "|" + strconv.FormatInt(id, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeAssignmentCursor(cursor string) (time.Time, int64, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	rawTime, rawID, ok := strings.Cut(string(decoded), "|")
+	if !ok {
+		return time.Time{}, 0, errors.New("malformed cursor")
+	}
+	assignedAt, err := time.Parse(time.RFC3339Nano, rawTime)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil || id <= 0 {
+		return time.Time{}, 0, errors.New("malformed cursor")
+	}
+	return assignedAt, id, nil
 }
 
 func (h *Handler) createAssignment(w http.ResponseWriter, r *http.Request) {
@@ -70,7 +205,7 @@ func (h *Handler) createAssignment(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
@@ -85,10 +220,41 @@ func (h *Handler) createAssignment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	row := h.db.QueryRow(r.Context(),
-		`INSERT INTO crew_assignments (manifest_id, participant_id, role)
-         VALUES ($1, $2, $3)
-         RETURNING id, assigned_at`,
+	// The manifest row stays locked until commit so concurrent assignments
+	// cannot both take the last staff slot.
+	ctx := r.Context()
+	tx, err := h.db.Begin(ctx)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to create crew assignment")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	limit, assigned, err := crewCapacity(ctx, tx, payload.ManifestID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			httpx.Error(w, http.StatusNotFound, "manifest not found")
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to create crew assignment")
+		return
+	}
+	if limit != nil && assigned >= *limit {
+		httpx.Error(w, http.StatusConflict, fmt.Sprintf("manifest has %d of %d staff slots filled", assigned, *limit))
+		return
+	}
+
+	row := tx.QueryRow(ctx,
+		`WITH participant AS (
+            SELECT id, full_name FROM participant_profiles WHERE id = $2
+        ), inserted AS (
+            INSERT INTO crew_assignments (manifest_id, participant_id, role)
+            SELECT $1, participant.id, $3 FROM participant
+            RETURNING id, participant_id, assigned_at
+        )
+        SELECT inserted.id, inserted.assigned_at, participant.full_name
+        FROM inserted
+        JOIN participant ON participant.id = inserted.participant_id`,
 		payload.ManifestID, payload.ParticipantID, role,
 	)
 
@@ -97,14 +263,26 @@ func (h *Handler) createAssignment(w http.ResponseWriter, r *http.Request) {
 	assignment.ParticipantID = payload.ParticipantID
 	assignment.Role = role
 
-	if err := row.Scan(&assignment.ID, &assignment.AssignedAt); err != nil {
+	if err := row.Scan(&assignment.ID, &assignment.AssignedAt, &assignment.ParticipantName); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			httpx.Error(w, http.StatusNotFound, "participant not found")
+			return
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			if strings.Contains(pgErr.ConstraintName, "participant") {
+				httpx.Error(w, http.StatusNotFound, "participant not found")
+				return
+			}
+			httpx.Error(w, http.StatusNotFound, "manifest not found")
+			return
+		}
 		httpx.Error(w, http.StatusInternalServerError, "failed to create crew assignment")
 		return
 	}
 
-	participantRow := h.db.QueryRow(r.Context(), `SELECT full_name FROM participant_profiles WHERE id = $1`, payload.ParticipantID)
-	if err := participantRow.Scan(&assignment.ParticipantName); err != nil {
-		httpx.Error(w, http.StatusInternalServerError, "failed to load participant for assignment")
+	if err := tx.Commit(ctx); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to create crew assignment")
 		return
 	}
 
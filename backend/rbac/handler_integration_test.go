@@ -0,0 +1,219 @@
+package rbac
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestCrewAssignmentsFilterByManifestAndDelete(t *testing.T) {
+	db := openRBACTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ensureRBACTestSchema(t, ctx, db)
+
+	var seasonID, eventID int64
+	if err := db.QueryRow(ctx, `INSERT INTO seasons (name, starts_on) VALUES ('Crew Season', CURRENT_DATE) RETURNING id`).Scan(&seasonID); err != nil {
+		t.Fatalf("insert season failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM seasons WHERE id = $1`, seasonID)
+	if err := db.QueryRow(ctx, `INSERT INTO events (season_id, name, starts_at) VALUES ($1, 'Crew Event', NOW()) RETURNING id`, seasonID).Scan(&eventID); err != nil {
+		t.Fatalf("insert event failed: %v", err)
+	}
+	manifestIDs := make([]int64, 2)
+	for i := range manifestIDs {
+		if err := db.QueryRow(ctx, `INSERT INTO manifests (event_id, load_number) VALUES ($1, $2) RETURNING id`, eventID, i+1).Scan(&manifestIDs[i]); err != nil {
+			t.Fatalf("insert manifest failed: %v", err)
+		}
+	}
+	email := "crew-filter-" + strconv.FormatInt(time.Now().UnixNano(), 10) + "@example.com"
+	var participantID int64
+	if err := db.QueryRow(ctx, `INSERT INTO participant_profiles (full_name, email) VALUES ('Crew Filter', $1) RETURNING id`, email).Scan(&participantID); err != nil {
+		t.Fatalf("insert participant failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM participant_profiles WHERE id = $1`, participantID)
+	assignmentIDs := make(map[int64]int64, len(manifestIDs))
+	for _, manifestID := range manifestIDs {
+		var id int64
+		if err := db.QueryRow(ctx,
+			`INSERT INTO crew_assignments (manifest_id, participant_id, role) VALUES ($1, $2, 'Jump Master') RETURNING id`,
+			manifestID, participantID,
+		).Scan(&id); err != nil {
+			t.Fatalf("insert crew assignment failed: %v", err)
+		}
+		assignmentIDs[manifestID] = id
+	}
+
+	router := chi.NewRouter()
+	router.Get("/crew-assignments", NewHandler(db).listAssignments)
+	router.Delete("/crew-assignments/{assignmentID}", NewHandler(db).deleteAssignment)
+	list := func(manifestID int64) []CrewAssignment {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/crew-assignments?manifest_id="+strconv.FormatInt(manifestID, 10), nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("list status = %d body=%s", rec.Code, rec.Body.String())
+		}
+		var items []CrewAssignment
+		if err := json.NewDecoder(rec.Body).Decode(&items); err != nil {
+			t.Fatalf("decode list failed: %v", err)
+		}
+		return items
+	}
+
+	for _, manifestID := range manifestIDs {
+		items := list(manifestID)
+		if len(items) != 1 || items[0].ManifestID != manifestID || items[0].ID != assignmentIDs[manifestID] {
+			t.Fatalf("list for manifest %d = %+v, want only assignment %d", manifestID, items, assignmentIDs[manifestID])
+		}
+	}
+
+	path := "/crew-assignments/" + strconv.FormatInt(assignmentIDs[manifestIDs[0]], 10)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, path, nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("delete status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	if items := list(manifestIDs[0]); len(items) != 0 {
+		t.Fatalf("list after delete = %+v, want none", items)
+	}
+	if items := list(manifestIDs[1]); len(items) != 1 {
+		t.Fatalf("delete removed assignments from another manifest: %+v", items)
+	}
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, path, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("second delete status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestCreateAssignmentRefusesFullManifest(t *testing.T) {
+	db := openRBACTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ensureRBACTestSchema(t, ctx, db)
+
+	var seasonID, eventID, manifestID int64
+	if err := db.QueryRow(ctx, `INSERT INTO seasons (name, starts_on) VALUES ('Slots Season', CURRENT_DATE) RETURNING id`).Scan(&seasonID); err != nil {
+		t.Fatalf("insert season failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM seasons WHERE id = $1`, seasonID)
+	if err := db.QueryRow(ctx, `INSERT INTO events (season_id, name, starts_at) VALUES ($1, 'Slots Event', NOW()) RETURNING id`, seasonID).Scan(&eventID); err != nil {
+		t.Fatalf("insert event failed: %v", err)
+	}
+	if err := db.QueryRow(ctx, `INSERT INTO manifests (event_id, load_number, staff_slots) VALUES ($1, 1, 2) RETURNING id`, eventID).Scan(&manifestID); err != nil {
+		t.Fatalf("insert manifest failed: %v", err)
+	}
+	participantIDs := make([]int64, 3)
+	for i := range participantIDs {
+		email := "crew-slots-" + strconv.Itoa(i) + "-" + strconv.FormatInt(time.Now().UnixNano(), 10) + "@example.com"
+		if err := db.QueryRow(ctx, `INSERT INTO participant_profiles (full_name, email) VALUES ('Crew Slots', $1) RETURNING id`, email).Scan(&participantIDs[i]); err != nil {
+			t.Fatalf("insert participant failed: %v", err)
+		}
+		defer db.Exec(context.Background(), `DELETE FROM participant_profiles WHERE id = $1`, participantIDs[i])
+	}
+
+	handler := NewHandler(db)
+	assign := func(participantID int64) *httptest.ResponseRecorder {
+		t.Helper()
+		body := `{"manifest_id":` + strconv.FormatInt(manifestID, 10) + `,"participant_id":` + strconv.FormatInt(participantID, 10) + `,"role":"Jump Master"}`
+		rec := httptest.NewRecorder()
+		handler.createAssignment(rec, httptest.NewRequest(http.MethodPost, "/crew-assignments", strings.NewReader(body)))
+		return rec
+	}
+
+	for _, participantID := range participantIDs[:2] {
+		if rec := assign(participantID); rec.Code != http.StatusCreated {
+			t.Fatalf("assign within the staff slots status = %d body=%s", rec.Code, rec.Body.String())
+		}
+	}
+	rec := assign(participantIDs[2])
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("assign to a full manifest status = %d body=%s, want %d", rec.Code, rec.Body.String(), http.StatusConflict)
+	}
+	if !strings.Contains(rec.Body.String(), "2 of 2 staff slots filled") {
+		t.Fatalf("conflict body = %s, want the filled slots", rec.Body.String())
+	}
+	var assigned int
+	if err := db.QueryRow(ctx, `SELECT COUNT(*) FROM crew_assignments WHERE manifest_id = $1`, manifestID).Scan(&assigned); err != nil {
+		t.Fatalf("count assignments failed: %v", err)
+	}
+	if assigned != 2 {
+		t.Fatalf("assignments = %d, want the refused one left out", assigned)
+	}
+}
+
+func openRBACTestDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Skip("DATABASE_URL not set; skipping integration rbac tests")
+	}
+	db, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatalf("connect db failed: %v", err)
+	}
+	return db
+}
+
+func ensureRBACTestSchema(t *testing.T, ctx context.Context, db *pgxpool.Pool) {
+	t.Helper()
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS seasons (
+            id SERIAL PRIMARY KEY,
+            name TEXT NOT NULL,
+            starts_on DATE NOT NULL,
+            ends_on DATE,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE TABLE IF NOT EXISTS events (
+            id SERIAL PRIMARY KEY,
+            season_id INTEGER NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+            name TEXT NOT NULL,
+            starts_at TIMESTAMPTZ NOT NULL,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE TABLE IF NOT EXISTS manifests (
+            id SERIAL PRIMARY KEY,
+            event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+            load_number INTEGER NOT NULL,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`ALTER TABLE manifests ADD COLUMN IF NOT EXISTS staff_slots INTEGER`,
+		`CREATE TABLE IF NOT EXISTS participant_profiles (
+            id SERIAL PRIMARY KEY,
+            full_name TEXT NOT NULL,
+            email TEXT NOT NULL UNIQUE,
+            created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+		`ALTER TABLE participant_profiles ADD COLUMN IF NOT EXISTS roles TEXT[]`,
+		`ALTER TABLE participant_profiles ADD COLUMN IF NOT EXISTS experience_level TEXT`,
+		`ALTER TABLE participant_profiles ADD COLUMN IF NOT EXISTS jump_count INTEGER`,
+		`ALTER TABLE participant_profiles ADD COLUMN IF NOT EXISTS years_in_sport INTEGER`,
+		`CREATE TABLE IF NOT EXISTS crew_assignments (
+            id SERIAL PRIMARY KEY,
+            manifest_id INTEGER NOT NULL REFERENCES manifests(id) ON DELETE CASCADE,
+            participant_id INTEGER NOT NULL REFERENCES participant_profiles(id) ON DELETE CASCADE,
+            role TEXT NOT NULL,
+            assigned_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+        )`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(ctx, stmt); err != nil {
+			t.Fatalf("schema setup failed: %v", err)
+		}
+	}
+}
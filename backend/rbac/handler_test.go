@@ -0,0 +1,108 @@
+package rbac
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestAssignmentCursorRoundTrip(t *testing.T) {
+	assignedAt := time.Date(2025, 6, 14, 9, 30, 15, 123456000, time.UTC)
+
+	gotAt, gotID, err := decodeAssignmentCursor(encodeAssignmentCursor(assignedAt, 42))
+	if err != nil {
+		t.Fatalf("decodeAssignmentCursor() returned error: %v", err)
+	}
+	if !gotAt.Equal(assignedAt) || gotID != 42 {
+		t.Fatalf("decodeAssignmentCursor() = %s, %d; want %s, 42", gotAt, gotID, assignedAt)
+	}
+}
+
+func TestDecodeAssignmentCursorRejectsGarbage(t *testing.T) {
+	for _, cursor := range []string{"", "not-base64!", "bm9waXBl"} {
+		if _, _, err := decodeAssignmentCursor(cursor); err == nil {
+			t.Fatalf("decodeAssignmentCursor(%q) expected error", cursor)
+		}
+	}
+}
+
+func TestPermissionsForRoleMirrorsRoleMatrix(t *testing.T) {
+	for permission, roles := range RoleMatrix {
+		for _, role := range roles {
+			found := false
+			for _, granted := range PermissionsForRole(role) {
+				if granted == permission {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Fatalf("PermissionsForRole(%s) is missing %s", role, permission)
+			}
+		}
+	}
+	for _, granted := range PermissionsForRole(RoleParticipant) {
+		if granted == PermissionManageEvents {
+			t.Fatalf("PermissionsForRole(%s) unexpectedly grants %s", RoleParticipant, PermissionManageEvents)
+		}
+	}
+}
+
+func TestBulkAssignCrewRejectsBadRequestsBeforeTouchingDB(t *testing.T) {
+	h := NewHandler(nil)
+	router := chi.NewRouter()
+	router.Post("/manifests/{manifestID}/crew/bulk", h.bulkAssignCrew)
+
+	cases := []struct {
+		path, body string
+	}{
+		{"/manifests/abc/crew/bulk", `{"assignments":[{"participant_id":1,"role":"Pilot"}]}`},
+		{"/manifests/1/crew/bulk", `{"assignments":[]}`},
+		{"/manifests/1/crew/bulk", `{"assignments":[` + strings.TrimSuffix(strings.Repeat(`{"participant_id":1,"role":"Pilot"},`, maxBulkAssignments+1), ",") + `]}`},
+		{"/manifests/1/crew/bulk", `{"unknown":true}`},
+	}
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("POST %s status = %d, want %d", tc.path, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestCrewAssignmentRoutesRejectBadIDsBeforeTouchingDB(t *testing.T) {
+	h := NewHandler(nil)
+	router := chi.NewRouter()
+	router.Get("/crew-assignments", h.listAssignments)
+	router.Delete("/crew-assignments/{assignmentID}", h.deleteAssignment)
+
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodGet, "/crew-assignments?manifest_id=0"},
+		{http.MethodGet, "/crew-assignments?manifest_id=load-1"},
+		{http.MethodDelete, "/crew-assignments/abc"},
+		{http.MethodDelete, "/crew-assignments/-3"},
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s %s status = %d, want %d", tc.method, tc.path, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestSuggestCrewRequiresRole(t *testing.T) {
+	router := chi.NewRouter()
+	router.Get("/manifests/{manifestID}/crew/suggestions", NewHandler(nil).suggestCrew)
+
+	for _, path := range []string{"/manifests/1/crew/suggestions", "/manifests/1/crew/suggestions?role=%20", "/manifests/x/crew/suggestions?role=Pilot"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("GET %s status = %d, want %d", path, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
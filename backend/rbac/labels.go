@@ -0,0 +1,114 @@
+package rbac
+
+import "strings"
+
+// roleInfo holds how an account role is shown to people and described in the
+// roles table.
+type roleInfo struct {
+	label       string
+	description string
+}
+
+var roleInfos = map[Role]roleInfo{
+	RoleAdmin:       {label: "Admin", description: "Full platform administration"},
+	RoleStaff:       {label: "Staff", description: "Dropzone staff operations"},
+	RoleJumpMaster:  {label: "Jump Master", description: "Jump master manifest authority"},
+	RoleJumpLeader:  {label: "Jump Leader", description: "Jump leader manifest visibility"},
+	RoleGroundCrew:  {label: "Ground Crew", description: "Ground crew logistics"},
+	RoleDriver:      {label: "Driver", description: "Driver logistics coordination"},
+	RolePacker:      {label: "Packer", description: "Rigging and packing responsibilities"},
+	RoleParticipant: {label: "Participant", description: "Participant self-service access"},
+}
+
+// Label returns the display name of the role, e.g. "Jump Master".
+func (r Role) Label() string {
+	return roleInfos[r].label
+}
+
+// Description returns the description seeded into the roles table.
+func (r Role) Description() string {
+	return roleInfos[r].description
+}
+
+// roleKey folds the spellings of a role name, "Jump Master", "jump_master" and
+// "jumpmaster", to one key.
+func roleKey(value string) string {
+	key := strings.ToLower(strings.TrimSpace(value))
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(key)
+}
+
+// ParseRole returns the account role named by value, accepting the role
+// itself or its label in any case, with spaces, underscores or neither.
+func ParseRole(value string) (Role, bool) {
+	key := roleKey(value)
+	if key == "" {
+		return "", false
+	}
+	for _, role := range Roles {
+		if roleKey(string(role)) == key {
+			return role, true
+		}
+	}
+	return "", false
+}
+
+// Profile roles that describe what someone does at an event but grant no
+// access of their own.
+const (
+	ProfileRoleSkydiver = "Skydiver"
+	ProfileRolePilot    = "Pilot"
+	ProfileRolePOC      = "POC"
+	ProfileRolePhoto    = "Photo"
+)
+
+// ProfileRoles lists the roles a participant profile or a comms audience may
+// name, as display labels. Labels shared with account roles come from Label
+// so the two vocabularies cannot drift apart.
+var ProfileRoles = []string{
+	RoleParticipant.Label(),
+	ProfileRoleSkydiver,
+	RoleStaff.Label(),
+	RoleGroundCrew.Label(),
+	RoleJumpMaster.Label(),
+	RoleJumpLeader.Label(),
+	RoleDriver.Label(),
+	ProfileRolePilot,
+	ProfileRolePOC,
+	ProfileRolePhoto,
+}
+
+// CrewRoles lists the roles offered when crewing a manifest, as display
+// labels matched against participant profile roles.
+var CrewRoles = []string{
+	RoleJumpMaster.Label(),
+	RoleJumpLeader.Label(),
+	RoleGroundCrew.Label(),
+	RoleDriver.Label(),
+	RolePacker.Label(),
+	ProfileRolePilot,
+	ProfileRolePhoto,
+}
+
+// profileRoleAliases are older spellings still found in stored data.
+var profileRoleAliases = map[string]string{
+	"cop": ProfileRolePOC,
+}
+
+// CanonicalProfileRole returns the ProfileRoles label value names, matched
+// like ParseRole so "jump_master" and "jump master" both give "Jump Master".
+// It returns "" for values that are not profile roles.
+func CanonicalProfileRole(value string) string {
+	key := roleKey(value)
+	if key == "" {
+		return ""
+	}
+	if alias, ok := profileRoleAliases[key]; ok {
+		return alias
+	}
+	for _, label := range ProfileRoles {
+		if roleKey(label) == key {
+			return label
+		}
+	}
+	return ""
+}
@@ -0,0 +1,51 @@
+package rbac
+
+import "testing"
+
+func TestParseRoleAcceptsNamesAndLabels(t *testing.T) {
+	for value, want := range map[string]Role{
+		"jump_master":  RoleJumpMaster,
+		"Jump Master":  RoleJumpMaster,
+		"jumpmaster":   RoleJumpMaster,
+		" GROUND CREW": RoleGroundCrew,
+		"Packer":       RolePacker,
+	} {
+		if got, ok := ParseRole(value); !ok || got != want {
+			t.Errorf("ParseRole(%q) = %q, %v; want %q", value, got, ok, want)
+		}
+	}
+	for _, value := range []string{"", "pilot", "jump"} {
+		if got, ok := ParseRole(value); ok {
+			t.Errorf("ParseRole(%q) = %q, want no role", value, got)
+		}
+	}
+}
+
+func TestEveryRoleHasLabelAndDescription(t *testing.T) {
+	for _, role := range Roles {
+		if role.Label() == "" || role.Description() == "" {
+			t.Errorf("role %q has label %q and description %q", role, role.Label(), role.Description())
+		}
+		if parsed, ok := ParseRole(role.Label()); !ok || parsed != role {
+			t.Errorf("ParseRole(%q) = %q, want %q", role.Label(), parsed, role)
+		}
+	}
+}
+
+func TestCanonicalProfileRole(t *testing.T) {
+	for value, want := range map[string]string{
+		"Jump Master": "Jump Master",
+		"jump_master": "Jump Master",
+		"ground crew": "Ground Crew",
+		"COP":         ProfileRolePOC,
+		"poc":         ProfileRolePOC,
+		"skydiver":    ProfileRoleSkydiver,
+		"packer":      "",
+		"admin":       "",
+		"":            "",
+	} {
+		if got := CanonicalProfileRole(value); got != want {
+			t.Errorf("CanonicalProfileRole(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
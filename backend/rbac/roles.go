@@ -1,5 +1,7 @@
 package rbac
 
+import "sort"
+
 // Role represents a logical capability grouping for authenticated users.
 type Role string
 
@@ -14,6 +16,18 @@ const (
 	RoleParticipant Role = "participant"
 )
 
+// Roles lists every account role from most to least privileged.
+var Roles = []Role{
+	RoleAdmin,
+	RoleStaff,
+	RoleJumpMaster,
+	RoleJumpLeader,
+	RoleGroundCrew,
+	RoleDriver,
+	RolePacker,
+	RoleParticipant,
+}
+
 // Permission represents an actionable verb within the API surface.
 type Permission string
 
@@ -41,6 +55,9 @@ const (
 	PermissionManageAccounting      Permission = "accounting:manage"
 	PermissionApproveAccounting     Permission = "accounting:approve"
 	PermissionViewSession           Permission = "session:view"
+	PermissionManageDebugLogging    Permission = "debug_logging:manage"
+	PermissionManageAccounts        Permission = "accounts:manage"
+	PermissionRecomputeDerivedData  Permission = "derived_data:recompute"
 )
 
 // RoleMatrix enumerates which roles satisfy a permission. The list is
@@ -180,4 +197,34 @@ var RoleMatrix = map[Permission][]Role{
 		RolePacker,
 		RoleParticipant,
 	},
+	PermissionManageDebugLogging: {
+		RoleAdmin,
+	},
+	PermissionManageAccounts: {
+		RoleAdmin,
+	},
+	PermissionRecomputeDerivedData: {
+		RoleAdmin,
+	},
+}
+
+// rolePermissions is the reverse of RoleMatrix, computed once at startup.
+var rolePermissions = buildRolePermissions(RoleMatrix)
+
+func buildRolePermissions(matrix map[Permission][]Role) map[Role][]Permission {
+	index := make(map[Role][]Permission)
+	for permission, roles := range matrix {
+		for _, role := range roles {
+			index[role] = append(index[role], permission)
+		}
+	}
+	for role := range index {
+		sort.Slice(index[role], func(i, j int) bool { return index[role][i] < index[role][j] })
+	}
+	return index
+}
+
+// PermissionsForRole lists the permissions granted to role in sorted order.
+func PermissionsForRole(role Role) []Permission {
+	return append([]Permission(nil), rolePermissions[role]...)
 }
@@ -0,0 +1,69 @@
+package rbac
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/innhopp/central/backend/internal/clock"
+)
+
+// DefaultRolesCacheTTL is how long the roles catalog is served from memory.
+const DefaultRolesCacheTTL = 5 * time.Minute
+
+// RoleInfo describes a role from the roles table with its granted permissions.
+type RoleInfo struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// rolesCache keeps the roles catalog in memory for a fixed TTL.
+type rolesCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	clock   clock.Clock
+	roles   []RoleInfo
+	expires time.Time
+}
+
+func (c *rolesCache) get(ctx context.Context, db *pgxpool.Pool) ([]RoleInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	if c.roles != nil && now.Before(c.expires) {
+		return c.roles, nil
+	}
+
+	roles, err := loadRoles(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if c.ttl > 0 {
+		c.roles = roles
+		c.expires = now.Add(c.ttl)
+	}
+	return roles, nil
+}
+
+func loadRoles(ctx context.Context, db *pgxpool.Pool) ([]RoleInfo, error) {
+	rows, err := db.Query(ctx, `SELECT name, COALESCE(description, '') FROM roles ORDER BY name ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := make([]RoleInfo, 0)
+	for rows.Next() {
+		var role RoleInfo
+		if err := rows.Scan(&role.Name, &role.Description); err != nil {
+			return nil, err
+		}
+		role.Permissions = PermissionsForRole(Role(role.Name))
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
@@ -0,0 +1,80 @@
+package rbac
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/innhopp/central/backend/httpx"
+)
+
+const maxCrewSuggestions = 25
+
+// CrewSuggestion is a participant qualified for a crew role on a manifest.
+type CrewSuggestion struct {
+	ParticipantID   int64  `json:"participant_id"`
+	FullName        string `json:"full_name"`
+	ExperienceLevel string `json:"experience_level,omitempty"`
+	JumpCount       *int   `json:"jump_count,omitempty"`
+	YearsInSport    *int   `json:"years_in_sport,omitempty"`
+}
+
+// suggestCrew lists participants whose profile roles include the requested
+// role and who are not already crew on the manifest, most experienced first.
+// Participants do not record availability yet, so everyone qualified is
+// considered available. The list is advisory; nothing is assigned.
+func (h *Handler) suggestCrew(w http.ResponseWriter, r *http.Request) {
+	manifestID, err := strconv.ParseInt(chi.URLParam(r, "manifestID"), 10, 64)
+	if err != nil || manifestID <= 0 {
+		httpx.Error(w, http.StatusBadRequest, "invalid manifest id")
+		return
+	}
+	role := strings.TrimSpace(r.URL.Query().Get("role"))
+	if role == "" {
+		httpx.Error(w, http.StatusBadRequest, "role is required")
+		return
+	}
+
+	ctx := r.Context()
+	var exists bool
+	if err := h.db.QueryRow(ctx, `SELECT TRUE FROM manifests WHERE id = $1`, manifestID).Scan(&exists); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			httpx.Error(w, http.StatusNotFound, "manifest not found")
+			return
+		}
+		httpx.Error(w, http.StatusInternalServerError, "failed to suggest crew")
+		return
+	}
+
+	rows, err := h.db.Query(ctx, `SELECT p.id, p.full_name, COALESCE(p.experience_level, ''), p.jump_count, p.years_in_sport
+        FROM participant_profiles p
+        WHERE EXISTS (SELECT 1 FROM unnest(p.roles) AS pr WHERE lower(pr) = lower($2))
+          AND NOT EXISTS (SELECT 1 FROM crew_assignments ca WHERE ca.manifest_id = $1 AND ca.participant_id = p.id)
+        ORDER BY p.jump_count DESC NULLS LAST, p.years_in_sport DESC NULLS LAST, p.full_name, p.id
+        LIMIT $3`, manifestID, role, maxCrewSuggestions)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to suggest crew")
+		return
+	}
+	defer rows.Close()
+
+	suggestions := []CrewSuggestion{}
+	for rows.Next() {
+		var s CrewSuggestion
+		if err := rows.Scan(&s.ParticipantID, &s.FullName, &s.ExperienceLevel, &s.JumpCount, &s.YearsInSport); err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to parse crew suggestion")
+			return
+		}
+		suggestions = append(suggestions, s)
+	}
+	if err := rows.Err(); err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to suggest crew")
+		return
+	}
+
+	httpx.WriteJSON(w, http.StatusOK, suggestions)
+}
@@ -0,0 +1,99 @@
+package rbac
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestSuggestCrewFiltersByRoleAndRanksByExperience(t *testing.T) {
+	db := openRBACTestDB(t)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	ensureRBACTestSchema(t, ctx, db)
+
+	var seasonID, eventID, manifestID int64
+	if err := db.QueryRow(ctx, `INSERT INTO seasons (name, starts_on) VALUES ('Suggest Season', CURRENT_DATE) RETURNING id`).Scan(&seasonID); err != nil {
+		t.Fatalf("insert season failed: %v", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM seasons WHERE id = $1`, seasonID)
+	if err := db.QueryRow(ctx, `INSERT INTO events (season_id, name, starts_at) VALUES ($1, 'Suggest Event', NOW()) RETURNING id`, seasonID).Scan(&eventID); err != nil {
+		t.Fatalf("insert event failed: %v", err)
+	}
+	if err := db.QueryRow(ctx, `INSERT INTO manifests (event_id, load_number) VALUES ($1, 1) RETURNING id`, eventID).Scan(&manifestID); err != nil {
+		t.Fatalf("insert manifest failed: %v", err)
+	}
+
+	// A role no other test data carries keeps the suggestions to this test's
+	// participants.
+	stamp := strconv.FormatInt(time.Now().UnixNano(), 10)
+	role := "Suggest Role " + stamp
+	type profile struct {
+		role  string
+		jumps *int
+		years *int
+	}
+	count := func(n int) *int { return &n }
+	profiles := map[string]profile{
+		"veteran": {role: strings.ToUpper(role), jumps: count(900), years: count(10)},
+		"senior":  {role: role, jumps: count(900), years: count(15)},
+		"rookie":  {role: role, jumps: count(50)},
+		"unknown": {role: role},
+		"crewed":  {role: role, jumps: count(2000)},
+		"driver":  {role: "Driver", jumps: count(3000)},
+	}
+	ids := make(map[string]int64, len(profiles))
+	names := make(map[int64]string, len(profiles))
+	for name, p := range profiles {
+		var id int64
+		if err := db.QueryRow(ctx,
+			`INSERT INTO participant_profiles (full_name, email, roles, jump_count, years_in_sport) VALUES ($1, $2, ARRAY[$3], $4, $5) RETURNING id`,
+			"Suggest "+name, "crew-suggest-"+name+"-"+stamp+"@example.com", p.role, p.jumps, p.years,
+		).Scan(&id); err != nil {
+			t.Fatalf("insert participant failed: %v", err)
+		}
+		defer db.Exec(context.Background(), `DELETE FROM participant_profiles WHERE id = $1`, id)
+		ids[name] = id
+		names[id] = name
+	}
+	if _, err := db.Exec(ctx, `INSERT INTO crew_assignments (manifest_id, participant_id, role) VALUES ($1, $2, $3)`, manifestID, ids["crewed"], role); err != nil {
+		t.Fatalf("insert crew assignment failed: %v", err)
+	}
+
+	router := chi.NewRouter()
+	router.Get("/manifests/{manifestID}/crew/suggestions", NewHandler(db).suggestCrew)
+	rec := httptest.NewRecorder()
+	path := "/manifests/" + strconv.FormatInt(manifestID, 10) + "/crew/suggestions?role=" + url.QueryEscape(strings.ToLower(role))
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("suggestions status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	var suggestions []CrewSuggestion
+	if err := json.NewDecoder(rec.Body).Decode(&suggestions); err != nil {
+		t.Fatalf("decode suggestions failed: %v", err)
+	}
+	var got []string
+	for _, s := range suggestions {
+		got = append(got, names[s.ParticipantID])
+	}
+	want := []string{"senior", "veteran", "rookie", "unknown"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("suggestions = %v, want %v", got, want)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/manifests/"+strconv.FormatInt(manifestID+1000000, 10)+"/crew/suggestions?role=Driver", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("suggestions for a missing manifest status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,199 @@
+// Package recompute lets an admin rerun the jobs that store derived data, such
+// as event statuses, after a deploy changes how it is derived. A run goes on
+// in the background because it can outlast the request timeout; its progress
+// is read back from the same endpoint. Every target only writes rows whose
+// stored value differs from the derived one, so running it again is harmless.
+package recompute
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/innhopp/central/backend/httpx"
+	"github.com/innhopp/central/backend/internal/clock"
+	"github.com/innhopp/central/backend/rbac"
+)
+
+// runTimeout bounds a single run.
+const runTimeout = 30 * time.Minute
+
+// Target is a derived dataset that can be recomputed. Run reports the running
+// totals through progress as it goes; targets that work in one transaction
+// report once at the end.
+type Target struct {
+	Name        string
+	Description string
+	Run         func(ctx context.Context, progress func(processed, updated int)) error
+}
+
+// Run is the state of the latest recompute of a target.
+type Run struct {
+	Target     string     `json:"target"`
+	Status     string     `json:"status"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Processed  int        `json:"processed"`
+	Updated    int        `json:"updated"`
+	Error      string     `json:"error,omitempty"`
+}
+
+const (
+	statusRunning   = "running"
+	statusSucceeded = "succeeded"
+	statusFailed    = "failed"
+)
+
+type targetStatus struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	LastRun     *Run   `json:"last_run,omitempty"`
+}
+
+// Handler starts recomputes and reports their progress.
+type Handler struct {
+	targets map[string]Target
+
+	mu   sync.Mutex
+	runs map[string]*Run
+	wg   sync.WaitGroup
+	// runCtx is the parent of every run; Shutdown cancels it.
+	runCtx     context.Context
+	cancelRuns context.CancelFunc
+	// clock stamps when runs start and finish.
+	clock clock.Clock
+}
+
+// NewHandler creates a recompute handler for targets.
+func NewHandler(targets ...Target) *Handler {
+	h := &Handler{targets: make(map[string]Target, len(targets)), runs: make(map[string]*Run), clock: clock.Real{}}
+	h.runCtx, h.cancelRuns = context.WithCancel(context.Background())
+	for _, target := range targets {
+		h.targets[target.Name] = target
+	}
+	return h
+}
+
+// SetClock replaces the time source runs are stamped with.
+func (h *Handler) SetClock(c clock.Clock) {
+	if c == nil {
+		c = clock.Real{}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clock = c
+}
+
+// Routes registers the recompute endpoints.
+func (h *Handler) Routes(enforcer *rbac.Enforcer) chi.Router {
+	r := chi.NewRouter()
+	r.With(enforcer.Authorize(rbac.PermissionRecomputeDerivedData)).Get("/recompute", h.listRuns)
+	r.With(enforcer.Authorize(rbac.PermissionRecomputeDerivedData), enforcer.AdminAction("derived_data.recompute")).Post("/recompute", h.startRun)
+	return r
+}
+
+// Wait blocks until every started run has finished.
+func (h *Handler) Wait() {
+	h.wg.Wait()
+}
+
+// Shutdown cancels the runs still going and waits for them to stop, or for
+// ctx to end. A cancelled run leaves the rows it had not reached as they
+// were, so starting it again after the restart finishes the job.
+func (h *Handler) Shutdown(ctx context.Context) error {
+	h.cancelRuns()
+	done := make(chan struct{})
+	go func() {
+		h.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h *Handler) listRuns(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	statuses := make([]targetStatus, 0, len(h.targets))
+	for name, target := range h.targets {
+		status := targetStatus{Name: name, Description: target.Description}
+		if run := h.runs[name]; run != nil {
+			copied := *run
+			status.LastRun = &copied
+		}
+		statuses = append(statuses, status)
+	}
+	h.mu.Unlock()
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	httpx.WriteJSON(w, http.StatusOK, statuses)
+}
+
+func (h *Handler) startRun(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSpace(r.URL.Query().Get("target"))
+	target, ok := h.targets[name]
+	if !ok {
+		httpx.Error(w, http.StatusBadRequest, "target must be one of: "+strings.Join(h.targetNames(), ", "))
+		return
+	}
+
+	h.mu.Lock()
+	if run := h.runs[name]; run != nil && run.Status == statusRunning {
+		copied := *run
+		h.mu.Unlock()
+		httpx.WriteJSON(w, http.StatusConflict, copied)
+		return
+	}
+	run := &Run{Target: name, Status: statusRunning, StartedAt: h.clock.Now().UTC()}
+	h.runs[name] = run
+	started := *run
+	h.mu.Unlock()
+
+	h.wg.Add(1)
+	go h.execute(target, run)
+	httpx.WriteJSON(w, http.StatusAccepted, started)
+}
+
+// execute runs target outside the request so that neither the request
+// timeout nor its query budget cuts it short.
+func (h *Handler) execute(target Target, run *Run) {
+	defer h.wg.Done()
+	ctx, cancel := context.WithTimeout(h.runCtx, runTimeout)
+	defer cancel()
+
+	err := target.Run(ctx, func(processed, updated int) {
+		h.mu.Lock()
+		run.Processed, run.Updated = processed, updated
+		h.mu.Unlock()
+	})
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	finished := h.clock.Now().UTC()
+	run.FinishedAt = &finished
+	if err != nil {
+		run.Status = statusFailed
+		run.Error = err.Error()
+		log.Printf("recompute %s failed after %d rows: %v", target.Name, run.Processed, err)
+		return
+	}
+	run.Status = statusSucceeded
+	log.Printf("recompute %s finished: %d rows checked, %d updated", target.Name, run.Processed, run.Updated)
+}
+
+func (h *Handler) targetNames() []string {
+	names := make([]string, 0, len(h.targets))
+	for name := range h.targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,121 @@
+package recompute
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/innhopp/central/backend/internal/clock"
+)
+
+func post(h *Handler, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.startRun(rec, httptest.NewRequest(http.MethodPost, "/recompute?target="+target, nil))
+	return rec
+}
+
+func lastRuns(t *testing.T, h *Handler) map[string]*Run {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.listRuns(rec, httptest.NewRequest(http.MethodGet, "/recompute", nil))
+	var statuses []targetStatus
+	if err := json.NewDecoder(rec.Body).Decode(&statuses); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	runs := make(map[string]*Run, len(statuses))
+	for _, status := range statuses {
+		runs[status.Name] = status.LastRun
+	}
+	return runs
+}
+
+func TestStartRunReportsProgressAndOutcome(t *testing.T) {
+	release := make(chan struct{})
+	h := NewHandler(
+		Target{Name: "event_status", Run: func(ctx context.Context, progress func(int, int)) error {
+			progress(500, 3)
+			<-release
+			progress(720, 4)
+			return nil
+		}},
+		Target{Name: "broken", Run: func(context.Context, func(int, int)) error {
+			return errors.New("relation does not exist")
+		}},
+	)
+
+	if rec := post(h, "event_status"); rec.Code != http.StatusAccepted {
+		t.Fatalf("start status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+	if rec := post(h, "event_status"); rec.Code != http.StatusConflict {
+		t.Fatalf("second start status = %d, want %d while running", rec.Code, http.StatusConflict)
+	}
+	if rec := post(h, "broken"); rec.Code != http.StatusAccepted {
+		t.Fatalf("start broken status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+	close(release)
+	h.Wait()
+
+	runs := lastRuns(t, h)
+	if run := runs["event_status"]; run == nil || run.Status != statusSucceeded || run.Processed != 720 || run.Updated != 4 || run.FinishedAt == nil {
+		t.Fatalf("event_status run = %+v, want succeeded with 720 processed and 4 updated", run)
+	}
+	if run := runs["broken"]; run == nil || run.Status != statusFailed || run.Error != "relation does not exist" {
+		t.Fatalf("broken run = %+v, want failed with the error", run)
+	}
+
+	if rec := post(h, "event_status"); rec.Code != http.StatusAccepted {
+		t.Fatalf("restart status = %d, want %d once finished", rec.Code, http.StatusAccepted)
+	}
+	h.Wait()
+}
+
+func TestRunsAreStampedWithTheHandlerClock(t *testing.T) {
+	at := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+	h := NewHandler(Target{Name: "event_status", Run: func(context.Context, func(int, int)) error { return nil }})
+	h.SetClock(clock.Fixed{At: at})
+
+	if rec := post(h, "event_status"); rec.Code != http.StatusAccepted {
+		t.Fatalf("start status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+	h.Wait()
+
+	run := lastRuns(t, h)["event_status"]
+	if run == nil || !run.StartedAt.Equal(at) || run.FinishedAt == nil || !run.FinishedAt.Equal(at) {
+		t.Fatalf("run = %+v, want started and finished at %s", run, at)
+	}
+}
+
+func TestStartRunRejectsUnknownTarget(t *testing.T) {
+	h := NewHandler(Target{Name: "event_status"}, Target{Name: "event_rosters"})
+	rec := post(h, "season_counts")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if !strings.Contains(rec.Body.String(), "event_rosters, event_status") {
+		t.Fatalf("body = %s, want the known targets", rec.Body.String())
+	}
+}
+
+func TestShutdownCancelsRunningRuns(t *testing.T) {
+	h := NewHandler(Target{Name: "event_status", Run: func(ctx context.Context, _ func(int, int)) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	if rec := post(h, "event_status"); rec.Code != http.StatusAccepted {
+		t.Fatalf("start status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := h.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if run := lastRuns(t, h)["event_status"]; run == nil || run.Status != statusFailed || run.Error != context.Canceled.Error() {
+		t.Fatalf("event_status run = %+v, want failed as cancelled", run)
+	}
+}
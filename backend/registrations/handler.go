@@ -17,6 +17,7 @@ import (
 
 	"github.com/innhopp/central/backend/auth"
 	"github.com/innhopp/central/backend/httpx"
+	"github.com/innhopp/central/backend/internal/email"
 	"github.com/innhopp/central/backend/internal/timeutil"
 	"github.com/innhopp/central/backend/rbac"
 )
@@ -378,7 +379,7 @@ func scanRegistration(scanner interface{ Scan(dest ...any) error }) (*Registrati
 	); err != nil {
 		return nil, err
 	}
-	registration.ParticipantEmail = strings.ToLower(strings.TrimSpace(registration.ParticipantEmail))
+	registration.ParticipantEmail = email.Normalize(registration.ParticipantEmail)
 	registration.Tags = normalizeTags(registration.Tags)
 	return &registration, nil
 }
@@ -536,6 +537,14 @@ func syncRegistrationPaymentMarkersTx(ctx context.Context, tx pgx.Tx, registrati
 	return err
 }
 
+func eventExists(ctx context.Context, q interface {
+	QueryRow(context.Context, string, ...any) pgx.Row
+}, eventID int64) (bool, error) {
+	var exists bool
+	err := q.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM events WHERE id = $1)`, eventID).Scan(&exists)
+	return exists, err
+}
+
 func activeRegistrationExists(ctx context.Context, q interface {
 	QueryRow(context.Context, string, ...any) pgx.Row
 }, eventID, participantID int64) (bool, error) {
@@ -547,7 +556,7 @@ func activeRegistrationExists(ctx context.Context, q interface {
 		  AND participant_id = $2
 		  AND cancelled_at IS NULL
 		  AND expired_at IS NULL
-		ORDER BY created_at DESC
+		ORDER BY created_at DESC, id DESC
 		LIMIT 1
 	`, eventID, participantID).Scan(&existingID)
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -1048,7 +1057,7 @@ func EnsureStaffParticipantRegistrations(ctx context.Context, db *pgxpool.Pool,
 			  AND participant_id = $2
 			  AND cancelled_at IS NULL
 			  AND expired_at IS NULL
-			ORDER BY created_at DESC
+			ORDER BY created_at DESC, id DESC
 			LIMIT 1
 		`, eventID, participantID).Scan(&registrationID, &registeredAt, &depositDueAt, &mainInvoiceDueAt)
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -1075,7 +1084,7 @@ func EnsureStaffParticipantRegistrations(ctx context.Context, db *pgxpool.Pool,
 	return tx.Commit(ctx)
 }
 
-func ExpireOverdueRegistrations(ctx context.Context, db *pgxpool.Pool) (int64, error) {
+func ExpireOverdueRegistrations(ctx context.Context, db *pgxpool.Pool, now time.Time) (int64, error) {
 	commandTag, err := db.Exec(ctx, `
 		UPDATE event_registrations
 		SET status = 'expired',
@@ -1085,11 +1094,11 @@ func ExpireOverdueRegistrations(ctx context.Context, db *pgxpool.Pool) (int64, e
 		  AND expired_at IS NULL
 		  AND status <> 'expired'
 		  AND (
-			(deposit_due_at IS NOT NULL AND deposit_paid_at IS NULL AND deposit_due_at < CURRENT_DATE)
+			(deposit_due_at IS NOT NULL AND deposit_paid_at IS NULL AND deposit_due_at < $1::date)
 			OR
-			(main_invoice_due_at IS NOT NULL AND main_invoice_paid_at IS NULL AND main_invoice_due_at < CURRENT_DATE)
+			(main_invoice_due_at IS NOT NULL AND main_invoice_paid_at IS NULL AND main_invoice_due_at < $1::date)
 		  )
-	`)
+	`, toUTCDate(now.UTC()))
 	if err != nil {
 		return 0, err
 	}
@@ -1211,8 +1220,8 @@ func publicDepositDueAt(now time.Time, event *PublicRegistrationEvent) *time.Tim
 
 func (h *Handler) findOrCreatePublicParticipantTx(ctx context.Context, tx pgx.Tx, payload *publicRegistrationPayload) (int64, error) {
 	fullName := strings.TrimSpace(payload.FullName)
-	email := strings.ToLower(strings.TrimSpace(payload.Email))
-	if fullName == "" || email == "" {
+	normalizedEmail := email.Normalize(payload.Email)
+	if fullName == "" || normalizedEmail == "" {
 		return 0, errors.New("full_name and email are required")
 	}
 
@@ -1223,7 +1232,7 @@ func (h *Handler) findOrCreatePublicParticipantTx(ctx context.Context, tx pgx.Tx
 		WHERE lower(email) = lower($1)
 		ORDER BY id ASC
 		LIMIT 1
-	`, email).Scan(&participantID)
+	`, normalizedEmail).Scan(&participantID)
 	if err == nil {
 		return participantID, nil
 	}
@@ -1281,7 +1290,7 @@ func (h *Handler) findOrCreatePublicParticipantTx(ctx context.Context, tx pgx.Tx
 			ARRAY['Participant']::TEXT[]
 		)
 		RETURNING id
-	`, fullName, email, payload.Phone, payload.ExperienceLevel, payload.EmergencyContact, payload.Whatsapp, payload.Instagram, payload.Citizenship, payload.DateOfBirth, payload.Jumper, payload.YearsInSport, payload.JumpCount, payload.RecentJumpCount, payload.License).Scan(&participantID)
+	`, fullName, normalizedEmail, payload.Phone, payload.ExperienceLevel, payload.EmergencyContact, payload.Whatsapp, payload.Instagram, payload.Citizenship, payload.DateOfBirth, payload.Jumper, payload.YearsInSport, payload.JumpCount, payload.RecentJumpCount, payload.License).Scan(&participantID)
 	if err != nil {
 		return 0, err
 	}
@@ -1292,28 +1301,29 @@ func ensureOwnParticipantProfileTx(ctx context.Context, tx pgx.Tx, claims *auth.
 	if claims == nil {
 		return 0, errors.New("authentication required")
 	}
-	email := strings.ToLower(strings.TrimSpace(claims.Email))
+	normalizedEmail := email.Normalize(claims.Email)
 	fullName := strings.TrimSpace(claims.FullName)
-	if email == "" {
+	if normalizedEmail == "" {
 		return 0, errors.New("email claim missing")
 	}
 	if fullName == "" {
-		fullName = email
+		fullName = normalizedEmail
 	}
 
 	var participantID int64
 	err := tx.QueryRow(ctx, `
 		SELECT id
 		FROM participant_profiles
-		WHERE ($1 > 0 AND account_id = $1) OR lower(email) = $2
+		WHERE ($1 > 0 AND account_id = $1) OR lower(email) = lower($2)
 		ORDER BY CASE WHEN $1 > 0 AND account_id = $1 THEN 0 ELSE 1 END, id ASC
 		LIMIT 1
-	`, claims.AccountID, email).Scan(&participantID)
+	`, claims.AccountID, normalizedEmail).Scan(&participantID)
 	if err == nil {
 		if claims.AccountID > 0 {
 			if _, err := tx.Exec(ctx, `
 				UPDATE participant_profiles
-				SET account_id = COALESCE(account_id, $2)
+				SET account_id = COALESCE(account_id, $2),
+				    account_subject = COALESCE(account_subject, (SELECT subject FROM accounts WHERE id = $2))
 				WHERE id = $1
 			`, participantID, claims.AccountID); err != nil {
 				return 0, err
@@ -1343,7 +1353,7 @@ func ensureOwnParticipantProfileTx(ctx context.Context, tx pgx.Tx, claims *auth.
 			$4
 		)
 		RETURNING id
-	`, fullName, email, nullableAccountID(claims.AccountID), normalizeAccountRoles(claims.Roles)).Scan(&participantID)
+	`, fullName, normalizedEmail, nullableAccountID(claims.AccountID), normalizeAccountRoles(claims.Roles)).Scan(&participantID)
 	if err != nil {
 		return 0, err
 	}
@@ -1510,7 +1520,7 @@ func (h *Handler) createPublicRegistration(w http.ResponseWriter, r *http.Reques
 
 	var payload publicRegistrationPayload
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	if strings.TrimSpace(payload.FullName) == "" || strings.TrimSpace(payload.Email) == "" {
@@ -1669,8 +1679,8 @@ func (h *Handler) listOwnRegistrations(w http.ResponseWriter, r *http.Request) {
 		httpx.Error(w, http.StatusUnauthorized, "authentication required")
 		return
 	}
-	email := strings.ToLower(strings.TrimSpace(claims.Email))
-	if email == "" {
+	normalizedEmail := email.Normalize(claims.Email)
+	if normalizedEmail == "" {
 		httpx.Error(w, http.StatusBadRequest, "email claim missing")
 		return
 	}
@@ -1686,7 +1696,7 @@ func (h *Handler) listOwnRegistrations(w http.ResponseWriter, r *http.Request) {
 			       r.registered_at
 			FROM event_registrations r
 			JOIN participant_profiles p ON p.id = r.participant_id
-			WHERE (($1 > 0 AND p.account_id = $1) OR lower(p.email) = $2)
+			WHERE (($1 > 0 AND p.account_id = $1) OR lower(p.email) = lower($2))
 			ORDER BY
 				r.event_id,
 				CASE WHEN r.cancelled_at IS NULL AND r.expired_at IS NULL THEN 0 ELSE 1 END,
@@ -1694,7 +1704,7 @@ func (h *Handler) listOwnRegistrations(w http.ResponseWriter, r *http.Request) {
 				r.id DESC
 		) own_registrations
 		ORDER BY registered_at DESC, id DESC
-	`, claims.AccountID, email)
+	`, claims.AccountID, normalizedEmail)
 	if err != nil {
 		httpx.Error(w, http.StatusInternalServerError, "failed to list registrations")
 		return
@@ -1729,6 +1739,16 @@ func (h *Handler) listEventRegistrations(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	exists, err := eventExists(r.Context(), h.db, eventID)
+	if err != nil {
+		httpx.Error(w, http.StatusInternalServerError, "failed to validate event")
+		return
+	}
+	if !exists {
+		httpx.Error(w, http.StatusNotFound, "event not found")
+		return
+	}
+
 	rows, err := h.db.Query(r.Context(), `
 		SELECT `+registrationSelectColumns+`
 		FROM (
@@ -1792,7 +1812,7 @@ func (h *Handler) createRegistration(w http.ResponseWriter, r *http.Request) {
 
 	var payload registrationPayload
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	if payload.ParticipantID <= 0 {
@@ -1944,7 +1964,7 @@ func (h *Handler) updateRegistration(w http.ResponseWriter, r *http.Request) {
 
 	var payload registrationPayload
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 
@@ -1998,7 +2018,7 @@ func (h *Handler) updateRegistrationStatus(w http.ResponseWriter, r *http.Reques
 
 	var payload registrationStatusPayload
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	status, err := normalizeRegistrationStatus(payload.Status)
@@ -2061,7 +2081,7 @@ func (h *Handler) createPayment(w http.ResponseWriter, r *http.Request) {
 
 	var payload paymentPayload
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	kind, err := normalizePaymentKind(payload.Kind)
@@ -2162,7 +2182,7 @@ func (h *Handler) updatePayment(w http.ResponseWriter, r *http.Request) {
 
 	var payload paymentPayload
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	kind, err := normalizePaymentKind(payload.Kind)
@@ -2270,7 +2290,7 @@ func (h *Handler) createActivity(w http.ResponseWriter, r *http.Request) {
 
 	var payload activityPayload
 	if err := httpx.DecodeJSON(r, &payload); err != nil {
-		httpx.Error(w, http.StatusBadRequest, "invalid request payload")
+		httpx.PayloadError(w, err, "invalid request payload")
 		return
 	}
 	activityType, err := normalizeActivityType(payload.Type)
@@ -0,0 +1,288 @@
+// Package syncapi serves incremental changes to offline-capable clients. A
+// client calls GET /api/sync without a token for a full download, then passes
+// the returned token on the next call to receive only rows created or updated
+// since. Each resource keeps its own (updated_at, id) cursor inside the
+// opaque token so bulk updates sharing one timestamp page correctly. Deletes
+// are read from the tombstones table, which database triggers fill whenever a
+// synced row is removed, including by cascade.
+package syncapi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/innhopp/central/backend/httpx"
+	"github.com/innhopp/central/backend/internal/clock"
+	"github.com/innhopp/central/backend/rbac"
+)
+
+// PageSize caps the rows returned per resource in one response. When any
+// resource fills its page the response sets has_more and the client should
+// call again with the new token straight away.
+const PageSize = 500
+
+// settleDelay keeps rows written in the last few seconds out of a response.
+// updated_at is the writing transaction's start time, so a transaction still
+// in flight could otherwise commit a row behind a cursor that has already
+// moved past it.
+const settleDelay = 5 * time.Second
+
+// DefaultTombstoneRetention is how long tombstones are kept when
+// SYNC_TOMBSTONE_RETENTION is unset. Tokens older than the retention window
+// are rejected because deletes they have not seen may already be pruned.
+const DefaultTombstoneRetention = 90 * 24 * time.Hour
+
+// Querier is the subset of pgx used to read changes.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// Execer is the subset of pgx used to prune tombstones.
+type Execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// PruneTombstones deletes tombstones older than retention and returns how
+// many were removed. A non-positive retention uses the default.
+func PruneTombstones(ctx context.Context, db Execer, retention time.Duration) (int64, error) {
+	if retention <= 0 {
+		retention = DefaultTombstoneRetention
+	}
+	tag, err := db.Exec(ctx, `DELETE FROM tombstones WHERE deleted_at < NOW() - make_interval(secs => $1)`, retention.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// resource is one synced table and the permission needed to receive it.
+type resource struct {
+	name       string
+	table      string
+	permission rbac.Permission
+}
+
+var resources = []resource{
+	{name: "events", table: "events", permission: rbac.PermissionViewEvents},
+	{name: "participants", table: "participant_profiles", permission: rbac.PermissionViewParticipants},
+	{name: "manifests", table: "manifests", permission: rbac.PermissionViewManifests},
+}
+
+// Changes lists the rows of one resource that changed since the token.
+// Updated rows are returned as stored, keyed by column name; clients should
+// upsert them by id. Deleted holds ids the client should purge; an id is
+// never in both lists.
+type Changes struct {
+	Updated []json.RawMessage `json:"updated"`
+	Deleted []int64           `json:"deleted"`
+}
+
+// Response is the body of GET /api/sync. Resources the caller may not view
+// are left out.
+type Response struct {
+	Token        string   `json:"token"`
+	HasMore      bool     `json:"has_more"`
+	Events       *Changes `json:"events,omitempty"`
+	Participants *Changes `json:"participants,omitempty"`
+	Manifests    *Changes `json:"manifests,omitempty"`
+}
+
+type cursor struct {
+	UpdatedAt time.Time `json:"t"`
+	ID        int64     `json:"id"`
+}
+
+type syncToken struct {
+	Version  int               `json:"v"`
+	IssuedAt time.Time         `json:"at"`
+	Cursors  map[string]cursor `json:"c"`
+}
+
+const tokenVersion = 1
+
+var errInvalidToken = errors.New("invalid sync token")
+
+func encodeToken(t syncToken, issuedAt time.Time) string {
+	t.Version = tokenVersion
+	t.IssuedAt = issuedAt.UTC()
+	raw, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeToken parses a token; an empty one starts a full sync.
+func decodeToken(raw string) (syncToken, error) {
+	t := syncToken{Cursors: map[string]cursor{}}
+	if raw == "" {
+		return t, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return t, errInvalidToken
+	}
+	if err := json.Unmarshal(decoded, &t); err != nil || t.Version != tokenVersion {
+		return t, errInvalidToken
+	}
+	if t.Cursors == nil {
+		t.Cursors = map[string]cursor{}
+	}
+	return t, nil
+}
+
+// Handler serves the sync feed.
+type Handler struct {
+	db        Querier
+	enforcer  *rbac.Enforcer
+	retention time.Duration
+	clock     clock.Clock
+}
+
+// NewHandler creates a sync handler.
+func NewHandler(db Querier) *Handler {
+	return &Handler{db: db, retention: DefaultTombstoneRetention, clock: clock.Real{}}
+}
+
+// SetTombstoneRetention matches token expiry to how long tombstones are
+// kept. Non-positive values keep the default.
+func (h *Handler) SetTombstoneRetention(retention time.Duration) {
+	if retention > 0 {
+		h.retention = retention
+	}
+}
+
+// Routes registers the sync endpoint.
+func (h *Handler) Routes(enforcer *rbac.Enforcer) chi.Router {
+	h.enforcer = enforcer
+	r := chi.NewRouter()
+	r.With(enforcer.Authorize(rbac.PermissionViewEvents)).Get("/", h.sync)
+	return r
+}
+
+func (h *Handler) sync(w http.ResponseWriter, r *http.Request) {
+	token, err := decodeToken(r.URL.Query().Get("since"))
+	if err != nil {
+		httpx.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	now := h.clock.Now()
+	if !token.IssuedAt.IsZero() && token.IssuedAt.Before(now.Add(-h.retention)) {
+		httpx.Error(w, http.StatusGone, "sync token expired; start a full sync without since")
+		return
+	}
+
+	var resp Response
+	for _, res := range resources {
+		if !h.enforcer.Allows(r, res.permission) {
+			continue
+		}
+		changes, next, full, err := h.changes(r.Context(), res, token.Cursors[res.name], h.hiddenColumns(r, res))
+		if err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to load "+res.name+" changes")
+			return
+		}
+		token.Cursors[res.name] = next
+		resp.HasMore = resp.HasMore || full
+
+		deletedKey := res.name + ".deleted"
+		deleted, nextDeleted, full, err := h.deletions(r.Context(), res, token.Cursors[deletedKey])
+		if err != nil {
+			httpx.Error(w, http.StatusInternalServerError, "failed to load "+res.name+" deletions")
+			return
+		}
+		changes.Deleted = deleted
+		token.Cursors[deletedKey] = nextDeleted
+		resp.HasMore = resp.HasMore || full
+		switch res.name {
+		case "events":
+			resp.Events = changes
+		case "participants":
+			resp.Participants = changes
+		case "manifests":
+			resp.Manifests = changes
+		}
+	}
+	resp.Token = encodeToken(token, now)
+
+	httpx.WriteJSON(w, http.StatusOK, resp)
+}
+
+// hiddenColumns are left out of synced rows for callers who would not see
+// them through the regular API.
+func (h *Handler) hiddenColumns(r *http.Request, res resource) []string {
+	if res.name == "events" && !h.enforcer.Allows(r, rbac.PermissionManageEvents) {
+		return []string{"notes"}
+	}
+	return []string{}
+}
+
+// changes reads one page of rows after from. It returns the cursor to resume
+// from and whether the page was full.
+func (h *Handler) changes(ctx context.Context, res resource, from cursor, hidden []string) (*Changes, cursor, bool, error) {
+	rows, err := h.db.Query(ctx, `
+		SELECT id, updated_at, to_jsonb(t) - $3::text[]
+		FROM `+res.table+` t
+		WHERE (updated_at, id) > ($1, $2)
+		  AND updated_at < NOW() - make_interval(secs => $4)
+		ORDER BY updated_at, id
+		LIMIT $5`,
+		from.UpdatedAt, from.ID, hidden, settleDelay.Seconds(), PageSize,
+	)
+	if err != nil {
+		return nil, from, false, err
+	}
+	defer rows.Close()
+
+	changes := &Changes{Updated: []json.RawMessage{}}
+	next := from
+	for rows.Next() {
+		var row []byte
+		if err := rows.Scan(&next.ID, &next.UpdatedAt, &row); err != nil {
+			return nil, from, false, err
+		}
+		changes.Updated = append(changes.Updated, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, from, false, err
+	}
+	return changes, next, len(changes.Updated) == PageSize, nil
+}
+
+// deletions reads one page of tombstones for res after from. The cursor id is
+// the tombstone's own id.
+func (h *Handler) deletions(ctx context.Context, res resource, from cursor) ([]int64, cursor, bool, error) {
+	rows, err := h.db.Query(ctx, `
+		SELECT id, deleted_at, resource_id
+		FROM tombstones
+		WHERE resource = $1
+		  AND (deleted_at, id) > ($2, $3)
+		  AND deleted_at < NOW() - make_interval(secs => $4)
+		ORDER BY deleted_at, id
+		LIMIT $5`,
+		res.name, from.UpdatedAt, from.ID, settleDelay.Seconds(), PageSize,
+	)
+	if err != nil {
+		return nil, from, false, err
+	}
+	defer rows.Close()
+
+	deleted := []int64{}
+	next := from
+	for rows.Next() {
+		var resourceID int64
+		if err := rows.Scan(&next.ID, &next.UpdatedAt, &resourceID); err != nil {
+			return nil, from, false, err
+		}
+		deleted = append(deleted, resourceID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, from, false, err
+	}
+	return deleted, next, len(deleted) == PageSize, nil
+}
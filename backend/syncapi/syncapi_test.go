@@ -0,0 +1,127 @@
+package syncapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/innhopp/central/backend/internal/clock"
+	"github.com/innhopp/central/backend/rbac"
+)
+
+type recordingQuerier struct {
+	tables     []string
+	args       [][]any
+	tombstones []string
+}
+
+func (q *recordingQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if strings.Contains(sql, "FROM tombstones") {
+		q.tombstones = append(q.tombstones, args[0].(string))
+		return emptyRows{}, nil
+	}
+	for _, res := range resources {
+		if strings.Contains(sql, "FROM "+res.table+" t") {
+			q.tables = append(q.tables, res.table)
+			q.args = append(q.args, args)
+		}
+	}
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Close()                                       {}
+func (emptyRows) Err() error                                   { return nil }
+func (emptyRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (emptyRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (emptyRows) Next() bool                                   { return false }
+func (emptyRows) Scan(dest ...any) error                       { return nil }
+func (emptyRows) Values() ([]any, error)                       { return nil, nil }
+func (emptyRows) RawValues() [][]byte                          { return nil }
+func (emptyRows) Conn() *pgx.Conn                              { return nil }
+
+func TestTokenRoundTripKeepsPerResourceCursors(t *testing.T) {
+	at := time.Date(2025, 6, 14, 9, 0, 0, 0, time.UTC)
+	token := encodeToken(syncToken{Cursors: map[string]cursor{"events": {UpdatedAt: at, ID: 7}}}, at)
+
+	got, err := decodeToken(token)
+	if err != nil {
+		t.Fatalf("decodeToken() error: %v", err)
+	}
+	if c := got.Cursors["events"]; !c.UpdatedAt.Equal(at) || c.ID != 7 || !got.IssuedAt.Equal(at) {
+		t.Fatalf("token = %+v, want events cursor %v/7 issued at %v", got, at, at)
+	}
+	if _, err := decodeToken("not-a-token"); err == nil {
+		t.Fatal("decodeToken() accepted garbage")
+	}
+}
+
+func TestSyncSkipsResourcesTheCallerCannotView(t *testing.T) {
+	db := &recordingQuerier{}
+	h := NewHandler(db)
+	router := h.Routes(rbac.NewEnforcer(func(*http.Request) []rbac.Role { return []rbac.Role{rbac.RoleParticipant} }))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("sync status = %d body=%s", rec.Code, rec.Body.String())
+	}
+	var resp map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if _, ok := resp["participants"]; ok {
+		t.Fatalf("participant role received participants: %s", rec.Body.String())
+	}
+	if _, ok := resp["events"]; !ok || resp["token"] == nil {
+		t.Fatalf("response lacks events or token: %s", rec.Body.String())
+	}
+	for i, table := range db.tables {
+		if table == "events" {
+			if hidden := db.args[i][2].([]string); len(hidden) != 1 || hidden[0] != "notes" {
+				t.Fatalf("event notes not hidden from participant: %v", hidden)
+			}
+		}
+	}
+
+	if len(db.tombstones) != len(db.tables) || db.tombstones[0] != "events" {
+		t.Fatalf("tombstones read for %v, want one lookup per synced resource %v", db.tombstones, db.tables)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?since=bogus", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("sync with bad token status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestSyncRejectsTokensOlderThanTombstoneRetention(t *testing.T) {
+	now := time.Date(2025, 6, 14, 9, 0, 0, 0, time.UTC)
+	h := NewHandler(&recordingQuerier{})
+	h.SetTombstoneRetention(24 * time.Hour)
+	h.clock = clock.Fixed{At: now}
+	router := h.Routes(rbac.NewEnforcer(func(*http.Request) []rbac.Role { return []rbac.Role{rbac.RoleStaff} }))
+
+	for _, tc := range []struct {
+		issued time.Time
+		want   int
+	}{
+		{now.Add(-23 * time.Hour), http.StatusOK},
+		{now.Add(-25 * time.Hour), http.StatusGone},
+	} {
+		token := encodeToken(syncToken{Cursors: map[string]cursor{}}, tc.issued)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?since="+token, nil))
+		if rec.Code != tc.want {
+			t.Fatalf("token issued %v: status = %d, want %d", tc.issued, rec.Code, tc.want)
+		}
+	}
+}
@@ -31,6 +31,15 @@ func RequestID(next http.Handler) http.Handler {
 	})
 }
 
+// GetReqID returns the request ID stored by RequestID, or an empty string.
+func GetReqID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
 // RealIP attempts to determine the client IP from standard headers.
 func RealIP(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
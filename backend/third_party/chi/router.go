@@ -29,6 +29,7 @@ type mux struct {
 
 type route struct {
 	method   string
+	pattern  string
 	segments []segment
 	handler  http.Handler
 	mws      []Middleware
@@ -43,11 +44,20 @@ type segment struct {
 type mount struct {
 	prefix  string
 	handler http.Handler
+	target  http.Handler
+	mws     []Middleware
 }
 
 type paramsKey struct{}
 
 // NewRouter constructs a new lightweight chi-compatible router.
+//
+// Trailing slashes are not significant: a request for "/events/" is served by
+// the route registered as "/events", and the other way round, both on this
+// router and inside routers mounted on it. No redirect is issued, so the
+// method and body of a POST or PUT reach the handler unchanged. Empty
+// segments inside a path ("/events//5") never match, so a path parameter is
+// never empty.
 func NewRouter() Router {
 	return &mux{}
 }
@@ -112,13 +122,13 @@ func (m *mux) addRoute(method, pattern string, handler http.HandlerFunc) {
 
 func (m *mux) addRouteWithMiddlewares(method, pattern string, handler http.HandlerFunc, middlewares []Middleware) {
 	segments := parsePattern(pattern)
-	m.routes = append(m.routes, route{method: method, segments: segments, handler: handler, mws: middlewares})
+	m.routes = append(m.routes, route{method: method, pattern: cleanPattern(pattern), segments: segments, handler: handler, mws: middlewares})
 }
 
 func (m *mux) mountWithMiddlewares(pattern string, h http.Handler, middlewares []Middleware) {
 	prefix := cleanPattern(pattern)
 	wrapped := applyMiddlewares(h, middlewares)
-	m.mounts = append(m.mounts, mount{prefix: prefix, handler: wrapped})
+	m.mounts = append(m.mounts, mount{prefix: prefix, handler: wrapped, target: h, mws: middlewares})
 }
 
 func (m *mux) matchMount(r *http.Request) (http.Handler, *http.Request) {
@@ -173,13 +183,15 @@ func parsePattern(pattern string) []segment {
 	return segments
 }
 
+// matchSegments matches path against a parsed pattern, ignoring leading and
+// trailing slashes as described on NewRouter.
 func matchSegments(segments []segment, path string) (map[string]string, bool) {
+	trimmed := strings.Trim(path, "/")
 	if len(segments) == 0 {
-		cleaned := cleanPattern(path)
-		return nil, cleaned == "/"
+		return nil, trimmed == ""
 	}
 
-	parts := strings.Split(strings.Trim(cleanPattern(path), "/"), "/")
+	parts := strings.Split(trimmed, "/")
 	if len(parts) != len(segments) {
 		return nil, false
 	}
@@ -187,6 +199,9 @@ func matchSegments(segments []segment, path string) (map[string]string, bool) {
 	params := make(map[string]string, len(segments))
 	for i, seg := range segments {
 		part := parts[i]
+		if part == "" {
+			return nil, false
+		}
 		if seg.isParam {
 			params[seg.key] = part
 			continue
@@ -220,6 +235,50 @@ func applyMiddlewares(handler http.Handler, middlewares []Middleware) http.Handl
 	return h
 }
 
+// WalkFunc is invoked by Walk for every registered route. Middlewares are
+// listed outermost first and include those inherited from parent routers.
+type WalkFunc func(method string, route string, handler http.Handler, middlewares ...Middleware) error
+
+// Walk visits every route registered on r, descending into mounted
+// sub-routers. Mounted handlers that are not routers are reported once with
+// the method "*".
+func Walk(r Router, walkFn WalkFunc) error {
+	return walk(r, "", nil, walkFn)
+}
+
+func walk(h http.Handler, prefix string, inherited []Middleware, walkFn WalkFunc) error {
+	var m *mux
+	switch router := h.(type) {
+	case *mux:
+		m = router
+	case *scopedMux:
+		m = router.mux
+	default:
+		return walkFn("*", prefix+"/*", h, inherited...)
+	}
+
+	for _, rt := range m.routes {
+		mws := append(append([]Middleware{}, inherited...), rt.mws...)
+		if err := walkFn(rt.method, joinRoute(prefix, rt.pattern), rt.handler, mws...); err != nil {
+			return err
+		}
+	}
+	for _, mt := range m.mounts {
+		mws := append(append([]Middleware{}, inherited...), mt.mws...)
+		if err := walk(mt.target, joinRoute(prefix, mt.prefix), mws, walkFn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func joinRoute(prefix, pattern string) string {
+	if pattern == "/" && prefix != "" {
+		return prefix + "/"
+	}
+	return prefix + pattern
+}
+
 // URLParam fetches a path parameter populated by the router.
 func URLParam(r *http.Request, key string) string {
 	params, _ := r.Context().Value(paramsKey{}).(map[string]string)
@@ -0,0 +1,67 @@
+package chi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serve(t *testing.T, h http.Handler, method, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	return rec
+}
+
+func TestTrailingSlashMatchesDirectRoutes(t *testing.T) {
+	r := NewRouter()
+	r.Get("/events", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("list")) })
+	r.Post("/events/{eventID}/", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(URLParam(r, "eventID"))) })
+
+	cases := []struct {
+		method, path, want string
+	}{
+		{http.MethodGet, "/events", "list"},
+		{http.MethodGet, "/events/", "list"},
+		{http.MethodPost, "/events/7", "7"},
+		{http.MethodPost, "/events/7/", "7"},
+	}
+	for _, tc := range cases {
+		rec := serve(t, r, tc.method, tc.path)
+		if rec.Code != http.StatusOK || rec.Body.String() != tc.want {
+			t.Errorf("%s %s = %d %q, want 200 %q", tc.method, tc.path, rec.Code, rec.Body.String(), tc.want)
+		}
+	}
+}
+
+func TestTrailingSlashMatchesMountedRoutes(t *testing.T) {
+	sub := NewRouter()
+	sub.Get("/", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("root")) })
+	sub.Get("/events/{eventID}", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(URLParam(r, "eventID"))) })
+	r := NewRouter()
+	r.Mount("/api/events/", sub)
+
+	cases := map[string]string{
+		"/api/events":           "root",
+		"/api/events/":          "root",
+		"/api/events/events/5":  "5",
+		"/api/events/events/5/": "5",
+	}
+	for path, want := range cases {
+		rec := serve(t, r, http.MethodGet, path)
+		if rec.Code != http.StatusOK || rec.Body.String() != want {
+			t.Errorf("GET %s = %d %q, want 200 %q", path, rec.Code, rec.Body.String(), want)
+		}
+	}
+}
+
+func TestEmptySegmentsDoNotMatch(t *testing.T) {
+	r := NewRouter()
+	r.Get("/profiles/{profileID}/notes", func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, path := range []string{"/profiles//notes", "/profiles/notes"} {
+		if rec := serve(t, r, http.MethodGet, path); rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", path, rec.Code)
+		}
+	}
+}
@@ -2,7 +2,7 @@ const defaultBaseUrl = '/api';
 
 const buildHeaders = (init?: RequestInit) => {
   const headers = new Headers(init?.headers || {});
-  if (!headers.has('Content-Type') && init?.body) {
+  if (!headers.has('Content-Type') && init?.body && !(init.body instanceof FormData)) {
     headers.set('Content-Type', 'application/json');
   }
   return headers;
@@ -36,3 +36,17 @@ export const apiRequest = async <T>(path: string, init?: RequestInit): Promise<T
 
   return payload as T;
 };
+
+export interface DownloadToken {
+  token: string;
+  url: string;
+  expires_at: string;
+}
+
+// Issues a short-lived token so a protected file can be loaded where headers
+// and cookies are unavailable, e.g. an <img> src. `path` is relative to /api.
+export const requestDownloadToken = (path: string) =>
+  apiRequest<DownloadToken>('/downloads/token', {
+    method: 'POST',
+    body: JSON.stringify({ path: `/api${path}` })
+  });
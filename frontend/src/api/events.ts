@@ -13,6 +13,8 @@ export interface Season {
   name: string;
   starts_on: string;
   ends_on?: string | null;
+  event_count: number;
+  upcoming_event_count: number;
   created_at: string;
 }
 
@@ -95,17 +97,24 @@ export interface Innhopp {
   land_owners?: LandOwner[];
   land_owner_permission?: boolean | null;
   image_files?: InnhoppImage[];
+  version: number;
   created_at: string;
 }
 
 export interface Event {
   id: number;
   season_id: number;
+  season_ids?: number[] | null;
+  client_id?: string;
   name: string;
   location?: string;
+  base_airfield_id?: number | null;
+  base_airfield_name?: string;
   slots: number;
   remaining_slots: number;
   status: EventStatus;
+  all_day: boolean;
+  tags: string[];
   starts_at: string;
   ends_at?: string | null;
   public_registration_slug?: string | null;
@@ -117,10 +126,13 @@ export interface Event {
   currency?: string | null;
   minimum_deposit_count: number;
   commercial_status: EventCommercialStatus;
+  briefing?: string;
+  notes?: string;
   airfield_ids: number[];
   participant_ids: number[];
   aircraft: EventAircraft[];
   innhopps: Innhopp[];
+  archived?: boolean;
   created_at: string;
 }
 
@@ -145,10 +157,15 @@ export interface CreateSeasonPayload {
 
 export interface CreateEventPayload {
   season_id: number;
+  season_ids?: number[];
+  client_id?: string;
   name: string;
   location?: string;
+  base_airfield_id?: number | null;
   slots?: number;
   status?: EventStatus;
+  all_day?: boolean;
+  tags?: string[];
   starts_at: string;
   ends_at?: string;
   public_registration_slug?: string;
@@ -160,6 +177,8 @@ export interface CreateEventPayload {
   currency?: string;
   minimum_deposit_count?: number;
   commercial_status?: EventCommercialStatus;
+  briefing?: string;
+  notes?: string;
   airfield_ids?: number[];
   participant_ids?: number[];
   aircraft?: AircraftInput[];
@@ -174,7 +193,33 @@ export const createSeason = (payload: CreateSeasonPayload) =>
 export const deleteSeason = (id: number) =>
   apiRequest<void>(`/events/seasons/${id}`, { method: 'DELETE' });
 
-export const listEvents = () => apiRequest<Event[]>('/events/events');
+export interface EventPage {
+  items: Event[];
+  total: number;
+  limit: number;
+  offset: number;
+}
+
+const eventPageSize = 200;
+
+// include names the relations to attach (participants, innhopps, aircraft,
+// airfields, seasons, remaining_slots); screens ask only for what they show.
+export const listEventsPage = (include = 'none', offset = 0, limit = eventPageSize) =>
+  apiRequest<EventPage>(
+    `/events/events?include=${encodeURIComponent(include)}&limit=${limit}&offset=${offset}`
+  );
+
+// listEvents walks every page of the event list.
+export const listEvents = async (include = 'none') => {
+  const events: Event[] = [];
+  for (;;) {
+    const page = await listEventsPage(include, events.length);
+    events.push(...page.items);
+    if (page.items.length === 0 || events.length >= page.total) {
+      return events;
+    }
+  }
+};
 
 export const createEvent = (payload: CreateEventPayload) =>
   apiRequest<Event>('/events/events', { method: 'POST', body: JSON.stringify(payload) });
@@ -218,6 +263,7 @@ export const deleteAccommodation = (eventId: number, accId: number) =>
   apiRequest<void>(`/events/events/${eventId}/accommodations/${accId}`, { method: 'DELETE' });
 
 export interface InnhoppInput {
+  id?: number;
   sequence?: number;
   name: string;
   aircraft_id?: number;
@@ -298,6 +344,7 @@ export interface UpdateInnhoppPayload {
   land_owners?: LandOwner[];
   land_owner_permission?: boolean;
   image_files?: InnhoppImage[];
+  version?: number;
 }
 
 export interface UpdateEventPayload extends Partial<CreateEventPayload> {
@@ -331,6 +378,20 @@ export const updateInnhopp = (id: number, payload: UpdateInnhoppPayload) =>
 export const deleteInnhopp = (id: number) =>
   apiRequest<void>(`/innhopps/${id}`, { method: 'DELETE' });
 
+export interface InnhoppImageList {
+  version: number;
+  images: InnhoppImage[];
+}
+
+export const uploadInnhoppImages = (id: number, files: File[]) => {
+  const body = new FormData();
+  files.forEach((file) => body.append('files', file));
+  return apiRequest<InnhoppImageList>(`/innhopps/${id}/images`, { method: 'POST', body });
+};
+
+export const deleteInnhoppImage = (id: number, name: string) =>
+  apiRequest<InnhoppImageList>(`/innhopps/${id}/images/${encodeURIComponent(name)}`, { method: 'DELETE' });
+
 export interface Manifest {
   id: number;
   event_id: number;
@@ -339,6 +400,7 @@ export interface Manifest {
   staff_slots?: number | null;
   notes?: string | null;
   participant_ids: number[];
+  client_id?: string;
   created_at: string;
 }
 
@@ -349,6 +411,7 @@ export interface CreateManifestPayload {
   staff_slots?: number;
   notes?: string;
   participant_ids?: number[];
+  client_id?: string;
 }
 
 export const listManifests = () => apiRequest<Manifest[]>('/events/manifests');
@@ -31,6 +31,8 @@ export interface ParticipantProfile {
   medical_expertise: string[];
   hss_qualities: string[];
   account_roles: string[];
+  photo_url?: string;
+  client_id?: string;
   created_at: string;
 }
 
@@ -67,6 +69,7 @@ export interface CreateParticipantPayload {
   medical_expertise?: string[];
   hss_qualities?: string[];
   account_roles?: string[];
+  client_id?: string;
 }
 
 export const createParticipantProfile = (payload: CreateParticipantPayload) =>
@@ -97,3 +100,15 @@ export const deleteParticipantProfile = (id: number) =>
   apiRequest<void>(`/participants/profiles/${id}`, {
     method: 'DELETE'
   });
+
+export interface FutureAssignmentsResult {
+  removed: number;
+  replaced: number;
+  events: { id: number; name: string; manifest_ids: number[] }[];
+}
+
+export const removeFutureAssignments = (id: number, replacementId?: number) =>
+  apiRequest<FutureAssignmentsResult>(
+    `/participants/profiles/${id}/assignments/future${replacementId ? `?replacement_id=${replacementId}` : ''}`,
+    { method: 'DELETE' }
+  );
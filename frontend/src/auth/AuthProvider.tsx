@@ -10,6 +10,8 @@ type ImpersonatorSession = {
 
 export type AuthSession = ImpersonatorSession & {
   impersonator?: ImpersonatorSession;
+  pending_approval?: boolean;
+  landing_route?: string;
 };
 
 type LoginResponse = {
@@ -255,7 +255,17 @@ const Layout = () => {
               </div>
             </section>
           )}
-          <Outlet key={location.pathname} />
+          {user?.pending_approval ? (
+            <section className="card">
+              <h2>Account pending approval</h2>
+              <p className="muted">
+                An administrator needs to approve your account before you can continue. Check back
+                later or contact the organisers.
+              </p>
+            </section>
+          ) : (
+            <Outlet key={location.pathname} />
+          )}
         </main>
       </div>
     </div>
@@ -140,7 +140,7 @@ const EventAccountingPage = () => {
 
     const loadFilters = async () => {
       try {
-        const [seasonResp, eventResp] = await Promise.all([listSeasons(), listEvents()]);
+        const [seasonResp, eventResp] = await Promise.all([listSeasons(), listEvents('remaining_slots')]);
         if (cancelled) return;
         setSeasons(Array.isArray(seasonResp) ? seasonResp : []);
         setEvents(Array.isArray(eventResp) ? eventResp : []);
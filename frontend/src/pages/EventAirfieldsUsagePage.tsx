@@ -26,7 +26,7 @@ const EventAirfieldsUsagePage = () => {
       setLoading(true);
       setError(null);
       try {
-        const [eventResp, airfieldResp] = await Promise.all([listEvents(), listAirfields()]);
+        const [eventResp, airfieldResp] = await Promise.all([listEvents('innhopps'), listAirfields()]);
         if (cancelled) return;
         setEvents(Array.isArray(eventResp) ? eventResp : []);
         setAirfields(Array.isArray(airfieldResp) ? airfieldResp : []);
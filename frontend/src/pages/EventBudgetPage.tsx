@@ -481,7 +481,7 @@ const EventBudgetPage = () => {
     const loadFilters = async () => {
       setLoadingFilters(true);
       try {
-        const [seasonResp, eventResp, airfieldResp] = await Promise.all([listSeasons(), listEvents(), listAirfields()]);
+        const [seasonResp, eventResp, airfieldResp] = await Promise.all([listSeasons(), listEvents('innhopps,aircraft'), listAirfields()]);
         if (cancelled) return;
         setSeasons(Array.isArray(seasonResp) ? seasonResp : []);
         setEvents(Array.isArray(eventResp) ? eventResp : []);
@@ -163,7 +163,7 @@ const EventCalendarPage = () => {
       ((error as { status?: number }).status === 401 || (error as { status?: number }).status === 403);
 
     const loadCalendarData = async () => {
-      const [seasonResponse, eventResponse] = await Promise.all([listSeasons(), listEvents()]);
+      const [seasonResponse, eventResponse] = await Promise.all([listSeasons(), listEvents('participants,innhopps,remaining_slots')]);
       if (cancelled) return;
       setSeasons(Array.isArray(seasonResponse) ? seasonResponse : []);
       setEvents(normalizeEvents(eventResponse as Event[]));
@@ -373,7 +373,7 @@ const EventCalendarPage = () => {
     : 'All seasons';
 
   const refreshCalendarData = async () => {
-    const [seasonResponse, eventResponse] = await Promise.all([listSeasons(), listEvents()]);
+    const [seasonResponse, eventResponse] = await Promise.all([listSeasons(), listEvents('participants,innhopps,remaining_slots')]);
     setSeasons(Array.isArray(seasonResponse) ? seasonResponse : []);
     setEvents(normalizeEvents(eventResponse as Event[]));
   };
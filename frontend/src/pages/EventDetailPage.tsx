@@ -76,6 +76,7 @@ const hasText = (value?: string | null) => !!value && value.trim().length > 0;
 
 type InnhoppFormRow = {
   id?: number;
+  version?: number;
   sequence: number;
   name: string;
   aircraft_id?: number;
@@ -283,6 +284,7 @@ const normalizeInnhopps = (event: Event): InnhoppFormRow[] => {
   const defaultScheduled = defaultStart ? toLocalInputFromDate(defaultStart) : '';
   return (Array.isArray(event.innhopps) ? event.innhopps : []).map((i, idx) => ({
     id: i.id,
+    version: i.version,
     sequence: i.sequence ?? idx + 1,
     name: i.name || '',
     aircraft_id: i.aircraft_id || undefined,
@@ -836,7 +838,7 @@ const missingOtherCoords = !hasText(otherForm.coordinates);
     let cancelled = false;
     const loadEvents = async () => {
       try {
-        const resp = await listEvents();
+        const resp = await listEvents('airfields');
         if (!cancelled && Array.isArray(resp)) {
           setAllEvents(resp);
         }
@@ -1232,6 +1234,8 @@ const missingOtherCoords = !hasText(otherForm.coordinates);
         innhopps: (nextInnhopps ?? innhopps)
           .filter((row) => row.name.trim() !== '')
           .map<InnhoppInput>((row, idx) => ({
+            id: row.id,
+            version: row.version,
             sequence: row.sequence || idx + 1,
             name: row.name.trim(),
             aircraft_id: row.aircraft_id,
@@ -1312,7 +1312,7 @@ const EventSchedulePage = () => {
               data: img.data
             }))
           };
-          await updateInnhopp(numericId, payload);
+          await updateInnhopp(numericId, { ...payload, version: full.version });
         } else if (entry.type === 'Transport') {
           const full = await getTransport(numericId);
           const vehicleIds =
@@ -44,7 +44,7 @@ const InnhoppCsvPage = () => {
       try {
         setLoading(true);
         setError(null);
-        const events = await listEvents();
+        const events = await listEvents('innhopps');
         if (!active) return;
         const eventOptions = events
           .map((event) => ({ id: event.id, name: event.name }))
@@ -593,7 +593,7 @@ const InnhoppDetailPage = () => {
     let cancelled = false;
     const loadAirfieldContext = async () => {
       try {
-        const [airfieldData, eventData] = await Promise.all([listAirfields(), listEvents()]);
+        const [airfieldData, eventData] = await Promise.all([listAirfields(), listEvents('airfields')]);
         if (!cancelled) {
           setAirfields(Array.isArray(airfieldData) ? airfieldData : []);
           setAllEvents(Array.isArray(eventData) ? eventData : []);
@@ -1109,7 +1109,7 @@ const InnhoppDetailPage = () => {
         setInnhopp(created);
         navigate(`/events/${eventId}/innhopps/${created.id}`, { replace: true, state: {} });
       } else {
-        const updated = await updateInnhopp(innhopp.id, payload);
+        const updated = await updateInnhopp(innhopp.id, { ...payload, version: innhopp.version });
         setInnhopp(updated);
       }
       if (isCreateMode || imagesDirty) {
@@ -1137,7 +1137,7 @@ const InnhoppDetailPage = () => {
     try {
       const payload = buildPayload();
       payload.image_files = buildImagePayload(nextImages);
-      const updated = await updateInnhopp(innhopp.id, payload);
+      const updated = await updateInnhopp(innhopp.id, { ...payload, version: innhopp.version });
       setInnhopp(updated);
       setForm((prev) => ({ ...prev, image_files: updated.image_files || nextImages }));
       setSaved(true);
@@ -1200,7 +1200,7 @@ const InnhoppDetailPage = () => {
           target === 'takeoff'
             ? buildPayload({ takeoff_airfield_id: created.id })
             : buildPayload({ landing_airfield_id: created.id });
-        const updated = await updateInnhopp(innhopp.id, payload);
+        const updated = await updateInnhopp(innhopp.id, { ...payload, version: innhopp.version });
         setInnhopp(updated);
         setForm((prev) => ({
           ...prev,
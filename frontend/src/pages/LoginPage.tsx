@@ -14,7 +14,7 @@ const LoginPage = () => {
   }, [user]);
 
   if (!isLoading && user) {
-    return <Navigate to={typeof from === 'string' ? from : '/events'} replace />;
+    return <Navigate to={typeof from === 'string' ? from : user.landing_route || '/events'} replace />;
   }
 
   const handleLogin = async () => {
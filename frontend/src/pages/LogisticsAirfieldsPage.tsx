@@ -22,7 +22,7 @@ const LogisticsAirfieldsPage = () => {
       try {
         const [seasonResp, eventResp, airfieldResp] = await Promise.all([
           listSeasons(),
-          listEvents(),
+          listEvents('airfields'),
           listAirfields()
         ]);
         if (cancelled) return;
@@ -100,7 +100,7 @@ const LogisticsCreatePage = () => {
     const loadEventsAndVehicles = async () => {
       try {
         const [resp, vehiclesResp, airfieldResp] = await Promise.all([
-          listEvents(),
+          listEvents('innhopps,airfields'),
           listEventVehicles(),
           listAirfields()
         ]);
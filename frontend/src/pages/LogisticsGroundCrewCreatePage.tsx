@@ -97,7 +97,7 @@ const LogisticsGroundCrewCreatePage = () => {
     const loadEventsAndVehicles = async () => {
       try {
         const [resp, vehiclesResp, airfieldResp] = await Promise.all([
-          listEvents(),
+          listEvents('innhopps,airfields'),
           listEventVehicles(),
           listAirfields()
         ]);
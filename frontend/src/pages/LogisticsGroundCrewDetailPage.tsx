@@ -88,7 +88,7 @@ const LogisticsGroundCrewDetailPage = () => {
         initialEventSet.current = true;
         const transport = await getGroundCrew(Number(groundCrewId));
         const [eventList, eventVehicles, airfieldList, accommodationList, otherList, mealList] = await Promise.all([
-          listEvents(),
+          listEvents('innhopps,airfields'),
           listEventVehicles(),
           listAirfields(),
           transport.event_id ? listAccommodations(transport.event_id) : Promise.resolve([]),
@@ -29,7 +29,7 @@ const LogisticsSummaryPage = () => {
       try {
         const [seasonResp, eventResp, transportResp, groundCrewResp, accResp, vehResp, otherResp, mealsResp, airfieldResp] = await Promise.all([
           listSeasons(),
-          listEvents(),
+          listEvents('airfields'),
           listTransports(),
           listGroundCrews(),
           listAllAccommodations(),
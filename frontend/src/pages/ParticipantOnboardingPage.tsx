@@ -74,7 +74,7 @@ const ParticipantOnboardingPage = () => {
       try {
         const [seasonResp, eventResp, participantResp] = await Promise.all([
           listSeasons(),
-          listEvents(),
+          listEvents('participants'),
           listParticipantProfiles()
         ]);
         if (cancelled) return;